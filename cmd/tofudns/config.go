@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tofudns/tofudns/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultURL is the API URL of profiles that don't set one
+	defaultURL = "https://tofudns.net"
+	// defaultProfile is the profile used when none is selected
+	defaultProfile = "default"
+)
+
+// config is the CLI configuration, a set of named profiles
+type config struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*profile `yaml:"profiles,omitempty"`
+}
+
+// profile is a TofuDNS instance and the API token used with it
+type profile struct {
+	URL   string `yaml:"url,omitempty"`
+	Token string `yaml:"token,omitempty"`
+}
+
+// defaultConfigPath returns the path of the config file in the user's
+// config directory
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "tofudns", "config.yaml"), nil
+}
+
+// loadConfig loads the config file, which may not exist yet
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// save writes the config file. It holds API tokens, so only the user can
+// read it.
+func (cfg *config) save(path string) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// profile returns the named profile, or the current profile if the name is
+// empty. Missing profiles are returned empty.
+func (cfg *config) profile(name string) (string, *profile) {
+	if name == "" {
+		name = cfg.CurrentProfile
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	if p, ok := cfg.Profiles[name]; ok {
+		return name, p
+	}
+	return name, &profile{}
+}
+
+// runLogin verifies an API token and stores it in the profile, making it the
+// current profile
+func runLogin(ctx context.Context, c *cli, args []string) error {
+	flags := c.flagSet("login")
+	name, p := c.config.profile(c.profile)
+	url := flags.String("url", p.URL, "TofuDNS URL (default "+defaultURL+")")
+	token := flags.String("token", "", "API token, read from standard input if not given")
+	if _, err := parseArgs(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *token == "" {
+		fmt.Fprintln(c.errOut, "Create an API token on your account page, then paste it here.")
+		fmt.Fprint(c.errOut, "API token: ")
+		line, err := c.in.ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+		*token = strings.TrimSpace(line)
+	}
+	if *token == "" {
+		return fmt.Errorf("an API token is required")
+	}
+
+	// Check the token before saving it
+	baseURL := *url
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+	api, err := client.New(baseURL, *token, client.WithUserAgent("tofudns-cli"))
+	if err != nil {
+		return err
+	}
+	if _, err := api.ListZones(ctx); err != nil {
+		return fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	c.config.setProfile(name, &profile{URL: *url, Token: *token})
+	c.config.CurrentProfile = name
+	if err := c.config.save(c.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.errOut, "Logged in, token stored in profile %q of %s\n", name, c.configPath)
+	return nil
+}
+
+// setProfile sets the named profile
+func (cfg *config) setProfile(name string, p *profile) {
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*profile)
+	}
+	cfg.Profiles[name] = p
+}
@@ -0,0 +1,229 @@
+// Command tofudns manages TofuDNS zones and records from the command line.
+//
+// Usage:
+//
+//	tofudns [-profile name] [-o table|json|yaml] <command> [flags] [args]
+//
+// Run tofudns help for the list of commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/tofudns/tofudns/pkg/client"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// errUsage is returned when a command is used incorrectly, after its usage
+// has been printed
+var errUsage = errors.New("invalid usage")
+
+// command is a subcommand of the CLI
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+// commands are the commands of the CLI, by their full name. They are set up
+// in init, as the commands look up their own usage.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"login", "[-url url] [-token token]", "Store an API token in the profile", runLogin},
+		{"zone list", "", "List zones", runZoneList},
+		{"zone create", "<zone>", "Create a zone", runZoneCreate},
+		{"zone soa", "<zone> <refresh> <retry> <expire> <minimum-ttl>", "Set the timers of a zone's SOA record, in seconds", runZoneSOA},
+		{"zone delete", "[-yes] <zone>", "Delete a zone and its records", runZoneDelete},
+		{"zone export", "[-file path] <zone>", "Write a zone's records as a zone file", runZoneExport},
+		{"zone import", "[-with-soa-ns] <zone> <file>", "Create a zone if needed and load a zone file into it", runZoneImport},
+		{"zone diff", "[-with-soa-ns] <zone> <file>", "Show the changes applying a zone file would make", runZoneDiff},
+		{"zone apply", "[-yes] [-with-soa-ns] <zone> <file>", "Converge a zone to a zone file", runZoneApply},
+		{"record list", "[-name name] [-prefix prefix] [-type type] [-content text] [-sort order] <zone>", "List the records of a zone", runRecordList},
+		{"record add", "[-ttl seconds] <zone> <name> <type> <rdata>...", "Add records", runRecordAdd},
+		{"record set", "[-ttl seconds] <zone> <name> <type> <rdata>...", "Replace the records of a name and type", runRecordSet},
+		{"record rm", "<zone> <id>[@version]...", "Delete records by ID, and version if given", runRecordRm},
+		{"search", "<text>", "Find records in all zones by name or content, such as an IP address", runSearch},
+		{"replace", "[-zone zone] [-yes] <type> <value> <replacement>", "Replace a value, such as an IP address, in the records of a type in all zones", runReplace},
+		{"token list", "", "List API tokens", runTokenList},
+		{"token rm", "<id>", "Delete an API token", runTokenRm},
+	}
+}
+
+// cli is the state shared by the commands
+type cli struct {
+	in      *bufio.Reader
+	out     io.Writer
+	errOut  io.Writer
+	output  string
+	profile string
+	config  *config
+	// configPath is where the config is loaded from and saved to
+	configPath string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tofudns:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command given by the arguments
+func run(ctx context.Context, args []string, in io.Reader, out, errOut io.Writer) error {
+	flags := flag.NewFlagSet("tofudns", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	profile := flags.String("profile", os.Getenv("TOFUDNS_PROFILE"), "config profile to use")
+	output := flags.String("o", outputTable, "output format: table, json or yaml")
+	configPath := flags.String("config", os.Getenv("TOFUDNS_CONFIG"), "config file path")
+	flags.Usage = func() { printUsage(errOut) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch *output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	c := &cli{
+		in:         bufio.NewReader(in),
+		out:        out,
+		errOut:     errOut,
+		output:     *output,
+		profile:    *profile,
+		configPath: *configPath,
+	}
+	if c.configPath == "" {
+		path, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		c.configPath = path
+	}
+	cfg, err := loadConfig(c.configPath)
+	if err != nil {
+		return err
+	}
+	c.config = cfg
+
+	args = flags.Args()
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(out)
+		return nil
+	}
+
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(errOut, "unknown command %q\n\n", strings.Join(args, " "))
+		printUsage(errOut)
+		return errUsage
+	}
+	return cmd.run(ctx, c, rest)
+}
+
+// findCommand returns the command named by the leading arguments and the
+// remaining arguments
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+// printUsage prints the commands of the CLI
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tofudns [-profile name] [-o table|json|yaml] [-config path] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The API URL and token are read from the profile, and can be overridden")
+	fmt.Fprintln(w, "with TOFUDNS_URL and TOFUDNS_TOKEN.")
+}
+
+// flagSet returns the flag set of a command
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	cmd, _, _ := findCommand(strings.Fields(name))
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.errOut)
+	flags.Usage = func() {
+		fmt.Fprintf(c.errOut, "Usage: tofudns %s %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses the flags of a command and checks the number of its
+// remaining arguments, at least min and at most max unless max is negative
+func parseArgs(flags *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	rest := flags.Args()
+	if len(rest) < min || (max >= 0 && len(rest) > max) {
+		flags.Usage()
+		return nil, errUsage
+	}
+	return rest, nil
+}
+
+// client returns an API client for the selected profile
+func (c *cli) client() (*client.Client, error) {
+	name, p := c.config.profile(c.profile)
+	url, token := p.URL, p.Token
+	if env := os.Getenv("TOFUDNS_URL"); env != "" {
+		url = env
+	}
+	if env := os.Getenv("TOFUDNS_TOKEN"); env != "" {
+		token = env
+	}
+	if url == "" {
+		url = defaultURL
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no API token for profile %q, run tofudns login", name)
+	}
+	return client.New(url, token, client.WithUserAgent("tofudns-cli"))
+}
+
+// confirm asks the user to confirm an action
+func (c *cli) confirm(prompt string) (bool, error) {
+	fmt.Fprintf(c.errOut, "%s [y/N] ", prompt)
+	answer, err := c.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testToken = "tofu_test"
+
+// testZoneJSON is the zone served by the test API
+const testZoneJSON = `{
+	"name": "example.org.",
+	"name_unicode": "example.org",
+	"status": "active",
+	"created_at": "2026-03-01T12:00:00Z",
+	"nameservers": ["ns1.tofudns.net.", "ns2.tofudns.net."]
+}`
+
+// testRecordsJSON are the records of the zone served by the test API
+const testRecordsJSON = `[
+	{"id": 1, "zone": "example.org.", "name": "", "record_type": "MX", "ttl": 3600, "content": {"host": "mx.example.net.", "preference": 10}, "version": 1},
+	{"id": 2, "zone": "example.org.", "name": "www", "record_type": "A", "ttl": 300, "content": {"ip": "192.0.2.1"}, "version": 3},
+	{"id": 3, "zone": "example.org.", "name": "txt", "record_type": "TXT", "ttl": 300, "content": {"text": "hello world"}, "version": 1},
+	{"id": 4, "zone": "example.org.", "name": "bad", "record_type": "A", "ttl": 300, "content": {"ip": "not an address"}, "content_error": "invalid A record content", "version": 1}
+]`
+
+// testAPI serves the zone example.org. to the token testToken
+type testAPI struct {
+	url string
+
+	mu      sync.Mutex
+	deleted []string
+}
+
+// newTestAPI starts the test API and points the CLI at it
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	api := &testAPI{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/zones", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"zones": [` + testZoneJSON + `]}`))
+	})
+	mux.HandleFunc("GET /api/zones/{zone}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("zone") != "example.org." && r.PathValue("zone") != "example.org" {
+			http.Error(w, `{"error": "Zone not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(testZoneJSON))
+	})
+	mux.HandleFunc("DELETE /api/zones/{zone}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.deleted = append(api.deleted, r.PathValue("zone"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/zones/{zone}/records", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"records": ` + testRecordsJSON + `}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	api.url = server.URL
+
+	t.Setenv("TOFUDNS_URL", server.URL)
+	t.Setenv("TOFUDNS_TOKEN", testToken)
+	t.Setenv("TOFUDNS_PROFILE", "")
+	return api
+}
+
+// deletedZones returns the zones deleted through the API
+func (api *testAPI) deletedZones() []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]string(nil), api.deleted...)
+}
+
+// runCLI runs the CLI with a config file in a temporary directory, returning
+// its output and error output
+func runCLI(t *testing.T, configPath, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+	var out, errOut strings.Builder
+	err := run(context.Background(), append([]string{"-config", configPath}, args...), strings.NewReader(stdin), &out, &errOut)
+	return out.String(), errOut.String(), err
+}
+
+// testConfigPath returns a config path in a temporary directory
+func testConfigPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "config.yaml")
+}
+
+func TestUsage(t *testing.T) {
+	newTestAPI(t)
+	tests := []struct {
+		name string
+		args []string
+		// wantErr is contained in the error, if any
+		wantErr string
+		// wantOut and wantErrOut are contained in the output and error output
+		wantOut    string
+		wantErrOut string
+	}{
+		{name: "no command", wantOut: "Commands:"},
+		{name: "help", args: []string{"help"}, wantOut: "zone create"},
+		{name: "unknown command", args: []string{"zone", "frobnicate"}, wantErr: errUsage.Error(), wantErrOut: `unknown command "zone frobnicate"`},
+		{name: "partial command", args: []string{"zone"}, wantErr: errUsage.Error(), wantErrOut: "unknown command"},
+		{name: "missing argument", args: []string{"zone", "create"}, wantErr: errUsage.Error(), wantErrOut: "Usage: tofudns zone create <zone>"},
+		{name: "extra argument", args: []string{"zone", "list", "example.org"}, wantErr: errUsage.Error(), wantErrOut: "Usage: tofudns zone list"},
+		{name: "unknown flag", args: []string{"zone", "delete", "-force", "example.org"}, wantErr: "flag provided but not defined", wantErrOut: "Usage: tofudns zone delete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut, err := runCLI(t, testConfigPath(t), "", tt.args...)
+			if (err == nil) != (tt.wantErr == "") || err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("output = %q, want %q in it", out, tt.wantOut)
+			}
+			if !strings.Contains(errOut, tt.wantErrOut) {
+				t.Errorf("error output = %q, want %q in it", errOut, tt.wantErrOut)
+			}
+		})
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	newTestAPI(t)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "output format", args: []string{"-o", "xml", "zone", "list"}, want: `unknown output format "xml"`},
+		{name: "SOA timer", args: []string{"zone", "soa", "example.org", "3600", "600", "1209600", "soon"}, want: `invalid timer "soon"`},
+		{name: "record ID", args: []string{"record", "rm", "example.org", "www"}, want: `invalid record ID "www"`},
+		{name: "record version", args: []string{"record", "rm", "example.org", "1@0"}, want: `invalid record version "1@0"`},
+		{name: "token ID", args: []string{"token", "rm", "deploy"}, want: `invalid token ID "deploy"`},
+		{name: "record type", args: []string{"record", "add", "example.org", "www", "HINFO", `"x86" "Linux"`}, want: "unsupported record type HINFO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCLI(t, testConfigPath(t), "", tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("run() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	cmd, rest, ok := findCommand([]string{"zone", "export", "-file", "zone.txt", "example.org"})
+	if !ok || cmd.name != "zone export" {
+		t.Fatalf("findCommand() = %q, %v, want zone export", cmd.name, ok)
+	}
+	if strings.Join(rest, " ") != "-file zone.txt example.org" {
+		t.Errorf("findCommand() arguments = %q", rest)
+	}
+	if _, _, ok := findCommand([]string{"record"}); ok {
+		t.Errorf("findCommand() found a command for a partial name")
+	}
+}
+
+func TestZoneListOutput(t *testing.T) {
+	newTestAPI(t)
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{
+			output: outputTable,
+			want: "ZONE         STATUS  CREATED\n" +
+				"example.org  active  2026-03-01\n",
+		},
+		{
+			output: outputJSON,
+			want: `[
+  {
+    "name": "example.org.",
+    "name_unicode": "example.org",
+    "status": "active",
+    "created_at": "2026-03-01T12:00:00Z",
+    "nameservers": [
+      "ns1.tofudns.net.",
+      "ns2.tofudns.net."
+    ]
+  }
+]
+`,
+		},
+		{
+			output: outputYAML,
+			want: `- name: example.org.
+  name_unicode: example.org
+  status: active
+  created_at: "2026-03-01T12:00:00Z"
+  nameservers:
+    - ns1.tofudns.net.
+    - ns2.tofudns.net.
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			out, _, err := runCLI(t, testConfigPath(t), "", "-o", tt.output, "zone", "list")
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordListOutput(t *testing.T) {
+	newTestAPI(t)
+	out, _, err := runCLI(t, testConfigPath(t), "", "record", "list", "example.org")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "ID  NAME  TYPE  TTL   VERSION  CONTENT\n" +
+		"1   @     MX    3600  1        10 mx.example.net.\n" +
+		"2   www   A     300   3        192.0.2.1\n" +
+		"3   txt   TXT   300   1        \"hello world\"\n" +
+		"4   bad   A     300   1        <invalid A record content>\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestZoneExport(t *testing.T) {
+	newTestAPI(t)
+	want := "$ORIGIN example.org.\n" +
+		"example.org.\t3600\tIN\tMX\t10 mx.example.net.\n" +
+		"www.example.org.\t300\tIN\tA\t192.0.2.1\n" +
+		"txt.example.org.\t300\tIN\tTXT\t\"hello world\"\n" +
+		"; skipped record 4: invalid A record content\n"
+
+	out, _, err := runCLI(t, testConfigPath(t), "", "zone", "export", "example.org")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "example.org.zone")
+	if _, _, err := runCLI(t, testConfigPath(t), "", "zone", "export", "-file", path, "example.org"); err != nil {
+		t.Fatalf("run() with a file error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading zone file: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("zone file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestZoneDeleteConfirmation(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  []string
+	}{
+		{name: "declined", args: []string{"example.org"}, stdin: "n\n"},
+		{name: "no answer", args: []string{"example.org"}},
+		{name: "confirmed", args: []string{"example.org"}, stdin: "yes\n", want: []string{"example.org"}},
+		{name: "without confirmation", args: []string{"-yes", "example.org"}, want: []string{"example.org"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			_, errOut, err := runCLI(t, testConfigPath(t), tt.stdin, append([]string{"zone", "delete"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if deleted := api.deletedZones(); strings.Join(deleted, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("deleted zones = %q, want %q", deleted, tt.want)
+			}
+			if asked := strings.Contains(errOut, "[y/N]"); asked == (tt.name == "without confirmation") {
+				t.Errorf("error output = %q, asked for confirmation = %v", errOut, asked)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	api := newTestAPI(t)
+	// The token is read from the profile rather than the environment
+	t.Setenv("TOFUDNS_URL", "")
+	t.Setenv("TOFUDNS_TOKEN", "")
+	configPath := testConfigPath(t)
+
+	if _, _, err := runCLI(t, configPath, "", "zone", "list"); err == nil || !strings.Contains(err.Error(), `no API token for profile "default"`) {
+		t.Fatalf("run() without a token error = %v, want one asking to log in", err)
+	}
+
+	// A token the API rejects isn't stored
+	_, _, err := runCLI(t, configPath, "tofu_wrong\n", "-profile", "work", "login", "-url", api.url)
+	if err == nil || !strings.Contains(err.Error(), "failed to verify token") {
+		t.Fatalf("login with a wrong token error = %v", err)
+	}
+	if _, err := os.Stat(configPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("config written for a wrong token: %v", err)
+	}
+
+	if _, _, err := runCLI(t, configPath, testToken+"\n", "-profile", "work", "login", "-url", api.url); err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("config not written: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("config mode = %v, want 0600", info.Mode().Perm())
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.CurrentProfile != "work" || cfg.Profiles["work"].Token != testToken || cfg.Profiles["work"].URL != api.url {
+		t.Errorf("config = %+v, want the work profile current with the token", cfg)
+	}
+
+	// The logged in profile is used by default
+	out, _, err := runCLI(t, configPath, "", "-o", outputJSON, "zone", "list")
+	if err != nil {
+		t.Fatalf("run() after login error = %v", err)
+	}
+	var zones []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &zones); err != nil || len(zones) != 1 {
+		t.Errorf("zone list output = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// table is the tabular form of a command's output
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes the value in the selected output format. The table function
+// returns its table form.
+func (c *cli) print(v interface{}, toTable func() table) error {
+	switch c.output {
+	case outputJSON:
+		encoder := json.NewEncoder(c.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case outputYAML:
+		return writeYAML(c, v)
+	default:
+		t := toTable()
+		w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		if len(t.header) > 0 {
+			fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// writeYAML writes the value as YAML. The value is converted through its JSON
+// form, so YAML output has the same fields, in the same order, as the API.
+func writeYAML(c *cli, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// JSON is YAML, decoding it into a node keeps the order of its fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(c.out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow style of the nodes decoded from JSON, so they
+// are written in YAML's block style
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Style&yaml.DoubleQuotedStyle != 0 {
+		// Strings only need quoting where YAML would misread them
+		node.Style &^= yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// recordHeader is the table header of records
+var recordHeader = []string{"ID", "NAME", "TYPE", "TTL", "VERSION", "CONTENT"}
+
+// recordRow returns the table row of a record
+func recordRow(zone string, record client.Record) []string {
+	name := record.NameUnicode
+	if name == "" {
+		name = record.Name
+	}
+	if name == "" {
+		name = client.ApexName
+	}
+	return []string{
+		strconv.FormatInt(record.ID, 10),
+		name,
+		record.Type,
+		strconv.FormatInt(int64(record.TTL), 10),
+		strconv.FormatInt(int64(record.Version), 10),
+		rdataString(zone, record),
+	}
+}
+
+// rdataString returns the record's rdata, or the reason it can't be written
+// in a zone file
+func rdataString(zone string, record client.Record) string {
+	rdata, err := zonefile.RData(zone, record)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return rdata
+}
+
+// printRecords prints records of the zone
+func (c *cli) printRecords(zone string, records []client.Record) error {
+	return c.print(records, func() table {
+		t := table{header: recordHeader}
+		for _, record := range records {
+			t.rows = append(t.rows, recordRow(zone, record))
+		}
+		return t
+	})
+}
+
+// runRecordList lists the records of a zone
+func runRecordList(ctx context.Context, c *cli, args []string) error {
+	flags := c.flagSet("record list")
+	var query client.RecordQuery
+	flags.StringVar(&query.Name, "name", "", "only list records with the name, @ for the apex")
+	flags.StringVar(&query.NamePrefix, "prefix", "", "only list records whose name starts with the prefix")
+	flags.StringVar(&query.Type, "type", "", "only list records of the type")
+	flags.StringVar(&query.Content, "content", "", "only list records whose content contains the text")
+	flags.StringVar(&query.Sort, "sort", "", "sort by name, type, ttl or id, prefixed with - for descending order")
+	args, err := parseArgs(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	zone, err := api.GetZone(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	records, err := api.SearchRecords(ctx, zone.Name, query)
+	if err != nil {
+		return err
+	}
+	return c.printRecords(zone.Name, records)
+}
+
+// runSearch lists the records of all zones containing the text
+func runSearch(ctx context.Context, c *cli, args []string) error {
+	args, err := parseArgs(c.flagSet("search"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	records, err := api.SearchAllRecords(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return c.print(records, func() table {
+		t := table{header: append([]string{"ZONE"}, recordHeader...)}
+		for _, record := range records {
+			t.rows = append(t.rows, append([]string{record.Zone}, recordRow(record.Zone, record)...))
+		}
+		return t
+	})
+}
+
+// runReplace replaces a value of the records of a type across zones after
+// confirmation, such as the IP address of A records
+func runReplace(ctx context.Context, c *cli, args []string) error {
+	flags := c.flagSet("replace")
+	zone := flags.String("zone", "", "only replace the value in the zone")
+	yes := flags.Bool("yes", false, "replace without asking for confirmation")
+	args, err := parseArgs(flags, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	replace := client.BulkReplace{
+		Zone:        *zone,
+		Type:        strings.ToUpper(args[0]),
+		Value:       args[1],
+		Replacement: args[2],
+	}
+	plan, err := api.PlanBulkReplace(ctx, replace)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		if c.output != outputTable {
+			if err := c.print(plan, nil); err != nil {
+				return err
+			}
+		} else {
+			for _, change := range plan.Changes {
+				fmt.Fprintf(c.out, "- %s\n", zoneFileLine(change.Current.Zone, *change.Current))
+				fmt.Fprintf(c.out, "+ %s\n", zoneFileLine(change.Record.Zone, *change.Record))
+			}
+		}
+	}
+	if len(plan.Changes) == 0 {
+		if c.output == outputTable {
+			fmt.Fprintln(c.out, "No records have the value")
+		}
+		return nil
+	}
+	if !*yes {
+		ok, err := c.confirm(fmt.Sprintf("Update %d records?", len(plan.Changes)))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	_, results, err := api.ApplyBulkReplace(ctx, replace, plan.Hash)
+	if errors.Is(err, client.ErrConflict) {
+		return errors.New("records have changed since the changes were planned, run the command again")
+	}
+	if err != nil {
+		return err
+	}
+	return c.print(results, func() table {
+		t := table{header: append([]string{"ZONE"}, recordHeader...)}
+		for _, result := range results {
+			if result.Record != nil {
+				t.rows = append(t.rows, append([]string{result.Record.Zone}, recordRow(result.Record.Zone, *result.Record)...))
+			}
+		}
+		return t
+	})
+}
+
+// recordArgs are the arguments of the commands writing records
+type recordArgs struct {
+	zone       *client.Zone
+	name       string
+	recordType string
+	records    []client.Record
+}
+
+// parseRecordArgs parses the zone, name, type and rdata arguments of a
+// command writing records
+func (c *cli) parseRecordArgs(ctx context.Context, api *client.Client, name string, args []string) (*recordArgs, error) {
+	flags := c.flagSet(name)
+	ttl := flags.Uint("ttl", zonefile.DefaultTTL, "TTL of the records in seconds")
+	args, err := parseArgs(flags, args, 4, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := api.GetZone(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	recordType := strings.ToUpper(args[2])
+	records, err := zonefile.ParseRData(zone.Name, args[1], recordType, uint32(*ttl), args[3:])
+	if err != nil {
+		return nil, err
+	}
+	return &recordArgs{
+		zone:       zone,
+		name:       args[1],
+		recordType: recordType,
+		records:    records,
+	}, nil
+}
+
+// runRecordAdd adds records to a zone
+func runRecordAdd(ctx context.Context, c *cli, args []string) error {
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	recordArgs, err := c.parseRecordArgs(ctx, api, "record add", args)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]client.Change, len(recordArgs.records))
+	for i := range recordArgs.records {
+		changes[i] = client.Change{Action: client.ChangeCreate, Record: &recordArgs.records[i]}
+	}
+	return c.applyRecordChanges(ctx, api, recordArgs.zone.Name, changes)
+}
+
+// runRecordSet replaces the records with a name and type, updating existing
+// records in place, in a single change set
+func runRecordSet(ctx context.Context, c *cli, args []string) error {
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	recordArgs, err := c.parseRecordArgs(ctx, api, "record set", args)
+	if err != nil {
+		return err
+	}
+
+	current, err := api.FindRecords(ctx, recordArgs.zone.Name, recordArgs.name, recordArgs.recordType)
+	if err != nil {
+		return err
+	}
+
+	var changes []client.Change
+	for i := range recordArgs.records {
+		record := &recordArgs.records[i]
+		if i < len(current) {
+			changes = append(changes, client.Change{
+				Action:  client.ChangeUpdate,
+				ID:      current[i].ID,
+				Version: current[i].Version,
+				Record:  record,
+			})
+			continue
+		}
+		changes = append(changes, client.Change{Action: client.ChangeCreate, Record: record})
+	}
+	for _, record := range current[min(len(current), len(recordArgs.records)):] {
+		changes = append(changes, client.Change{
+			Action:  client.ChangeDelete,
+			ID:      record.ID,
+			Version: record.Version,
+		})
+	}
+	return c.applyRecordChanges(ctx, api, recordArgs.zone.Name, changes)
+}
+
+// runRecordRm deletes records by their IDs in a single change set. An ID may
+// be followed by @ and the version of the record to delete, otherwise the
+// record is deleted whatever its version.
+func runRecordRm(ctx context.Context, c *cli, args []string) error {
+	args, err := parseArgs(c.flagSet("record rm"), args, 2, -1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	changes := make([]client.Change, len(args)-1)
+	for i, arg := range args[1:] {
+		change := client.Change{Action: client.ChangeDelete, Version: client.AnyVersion}
+		idArg, versionArg, hasVersion := strings.Cut(arg, "@")
+		change.ID, err = strconv.ParseInt(idArg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid record ID %q", arg)
+		}
+		if hasVersion {
+			version, err := strconv.ParseInt(versionArg, 10, 32)
+			if err != nil || version < 1 {
+				return fmt.Errorf("invalid record version %q", arg)
+			}
+			change.Version = int32(version)
+		}
+		changes[i] = change
+	}
+	return c.applyRecordChanges(ctx, api, args[0], changes)
+}
+
+// applyRecordChanges applies a change set and prints the changed records
+func (c *cli) applyRecordChanges(ctx context.Context, api *client.Client, zone string, changes []client.Change) error {
+	results, err := api.ApplyChanges(ctx, zone, changes)
+	if err != nil {
+		return err
+	}
+	return c.print(results, func() table {
+		t := table{header: append([]string{"ACTION"}, recordHeader...)}
+		for _, result := range results {
+			row := append([]string{strconv.FormatInt(result.ID, 10)}, make([]string, len(recordHeader)-1)...)
+			if result.Record != nil {
+				row = recordRow(zone, *result.Record)
+			}
+			t.rows = append(t.rows, append([]string{result.Action}, row...))
+		}
+		return t
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// runTokenList lists the user's API tokens
+func runTokenList(ctx context.Context, c *cli, args []string) error {
+	if _, err := parseArgs(c.flagSet("token list"), args, 0, 0); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := api.ListTokens(ctx)
+	if err != nil {
+		return err
+	}
+	return c.print(tokens, func() table {
+		t := table{header: []string{"ID", "NAME", "CREATED", "EXPIRES", "LAST USED"}}
+		for _, token := range tokens {
+			expires, lastUsed := "never", "never"
+			if token.ExpiresAt != nil {
+				expires = token.ExpiresAt.Format("2006-01-02")
+			}
+			if token.LastUsedAt != nil {
+				lastUsed = token.LastUsedAt.Format("2006-01-02")
+			}
+			t.rows = append(t.rows, []string{
+				strconv.FormatInt(int64(token.ID), 10),
+				token.Name,
+				token.CreatedAt.Format("2006-01-02"),
+				expires,
+				lastUsed,
+			})
+		}
+		return t
+	})
+}
+
+// runTokenRm deletes an API token
+func runTokenRm(ctx context.Context, c *cli, args []string) error {
+	args, err := parseArgs(c.flagSet("token rm"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid token ID %q", args[0])
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	return api.DeleteToken(ctx, int32(id))
+}
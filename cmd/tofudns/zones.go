@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// runZoneList lists the user's zones
+func runZoneList(ctx context.Context, c *cli, args []string) error {
+	if _, err := parseArgs(c.flagSet("zone list"), args, 0, 0); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	zones, err := api.ListZones(ctx)
+	if err != nil {
+		return err
+	}
+	return c.print(zones, func() table {
+		t := table{header: []string{"ZONE", "STATUS", "CREATED"}}
+		for _, zone := range zones {
+			t.rows = append(t.rows, []string{zone.NameUnicode, zone.Status, zone.CreatedAt.Format("2006-01-02")})
+		}
+		return t
+	})
+}
+
+// runZoneCreate creates a zone and shows how to verify it
+func runZoneCreate(ctx context.Context, c *cli, args []string) error {
+	args, err := parseArgs(c.flagSet("zone create"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	zone, err := api.CreateZone(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return c.print(zone, func() table {
+		return zoneTable(zone)
+	})
+}
+
+// zoneTable returns the details of a zone, including how to verify it while
+// it is pending
+func zoneTable(zone *client.Zone) table {
+	t := table{rows: [][]string{
+		{"Zone:", zone.NameUnicode},
+		{"Status:", zone.Status},
+	}}
+	for _, nameserver := range zone.Nameservers {
+		t.rows = append(t.rows, []string{"Nameserver:", nameserver})
+	}
+	if zone.Verification != nil {
+		t.rows = append(t.rows,
+			[]string{"Verify with TXT:", zone.Verification.TXTName},
+			[]string{"TXT value:", zone.Verification.TXTValue},
+		)
+	}
+	return t
+}
+
+// runZoneSOA sets the timers of a zone's SOA record
+func runZoneSOA(ctx context.Context, c *cli, args []string) error {
+	args, err := parseArgs(c.flagSet("zone soa"), args, 5, 5)
+	if err != nil {
+		return err
+	}
+	var timers [4]uint32
+	for i, arg := range args[1:] {
+		value, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid timer %q: must be a number of seconds", arg)
+		}
+		timers[i] = uint32(value)
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	record, err := api.UpdateSOATimers(ctx, args[0], client.SOATimers{
+		Refresh: timers[0],
+		Retry:   timers[1],
+		Expire:  timers[2],
+		MinTtl:  timers[3],
+	})
+	if err != nil {
+		return err
+	}
+	return c.printRecords(record.Zone, []client.Record{*record})
+}
+
+// runZoneDelete deletes a zone and its records
+func runZoneDelete(ctx context.Context, c *cli, args []string) error {
+	flags := c.flagSet("zone delete")
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	args, err := parseArgs(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		ok, err := c.confirm(fmt.Sprintf("Delete zone %s and all its records?", args[0]))
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return api.DeleteZone(ctx, args[0])
+}
+
+// runZoneExport writes a zone's records as a zone file
+func runZoneExport(ctx context.Context, c *cli, args []string) error {
+	flags := c.flagSet("zone export")
+	file := flags.String("file", "", "file to write, standard output if not given")
+	args, err := parseArgs(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	zone, err := api.GetZone(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	records, err := api.ListRecords(ctx, zone.Name)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = c.out
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return zonefile.Write(w, zone.Name, records)
+}
+
+// runZoneImport creates a zone if it doesn't exist yet and converges it to
+// a zone file
+func runZoneImport(ctx context.Context, c *cli, args []string) error {
+	return c.syncZone(ctx, "zone import", args, true)
+}
+
+// runZoneDiff shows the changes converging a zone to a zone file
+func runZoneDiff(ctx context.Context, c *cli, args []string) error {
+	return c.syncZone(ctx, "zone diff", args, false)
+}
+
+// runZoneApply converges a zone to a zone file after confirmation
+func runZoneApply(ctx context.Context, c *cli, args []string) error {
+	return c.syncZone(ctx, "zone apply", args, false)
+}
+
+// syncZone plans the changes converging a zone to a zone file, and applies
+// them unless the command only shows them
+func (c *cli) syncZone(ctx context.Context, name string, args []string, create bool) error {
+	flags := c.flagSet(name)
+	withSOANS := flags.Bool("with-soa-ns", false, "also manage the zone's SOA and apex NS records, which TofuDNS sets up")
+	var yes *bool
+	if name == "zone apply" {
+		yes = flags.Bool("yes", false, "apply without asking for confirmation")
+	}
+	args, err := parseArgs(flags, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	zone, path := args[0], args[1]
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	desired, err := zonefile.Parse(f, zone, path, *withSOANS)
+	if err != nil {
+		return err
+	}
+
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	if create {
+		_, err := api.CreateZone(ctx, zone)
+		if err != nil && !errors.Is(err, client.ErrConflict) {
+			return err
+		}
+	}
+
+	plan, err := api.PlanZone(ctx, zone, desired)
+	if err != nil {
+		return err
+	}
+	if name == "zone diff" || (yes != nil && !*yes) {
+		if err := c.printPlan(plan); err != nil {
+			return err
+		}
+	}
+	if name == "zone diff" || len(plan.Changes) == 0 {
+		return nil
+	}
+	if yes != nil && !*yes {
+		ok, err := c.confirm(fmt.Sprintf("Apply %d changes to %s?", len(plan.Changes), plan.ZoneUnicode))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	_, results, err := api.ApplyZone(ctx, zone, desired, plan.Hash)
+	if errors.Is(err, client.ErrConflict) {
+		return fmt.Errorf("%s has changed since the changes were planned, run the command again", zone)
+	}
+	if err != nil {
+		return err
+	}
+	return c.print(results, func() table {
+		t := table{header: append([]string{"ACTION"}, recordHeader...)}
+		for _, result := range results {
+			row := append([]string{strconv.FormatInt(result.ID, 10)}, make([]string, len(recordHeader)-1)...)
+			if result.Record != nil {
+				row = recordRow(plan.Zone, *result.Record)
+			}
+			t.rows = append(t.rows, append([]string{result.Action}, row...))
+		}
+		return t
+	})
+}
+
+// printPlan prints the changes of a plan. The table form is a diff of the
+// records removed and added.
+func (c *cli) printPlan(plan *client.Plan) error {
+	if c.output != outputTable {
+		return c.print(plan, nil)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Fprintf(c.out, "%s is up to date\n", plan.ZoneUnicode)
+		return nil
+	}
+	for _, change := range plan.Changes {
+		if change.Current != nil {
+			fmt.Fprintf(c.out, "- %s\n", zoneFileLine(plan.Zone, *change.Current))
+		}
+		if change.Record != nil {
+			fmt.Fprintf(c.out, "+ %s\n", zoneFileLine(plan.Zone, *change.Record))
+		}
+	}
+	return nil
+}
+
+// zoneFileLine returns the record as a line of a zone file, or the reason it
+// can't be written as one
+func zoneFileLine(zone string, record client.Record) string {
+	line, err := zonefile.Line(zone, record)
+	if err != nil {
+		return fmt.Sprintf("%s %s <%v>", record.Name, record.Type, err)
+	}
+	return line
+}
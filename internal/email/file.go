@@ -0,0 +1,66 @@
+package email
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSender writes each message to a maildir, so tests and local tooling can
+// inspect sent email
+type FileSender struct {
+	dir       string
+	fromEmail string
+	hostname  string
+}
+
+// NewFileSender creates a new email sender that delivers into the maildir at
+// dir, creating it if needed
+func NewFileSender(dir, fromEmail string) (*FileSender, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create maildir: %w", err)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	return &FileSender{
+		dir:       dir,
+		fromEmail: fromEmail,
+		// Maildir reserves these characters in file names
+		hostname: strings.NewReplacer("/", `\057`, ":", `\072`).Replace(hostname),
+	}, nil
+}
+
+// Send writes the message into the maildir's new directory
+func (s *FileSender) Send(message Message) error {
+	body, err := message.encode(s.fromEmail)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.%s.%s", time.Now().UnixNano(), hex.EncodeToString(randomBytes), s.hostname)
+
+	// Write to tmp first so readers never see a partial message
+	tmpPath := filepath.Join(s.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, body, 0o600); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, "new", name)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to deliver message: %w", err)
+	}
+	return nil
+}
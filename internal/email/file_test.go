@@ -0,0 +1,74 @@
+package email
+
+import (
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "maildir")
+	sender, err := NewFileSender(dir, "noreply@example.net")
+	if err != nil {
+		t.Fatalf("NewFileSender() error = %v", err)
+	}
+
+	for _, to := range []string{"one@example.com", "two@example.com"} {
+		message := testMessage
+		message.To = to
+		if err := sender.Send(message); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	// Messages are delivered into new, leaving nothing in tmp
+	if entries, err := os.ReadDir(filepath.Join(dir, "tmp")); err != nil || len(entries) != 0 {
+		t.Errorf("tmp = %v, %v, want it empty", entries, err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatalf("reading new: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("new has %d messages, want 2", len(entries))
+	}
+	recipients := make(map[string]bool)
+	for _, entry := range entries {
+		if strings.ContainsAny(entry.Name(), "/:") {
+			t.Errorf("message file name %q has characters reserved by maildir", entry.Name())
+		}
+		file, err := os.Open(filepath.Join(dir, "new", entry.Name()))
+		if err != nil {
+			t.Fatalf("opening message: %v", err)
+		}
+		message, err := mail.ReadMessage(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("reading message: %v", err)
+		}
+		if message.Header.Get("Subject") != testMessage.Subject || message.Header.Get("From") != "noreply@example.net" {
+			t.Errorf("headers = %v", message.Header)
+		}
+		recipients[message.Header.Get("To")] = true
+	}
+	if !recipients["one@example.com"] || !recipients["two@example.com"] {
+		t.Errorf("recipients = %v, want both messages", recipients)
+	}
+}
+
+func TestFileSenderExistingMaildir(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileSender(dir, "noreply@example.net"); err != nil {
+		t.Fatalf("NewFileSender() error = %v", err)
+	}
+	// Reopening the maildir keeps it
+	sender, err := NewFileSender(dir, "noreply@example.net")
+	if err != nil {
+		t.Fatalf("NewFileSender() of an existing maildir error = %v", err)
+	}
+	if err := sender.Send(testMessage); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+}
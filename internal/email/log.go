@@ -0,0 +1,28 @@
+package email
+
+import (
+	"log/slog"
+)
+
+// LogSender writes messages to the log instead of delivering them, for
+// local development
+type LogSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender creates a new email sender that logs messages
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{
+		logger: logger,
+	}
+}
+
+// Send logs the message
+func (s *LogSender) Send(message Message) error {
+	s.logger.Info("Email not delivered (log transport)",
+		"to", message.To,
+		"subject", message.Subject,
+		"body", message.TextBody,
+	)
+	return nil
+}
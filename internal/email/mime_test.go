@@ -0,0 +1,116 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// decodeMessage parses an encoded message, returning its headers and the
+// decoded bodies of its parts by content type
+func decodeMessage(t *testing.T, data []byte) (mail.Header, map[string]string) {
+	t.Helper()
+	message, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("reading message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", message.Header.Get("Content-Type"))
+	}
+
+	bodies := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		// The reader decodes quoted-printable parts
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		bodies[part.Header.Get("Content-Type")] = string(body)
+	}
+	return message.Header, bodies
+}
+
+func TestMessageEncode(t *testing.T) {
+	message := Message{
+		To:       "user@example.com",
+		Subject:  "Ihr Anmeldecode für TofuDNS",
+		TextBody: "Grüße!\n" + strings.Repeat("a long line ", 20) + "\nend = here",
+		HTMLBody: `<p style="color: red">Grüße</p>`,
+	}
+	data, err := message.encode(`"TofuDNS" <noreply@example.net>`)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	// Lines are 7-bit and within the limit of RFC 5322, as the bodies are
+	// quoted-printable
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line longer than 998 characters: %q", line)
+		}
+		for _, c := range []byte(line) {
+			if c >= 0x80 {
+				t.Fatalf("line with 8-bit characters: %q", line)
+			}
+		}
+	}
+
+	header, bodies := decodeMessage(t, data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("Subject = %q, %v, want %q", subject, err, message.Subject)
+	}
+	if header.Get("To") != message.To || header.Get("MIME-Version") != "1.0" {
+		t.Errorf("headers = %v", header)
+	}
+	if _, err := header.Date(); err != nil {
+		t.Errorf("Date error = %v", err)
+	}
+	if id := header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.net>") {
+		t.Errorf("Message-ID = %q, want one in the sender's domain", id)
+	}
+	// Text line breaks are sent as CRLF
+	if want := strings.ReplaceAll(message.TextBody, "\n", "\r\n"); bodies["text/plain; charset=utf-8"] != want {
+		t.Errorf("text body = %q, want %q", bodies["text/plain; charset=utf-8"], want)
+	}
+	if bodies["text/html; charset=utf-8"] != message.HTMLBody {
+		t.Errorf("HTML body = %q, want %q", bodies["text/html; charset=utf-8"], message.HTMLBody)
+	}
+}
+
+func TestMessageEncodeTextOnly(t *testing.T) {
+	data, err := Message{To: "user@example.com", Subject: "Hello", TextBody: "Hello"}.encode("noreply@example.net")
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+	_, bodies := decodeMessage(t, data)
+	if len(bodies) != 1 || bodies["text/plain; charset=utf-8"] != "Hello" {
+		t.Errorf("parts = %q, want only the text part", bodies)
+	}
+}
+
+func TestMessageIDsAreUnique(t *testing.T) {
+	first, err := newMessageID("noreply@example.net")
+	if err != nil {
+		t.Fatalf("newMessageID() error = %v", err)
+	}
+	second, err := newMessageID("noreply@example.net")
+	if err != nil {
+		t.Fatalf("newMessageID() error = %v", err)
+	}
+	if first == second {
+		t.Errorf("newMessageID() returned %q twice", first)
+	}
+}
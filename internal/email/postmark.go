@@ -1,40 +1,38 @@
 package email
 
 import (
-	"fmt"
-
 	"github.com/keighl/postmark"
 )
 
-// PostmarkConfig contains configuration for the Postmark service
+// PostmarkConfig contains configuration for the Postmark sender
 type PostmarkConfig struct {
-	ServerToken string `envconfig:"POSTMARK_SERVER_TOKEN" required:"true"`
-	FromEmail   string `envconfig:"POSTMARK_EMAIL_FROM" default:"noreply@tofudns.net"`
+	ServerToken string
+	FromEmail   string
 }
 
-// PostmarkService implements the email service using Postmark
-type PostmarkService struct {
+// PostmarkSender delivers email through the Postmark API
+type PostmarkSender struct {
 	client    *postmark.Client
 	fromEmail string
 }
 
-// NewPostmarkService creates a new Postmark email service
-func NewPostmarkService(config PostmarkConfig) *PostmarkService {
+// NewPostmarkSender creates a new Postmark email sender
+func NewPostmarkSender(config PostmarkConfig) *PostmarkSender {
 	client := postmark.NewClient(config.ServerToken, "")
-	return &PostmarkService{
+	return &PostmarkSender{
 		client:    client,
 		fromEmail: config.FromEmail,
 	}
 }
 
-// SendOTP sends an OTP code to the specified email
-func (s *PostmarkService) SendOTP(email, otp string) error {
+// Send sends the message through Postmark
+func (s *PostmarkSender) Send(message Message) error {
 	emailReq := postmark.Email{
 		From:       s.fromEmail,
-		To:         email,
-		Subject:    "Your TofuDNS Verification Code",
-		TextBody:   fmt.Sprintf("Your verification code is: %s\nThis code will expire in 10 minutes.", otp),
-		HtmlBody:   fmt.Sprintf("<h2>Your TofuDNS Verification Code</h2><p>Your verification code is: <strong>%s</strong></p><p>This code will expire in 10 minutes.</p>", otp),
+		To:         message.To,
+		Subject:    message.Subject,
+		TextBody:   message.TextBody,
+		HtmlBody:   message.HTMLBody,
 		TrackOpens: true,
 	}
 
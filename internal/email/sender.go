@@ -0,0 +1,119 @@
+package email
+
+import (
+	"fmt"
+	"time"
+)
+
+// Security notification events
+const (
+	NotificationTOTPEnabled              = "totp_enabled"
+	NotificationTOTPDisabled             = "totp_disabled"
+	NotificationRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	NotificationPasskeyAdded             = "passkey_added"
+	NotificationPasskeyRemoved           = "passkey_removed"
+	NotificationAPITokenCreated          = "api_token_created"
+	NotificationAPITokenDeleted          = "api_token_deleted"
+)
+
+// Message is an email to a single recipient
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Sender delivers email messages over a transport
+type Sender interface {
+	Send(message Message) error
+}
+
+// Service composes the emails sent by TofuDNS from templates and delivers
+// them with a Sender. Locales may be given as a locale tag or as a raw
+// Accept-Language header.
+type Service struct {
+	sender   Sender
+	renderer *Renderer
+}
+
+// NewService creates a new email service using the given sender and renderer
+func NewService(sender Sender, renderer *Renderer) *Service {
+	return &Service{
+		sender:   sender,
+		renderer: renderer,
+	}
+}
+
+// SendOTP sends an OTP code and an equivalent magic link to the specified email
+func (s *Service) SendOTP(email, locale, otp, magicLink string, expiresIn time.Duration) error {
+	return s.send(email, TemplateOTP, locale, map[string]interface{}{
+		"Code":             otp,
+		"MagicLink":        magicLink,
+		"ExpiresInMinutes": int(expiresIn.Minutes()),
+	})
+}
+
+// SendInvitation invites the specified email to TofuDNS with a link to sign in
+func (s *Service) SendInvitation(email, locale, inviterEmail, inviteURL string) error {
+	return s.send(email, TemplateInvitation, locale, map[string]interface{}{
+		"InviterEmail": inviterEmail,
+		"InviteURL":    inviteURL,
+	})
+}
+
+// SendNotification notifies the specified email of a security event on
+// their account
+func (s *Service) SendNotification(email, locale, event string) error {
+	return s.send(email, TemplateNotification, locale, map[string]interface{}{
+		"Email": email,
+		"Event": event,
+	})
+}
+
+// Locales returns the locales that have templates
+func (s *Service) Locales() []string {
+	return s.renderer.Locales()
+}
+
+// Templates returns the names of the available templates
+func (s *Service) Templates() []string {
+	return s.renderer.Templates()
+}
+
+// Preview renders a template with sample data
+func (s *Service) Preview(name, locale string) (Message, error) {
+	message, err := s.renderer.Render(name, locale, previewData[name])
+	if err != nil {
+		return Message{}, err
+	}
+	message.To = "user@example.com"
+	return message, nil
+}
+
+// previewData contains sample data for previewing each template
+var previewData = map[string]map[string]interface{}{
+	TemplateOTP: {
+		"Code":             "123456",
+		"MagicLink":        "https://tofudns.example.com/auth/magic?token=preview",
+		"ExpiresInMinutes": 10,
+	},
+	TemplateInvitation: {
+		"InviterEmail": "admin@example.com",
+		"InviteURL":    "https://tofudns.example.com/auth/login?email=user%40example.com",
+	},
+	TemplateNotification: {
+		"Email": "user@example.com",
+		"Event": NotificationTOTPEnabled,
+	},
+}
+
+// send renders the template and delivers it to the recipient
+func (s *Service) send(to, name, locale string, data map[string]interface{}) error {
+	message, err := s.renderer.Render(name, locale, data)
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+	message.To = to
+	return s.sender.Send(message)
+}
@@ -0,0 +1,145 @@
+package email
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	// SMTPTLSNone sends mail over an unencrypted connection
+	SMTPTLSNone = "none"
+	// SMTPTLSStartTLS upgrades the connection with STARTTLS, failing if the
+	// server doesn't support it
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects over TLS from the start
+	SMTPTLSImplicit = "implicit"
+
+	// smtpTimeout bounds the time spent delivering a single message
+	smtpTimeout = 30 * time.Second
+)
+
+// SMTPConfig contains configuration for the SMTP sender
+type SMTPConfig struct {
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	TLS       string
+	FromEmail string
+}
+
+// SMTPSender delivers email to an SMTP server
+type SMTPSender struct {
+	config SMTPConfig
+	// rootCAs verify the server's certificate, the system's roots if nil
+	rootCAs *x509.CertPool
+}
+
+// NewSMTPSender creates a new SMTP email sender
+func NewSMTPSender(config SMTPConfig) (*SMTPSender, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	switch config.TLS {
+	case SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode: %s", config.TLS)
+	}
+
+	return &SMTPSender{
+		config: config,
+	}, nil
+}
+
+// Send delivers the message to the SMTP server
+func (s *SMTPSender) Send(message Message) error {
+	body, err := message.encode(s.config.FromEmail)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// Authenticate when credentials are configured. PlainAuth refuses to send
+	// credentials over an unencrypted connection to a remote host.
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	// The envelope sender is the bare address without a display name
+	from, err := mail.ParseAddress(s.config.FromEmail)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the SMTP server and negotiates TLS
+func (s *SMTPSender) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host, RootCAs: s.rootCAs}
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if s.config.TLS == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if s.config.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,364 @@
+package email
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer is an SMTP server accepting a single message
+type smtpServer struct {
+	t        *testing.T
+	listener net.Listener
+	tls      *tls.Config
+	// implicit serves TLS from the start, starttls advertises STARTTLS
+	implicit bool
+	starttls bool
+	// username and password, if set, are required before a message is sent
+	username string
+	password string
+
+	received chan smtpDelivery
+}
+
+// smtpDelivery is what an SMTP session delivered
+type smtpDelivery struct {
+	// tls is whether the message was sent over TLS
+	tls      bool
+	username string
+	from     string
+	to       []string
+	data     []byte
+}
+
+// newSMTPServer starts an SMTP server on a local port with a certificate for
+// 127.0.0.1, returning it with the pool verifying the certificate
+func newSMTPServer(t *testing.T, configure func(s *smtpServer)) (*smtpServer, *x509.CertPool) {
+	t.Helper()
+	cert, pool := newTestCertificate(t)
+	s := &smtpServer{
+		t:        t,
+		tls:      &tls.Config{Certificates: []tls.Certificate{cert}},
+		received: make(chan smtpDelivery, 1),
+	}
+	configure(s)
+
+	var err error
+	if s.implicit {
+		s.listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tls)
+	} else {
+		s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+	go s.serve()
+	return s, pool
+}
+
+// port returns the port the server listens on
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve runs a single SMTP session
+func (s *smtpServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	text := textproto.NewConn(conn)
+	_, secure := conn.(*tls.Conn)
+	var delivery smtpDelivery
+	text.PrintfLine("220 127.0.0.1 ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			extensions := []string{"127.0.0.1", "8BITMIME"}
+			if s.starttls && !secure {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if s.username != "" {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, extension)
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			credentials, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(credentials), "\x00")
+			if mechanism != "PLAIN" || err != nil || len(parts) != 3 || parts[1] != s.username || parts[2] != s.password {
+				text.PrintfLine("535 Authentication failed")
+				continue
+			}
+			delivery.username = parts[1]
+			text.PrintfLine("235 Authenticated")
+		case "MAIL":
+			if s.username != "" && delivery.username == "" {
+				text.PrintfLine("530 Authentication required")
+				continue
+			}
+			delivery.from = envelopeAddress(arg)
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			delivery.to = append(delivery.to, envelopeAddress(arg))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 Send the message")
+			if delivery.data, err = text.ReadDotBytes(); err != nil {
+				return
+			}
+			delivery.tls = secure
+			s.received <- delivery
+			text.PrintfLine("250 Queued")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// envelopeAddress returns the address of a MAIL or RCPT command's argument,
+// such as FROM:<user@example.com> BODY=8BITMIME
+func envelopeAddress(arg string) string {
+	_, address, _ := strings.Cut(arg, "<")
+	address, _, _ = strings.Cut(address, ">")
+	return address
+}
+
+// delivery returns the delivered message
+func (s *smtpServer) delivery() smtpDelivery {
+	s.t.Helper()
+	select {
+	case delivery := <-s.received:
+		return delivery
+	default:
+		s.t.Fatalf("no message delivered")
+		return smtpDelivery{}
+	}
+}
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1 and a
+// pool trusting it
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// testMessage is the message sent in the tests
+var testMessage = Message{
+	To:       "user@example.com",
+	Subject:  "Your login code",
+	TextBody: "Your code is 123456",
+	HTMLBody: "<p>Your code is <b>123456</b></p>",
+}
+
+// newTestSMTPSender returns a sender for the server trusting its certificate
+func newTestSMTPSender(t *testing.T, server *smtpServer, pool *x509.CertPool, config SMTPConfig) *SMTPSender {
+	t.Helper()
+	config.Host = "127.0.0.1"
+	config.Port = server.port()
+	config.FromEmail = `"TofuDNS" <noreply@example.net>`
+	sender, err := NewSMTPSender(config)
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	sender.rootCAs = pool
+	return sender
+}
+
+func TestSMTPSender(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(s *smtpServer)
+		config    SMTPConfig
+		wantTLS   bool
+	}{
+		{
+			name:      "plain",
+			configure: func(*smtpServer) {},
+			config:    SMTPConfig{TLS: SMTPTLSNone},
+		},
+		{
+			name: "STARTTLS with authentication",
+			configure: func(s *smtpServer) {
+				s.starttls = true
+				s.username, s.password = "tofudns", "secret"
+			},
+			config:  SMTPConfig{TLS: SMTPTLSStartTLS, Username: "tofudns", Password: "secret"},
+			wantTLS: true,
+		},
+		{
+			name: "implicit TLS with authentication",
+			configure: func(s *smtpServer) {
+				s.implicit = true
+				s.username, s.password = "tofudns", "secret"
+			},
+			config:  SMTPConfig{TLS: SMTPTLSImplicit, Username: "tofudns", Password: "secret"},
+			wantTLS: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, pool := newSMTPServer(t, tt.configure)
+			sender := newTestSMTPSender(t, server, pool, tt.config)
+
+			if err := sender.Send(testMessage); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			delivery := server.delivery()
+			if delivery.tls != tt.wantTLS {
+				t.Errorf("sent over TLS = %v, want %v", delivery.tls, tt.wantTLS)
+			}
+			if delivery.username != tt.config.Username {
+				t.Errorf("authenticated as %q, want %q", delivery.username, tt.config.Username)
+			}
+			// The envelope has the bare addresses
+			if delivery.from != "noreply@example.net" || len(delivery.to) != 1 || delivery.to[0] != testMessage.To {
+				t.Errorf("envelope from %q to %q, want from noreply@example.net to %s", delivery.from, delivery.to, testMessage.To)
+			}
+			message, err := mail.ReadMessage(strings.NewReader(string(delivery.data)))
+			if err != nil {
+				t.Fatalf("reading message: %v", err)
+			}
+			if message.Header.Get("Subject") != testMessage.Subject || message.Header.Get("From") != `"TofuDNS" <noreply@example.net>` {
+				t.Errorf("headers = %v", message.Header)
+			}
+		})
+	}
+}
+
+func TestSMTPSenderFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(s *smtpServer)
+		config    SMTPConfig
+		want      string
+	}{
+		{
+			name:      "STARTTLS unsupported",
+			configure: func(*smtpServer) {},
+			config:    SMTPConfig{TLS: SMTPTLSStartTLS},
+			want:      "SMTP server does not support STARTTLS",
+		},
+		{
+			name: "wrong password",
+			configure: func(s *smtpServer) {
+				s.starttls = true
+				s.username, s.password = "tofudns", "secret"
+			},
+			config: SMTPConfig{TLS: SMTPTLSStartTLS, Username: "tofudns", Password: "wrong"},
+			want:   "failed to authenticate",
+		},
+		{
+			name:      "implicit TLS to a plain server",
+			configure: func(*smtpServer) {},
+			config:    SMTPConfig{TLS: SMTPTLSImplicit},
+			want:      "failed to connect to SMTP server",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, pool := newSMTPServer(t, tt.configure)
+			sender := newTestSMTPSender(t, server, pool, tt.config)
+
+			err := sender.Send(testMessage)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Send() error = %v, want %q", err, tt.want)
+			}
+			select {
+			case <-server.received:
+				t.Errorf("message delivered")
+			default:
+			}
+		})
+	}
+}
+
+func TestSMTPSenderUntrustedCertificate(t *testing.T) {
+	server, _ := newSMTPServer(t, func(s *smtpServer) { s.implicit = true })
+	sender := newTestSMTPSender(t, server, x509.NewCertPool(), SMTPConfig{TLS: SMTPTLSImplicit})
+
+	if err := sender.Send(testMessage); err == nil {
+		t.Fatalf("Send() succeeded with an untrusted certificate")
+	}
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SMTPConfig
+		wantErr bool
+	}{
+		{name: "valid", config: SMTPConfig{Host: "smtp.example.net", Port: 587, TLS: SMTPTLSStartTLS}},
+		{name: "no host", config: SMTPConfig{Port: 587, TLS: SMTPTLSStartTLS}, wantErr: true},
+		{name: "no TLS mode", config: SMTPConfig{Host: "smtp.example.net", Port: 587}, wantErr: true},
+		{name: "unknown TLS mode", config: SMTPConfig{Host: "smtp.example.net", Port: 587, TLS: "ssl"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSMTPSender(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSMTPSender() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+const (
+	// DefaultLocale is used when no requested locale has templates
+	DefaultLocale = "en"
+
+	// Template names
+	TemplateOTP          = "otp"
+	TemplateInvitation   = "invitation"
+	TemplateNotification = "notification"
+)
+
+// Branding contains the values used to brand outgoing email
+type Branding struct {
+	Name         string
+	URL          string
+	LogoURL      string
+	Color        string
+	SupportEmail string
+}
+
+// Renderer renders the embedded email templates. Each locale has a directory
+// containing a text and an HTML variant of every template; the text variant
+// also defines the subject.
+type Renderer struct {
+	branding  Branding
+	locales   []string
+	templates map[string]map[string]*localizedTemplate
+}
+
+// localizedTemplate is a template in a single locale
+type localizedTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewRenderer parses the embedded email templates
+func NewRenderer(branding Branding) (*Renderer, error) {
+	layout, err := htmltemplate.ParseFS(templateFS, "templates/layout.html")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	renderer := &Renderer{
+		branding:  branding,
+		templates: make(map[string]map[string]*localizedTemplate),
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := entry.Name()
+
+		textFiles, err := fs.Glob(templateFS, path.Join("templates", locale, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		renderer.templates[locale] = make(map[string]*localizedTemplate)
+		for _, textFile := range textFiles {
+			name := strings.TrimSuffix(path.Base(textFile), ".txt")
+
+			text, err := texttemplate.ParseFS(templateFS, textFile)
+			if err != nil {
+				return nil, err
+			}
+
+			// The HTML variant may use blocks defined alongside the subject
+			html, err := htmltemplate.Must(layout.Clone()).ParseFS(templateFS, textFile, path.Join("templates", locale, name+".html"))
+			if err != nil {
+				return nil, err
+			}
+
+			renderer.templates[locale][name] = &localizedTemplate{text: text, html: html}
+		}
+		renderer.locales = append(renderer.locales, locale)
+	}
+
+	if _, ok := renderer.templates[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing templates for default locale %s", DefaultLocale)
+	}
+	sort.Strings(renderer.locales)
+	return renderer, nil
+}
+
+// Locales returns the locales that have templates
+func (r *Renderer) Locales() []string {
+	return r.locales
+}
+
+// Templates returns the names of the templates in the default locale
+func (r *Renderer) Templates() []string {
+	var names []string
+	for name := range r.templates[DefaultLocale] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders the named template for the best match of the given locale
+// or Accept-Language header. The recipient of the returned message is unset.
+func (r *Renderer) Render(name, locale string, data map[string]interface{}) (Message, error) {
+	tmpl, matched := r.lookup(name, locale)
+	if tmpl == nil {
+		return Message{}, fmt.Errorf("unknown email template: %s", name)
+	}
+
+	values := map[string]interface{}{
+		"Brand":  r.branding,
+		"Locale": matched,
+	}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return Message{}, fmt.Errorf("failed to render subject: %w", err)
+	}
+	values["Subject"] = strings.TrimSpace(subject.String())
+
+	if err := tmpl.text.ExecuteTemplate(&text, name+".txt", values); err != nil {
+		return Message{}, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, name+".html", values); err != nil {
+		return Message{}, fmt.Errorf("failed to render HTML body: %w", err)
+	}
+
+	return Message{
+		Subject:  values["Subject"].(string),
+		TextBody: strings.TrimSpace(text.String()) + "\n",
+		HTMLBody: strings.TrimSpace(html.String()),
+	}, nil
+}
+
+// lookup finds the template in the best matching locale, falling back to the
+// default locale
+func (r *Renderer) lookup(name, locale string) (*localizedTemplate, string) {
+	for _, candidate := range append(parseLocales(locale), DefaultLocale) {
+		if tmpl, ok := r.templates[candidate][name]; ok {
+			return tmpl, candidate
+		}
+	}
+	return nil, ""
+}
+
+// parseLocales returns the candidate locales from a locale tag or an
+// Accept-Language header, most preferred first. Regional tags are followed by
+// their base language.
+func parseLocales(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	var locales []string
+	for _, tag := range tags {
+		candidates := []string{tag.tag}
+		if base, _, ok := strings.Cut(tag.tag, "-"); ok {
+			candidates = append(candidates, base)
+		}
+		for _, candidate := range candidates {
+			if !slices.Contains(locales, candidate) {
+				locales = append(locales, candidate)
+			}
+		}
+	}
+	return locales
+}
@@ -0,0 +1,80 @@
+package frontend
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// setupAccountRoutes registers account management routes
+func (s *Service) setupAccountRoutes(r chi.Router) {
+	r.Get("/account", s.handleAccountPage)
+	r.Get("/account/totp/setup", s.handleTOTPSetupPage)
+	r.Post("/account/totp/setup", s.handleTOTPSetup)
+	r.Post("/account/totp/confirm", s.handleTOTPConfirm)
+	r.Post("/account/totp/disable", s.handleTOTPDisable)
+	r.Post("/account/recovery-codes", s.handleRecoveryCodesRegenerate)
+	r.Post("/account/passkeys/register/begin", s.handlePasskeyRegisterBegin)
+	r.Post("/account/passkeys/register/finish", s.handlePasskeyRegisterFinish)
+	r.Post("/account/passkeys/{passkeyId}/delete", s.handlePasskeyDelete)
+	r.Post("/account/passkeys/{passkeyId}/delete/begin", s.handlePasskeyDeleteBegin)
+	r.Post("/account/passkeys/{passkeyId}/delete/finish", s.handlePasskeyDeleteFinish)
+	r.Post("/account/tokens", s.handleAPITokenCreate)
+	r.Post("/account/tokens/{tokenId}/delete", s.handleAPITokenDelete)
+}
+
+// handleAccountPage displays the account security settings
+func (s *Service) handleAccountPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	totpEnabled, err := s.hasTOTP(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to look up TOTP credential", "error", err)
+	}
+	var recoveryCodesRemaining int64
+	if totpEnabled {
+		count, err := s.db.CountUnusedRecoveryCodes(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to count recovery codes", "error", err)
+		}
+		recoveryCodesRemaining = count
+	}
+
+	passkeys, err := s.db.ListWebAuthnCredentialsByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list passkeys", "error", err)
+	}
+
+	apiTokens, err := s.db.ListAPITokensByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list API tokens", "error", err)
+	}
+
+	var organization *storage.Organization
+	if row, err := s.db.GetUserOrganization(ctx, userID); err == nil {
+		organization = &row
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error("Failed to look up organization", "error", err)
+	}
+
+	data := map[string]interface{}{
+		"Email":                  getUserEmail(r),
+		"Passkeys":               passkeys,
+		"APITokens":              apiTokens,
+		"TOTPEnabled":            totpEnabled,
+		"TOTPRequired":           s.requireTOTP || (organization != nil && organization.RequireTwoFactor),
+		"Organization":           organization,
+		"RecoveryCodesRemaining": recoveryCodesRemaining,
+		"IsAdmin":                s.isAdmin(r),
+		"Error":                  r.URL.Query().Get("error"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "account.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
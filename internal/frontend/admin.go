@@ -0,0 +1,134 @@
+package frontend
+
+import (
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// setupAdminRoutes registers administration routes, available only to the
+// configured administrators
+func (s *Service) setupAdminRoutes(r chi.Router) {
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(s.adminMiddleware)
+		r.Get("/emails", s.handleEmailPreviewList)
+		r.Get("/emails/{template}", s.handleEmailPreview)
+		r.Get("/invitations", s.handleInvitationsPage)
+		r.Post("/invitations", s.handleInvitationSend)
+		r.Get("/organizations", s.handleOrganizationsPage)
+		r.Post("/organizations", s.handleOrganizationCreate)
+		r.Get("/organizations/{organizationId}", s.handleOrganizationPage)
+		r.Post("/organizations/{organizationId}/policy", s.handleOrganizationPolicy)
+		r.Post("/organizations/{organizationId}/members", s.handleOrganizationMemberAdd)
+		r.Post("/organizations/{organizationId}/members/{userId}/delete", s.handleOrganizationMemberRemove)
+		r.Post("/organizations/{organizationId}/delete", s.handleOrganizationDelete)
+	})
+}
+
+// adminMiddleware hides administration routes from everyone but administrators
+func (s *Service) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAdmin(r) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin reports whether the authenticated user is an administrator
+func (s *Service) isAdmin(r *http.Request) bool {
+	return slices.Contains(s.adminEmails, strings.ToLower(getUserEmail(r)))
+}
+
+// handleEmailPreviewList lists the email templates available for preview
+func (s *Service) handleEmailPreviewList(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Templates": s.emailService.Templates(),
+		"Locales":   s.emailService.Locales(),
+	}
+	if err := s.templates.ExecuteTemplate(w, "admin_emails.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleEmailPreview renders an email template with sample data
+func (s *Service) handleEmailPreview(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "template")
+	if !slices.Contains(s.emailService.Templates(), name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	locale := r.URL.Query().Get("locale")
+	message, err := s.emailService.Preview(name, locale)
+	if err != nil {
+		s.logger.Error("Failed to render email preview", "error", err, "template", name)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Template": name,
+		"Locale":   locale,
+		"Locales":  s.emailService.Locales(),
+		"Message":  message,
+	}
+	if err := s.templates.ExecuteTemplate(w, "admin_email_preview.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleInvitationsPage displays the form inviting people to TofuDNS
+func (s *Service) handleInvitationsPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Locales": s.emailService.Locales(),
+		"Sent":    r.URL.Query().Get("sent"),
+		"Error":   r.URL.Query().Get("error"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "admin_invitations.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleInvitationSend emails an invitation linking to the login page with
+// the invited email filled in. Accounts are created on their first login, so
+// invitations keep no state of their own.
+func (s *Service) handleInvitationSend(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/admin/invitations?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(r.Form.Get("email")))
+	if err != nil {
+		http.Redirect(w, r, "/admin/invitations?error=Invalid+email+address", http.StatusSeeOther)
+		return
+	}
+
+	inviteURL := s.baseURL + "/auth/login?email=" + url.QueryEscape(address.Address)
+	err = s.emailService.SendInvitation(address.Address, r.Form.Get("locale"), getUserEmail(r), inviteURL)
+	if err != nil {
+		s.logger.Error("Failed to send invitation email", "error", err)
+		http.Redirect(w, r, "/admin/invitations?error=Failed+to+send+invitation", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/admin/invitations?sent="+url.QueryEscape(address.Address), http.StatusSeeOther)
+}
+
+// notify sends a security notification to the authenticated user. Failures
+// are logged but don't fail the request.
+func (s *Service) notify(r *http.Request, event string) {
+	if err := s.emailService.SendNotification(getUserEmail(r), r.Header.Get("Accept-Language"), event); err != nil {
+		s.logger.Error("Failed to send notification email", "error", err, "event", event)
+	}
+}
@@ -0,0 +1,116 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/email"
+)
+
+// testEmailService records the invitations and notifications it is asked to
+// send
+type testEmailService struct {
+	EmailService
+	invitations   []url.Values
+	notifications []string
+}
+
+func (e *testEmailService) SendInvitation(to, locale, inviterEmail, inviteURL string) error {
+	e.invitations = append(e.invitations, url.Values{
+		"to":      {to},
+		"locale":  {locale},
+		"inviter": {inviterEmail},
+		"url":     {inviteURL},
+	})
+	return nil
+}
+
+func (e *testEmailService) SendNotification(to, locale, event string) error {
+	e.notifications = append(e.notifications, event)
+	return nil
+}
+
+func (e *testEmailService) Locales() []string {
+	return []string{email.DefaultLocale}
+}
+
+func TestInvitation(t *testing.T) {
+	const admin = "admin@example.com"
+	config := testConfig
+	config.AdminEmails = []string{admin}
+
+	tests := []struct {
+		name     string
+		user     string
+		email    string
+		want     int
+		location string
+		sent     bool
+	}{
+		{
+			name:     "admin",
+			user:     admin,
+			email:    " new+user@example.com ",
+			want:     http.StatusSeeOther,
+			location: "/admin/invitations?sent=new%2Buser%40example.com",
+			sent:     true,
+		},
+		{
+			name:     "invalid email",
+			user:     admin,
+			email:    "not an email",
+			want:     http.StatusSeeOther,
+			location: "/admin/invitations?error=Invalid+email+address",
+		},
+		{
+			name:  "not an admin",
+			user:  "user@example.com",
+			email: "new@example.com",
+			want:  http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, config)
+			emails := &testEmailService{}
+			s.emailService = emails
+			expectUser(querier, tt.user)
+
+			form := url.Values{"email": {tt.email}, "locale": {"de"}}
+			r := httptest.NewRequest(http.MethodPost, "/admin/invitations", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router := chi.NewRouter()
+			s.Router(router)
+			resp := serve(router, withSession(t, s, r, tt.user))
+
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+			if !tt.sent {
+				if len(emails.invitations) != 0 {
+					t.Errorf("sent %d invitations, want none", len(emails.invitations))
+				}
+				return
+			}
+			if len(emails.invitations) != 1 {
+				t.Fatalf("sent %d invitations, want 1", len(emails.invitations))
+			}
+			want := url.Values{
+				"to":      {"new+user@example.com"},
+				"locale":  {"de"},
+				"inviter": {admin},
+				"url":     {"http://localhost/auth/login?email=new%2Buser%40example.com"},
+			}
+			if got := emails.invitations[0]; got.Encode() != want.Encode() {
+				t.Errorf("invitation = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/internal/zoneverify"
+)
+
+// setupAPIRoutes registers the JSON API routes. Requests authenticate with
+// an API token or a session.
+func (s *Service) setupAPIRoutes(r chi.Router) {
+	r.Route("/api", func(r chi.Router) {
+		r.Use(noStore)
+		r.Get("/zones", s.handleAPIZoneList)
+		r.Get("/search", s.handleAPISearch)
+		r.Post("/zones", s.handleAPIZoneCreate)
+		r.Get("/zones/{zone}", s.handleAPIZoneGet)
+		r.Delete("/zones/{zone}", s.handleAPIZoneDelete)
+		r.Get("/zones/{zone}/records", s.handleAPIRecordList)
+		r.Get("/zones/{zone}/invalid-records", s.handleAPIInvalidRecordList)
+		r.Post("/zones/{zone}/records", s.handleRecordCreate)
+		r.Get("/zones/{zone}/records/{recordId}", s.handleRecordGet)
+		r.Put("/zones/{zone}/records/{recordId}", s.handleRecordUpdate)
+		r.Delete("/zones/{zone}/records/{recordId}", s.handleRecordDelete)
+		r.Get("/zones/{zone}/rrsets", s.handleRRSetList)
+		r.Get("/zones/{zone}/rrsets/{name}/{type}", s.handleRRSetGet)
+		r.Put("/zones/{zone}/rrsets/{name}/{type}", s.handleRRSetReplace)
+		r.Delete("/zones/{zone}/rrsets/{name}/{type}", s.handleRRSetDelete)
+		r.Post("/zones/{zone}/changes", s.handleChangeSet)
+		r.Post("/zones/{zone}/plan", s.handleZonePlan)
+		r.Post("/zones/{zone}/apply", s.handleZoneApply)
+		r.Put("/zones/{zone}/soa", s.handleAPISOAUpdate)
+		r.Post("/replace/plan", s.handleAPIReplacePlan)
+		r.Post("/replace/apply", s.handleAPIReplaceApply)
+		r.Get("/templates", s.handleAPITemplateList)
+		r.Post("/templates", s.handleAPITemplateCreate)
+		r.Get("/templates/{templateId}", s.handleAPITemplateGet)
+		r.Put("/templates/{templateId}", s.handleAPITemplateUpdate)
+		r.Delete("/templates/{templateId}", s.handleAPITemplateDelete)
+		r.Post("/zones/{zone}/templates/{templateId}/plan", s.handleAPIZoneTemplatePlan)
+		r.Post("/zones/{zone}/templates/{templateId}/apply", s.handleAPIZoneTemplateApply)
+		r.Get("/nameservers", s.handleAPINameserversGet)
+		r.Put("/nameservers", s.handleAPINameserversSet)
+		r.Delete("/nameservers", s.handleAPINameserversDelete)
+		r.Get("/tokens", s.handleAPITokenList)
+		r.Post("/tokens", s.handleAPITokenCreateJSON)
+		r.Delete("/tokens/{tokenId}", s.handleAPITokenDeleteJSON)
+	})
+}
+
+// noStore keeps API responses out of caches, so a read following a write
+// always reflects it
+func noStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ZoneResponse is a zone as returned by the API. Pending zones carry the TXT
+// record proving their ownership.
+type ZoneResponse struct {
+	Name         string            `json:"name"`
+	NameUnicode  string            `json:"name_unicode"`
+	Status       string            `json:"status"`
+	VerifiedAt   *time.Time        `json:"verified_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Nameservers  []string          `json:"nameservers"`
+	Verification *ZoneVerification `json:"verification,omitempty"`
+}
+
+// ZoneVerification is the TXT record proving the ownership of a zone
+type ZoneVerification struct {
+	TXTName  string `json:"txt_name"`
+	TXTValue string `json:"txt_value"`
+}
+
+// newZoneResponse converts a zone to its API representation. The
+// nameservers are those of the zone's owner.
+func newZoneResponse(zone *recordmanager.Zone, nameservers []string) ZoneResponse {
+	response := ZoneResponse{
+		Name:        zone.Name,
+		NameUnicode: zone.UnicodeName(),
+		Status:      zone.Status,
+		CreatedAt:   zone.CreatedAt,
+		Nameservers: nameservers,
+	}
+	if zone.VerifiedAt.Valid {
+		response.VerifiedAt = &zone.VerifiedAt.Time
+	}
+	if zone.Pending() {
+		response.Verification = &ZoneVerification{
+			TXTName:  zoneverify.TXTRecordName(zone.Name),
+			TXTValue: zoneverify.TXTRecordValue(zone.VerificationToken),
+		}
+	}
+	return response
+}
+
+// handleAPIZoneList lists a page of the user's zones. The name_prefix and
+// status query parameters filter the zones, sort orders them, and limit and
+// cursor select the page.
+func (s *Service) handleAPIZoneList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageSize, err := pageSizeParam(query)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid limit")
+		return
+	}
+	page, err := s.records.SearchZones(r.Context(), getUserID(r), recordmanager.ZoneFilter{
+		NamePrefix: query.Get("name_prefix"),
+		Status:     query.Get("status"),
+		Sort:       query.Get("sort"),
+		Cursor:     query.Get("cursor"),
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zones")
+		return
+	}
+	nameservers, err := s.zoneChecker.ZoneNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve nameservers")
+		return
+	}
+
+	response := make([]ZoneResponse, len(page.Zones))
+	for i, zone := range page.Zones {
+		response[i] = newZoneResponse(zone, nameservers)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listResponse("zones", response, page.NextCursor))
+}
+
+// handleAPIZoneCreate claims a zone for the user
+func (s *Service) handleAPIZoneCreate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Name string `json:"name"`
+		// TemplateID is the ID of the zone template the zone starts from
+		TemplateID int32 `json:"template_id,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	zone, err := recordmanager.CanonicalZone(payload.Name)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid zone")
+		return
+	}
+
+	var template *recordmanager.ZoneTemplate
+	if payload.TemplateID != 0 {
+		template, err = s.records.GetZoneTemplate(r.Context(), payload.TemplateID, getUserID(r))
+		if err != nil {
+			respondWithRecordError(w, err, "Failed to get zone template")
+			return
+		}
+	}
+
+	created, err := s.createZone(r.Context(), zone, getUserID(r), template)
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid zone template", validationErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, recordmanager.ErrZoneExists):
+		respondWithError(w, http.StatusConflict, "Zone already exists", nil)
+		return
+	case errors.Is(err, recordmanager.ErrZoneTaken):
+		respondWithError(w, http.StatusConflict, "Zone is already in use", nil)
+		return
+	case err != nil:
+		slog.Error("Failed to create zone", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create zone", nil)
+		return
+	}
+	nameservers, err := s.zoneChecker.ZoneNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve nameservers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"zone":   newZoneResponse(created, nameservers),
+	})
+}
+
+// handleAPIZoneGet returns a zone
+func (s *Service) handleAPIZoneGet(w http.ResponseWriter, r *http.Request) {
+	zone, err := s.records.GetZone(r.Context(), chi.URLParam(r, "zone"), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone")
+		return
+	}
+	nameservers, err := s.zoneChecker.ZoneNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve nameservers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newZoneResponse(zone, nameservers))
+}
+
+// handleAPIZoneDelete deletes a zone along with its records
+func (s *Service) handleAPIZoneDelete(w http.ResponseWriter, r *http.Request) {
+	if err := s.records.DeleteZone(r.Context(), chi.URLParam(r, "zone"), getUserID(r)); err != nil {
+		respondWithRecordError(w, err, "Failed to delete zone")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIRecordList lists a page of the records of a zone. The name query
+// parameter only lists the records with the owner name, which looks up
+// records by name rather than ID. The name_prefix, type and content query
+// parameters filter the records, sort orders them, and limit and cursor
+// select the page.
+func (s *Service) handleAPIRecordList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+	zone, err := s.records.GetZone(ctx, chi.URLParam(r, "zone"), userID)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone")
+		return
+	}
+
+	query := r.URL.Query()
+	pageSize, err := pageSizeParam(query)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid limit")
+		return
+	}
+	page, err := s.records.SearchRecords(ctx, zone.Name, userID, recordmanager.RecordFilter{
+		Name:       query.Get("name"),
+		NamePrefix: query.Get("name_prefix"),
+		RecordType: query.Get("type"),
+		Content:    query.Get("content"),
+		Sort:       query.Get("sort"),
+		Cursor:     query.Get("cursor"),
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone records")
+		return
+	}
+
+	response := make([]RecordResponse, len(page.Records))
+	for i, record := range page.Records {
+		response[i] = newRecordResponse(record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listResponse("records", response, page.NextCursor))
+}
+
+// handleAPIInvalidRecordList lists the records of a zone that were set aside
+// as their content was invalid. They are deleted like other records.
+func (s *Service) handleAPIInvalidRecordList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+	zone, err := s.records.GetZone(ctx, chi.URLParam(r, "zone"), userID)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone")
+		return
+	}
+	records, err := s.records.ListInvalidRecords(ctx, zone.Name, userID)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve invalid records")
+		return
+	}
+
+	response := make([]RecordResponse, len(records))
+	for i, record := range records {
+		response[i] = newRecordResponse(record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listResponse("records", response, ""))
+}
+
+// pageSizeParam returns the page size given by the limit query parameter,
+// zero for the default page size
+func pageSizeParam(query url.Values) (int, error) {
+	limit := query.Get("limit")
+	if limit == "" {
+		return 0, nil
+	}
+	pageSize, err := strconv.Atoi(limit)
+	if err != nil || pageSize < 1 {
+		return 0, &recordmanager.ValidationError{
+			Field:   "limit",
+			Message: fmt.Sprintf("limit must be between 1 and %d", recordmanager.MaxPageSize),
+		}
+	}
+	return pageSize, nil
+}
+
+// listResponse returns the response of a page of a list, with the cursor of
+// the next page unless it is the last
+func listResponse(key string, items interface{}, nextCursor string) map[string]interface{} {
+	response := map[string]interface{}{
+		key: items,
+	}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
+	return response
+}
@@ -0,0 +1,60 @@
+package frontend
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIRecordListWithCorruptRecords(t *testing.T) {
+	const userEmail = "user@example.com"
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	s, err := New(testLogger, recordmanager.NewWithQuerier(querier), querier, nil, newTestKeys(t, querier), nil, testConfig)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	user := expectUser(querier, userEmail)
+	querier.EXPECT().GetZone(gomock.Any(), storage.GetZoneParams{Zone: "example.org.", UserID: user.ID}).Return(storage.Zone{
+		Zone:   "example.org.",
+		UserID: user.ID,
+		Status: recordmanager.ZoneStatusActive,
+	}, nil)
+	querier.EXPECT().SearchRecords(gomock.Any(), gomock.Any()).Return([]storage.SearchRecordsRow{
+		{ID: 1, UserID: user.ID, Zone: "example.org.", Name: "www", RecordType: "A", Ttl: sql.NullInt32{Int32: 300, Valid: true}, Content: json.RawMessage(`{"ip":"192.0.2.1"}`), Version: 1},
+		{ID: 2, UserID: user.ID, Zone: "example.org.", Name: "mail", RecordType: "A", Ttl: sql.NullInt32{Int32: 300, Valid: true}, Content: json.RawMessage(`{"ip":"not an address"}`), Version: 1},
+		{ID: 3, UserID: user.ID, Zone: "example.org.", Name: "txt", RecordType: "TXT", Ttl: sql.NullInt32{Int32: 300, Valid: true}, Content: json.RawMessage(`{"text":"hello"}`), Version: 1},
+	}, nil)
+
+	router := chi.NewRouter()
+	s.Router(router)
+	r := withSession(t, s, httptest.NewRequest(http.MethodGet, "/api/zones/example.org/records", nil), userEmail)
+	resp := serve(router, r)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var body struct {
+		Records []RecordResponse `json:"records"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(body.Records) != 3 {
+		t.Fatalf("records = %d, want all 3", len(body.Records))
+	}
+	for _, record := range body.Records {
+		if corrupt := record.ID == 2; corrupt != (record.ContentError != "") {
+			t.Errorf("record %d content error = %q", record.ID, record.ContentError)
+		}
+	}
+	if string(body.Records[1].Content) != `{"ip":"not an address"}` {
+		t.Errorf("corrupt record content = %s, want it as stored", body.Records[1].Content)
+	}
+}
@@ -3,6 +3,8 @@ package frontend
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -22,6 +24,13 @@ const (
 	jwtExpiration = 24 * time.Hour
 	otpExpiration = 10 * time.Minute
 	otpLength     = 6
+
+	// SessionAudience is the audience of session tokens. Requiring it keeps
+	// the other tokens the service signs from being used as sessions.
+	SessionAudience = "session"
+
+	// MaxTokenLifetime is the longest lifetime of any token the frontend signs
+	MaxTokenLifetime = jwtExpiration
 )
 
 // Claims defines the JWT claims structure
@@ -39,7 +48,12 @@ const UserEmailKey contextKey = "userEmail"
 // UserIDKey is the context key for the user ID (UUID)
 const UserIDKey contextKey = "userID"
 
-// authMiddleware checks for a valid JWT token and redirects to login if not present
+// APITokenKey is the context key set on requests authenticated with an API
+// token rather than a session
+const APITokenKey contextKey = "apiToken"
+
+// authMiddleware checks for a valid JWT token and redirects to login if not
+// present. API requests may authenticate with an API token instead.
 func (s *Service) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip auth for login pages
@@ -48,23 +62,24 @@ func (s *Service) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// API requests may authenticate with a token instead of a session
+		if isAPIRequest(r) {
+			if token, ok := bearerToken(r); ok {
+				s.authenticateAPIToken(w, r, next, token)
+				return
+			}
+		}
+
 		// Get the JWT token from cookie
 		cookie, err := r.Cookie(cookieName)
 		if err != nil {
 			// Redirect to login page if cookie not present
-			http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+			s.redirectToLogin(w, r)
 			return
 		}
 
 		// Parse and validate the JWT token
-		token, err := jwt.ParseWithClaims(cookie.Value, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			// Return the secret key used for signing
-			return []byte(s.jwtSecret), nil
-		})
+		token, err := s.keys.Parse(r.Context(), cookie.Value, &Claims{}, jwt.WithAudience(SessionAudience))
 
 		if err != nil || !token.Valid {
 			// Clear the invalid cookie
@@ -78,7 +93,7 @@ func (s *Service) authMiddleware(next http.Handler) http.Handler {
 				SameSite: http.SameSiteLaxMode,
 			})
 			// Redirect to login page
-			http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+			s.redirectToLogin(w, r)
 			return
 		}
 
@@ -88,17 +103,34 @@ func (s *Service) authMiddleware(next http.Handler) http.Handler {
 
 			// Look up the user ID based on email
 			ctx := r.Context()
-			user, err := s.db.GetUserByEmail(ctx, email)
-			// If user doesn't exist, create the user
+			user, err := s.findOrCreateUser(ctx, email)
 			if err != nil {
-				// Only create if it's a "not found" error
-				newUser, createErr := s.db.CreateUser(ctx, email)
-				if createErr != nil {
-					s.logger.Error("Failed to create user", "error", createErr, "email", email)
-					http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+				s.logger.Error("Failed to create user", "error", err, "email", email)
+				s.redirectToLogin(w, r)
+				return
+			}
+
+			// Enforce the two-factor policy, leaving account pages reachable for
+			// enrollment
+			if !strings.HasPrefix(r.URL.Path, "/account") {
+				enrolled, err := s.meetsTwoFactorPolicy(ctx, user.ID)
+				if err != nil {
+					s.logger.Error("Failed to look up second factors", "error", err)
+					if isAPIRequest(r) {
+						respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+						return
+					}
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+				if !enrolled {
+					if isAPIRequest(r) {
+						respondWithError(w, http.StatusForbidden, "Two-factor authentication is required", nil)
+						return
+					}
+					http.Redirect(w, r, "/account?error=Two-factor+authentication+is+required", http.StatusSeeOther)
 					return
 				}
-				user = newUser
 			}
 
 			// Add both email and UUID to the context
@@ -111,23 +143,48 @@ func (s *Service) authMiddleware(next http.Handler) http.Handler {
 		}
 
 		// If we can't extract claims, redirect to login
-		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		s.redirectToLogin(w, r)
 	})
 }
 
+// redirectToLogin sends unauthenticated browsers to the login page. API
+// clients can't follow it, so they get a JSON error instead.
+func (s *Service) redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	if isAPIRequest(r) {
+		respondWithError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+	http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+}
+
 // setupAuthRoutes registers authentication-related routes
 func (s *Service) setupAuthRoutes(r chi.Router) {
 	r.Get("/auth/login", s.handleLoginPage)
 	r.Post("/auth/login", s.handleLoginSubmit)
 	r.Get("/auth/verify", s.handleVerifyPage)
 	r.Post("/auth/verify", s.handleVerifyOTP)
+	r.Get("/auth/magic", s.handleMagicLinkPage)
+	r.Post("/auth/magic", s.handleMagicLink)
+	r.Get("/auth/2fa", s.handleTwoFactorPage)
+	r.Post("/auth/2fa", s.handleVerifyTwoFactor)
+	r.Post("/auth/passkey/begin", s.handlePasskeyLoginBegin)
+	r.Post("/auth/passkey/finish", s.handlePasskeyLoginFinish)
+	r.Post("/auth/2fa/passkey/begin", s.handlePasskeyTwoFactorBegin)
+	r.Post("/auth/2fa/passkey/finish", s.handlePasskeyTwoFactorFinish)
+	r.Get("/auth/oidc/login", s.handleOIDCLogin)
+	r.Get("/auth/oidc/callback", s.handleOIDCCallback)
 	r.Get("/auth/logout", s.handleLogout)
 }
 
-// handleLoginPage displays the login form
+// handleLoginPage displays the login form, filled in with the email of
+// invitation links
 func (s *Service) handleLoginPage(w http.ResponseWriter, r *http.Request) {
 	data := map[string]interface{}{
 		"Error": r.URL.Query().Get("error"),
+		"Email": r.URL.Query().Get("email"),
+	}
+	if s.oidc != nil {
+		data["SSOProvider"] = s.oidc.config.ProviderName
 	}
 	s.templates.ExecuteTemplate(w, "login.html", data)
 }
@@ -159,7 +216,7 @@ func (s *Service) handleLoginSubmit(w http.ResponseWriter, r *http.Request) {
 	// Store the OTP in the database
 	ctx := r.Context()
 	expiresAt := time.Now().Add(otpExpiration)
-	_, err = s.db.CreateOTP(ctx, storage.CreateOTPParams{
+	otpCode, err := s.db.CreateOTP(ctx, storage.CreateOTPParams{
 		Email:     email,
 		Code:      otp,
 		ExpiresAt: expiresAt,
@@ -170,8 +227,16 @@ func (s *Service) handleLoginSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Offer a magic link alongside the code, bound to this browser
+	magicLink, err := s.createMagicLink(w, r, otpCode)
+	if err != nil {
+		s.logger.Error("Failed to create magic link", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
 	// Send the OTP via email
-	err = s.emailService.SendOTP(email, otp)
+	err = s.emailService.SendOTP(email, r.Header.Get("Accept-Language"), otp, magicLink, otpExpiration)
 	if err != nil {
 		s.logger.Error("Failed to send OTP email", "error", err)
 		http.Redirect(w, r, "/auth/login?error=Failed+to+send+email", http.StatusSeeOther)
@@ -229,14 +294,76 @@ func (s *Service) handleVerifyOTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// If we get here, the OTP is valid and consumed
-	// Create a JWT token for the user
-	token, err := s.createJWTToken(email)
+	s.beginLogin(w, r, email)
+}
+
+// beginLogin is called once the primary factor has been verified. It either
+// completes the login or, when the user has enrolled a second factor, hands
+// over to the two-factor step.
+func (s *Service) beginLogin(w http.ResponseWriter, r *http.Request, email string) {
+	// Users signing in for the first time have no second factor yet
+	user, err := s.db.GetUserByEmail(r.Context(), email)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.completeLogin(w, r, email)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to look up user", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	enrolled, err := s.hasSecondFactor(r.Context(), user.ID)
 	if err != nil {
+		s.logger.Error("Failed to look up second factors", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !enrolled {
+		s.completeLogin(w, r, email)
+		return
+	}
+
+	// Issue a short-lived token that only grants access to the two-factor step
+	token, err := s.createTwoFactorToken(email)
+	if err != nil {
+		s.logger.Error("Failed to create two-factor token", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     twoFactorCookieName,
+		Value:    token,
+		Path:     "/auth/",
+		Expires:  time.Now().Add(twoFactorExpiration),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, "/auth/2fa", http.StatusSeeOther)
+}
+
+// completeLogin sets the session cookie for the email and redirects home
+func (s *Service) completeLogin(w http.ResponseWriter, r *http.Request, email string) {
+	if err := s.setSessionCookie(w, r, email); err != nil {
 		s.logger.Error("Failed to create JWT token", "error", err)
 		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
 		return
 	}
 
+	// Redirect to the home page
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// setSessionCookie issues a JWT for the email and sets it as the auth cookie
+func (s *Service) setSessionCookie(w http.ResponseWriter, r *http.Request, email string) error {
+	// Create a JWT token for the user
+	token, err := s.createJWTToken(email)
+	if err != nil {
+		return err
+	}
+
 	// Set the JWT as a cookie
 	http.SetCookie(w, &http.Cookie{
 		Name:     cookieName,
@@ -247,9 +374,7 @@ func (s *Service) handleVerifyOTP(w http.ResponseWriter, r *http.Request) {
 		Secure:   r.TLS != nil,
 		SameSite: http.SameSiteLaxMode,
 	})
-
-	// Redirect to the home page
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	return nil
 }
 
 // handleLogout logs out the user by clearing the auth cookie
@@ -297,17 +422,17 @@ func (s *Service) createJWTToken(email string) (string, error) {
 	claims := &Claims{
 		Email: email,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Subject:   email,
+			Audience:  jwt.ClaimStrings{SessionAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	// Create a new token with the claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with the secret key
-	return token.SignedString([]byte(s.jwtSecret))
+	// Sign the token with the current signing key
+	return s.keys.Sign(claims)
 }
 
 // Configuration helpers
@@ -343,6 +468,22 @@ func getEmailFrom() string {
 	return from
 }
 
+// findOrCreateUser looks up the user by email, creating it on first login
+func (s *Service) findOrCreateUser(ctx context.Context, email string) (storage.User, error) {
+	if email == "" {
+		return storage.User{}, errors.New("email is required")
+	}
+
+	user, err := s.db.GetUserByEmail(ctx, email)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return storage.User{}, err
+	}
+	return s.db.CreateUser(ctx, email)
+}
+
 // getUserEmail gets the user email from the request context
 func getUserEmail(r *http.Request) string {
 	if email, ok := r.Context().Value(UserEmailKey).(string); ok {
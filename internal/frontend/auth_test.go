@@ -0,0 +1,82 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+func TestAuthMiddlewareRequiresSessionToken(t *testing.T) {
+	const email = "user@example.com"
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T, s *Service) string
+		want  int
+	}{
+		{
+			name: "session",
+			token: func(t *testing.T, s *Service) string {
+				token, err := s.createJWTToken(email)
+				if err != nil {
+					t.Fatalf("createJWTToken() error = %v", err)
+				}
+				return token
+			},
+			want: http.StatusOK,
+		},
+		{
+			name: "magic link",
+			token: func(t *testing.T, s *Service) string {
+				w := httptest.NewRecorder()
+				link, err := s.createMagicLink(w, httptest.NewRequest(http.MethodPost, "/auth/login", nil), storage.OtpCode{
+					ID:        1,
+					Email:     email,
+					ExpiresAt: time.Now().Add(otpExpiration),
+				})
+				if err != nil {
+					t.Fatalf("createMagicLink() error = %v", err)
+				}
+				r := httptest.NewRequest(http.MethodGet, link, nil)
+				return r.URL.Query().Get("token")
+			},
+			want: http.StatusSeeOther,
+		},
+		{
+			name: "two-factor",
+			token: func(t *testing.T, s *Service) string {
+				token, err := s.createTwoFactorToken(email)
+				if err != nil {
+					t.Fatalf("createTwoFactorToken() error = %v", err)
+				}
+				return token
+			},
+			want: http.StatusSeeOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			expectUser(querier, email)
+
+			handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := getUserEmail(r); got != email {
+					t.Errorf("user email = %q, want %q", got, email)
+				}
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: cookieName, Value: tt.token(t, s)})
+
+			resp := serve(handler, r)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusSeeOther && resp.Header.Get("Location") != "/auth/login" {
+				t.Errorf("Location = %q, want /auth/login", resp.Header.Get("Location"))
+			}
+		})
+	}
+}
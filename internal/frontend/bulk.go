@@ -0,0 +1,205 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// bulkReplacePayload is a bulk replacement as submitted to the API. An empty
+// zone replaces the value in all of the user's zones.
+type bulkReplacePayload struct {
+	Zone        string `json:"zone,omitempty"`
+	RecordType  string `json:"record_type"`
+	Value       string `json:"value"`
+	Replacement string `json:"replacement"`
+	PlanHash    string `json:"plan_hash,omitempty"`
+}
+
+// BulkReplacePlanResponse is the plan of a bulk replacement as returned by
+// the API
+type BulkReplacePlanResponse struct {
+	Hash    string                  `json:"hash"`
+	Changes []PlannedChangeResponse `json:"changes"`
+}
+
+// replaceRow is an update of a bulk replacement as shown on the replace page
+type replaceRow struct {
+	// Record is the record before the update
+	*recordmanager.Record
+	Content    string
+	NewContent string
+}
+
+// handleAPIReplacePlan previews the updates of a bulk replacement without
+// applying them
+func (s *Service) handleAPIReplacePlan(w http.ResponseWriter, r *http.Request) {
+	var payload bulkReplacePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	plan, err := s.records.PlanBulkReplace(r.Context(), getUserID(r), payload.bulkReplace())
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to plan replacement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newBulkReplacePlanResponse(plan))
+}
+
+// handleAPIReplaceApply applies a bulk replacement atomically, provided the
+// plan with the submitted hash still applies
+func (s *Service) handleAPIReplaceApply(w http.ResponseWriter, r *http.Request) {
+	var payload bulkReplacePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+	if payload.PlanHash == "" {
+		respondWithError(w, http.StatusBadRequest, "Plan hash is required", nil)
+		return
+	}
+
+	plan, results, err := s.records.ApplyBulkReplace(r.Context(), getUserID(r), payload.bulkReplace(), payload.PlanHash)
+	if errors.Is(err, recordmanager.ErrZoneChanged) {
+		respondWithError(w, http.StatusConflict, "Records have changed since the plan was made, plan again", nil)
+		return
+	}
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to apply replacement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"plan":    newBulkReplacePlanResponse(plan),
+		"results": newChangeResultResponses(results),
+	})
+}
+
+// handleReplace renders the replace page, previewing the updates of the bulk
+// replacement given by the query parameters
+func (s *Service) handleReplace(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	replace := recordmanager.BulkReplace{
+		Zone:        query.Get("zone"),
+		RecordType:  query.Get("type"),
+		Value:       query.Get("value"),
+		Replacement: query.Get("replacement"),
+	}
+	s.renderReplace(w, r, replace, nil)
+}
+
+// handleReplaceApply applies the bulk replacement previewed on the replace
+// page
+func (s *Service) handleReplaceApply(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	replace := recordmanager.BulkReplace{
+		Zone:        r.Form.Get("zone"),
+		RecordType:  r.Form.Get("type"),
+		Value:       r.Form.Get("value"),
+		Replacement: r.Form.Get("replacement"),
+	}
+
+	data := map[string]interface{}{}
+	_, results, err := s.records.ApplyBulkReplace(r.Context(), getUserID(r), replace, r.Form.Get("plan_hash"))
+	var changeErr *recordmanager.ChangeError
+	switch {
+	case errors.Is(err, recordmanager.ErrZoneChanged):
+		data["Error"] = "Records have changed since the preview, review the changes again"
+	case errors.As(err, &changeErr):
+		data["Error"] = changeErr.Err.Error()
+	case err != nil:
+		if validationErrors, ok := recordValidationErrors(err); ok {
+			data["Error"] = validationErrors[0].Message
+			break
+		}
+		slog.Error("Failed to apply replacement", "error", err)
+		http.Error(w, "Failed to apply replacement", http.StatusInternalServerError)
+		return
+	default:
+		data["Message"] = fmt.Sprintf("Replaced the value of %d records", len(results))
+	}
+	s.renderReplace(w, r, replace, data)
+}
+
+// renderReplace renders the replace page with the form filled in from the
+// bulk replacement and the preview of its updates
+func (s *Service) renderReplace(w http.ResponseWriter, r *http.Request, replace recordmanager.BulkReplace, data map[string]interface{}) {
+	ctx := r.Context()
+	userID := getUserID(r)
+	zones, err := s.records.ListUserZones(ctx, userID)
+	if err != nil {
+		slog.Error("Failed to retrieve zones", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["Zones"] = zones
+	data["RecordTypes"] = recordmanager.ReplaceTypes
+	data["Replace"] = replace
+
+	// Preview the updates unless they have just been applied
+	if replace.Value != "" && replace.Replacement != "" && data["Message"] == nil {
+		plan, err := s.records.PlanBulkReplace(ctx, userID, replace)
+		if validationErrors, ok := recordValidationErrors(err); ok {
+			data["Error"] = validationErrors[0].Message
+		} else if errors.Is(err, recordmanager.ErrZoneNotFound) {
+			data["Error"] = "Zone not found"
+		} else if err != nil {
+			slog.Error("Failed to plan replacement", "error", err)
+			http.Error(w, "Failed to plan replacement", http.StatusInternalServerError)
+			return
+		} else {
+			rows := make([]replaceRow, len(plan.Changes))
+			for i, change := range plan.Changes {
+				content, _ := recordmanager.ContentJSON(change.Current)
+				newContent, _ := recordmanager.ContentJSON(change.Record)
+				rows[i] = replaceRow{Record: change.Current, Content: content, NewContent: newContent}
+			}
+			data["Rows"] = rows
+			data["PlanHash"] = plan.Hash
+			data["Previewed"] = true
+		}
+	}
+
+	if err := s.templates.ExecuteTemplate(w, "replace.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// bulkReplace returns the bulk replacement of the payload
+func (p bulkReplacePayload) bulkReplace() recordmanager.BulkReplace {
+	return recordmanager.BulkReplace{
+		Zone:        p.Zone,
+		RecordType:  p.RecordType,
+		Value:       p.Value,
+		Replacement: p.Replacement,
+	}
+}
+
+// newBulkReplacePlanResponse converts a bulk replacement plan to its API
+// representation
+func newBulkReplacePlanResponse(plan *recordmanager.BulkReplacePlan) BulkReplacePlanResponse {
+	return BulkReplacePlanResponse{
+		Hash:    plan.Hash,
+		Changes: newPlannedChangeResponses(plan.Changes),
+	}
+}
@@ -0,0 +1,166 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// changeSetPayload is a change set as submitted to the API. Updates and
+// deletes must have the version of the record they expect, or * for any.
+type changeSetPayload struct {
+	Changes []struct {
+		Action  string          `json:"action"`
+		ID      int64           `json:"id,omitempty"`
+		Version json.RawMessage `json:"version,omitempty"`
+		Record  *recordPayload  `json:"record,omitempty"`
+	} `json:"changes"`
+}
+
+// errVersionRequired is returned when a change updating or deleting a record
+// doesn't say which version of the record it expects
+var errVersionRequired = errors.New("version is required")
+
+// ChangeResultResponse is the result of a change as returned by the API
+type ChangeResultResponse struct {
+	Action string          `json:"action"`
+	ID     int64           `json:"id"`
+	Record *RecordResponse `json:"record,omitempty"`
+}
+
+// handleChangeSet applies a set of record changes to the zone atomically.
+// Either all changes are applied and their results returned, or none are and
+// the errors identify the failing change by its index. Updates and deletes
+// fail if the record has changed since the version they expect.
+func (s *Service) handleChangeSet(w http.ResponseWriter, r *http.Request) {
+	zone := chi.URLParam(r, "zone")
+	if zone == "" {
+		respondWithError(w, http.StatusBadRequest, "Zone is required", nil)
+		return
+	}
+
+	var payload changeSetPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+	if len(payload.Changes) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one change is required", nil)
+		return
+	}
+
+	changes := make([]recordmanager.Change, len(payload.Changes))
+	for i, change := range payload.Changes {
+		version, err := changeVersion(change.Action, change.Version)
+		if errors.Is(err, errVersionRequired) {
+			respondWithError(w, http.StatusPreconditionRequired, fmt.Sprintf("change %d: %v", i, err), nil)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("change %d: %v", i, err), nil)
+			return
+		}
+		changes[i] = recordmanager.Change{
+			Action:  change.Action,
+			ID:      change.ID,
+			Version: version,
+		}
+		if change.Record == nil {
+			continue
+		}
+
+		record, validationErrors, contentErr := change.Record.toRecord()
+		if contentErr != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("change %d: %v", i, contentErr), nil)
+			return
+		}
+		if len(validationErrors) > 0 {
+			respondWithError(w, http.StatusBadRequest, "Validation failed", withIndex(validationErrors, i))
+			return
+		}
+		changes[i].Record = record
+	}
+
+	results, err := s.records.ApplyChangeSet(r.Context(), zone, getUserID(r), changes)
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to apply changes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"results": newChangeResultResponses(results),
+	})
+}
+
+// changeVersion returns the version of the record a change expects, zero
+// for * matching any version. Creates expect no version.
+func changeVersion(action string, value json.RawMessage) (int32, error) {
+	if action != recordmanager.ChangeUpdate && action != recordmanager.ChangeDelete {
+		return 0, nil
+	}
+	if len(value) == 0 || string(value) == "null" {
+		return 0, errVersionRequired
+	}
+	if string(value) == `"*"` {
+		return 0, nil
+	}
+
+	var version int32
+	if err := json.Unmarshal(value, &version); err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid version: %s", value)
+	}
+	return version, nil
+}
+
+// respondWithChangeError writes the JSON error response for an error
+// returned when applying changes, identifying the failing change
+func respondWithChangeError(w http.ResponseWriter, err error, message string) {
+	var changeErr *recordmanager.ChangeError
+	if errors.As(err, &changeErr) {
+		if validationErrors, ok := recordValidationErrors(changeErr.Err); ok {
+			respondWithError(w, http.StatusBadRequest, "Validation failed", withIndex(validationErrors, changeErr.Index))
+			return
+		}
+		if errors.Is(err, recordmanager.ErrRecordNotFound) {
+			respondWithError(w, http.StatusNotFound, changeErr.Error(), nil)
+			return
+		}
+		if errors.Is(err, recordmanager.ErrRecordChanged) {
+			respondWithError(w, http.StatusPreconditionFailed, changeErr.Error(), nil)
+			return
+		}
+	}
+	respondWithRecordError(w, err, message)
+}
+
+// newChangeResultResponses converts change results to their API
+// representation
+func newChangeResultResponses(results []recordmanager.ChangeResult) []ChangeResultResponse {
+	response := make([]ChangeResultResponse, len(results))
+	for i, result := range results {
+		response[i] = ChangeResultResponse{
+			Action: result.Action,
+			ID:     result.ID,
+		}
+		if result.Record != nil {
+			record := newRecordResponse(result.Record)
+			response[i].Record = &record
+		}
+	}
+	return response
+}
+
+// withIndex sets the index of the change the validation errors belong to
+func withIndex(validationErrors []ValidationError, index int) []ValidationError {
+	for i := range validationErrors {
+		validationErrors[i].Index = &index
+	}
+	return validationErrors
+}
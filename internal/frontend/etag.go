@@ -0,0 +1,99 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// errPreconditionRequired is returned when a request changing a record
+// doesn't say which version of the record it expects
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+// recordETag returns the entity tag of the record's current version
+func recordETag(record *recordmanager.Record) string {
+	return fmt.Sprintf(`"%d"`, record.Version)
+}
+
+// expectedVersion returns the version of the record a request changing it
+// expects, from its If-Match header or, for HTML forms, its version field.
+// An If-Match of * matches any version and returns zero.
+func expectedVersion(r *http.Request) (int32, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		value = r.PostFormValue("version")
+	}
+	if value == "" {
+		return 0, errPreconditionRequired
+	}
+	if value == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 32)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid If-Match header: %s", value)
+	}
+	return int32(version), nil
+}
+
+// respondWithVersionError writes the response for a missing or invalid
+// If-Match header
+func respondWithVersionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPreconditionRequired) {
+		respondWithError(w, http.StatusPreconditionRequired, err.Error(), nil)
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+}
+
+// respondWithRecordChanged writes a 412 response with the current state of a
+// record that has changed since the client loaded it
+func (s *Service) respondWithRecordChanged(w http.ResponseWriter, r *http.Request, id int64, zone string) {
+	record, err := s.records.GetRecord(r.Context(), id, zone, getUserID(r))
+	if errors.Is(err, recordmanager.ErrRecordNotFound) {
+		respondWithError(w, http.StatusNotFound, "Record has been deleted", nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to retrieve record", "error", err, "zone", zone)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve record", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", recordETag(record))
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "error",
+		"message": "Record has been changed since it was loaded, reload to see the current version",
+		"record":  newRecordResponse(record),
+	})
+}
+
+// handleRecordGet returns a record with its version as the ETag
+func (s *Service) handleRecordGet(w http.ResponseWriter, r *http.Request) {
+	zone := chi.URLParam(r, "zone")
+	recordId, err := strconv.ParseInt(chi.URLParam(r, "recordId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Record ID is not a number", nil)
+		return
+	}
+
+	record, err := s.records.GetRecord(r.Context(), recordId, zone, getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve record")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", recordETag(record))
+	json.NewEncoder(w).Encode(newRecordResponse(record))
+}
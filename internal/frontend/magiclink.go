@@ -0,0 +1,141 @@
+package frontend
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// Magic link settings
+	magicLinkCookieName = "tofudns_magic"
+	magicLinkAudience   = "magic-link"
+)
+
+// magicLinkClaims identify the OTP a magic link redeems and the browser it is
+// bound to
+type magicLinkClaims struct {
+	OTPID     int32  `json:"otp"`
+	Email     string `json:"email"`
+	NonceHash string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// createMagicLink binds a magic link for the OTP to the requesting browser
+// with a nonce cookie and returns the link's URL
+func (s *Service) createMagicLink(w http.ResponseWriter, r *http.Request, otp storage.OtpCode) (string, error) {
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	claims := &magicLinkClaims{
+		OTPID:     otp.ID,
+		Email:     otp.Email,
+		NonceHash: hashNonce(nonce),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{magicLinkAudience},
+			ExpiresAt: jwt.NewNumericDate(otp.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkCookieName,
+		Value:    nonce,
+		Path:     "/auth/",
+		Expires:  otp.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return s.baseURL + "/auth/magic?token=" + url.QueryEscape(token), nil
+}
+
+// handleMagicLinkPage asks for confirmation before redeeming a magic link, so
+// that link scanners in mail clients can't consume it
+func (s *Service) handleMagicLinkPage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	claims := &magicLinkClaims{}
+	if _, err := s.keys.Parse(r.Context(), token, claims, jwt.WithAudience(magicLinkAudience)); err != nil {
+		http.Redirect(w, r, "/auth/login?error=Login+link+expired", http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Email": claims.Email,
+		"Token": token,
+	}
+	if err := s.templates.ExecuteTemplate(w, "magic_link.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleMagicLink redeems a magic link in the browser that requested it
+func (s *Service) handleMagicLink(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/auth/login?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	claims := &magicLinkClaims{}
+	if _, err := s.keys.Parse(r.Context(), r.Form.Get("token"), claims, jwt.WithAudience(magicLinkAudience)); err != nil {
+		http.Redirect(w, r, "/auth/login?error=Login+link+expired", http.StatusSeeOther)
+		return
+	}
+
+	// The link only works in the browser that started the login
+	cookie, err := r.Cookie(magicLinkCookieName)
+	if err != nil || subtle.ConstantTimeCompare([]byte(hashNonce(cookie.Value)), []byte(claims.NonceHash)) != 1 {
+		http.Redirect(w, r, "/auth/login?error=Open+the+login+link+in+the+browser+where+you+requested+it", http.StatusSeeOther)
+		return
+	}
+
+	// Redeeming the link consumes its OTP, so the link and the code are
+	// single-use together
+	_, err = s.db.ConsumeOTPByID(r.Context(), storage.ConsumeOTPByIDParams{
+		ID:    claims.OTPID,
+		Email: claims.Email,
+	})
+	if err != nil {
+		http.Redirect(w, r, "/auth/login?error=Login+link+expired", http.StatusSeeOther)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkCookieName,
+		Value:    "",
+		Path:     "/auth/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	s.beginLogin(w, r, claims.Email)
+}
+
+// hashNonce returns the hex-encoded SHA-256 hash of a nonce
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
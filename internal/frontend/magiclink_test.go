@@ -0,0 +1,187 @@
+package frontend
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// newMagicLink creates a magic link for an OTP expiring at the time,
+// returning its token and the nonce cookie binding it to the browser
+func newMagicLink(t *testing.T, s *Service, expiresAt time.Time) (string, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	link, err := s.createMagicLink(w, httptest.NewRequest(http.MethodPost, "/auth/login", nil), storage.OtpCode{
+		ID:        1,
+		Email:     "user@example.com",
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("createMagicLink() error = %v", err)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parsing link: %v", err)
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == magicLinkCookieName {
+			return u.Query().Get("token"), cookie
+		}
+	}
+	t.Fatalf("createMagicLink() set no nonce cookie")
+	return "", nil
+}
+
+// redeemMagicLink posts the token with the nonce cookie, if any
+func redeemMagicLink(s *Service, token string, nonce *http.Cookie) *http.Response {
+	r := httptest.NewRequest(http.MethodPost, "/auth/magic", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if nonce != nil {
+		r.AddCookie(nonce)
+	}
+	return serve(http.HandlerFunc(s.handleMagicLink), r)
+}
+
+// sessionCookie returns the session cookie set by the response, if any
+func sessionCookie(resp *http.Response) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == cookieName && cookie.Value != "" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestMagicLink(t *testing.T) {
+	s, querier := newTestService(t, testConfig)
+	token, nonce := newMagicLink(t, s, time.Now().Add(otpExpiration))
+	querier.EXPECT().ConsumeOTPByID(gomock.Any(), storage.ConsumeOTPByIDParams{ID: 1, Email: "user@example.com"}).Return(storage.OtpCode{ID: 1}, nil)
+	querier.EXPECT().GetUserByEmail(gomock.Any(), "user@example.com").Return(storage.User{}, sql.ErrNoRows)
+
+	resp := redeemMagicLink(s, token, nonce)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/" {
+		t.Fatalf("response = %d to %q, want a redirect home", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if sessionCookie(resp) == nil {
+		t.Errorf("no session cookie set")
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == magicLinkCookieName && cookie.Value != "" {
+			t.Errorf("nonce cookie not cleared")
+		}
+	}
+}
+
+func TestMagicLinkRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		// redeem sends the link, expecting the querier calls it makes
+		redeem func(t *testing.T, s *Service, querier *storage.MockQuerier) *http.Response
+		error  string
+	}{
+		{
+			name: "missing nonce cookie",
+			redeem: func(t *testing.T, s *Service, _ *storage.MockQuerier) *http.Response {
+				token, _ := newMagicLink(t, s, time.Now().Add(otpExpiration))
+				return redeemMagicLink(s, token, nil)
+			},
+			error: "Open the login link in the browser where you requested it",
+		},
+		{
+			name: "wrong nonce cookie",
+			redeem: func(t *testing.T, s *Service, _ *storage.MockQuerier) *http.Response {
+				token, _ := newMagicLink(t, s, time.Now().Add(otpExpiration))
+				// The cookie of another login in another browser
+				_, other := newMagicLink(t, s, time.Now().Add(otpExpiration))
+				return redeemMagicLink(s, token, other)
+			},
+			error: "Open the login link in the browser where you requested it",
+		},
+		{
+			name: "consumed OTP",
+			redeem: func(t *testing.T, s *Service, querier *storage.MockQuerier) *http.Response {
+				token, nonce := newMagicLink(t, s, time.Now().Add(otpExpiration))
+				querier.EXPECT().ConsumeOTPByID(gomock.Any(), gomock.Any()).Return(storage.OtpCode{}, sql.ErrNoRows)
+				return redeemMagicLink(s, token, nonce)
+			},
+			error: "Login link expired",
+		},
+		{
+			name: "expired link",
+			redeem: func(t *testing.T, s *Service, _ *storage.MockQuerier) *http.Response {
+				token, nonce := newMagicLink(t, s, time.Now().Add(-time.Minute))
+				return redeemMagicLink(s, token, nonce)
+			},
+			error: "Login link expired",
+		},
+		{
+			name: "session token",
+			redeem: func(t *testing.T, s *Service, _ *storage.MockQuerier) *http.Response {
+				_, nonce := newMagicLink(t, s, time.Now().Add(otpExpiration))
+				token, err := s.createJWTToken("user@example.com")
+				if err != nil {
+					t.Fatalf("createJWTToken() error = %v", err)
+				}
+				return redeemMagicLink(s, token, nonce)
+			},
+			error: "Login link expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The querier fails the test if an unexpected OTP is consumed
+			s, querier := newTestService(t, testConfig)
+			resp := tt.redeem(t, s, querier)
+
+			want := "/auth/login?error=" + url.QueryEscape(tt.error)
+			if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != want {
+				t.Errorf("response = %d to %q, want a redirect to %q", resp.StatusCode, resp.Header.Get("Location"), want)
+			}
+			if sessionCookie(resp) != nil {
+				t.Errorf("session cookie set")
+			}
+		})
+	}
+}
+
+func TestMagicLinkReplay(t *testing.T) {
+	s, querier := newTestService(t, testConfig)
+	token, nonce := newMagicLink(t, s, time.Now().Add(otpExpiration))
+	consumed := false
+	querier.EXPECT().ConsumeOTPByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ConsumeOTPByIDParams) (storage.OtpCode, error) {
+		if consumed {
+			return storage.OtpCode{}, sql.ErrNoRows
+		}
+		consumed = true
+		return storage.OtpCode{ID: arg.ID}, nil
+	}).Times(2)
+	querier.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(storage.User{}, sql.ErrNoRows)
+
+	if resp := redeemMagicLink(s, token, nonce); sessionCookie(resp) == nil {
+		t.Fatalf("first redemption set no session cookie")
+	}
+	resp := redeemMagicLink(s, token, nonce)
+	if resp.Header.Get("Location") != "/auth/login?error=Login+link+expired" || sessionCookie(resp) != nil {
+		t.Errorf("replayed link redirected to %q, want the login page", resp.Header.Get("Location"))
+	}
+}
+
+func TestMagicLinkPageExpired(t *testing.T) {
+	s, _ := newTestService(t, testConfig)
+	token, _ := newMagicLink(t, s, time.Now().Add(-time.Minute))
+
+	// The confirmation page doesn't consume the OTP, and rejects expired links
+	r := httptest.NewRequest(http.MethodGet, "/auth/magic?token="+url.QueryEscape(token), nil)
+	resp := serve(http.HandlerFunc(s.handleMagicLinkPage), r)
+	if resp.Header.Get("Location") != "/auth/login?error=Login+link+expired" {
+		t.Errorf("Location = %q, want the login page", resp.Header.Get("Location"))
+	}
+}
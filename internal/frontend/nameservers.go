@@ -0,0 +1,284 @@
+package frontend
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/internal/storage"
+	"github.com/tofudns/tofudns/internal/zoneverify"
+)
+
+// errNoOrganization is returned when a user who belongs to no organization
+// sets vanity nameservers, which belong to organizations
+var errNoOrganization = errors.New("vanity nameservers belong to an organization, ask an administrator to add you to one")
+
+// NameserversResponse is the service's nameservers and the vanity nameservers
+// of the user's organization standing in for them, as returned by the API
+type NameserversResponse struct {
+	Nameservers []string       `json:"nameservers"`
+	Vanity      []string       `json:"vanity"`
+	Glue        []GlueResponse `json:"glue,omitempty"`
+}
+
+// GlueResponse is the addresses a vanity nameserver must resolve to, as
+// returned by the API
+type GlueResponse struct {
+	Host       string   `json:"host"`
+	Nameserver string   `json:"nameserver"`
+	Addresses  []string `json:"addresses"`
+}
+
+// vanityRow is a service nameserver and the vanity nameserver standing in for
+// it as shown on the nameservers page
+type vanityRow struct {
+	Nameserver string
+	Host       string
+}
+
+// glueRecord is an address record of a vanity nameserver as shown on the
+// nameservers page
+type glueRecord struct {
+	Host       string
+	RecordType string
+	Address    string
+}
+
+// handleAPINameserversGet returns the vanity nameservers of the user's
+// organization and the addresses they must resolve to
+func (s *Service) handleAPINameserversGet(w http.ResponseWriter, r *http.Request) {
+	vanity, err := s.zoneChecker.VanityNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve vanity nameservers")
+		return
+	}
+	s.respondWithNameservers(w, r, vanity)
+}
+
+// handleAPINameserversSet replaces the vanity nameservers of the user's
+// organization
+func (s *Service) handleAPINameserversSet(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Vanity []string `json:"vanity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	vanity, err := s.setVanityNameservers(r, payload.Vanity)
+	if errors.Is(err, errNoOrganization) {
+		respondWithError(w, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+	if errors.Is(err, zoneverify.ErrInvalidVanityNameservers) {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to set vanity nameservers")
+		return
+	}
+	s.respondWithNameservers(w, r, vanity)
+}
+
+// handleAPINameserversDelete removes the vanity nameservers of the user's
+// organization
+func (s *Service) handleAPINameserversDelete(w http.ResponseWriter, r *http.Request) {
+	err := s.clearVanityNameservers(r.Context(), getUserID(r))
+	if errors.Is(err, errNoOrganization) {
+		respondWithError(w, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to delete vanity nameservers")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithNameservers writes the service's nameservers and the vanity
+// nameservers, with their glue, as the JSON response
+func (s *Service) respondWithNameservers(w http.ResponseWriter, r *http.Request, vanity []string) {
+	response := NameserversResponse{
+		Nameservers: s.zoneChecker.Nameservers(),
+		Vanity:      vanity,
+	}
+	glue, err := s.zoneChecker.Glue(r.Context(), vanity)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to look up nameserver addresses")
+		return
+	}
+	for _, g := range glue {
+		response.Glue = append(response.Glue, GlueResponse{
+			Host:       g.Host,
+			Nameserver: g.Nameserver,
+			Addresses:  g.Addresses,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleNameservers renders the vanity nameservers page
+func (s *Service) handleNameservers(w http.ResponseWriter, r *http.Request) {
+	vanity, err := s.zoneChecker.VanityNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		slog.Error("Failed to retrieve vanity nameservers", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.renderNameservers(w, r, vanity, vanity, "")
+}
+
+// handleNameserversSet replaces the vanity nameservers of the user's
+// organization from the form of the nameservers page
+func (s *Service) handleNameserversSet(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	hosts := r.Form["host"]
+
+	_, err := s.setVanityNameservers(r, hosts)
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		s.renderVanityError(w, r, hosts, validationErrors[0].Message)
+		return
+	}
+	if errors.Is(err, zoneverify.ErrInvalidVanityNameservers) || errors.Is(err, errNoOrganization) {
+		s.renderVanityError(w, r, hosts, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to set vanity nameservers", "error", err)
+		http.Error(w, "Failed to set vanity nameservers", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/nameservers", http.StatusSeeOther)
+}
+
+// handleNameserversDelete removes the vanity nameservers of the user's
+// organization
+func (s *Service) handleNameserversDelete(w http.ResponseWriter, r *http.Request) {
+	err := s.clearVanityNameservers(r.Context(), getUserID(r))
+	if err != nil && !errors.Is(err, errNoOrganization) {
+		slog.Error("Failed to delete vanity nameservers", "error", err)
+		http.Error(w, "Failed to delete vanity nameservers", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/nameservers", http.StatusSeeOther)
+}
+
+// renderVanityError renders the nameservers page with the hosts the user
+// entered and why they can't be used
+func (s *Service) renderVanityError(w http.ResponseWriter, r *http.Request, hosts []string, message string) {
+	vanity, err := s.zoneChecker.VanityNameservers(r.Context(), getUserID(r))
+	if err != nil {
+		slog.Error("Failed to retrieve vanity nameservers", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.renderNameservers(w, r, vanity, hosts, message)
+}
+
+// renderNameservers renders the nameservers page with the vanity nameservers
+// of the user's organization and their glue, and the form filled in with the
+// hosts
+func (s *Service) renderNameservers(w http.ResponseWriter, r *http.Request, vanity, hosts []string, message string) {
+	organization, err := s.userOrganization(r.Context(), getUserID(r))
+	if err != nil && !errors.Is(err, errNoOrganization) {
+		slog.Error("Failed to look up organization", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	nameservers := s.zoneChecker.Nameservers()
+	rows := make([]vanityRow, len(nameservers))
+	for i, nameserver := range nameservers {
+		rows[i].Nameserver = nameserver
+		if i < len(hosts) {
+			rows[i].Host = hosts[i]
+		}
+	}
+
+	data := map[string]interface{}{
+		"Organization": organization,
+		"Rows":         rows,
+		"Vanity":       vanity,
+		"Error":        message,
+	}
+	if len(vanity) > 0 {
+		// The glue is guidance only, so a failed lookup doesn't fail the page
+		glue, err := s.zoneChecker.Glue(r.Context(), vanity)
+		if err != nil {
+			data["GlueError"] = err.Error()
+		}
+		var records []glueRecord
+		for _, g := range glue {
+			for _, addr := range g.Addresses {
+				recordType := "A"
+				if strings.Contains(addr, ":") {
+					recordType = "AAAA"
+				}
+				records = append(records, glueRecord{Host: g.Host, RecordType: recordType, Address: addr})
+			}
+		}
+		data["Glue"] = records
+	}
+
+	if err := s.templates.ExecuteTemplate(w, "nameservers.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// setVanityNameservers validates the hostnames of the vanity nameservers of
+// the user's organization and replaces them
+func (s *Service) setVanityNameservers(r *http.Request, hosts []string) ([]string, error) {
+	organization, err := s.userOrganization(r.Context(), getUserID(r))
+	if err != nil {
+		return nil, err
+	}
+	canonical := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host, err := recordmanager.CanonicalHostname("vanity", strings.TrimSpace(host))
+		if err != nil {
+			return nil, err
+		}
+		canonical = append(canonical, host)
+	}
+	return s.zoneChecker.SetVanityNameservers(r.Context(), organization.ID, canonical)
+}
+
+// clearVanityNameservers removes the vanity nameservers of the user's
+// organization
+func (s *Service) clearVanityNameservers(ctx context.Context, userID uuid.UUID) error {
+	organization, err := s.userOrganization(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.zoneChecker.ClearVanityNameservers(ctx, organization.ID)
+}
+
+// userOrganization returns the user's organization, or errNoOrganization if
+// the user belongs to none
+func (s *Service) userOrganization(ctx context.Context, userID uuid.UUID) (*storage.Organization, error) {
+	organization, err := s.db.GetUserOrganization(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNoOrganization
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
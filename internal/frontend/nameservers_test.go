@@ -0,0 +1,81 @@
+package frontend
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+	"github.com/tofudns/tofudns/internal/zoneverify"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPINameserversOfOrganization(t *testing.T) {
+	const email = "member@example.com"
+	organization := storage.Organization{ID: uuid.New(), Name: "Example"}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		// organization is the user's organization, if any
+		organization *storage.Organization
+		expect       func(querier *storage.MockQuerier)
+		want         int
+	}{
+		{
+			name:   "set without organization",
+			method: http.MethodPut,
+			body:   `{"vanity":["ns1.example.org","ns2.example.org"]}`,
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "delete without organization",
+			method: http.MethodDelete,
+			want:   http.StatusForbidden,
+		},
+		{
+			name:         "delete",
+			method:       http.MethodDelete,
+			organization: &organization,
+			expect: func(querier *storage.MockQuerier) {
+				querier.EXPECT().DeleteVanityNameservers(gomock.Any(), organization.ID).Return(int64(1), nil)
+			},
+			want: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The querier fails the test if vanity nameservers are stored
+			// without an organization
+			querier := storage.NewMockQuerier(gomock.NewController(t))
+			checker := zoneverify.New(testLogger, querier, nil, zoneverify.Config{Nameservers: []string{"ns1.tofudns.test.", "ns2.tofudns.test."}})
+			s, err := New(testLogger, nil, querier, nil, newTestKeys(t, querier), checker, testConfig)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			user := storage.User{ID: uuid.New(), Email: email}
+			querier.EXPECT().GetUserByEmail(gomock.Any(), email).Return(user, nil)
+			if tt.organization != nil {
+				querier.EXPECT().GetUserOrganization(gomock.Any(), user.ID).Return(*tt.organization, nil).AnyTimes()
+			} else {
+				querier.EXPECT().GetUserOrganization(gomock.Any(), user.ID).Return(storage.Organization{}, sql.ErrNoRows).AnyTimes()
+			}
+			if tt.expect != nil {
+				tt.expect(querier)
+			}
+
+			router := chi.NewRouter()
+			s.Router(router)
+			r := httptest.NewRequest(tt.method, "/api/nameservers", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if resp := serve(router, withSession(t, s, r, email)); resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package frontend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// OIDC settings
+	oidcCookieName = "tofudns_oidc"
+	oidcAudience   = "oidc"
+	oidcExpiration = 10 * time.Minute
+)
+
+// OIDCConfig contains configuration for OpenID Connect single sign-on
+type OIDCConfig struct {
+	IssuerURL      string
+	ClientID       string
+	ClientSecret   string
+	RedirectURL    string
+	ProviderName   string
+	AllowedDomains []string
+	AutoProvision  bool
+}
+
+// oidcClient lazily performs provider discovery, so that an unreachable
+// identity provider doesn't prevent the service from starting
+type oidcClient struct {
+	config OIDCConfig
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// oidcStateClaims carries the per-login state between the redirect and the callback
+type oidcStateClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// oidcIdentityClaims are the ID token claims used to identify the user
+type oidcIdentityClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// setup returns the OAuth2 configuration and ID token verifier, discovering
+// the provider on first use
+func (c *oidcClient) setup(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.oauth2 != nil {
+		return c.oauth2, c.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, c.config.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	c.oauth2 = &oauth2.Config{
+		ClientID:     c.config.ClientID,
+		ClientSecret: c.config.ClientSecret,
+		RedirectURL:  c.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+	c.verifier = provider.Verifier(&oidc.Config{ClientID: c.config.ClientID})
+	return c.oauth2, c.verifier, nil
+}
+
+// emailAllowed reports whether the email's domain passes the domain restriction
+func (c *oidcClient) emailAllowed(email string) bool {
+	if len(c.config.AllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	return slices.Contains(c.config.AllowedDomains, strings.ToLower(email[at+1:]))
+}
+
+// handleOIDCLogin redirects to the identity provider using the authorization
+// code flow with PKCE
+func (s *Service) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	oauth2Config, _, err := s.oidc.setup(r.Context())
+	if err != nil {
+		s.logger.Error("Failed to set up OIDC", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Single+sign-on+is+unavailable", http.StatusSeeOther)
+		return
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		s.logger.Error("Failed to generate OIDC state", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		s.logger.Error("Failed to generate OIDC nonce", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Keep the state in a signed cookie bound to this browser
+	claims := &oidcStateClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{oidcAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		s.logger.Error("Failed to sign OIDC state", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    token,
+		Path:     "/auth/oidc/",
+		Expires:  time.Now().Add(oidcExpiration),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), http.StatusFound)
+}
+
+// handleOIDCCallback exchanges the authorization code, validates the ID token
+// and logs in the user identified by its verified email
+func (s *Service) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Restore and clear the login state
+	stateClaims, err := s.oidcState(r)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    "",
+		Path:     "/auth/oidc/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	if err != nil {
+		http.Redirect(w, r, "/auth/login?error=Login+expired", http.StatusSeeOther)
+		return
+	}
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		s.logger.Warn("OIDC provider returned an error", "error", providerErr, "description", query.Get("error_description"))
+		http.Redirect(w, r, "/auth/login?error=Single+sign-on+failed", http.StatusSeeOther)
+		return
+	}
+	if query.Get("state") != stateClaims.State {
+		http.Redirect(w, r, "/auth/login?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	email, err := s.oidcExchange(ctx, query.Get("code"), stateClaims)
+	if err != nil {
+		s.logger.Error("Failed to complete OIDC login", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Single+sign-on+failed", http.StatusSeeOther)
+		return
+	}
+
+	if !s.oidc.emailAllowed(email) {
+		http.Redirect(w, r, "/auth/login?error=Email+domain+is+not+allowed", http.StatusSeeOther)
+		return
+	}
+
+	// Without auto-provisioning only existing users may sign in
+	if !s.oidc.config.AutoProvision {
+		if _, err := s.db.GetUserByEmail(ctx, email); err != nil {
+			http.Redirect(w, r, "/auth/login?error=No+account+exists+for+this+email", http.StatusSeeOther)
+			return
+		}
+	} else if _, err := s.findOrCreateUser(ctx, email); err != nil {
+		s.logger.Error("Failed to provision user", "error", err, "email", email)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	s.beginLogin(w, r, email)
+}
+
+// oidcState parses the signed login state cookie
+func (s *Service) oidcState(r *http.Request) (*oidcStateClaims, error) {
+	cookie, err := r.Cookie(oidcCookieName)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidcStateClaims{}
+	_, err = s.keys.Parse(r.Context(), cookie.Value, claims, jwt.WithAudience(oidcAudience))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// oidcExchange redeems the authorization code and returns the verified email
+// from the ID token
+func (s *Service) oidcExchange(ctx context.Context, code string, state *oidcStateClaims) (string, error) {
+	oauth2Config, verifier, err := s.oidc.setup(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("token response did not include an id_token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != state.Nonce {
+		return "", errors.New("id_token nonce mismatch")
+	}
+
+	var claims oidcIdentityClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return "", fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return "", errors.New("id_token does not contain a verified email")
+	}
+
+	return strings.ToLower(claims.Email), nil
+}
+
+// randomToken returns a random URL-safe token
+func randomToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
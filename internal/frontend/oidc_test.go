@@ -0,0 +1,235 @@
+package frontend
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/oidctest"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+const oidcTestEmail = "employee@example.com"
+
+// oidcTest is a service signing in with a mock identity provider
+type oidcTest struct {
+	service  *Service
+	querier  *storage.MockQuerier
+	router   chi.Router
+	provider *oidctest.Provider
+}
+
+// newOIDCTest starts a mock identity provider and returns a service using it
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+	provider, err := oidctest.NewProvider("tofudns")
+	if err != nil {
+		t.Fatalf("oidctest.NewProvider() error = %v", err)
+	}
+	t.Cleanup(provider.Close)
+	provider.SetIdentity(oidcTestEmail, true)
+
+	config := testConfig
+	config.OIDC = &OIDCConfig{
+		IssuerURL:     provider.URL,
+		ClientID:      provider.ClientID,
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost/auth/oidc/callback",
+		ProviderName:  "Test",
+		AutoProvision: true,
+	}
+	s, querier := newTestService(t, config)
+
+	router := chi.NewRouter()
+	s.Router(router)
+	return &oidcTest{service: s, querier: querier, router: router, provider: provider}
+}
+
+// login starts a login, returning the state cookie and the URL of the
+// provider's authorization endpoint
+func (o *oidcTest) login(t *testing.T) (*http.Cookie, *url.URL) {
+	t.Helper()
+	resp := serve(o.router, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("login status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	cookie := responseCookie(resp, oidcCookieName)
+	if cookie == nil {
+		t.Fatal("login did not set the state cookie")
+	}
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatalf("login Location error = %v", err)
+	}
+	return cookie, location
+}
+
+// authorize has the provider approve the authorization request and returns
+// the callback URL it redirects to
+func (o *oidcTest) authorize(t *testing.T, authorizeURL *url.URL) *url.URL {
+	t.Helper()
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authorizeURL.String())
+	if err != nil {
+		t.Fatalf("authorize error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatalf("authorize Location error = %v", err)
+	}
+	return location
+}
+
+// callback redeems the callback URL in the browser holding the state cookie
+func (o *oidcTest) callback(callbackURL *url.URL, cookie *http.Cookie) *http.Response {
+	r := httptest.NewRequest(http.MethodGet, callbackURL.RequestURI(), nil)
+	r.AddCookie(cookie)
+	return serve(o.router, r)
+}
+
+// expectLogin makes the querier know the signing-in user, without a second
+// factor
+func (o *oidcTest) expectLogin() {
+	user := expectUser(o.querier, oidcTestEmail)
+	o.querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{}, sql.ErrNoRows).AnyTimes()
+	o.querier.EXPECT().ListWebAuthnCredentialsByUser(gomock.Any(), user.ID).Return(nil, nil).AnyTimes()
+}
+
+// responseCookie returns the cookie the response sets, if any
+func responseCookie(resp *http.Response, name string) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == name && cookie.Value != "" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// assertLoginFailed checks the callback was refused with the error
+func assertLoginFailed(t *testing.T, resp *http.Response, wantError string) {
+	t.Helper()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("callback status = %d, want %d", resp.StatusCode, http.StatusSeeOther)
+	}
+	if got, want := resp.Header.Get("Location"), "/auth/login?error="+wantError; got != want {
+		t.Errorf("callback Location = %q, want %q", got, want)
+	}
+	if responseCookie(resp, cookieName) != nil {
+		t.Error("callback set a session cookie")
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	o := newOIDCTest(t)
+	o.expectLogin()
+
+	cookie, authorizeURL := o.login(t)
+	resp := o.callback(o.authorize(t, authorizeURL), cookie)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/" {
+		t.Fatalf("callback = %d %s, want %d /", resp.StatusCode, resp.Header.Get("Location"), http.StatusSeeOther)
+	}
+
+	session := responseCookie(resp, cookieName)
+	if session == nil {
+		t.Fatal("callback did not set a session cookie")
+	}
+	r := httptest.NewRequest(http.MethodGet, "/account", nil)
+	r.AddCookie(session)
+	handler := o.service.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := getUserEmail(r); got != oidcTestEmail {
+			t.Errorf("user email = %q, want %q", got, oidcTestEmail)
+		}
+	}))
+	if resp := serve(handler, r); resp.StatusCode != http.StatusOK {
+		t.Errorf("session status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestOIDCCallbackStateMismatch(t *testing.T) {
+	o := newOIDCTest(t)
+
+	cookie, authorizeURL := o.login(t)
+	callbackURL := o.authorize(t, authorizeURL)
+	query := callbackURL.Query()
+	query.Set("state", "forged")
+	callbackURL.RawQuery = query.Encode()
+
+	assertLoginFailed(t, o.callback(callbackURL, cookie), "Invalid+request")
+}
+
+func TestOIDCCallbackWithoutStateCookie(t *testing.T) {
+	o := newOIDCTest(t)
+
+	_, authorizeURL := o.login(t)
+	r := httptest.NewRequest(http.MethodGet, o.authorize(t, authorizeURL).RequestURI(), nil)
+
+	assertLoginFailed(t, serve(o.router, r), "Login+expired")
+}
+
+func TestOIDCCallbackUnverifiedEmail(t *testing.T) {
+	o := newOIDCTest(t)
+	o.provider.SetIdentity(oidcTestEmail, false)
+
+	cookie, authorizeURL := o.login(t)
+
+	assertLoginFailed(t, o.callback(o.authorize(t, authorizeURL), cookie), "Single+sign-on+failed")
+}
+
+func TestOIDCCallbackNonceMismatch(t *testing.T) {
+	o := newOIDCTest(t)
+
+	// An ID token issued for another login carries that login's nonce
+	cookie, authorizeURL := o.login(t)
+	query := authorizeURL.Query()
+	query.Set("nonce", "another-login")
+	authorizeURL.RawQuery = query.Encode()
+
+	assertLoginFailed(t, o.callback(o.authorize(t, authorizeURL), cookie), "Single+sign-on+failed")
+}
+
+func TestOIDCCallbackReplay(t *testing.T) {
+	o := newOIDCTest(t)
+	o.expectLogin()
+
+	cookie, authorizeURL := o.login(t)
+	callbackURL := o.authorize(t, authorizeURL)
+	if resp := o.callback(callbackURL, cookie); responseCookie(resp, cookieName) == nil {
+		t.Fatal("first callback did not set a session cookie")
+	}
+
+	assertLoginFailed(t, o.callback(callbackURL, cookie), "Single+sign-on+failed")
+}
+
+func TestOIDCStateIsNotASession(t *testing.T) {
+	o := newOIDCTest(t)
+
+	cookie, _ := o.login(t)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: cookieName, Value: cookie.Value})
+
+	resp := serve(o.router, r)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/auth/login" {
+		t.Fatalf("status = %d %s, want %d /auth/login", resp.StatusCode, resp.Header.Get("Location"), http.StatusSeeOther)
+	}
+}
+
+func TestFindOrCreateUserRequiresEmail(t *testing.T) {
+	s, _ := newTestService(t, testConfig)
+
+	if _, err := s.findOrCreateUser(context.Background(), ""); err == nil {
+		t.Fatal("findOrCreateUser(\"\") error = nil, want an error")
+	}
+}
@@ -0,0 +1,223 @@
+package frontend
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// handleOrganizationsPage lists the organizations and the form creating one
+func (s *Service) handleOrganizationsPage(w http.ResponseWriter, r *http.Request) {
+	organizations, err := s.db.ListOrganizations(r.Context())
+	if err != nil {
+		s.logger.Error("Failed to list organizations", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Organizations": organizations,
+		"Error":         r.URL.Query().Get("error"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "admin_organizations.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleOrganizationCreate creates an organization without members
+func (s *Service) handleOrganizationCreate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/admin/organizations?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+	name := strings.TrimSpace(r.Form.Get("name"))
+	if name == "" || len(name) > 255 {
+		http.Redirect(w, r, "/admin/organizations?error=Name+must+be+1+to+255+characters", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	_, err := s.db.GetOrganizationByName(ctx, name)
+	if err == nil {
+		http.Redirect(w, r, "/admin/organizations?error=Organization+already+exists", http.StatusSeeOther)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error("Failed to get organization", "error", err)
+		http.Redirect(w, r, "/admin/organizations?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	organization, err := s.db.CreateOrganization(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to create organization", "error", err)
+		http.Redirect(w, r, "/admin/organizations?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, organizationPath(organization.ID), http.StatusSeeOther)
+}
+
+// handleOrganizationPage displays an organization's policy and members
+func (s *Service) handleOrganizationPage(w http.ResponseWriter, r *http.Request) {
+	organization, ok := s.organization(w, r)
+	if !ok {
+		return
+	}
+	members, err := s.db.ListOrganizationMembers(r.Context(), organization.ID)
+	if err != nil {
+		s.logger.Error("Failed to list organization members", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Organization": organization,
+		"Members":      members,
+		"Error":        r.URL.Query().Get("error"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "admin_organization.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleOrganizationPolicy sets whether the organization requires its
+// members to have a second factor. Members without one are sent to enroll
+// on their next request.
+func (s *Service) handleOrganizationPolicy(w http.ResponseWriter, r *http.Request) {
+	organization, ok := s.organization(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, organizationPath(organization.ID)+"?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	_, err := s.db.SetOrganizationTwoFactor(r.Context(), storage.SetOrganizationTwoFactorParams{
+		ID:               organization.ID,
+		RequireTwoFactor: r.Form.Get("require_two_factor") == "on",
+	})
+	if err != nil {
+		s.logger.Error("Failed to set organization policy", "error", err)
+		http.Redirect(w, r, organizationPath(organization.ID)+"?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, organizationPath(organization.ID), http.StatusSeeOther)
+}
+
+// handleOrganizationMemberAdd adds the user with the email to the
+// organization. Accounts are created on the first login, so people who
+// haven't signed in yet must be invited first.
+func (s *Service) handleOrganizationMemberAdd(w http.ResponseWriter, r *http.Request) {
+	organization, ok := s.organization(w, r)
+	if !ok {
+		return
+	}
+	path := organizationPath(organization.ID)
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, path+"?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := s.db.GetUserByEmail(ctx, strings.TrimSpace(r.Form.Get("email")))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Redirect(w, r, path+"?error=No+account+has+that+email+address,+invite+them+first", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err)
+		http.Redirect(w, r, path+"?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	added, err := s.db.AddOrganizationMember(ctx, storage.AddOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to add organization member", "error", err)
+		http.Redirect(w, r, path+"?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if added == 0 {
+		http.Redirect(w, r, path+"?error="+url.QueryEscape(user.Email+" is already a member of an organization"), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+// handleOrganizationMemberRemove removes a user from the organization
+func (s *Service) handleOrganizationMemberRemove(w http.ResponseWriter, r *http.Request) {
+	organization, ok := s.organization(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	_, err = s.db.RemoveOrganizationMember(r.Context(), storage.RemoveOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		UserID:         userID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to remove organization member", "error", err)
+		http.Redirect(w, r, organizationPath(organization.ID)+"?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, organizationPath(organization.ID), http.StatusSeeOther)
+}
+
+// handleOrganizationDelete deletes an organization. Its members keep their
+// accounts and zones.
+func (s *Service) handleOrganizationDelete(w http.ResponseWriter, r *http.Request) {
+	organization, ok := s.organization(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.db.DeleteOrganization(r.Context(), organization.ID); err != nil {
+		s.logger.Error("Failed to delete organization", "error", err)
+		http.Redirect(w, r, organizationPath(organization.ID)+"?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/admin/organizations", http.StatusSeeOther)
+}
+
+// organization returns the organization of the request's path, responding
+// with an error if there is none
+func (s *Service) organization(w http.ResponseWriter, r *http.Request) (storage.Organization, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "organizationId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return storage.Organization{}, false
+	}
+	organization, err := s.db.GetOrganization(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return storage.Organization{}, false
+	}
+	if err != nil {
+		s.logger.Error("Failed to get organization", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return storage.Organization{}, false
+	}
+	return organization, true
+}
+
+// organizationPath returns the path of an organization's page
+func organizationPath(id uuid.UUID) string {
+	return "/admin/organizations/" + id.String()
+}
@@ -0,0 +1,217 @@
+package frontend
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// postAdminForm posts the form to the administration route as the user
+func postAdminForm(t *testing.T, s *Service, user, path string, form url.Values) *http.Response {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router := chi.NewRouter()
+	s.Router(router)
+	return serve(router, withSession(t, s, r, user))
+}
+
+func TestOrganizationCreate(t *testing.T) {
+	const admin = "admin@example.com"
+	config := testConfig
+	config.AdminEmails = []string{admin}
+	created := storage.Organization{ID: uuid.New(), Name: "Example"}
+
+	tests := []struct {
+		name     string
+		user     string
+		form     url.Values
+		expect   func(querier *storage.MockQuerier)
+		want     int
+		location string
+	}{
+		{
+			name: "admin",
+			user: admin,
+			form: url.Values{"name": {" Example "}},
+			expect: func(querier *storage.MockQuerier) {
+				querier.EXPECT().GetOrganizationByName(gomock.Any(), "Example").Return(storage.Organization{}, sql.ErrNoRows)
+				querier.EXPECT().CreateOrganization(gomock.Any(), "Example").Return(created, nil)
+			},
+			want:     http.StatusSeeOther,
+			location: "/admin/organizations/" + created.ID.String(),
+		},
+		{
+			name: "existing name",
+			user: admin,
+			form: url.Values{"name": {"Example"}},
+			expect: func(querier *storage.MockQuerier) {
+				querier.EXPECT().GetOrganizationByName(gomock.Any(), "Example").Return(created, nil)
+			},
+			want:     http.StatusSeeOther,
+			location: "/admin/organizations?error=Organization+already+exists",
+		},
+		{
+			name:     "no name",
+			user:     admin,
+			form:     url.Values{"name": {" "}},
+			want:     http.StatusSeeOther,
+			location: "/admin/organizations?error=Name+must+be+1+to+255+characters",
+		},
+		{
+			name: "not an admin",
+			user: "user@example.com",
+			form: url.Values{"name": {"Example"}},
+			want: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The querier fails the test if an organization is created
+			// unexpectedly
+			s, querier := newTestService(t, config)
+			expectUser(querier, tt.user)
+			if tt.expect != nil {
+				tt.expect(querier)
+			}
+
+			resp := postAdminForm(t, s, tt.user, "/admin/organizations", tt.form)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+		})
+	}
+}
+
+func TestOrganizationMemberAdd(t *testing.T) {
+	const admin = "admin@example.com"
+	config := testConfig
+	config.AdminEmails = []string{admin}
+	organization := storage.Organization{ID: uuid.New(), Name: "Example"}
+	member := storage.User{ID: uuid.New(), Email: "member@example.com"}
+	path := "/admin/organizations/" + organization.ID.String()
+
+	tests := []struct {
+		name     string
+		email    string
+		user     error
+		added    int64
+		location string
+	}{
+		{name: "added", email: member.Email, added: 1, location: path},
+		{
+			name:     "already a member",
+			email:    member.Email,
+			location: path + "?error=member%40example.com+is+already+a+member+of+an+organization",
+		},
+		{
+			name:     "no account",
+			email:    "new@example.com",
+			user:     sql.ErrNoRows,
+			location: path + "?error=No+account+has+that+email+address,+invite+them+first",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, config)
+			expectUser(querier, admin)
+			querier.EXPECT().GetOrganization(gomock.Any(), organization.ID).Return(organization, nil)
+			querier.EXPECT().GetUserByEmail(gomock.Any(), tt.email).Return(member, tt.user)
+			if tt.user == nil {
+				querier.EXPECT().AddOrganizationMember(gomock.Any(), storage.AddOrganizationMemberParams{
+					OrganizationID: organization.ID,
+					UserID:         member.ID,
+				}).Return(tt.added, nil)
+			}
+
+			resp := postAdminForm(t, s, admin, path+"/members", url.Values{"email": {tt.email}})
+			if location := resp.Header.Get("Location"); resp.StatusCode != http.StatusSeeOther || location != tt.location {
+				t.Errorf("response = %d to %q, want a redirect to %q", resp.StatusCode, location, tt.location)
+			}
+		})
+	}
+}
+
+func TestOrganizationPolicy(t *testing.T) {
+	const admin = "admin@example.com"
+	config := testConfig
+	config.AdminEmails = []string{admin}
+	organization := storage.Organization{ID: uuid.New(), Name: "Example"}
+	path := "/admin/organizations/" + organization.ID.String()
+
+	for _, require := range []bool{true, false} {
+		s, querier := newTestService(t, config)
+		expectUser(querier, admin)
+		querier.EXPECT().GetOrganization(gomock.Any(), organization.ID).Return(organization, nil)
+		querier.EXPECT().SetOrganizationTwoFactor(gomock.Any(), storage.SetOrganizationTwoFactorParams{
+			ID:               organization.ID,
+			RequireTwoFactor: require,
+		}).Return(organization, nil)
+
+		// Unchecked checkboxes aren't sent
+		form := url.Values{}
+		if require {
+			form.Set("require_two_factor", "on")
+		}
+		resp := postAdminForm(t, s, admin, path+"/policy", form)
+		if location := resp.Header.Get("Location"); resp.StatusCode != http.StatusSeeOther || location != path {
+			t.Errorf("response = %d to %q, want a redirect to %q", resp.StatusCode, location, path)
+		}
+	}
+}
+
+func TestOrganizationRequiresTwoFactor(t *testing.T) {
+	const email = "member@example.com"
+
+	tests := []struct {
+		name         string
+		organization storage.Organization
+		passkeys     []storage.WebauthnCredential
+		want         int
+	}{
+		{name: "not required", want: http.StatusOK},
+		{
+			name:         "second factor",
+			organization: storage.Organization{RequireTwoFactor: true},
+			passkeys:     []storage.WebauthnCredential{{ID: 1}},
+			want:         http.StatusOK,
+		},
+		{
+			name:         "no second factor",
+			organization: storage.Organization{RequireTwoFactor: true},
+			want:         http.StatusSeeOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			user := storage.User{ID: uuid.New(), Email: email}
+			querier.EXPECT().GetUserByEmail(gomock.Any(), email).Return(user, nil)
+			querier.EXPECT().GetUserOrganization(gomock.Any(), user.ID).Return(tt.organization, nil)
+			if tt.organization.RequireTwoFactor {
+				querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{}, sql.ErrNoRows)
+				querier.EXPECT().ListWebAuthnCredentialsByUser(gomock.Any(), user.ID).Return(tt.passkeys, nil)
+			}
+
+			handler := s.authMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			resp := serve(handler, withSession(t, s, httptest.NewRequest(http.MethodGet, "/", nil), email))
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusSeeOther && resp.Header.Get("Location") != "/account?error=Two-factor+authentication+is+required" {
+				t.Errorf("Location = %q, want the account page", resp.Header.Get("Location"))
+			}
+		})
+	}
+}
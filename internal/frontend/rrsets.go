@@ -0,0 +1,148 @@
+package frontend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// RRSetResponse is an RRset as returned by the API, the records of a zone
+// sharing an owner name, type and TTL
+type RRSetResponse struct {
+	Zone        string           `json:"zone"`
+	ZoneUnicode string           `json:"zone_unicode"`
+	Name        string           `json:"name"`
+	NameUnicode string           `json:"name_unicode"`
+	RecordType  string           `json:"record_type"`
+	TTL         int32            `json:"ttl"`
+	Records     []RecordResponse `json:"records"`
+}
+
+// rrsetPayload is the replacement of an RRset as submitted to the API. The
+// records only carry their content, they share the RRset's name, type and
+// TTL.
+type rrsetPayload struct {
+	TTL     int32 `json:"ttl"`
+	Records []struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"records"`
+}
+
+// newRRSetResponse converts an RRset to its API representation
+func newRRSetResponse(rrset *recordmanager.RRSet) RRSetResponse {
+	response := RRSetResponse{
+		Zone:        rrset.Zone,
+		ZoneUnicode: recordmanager.UnicodeName(rrset.Zone),
+		Name:        rrset.Name,
+		NameUnicode: rrset.UnicodeName(),
+		RecordType:  rrset.RecordType,
+		TTL:         rrset.Ttl,
+		Records:     make([]RecordResponse, len(rrset.Records)),
+	}
+	for i, record := range rrset.Records {
+		response.Records[i] = newRecordResponse(record)
+	}
+	return response
+}
+
+// handleRRSetList lists the records of a zone grouped into RRsets
+func (s *Service) handleRRSetList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+	zone, err := s.records.GetZone(ctx, chi.URLParam(r, "zone"), userID)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone")
+		return
+	}
+
+	records, err := s.records.ListRecordsByZone(ctx, zone.Name, userID)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve zone records")
+		return
+	}
+
+	rrsets := recordmanager.GroupRRSets(records)
+	response := make([]RRSetResponse, len(rrsets))
+	for i, rrset := range rrsets {
+		response[i] = newRRSetResponse(rrset)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rrsets": response,
+	})
+}
+
+// handleRRSetGet returns the RRset with the owner name and type, @ for the
+// apex
+func (s *Service) handleRRSetGet(w http.ResponseWriter, r *http.Request) {
+	rrset, err := s.records.GetRRSet(r.Context(), chi.URLParam(r, "zone"), getUserID(r),
+		chi.URLParam(r, "name"), chi.URLParam(r, "type"))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to retrieve RRset")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newRRSetResponse(rrset))
+}
+
+// handleRRSetReplace replaces the records of an RRset atomically. Replacing
+// an RRset with no records deletes it.
+func (s *Service) handleRRSetReplace(w http.ResponseWriter, r *http.Request) {
+	var payload rrsetPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	recordType := strings.ToUpper(chi.URLParam(r, "type"))
+	records := make([]*recordmanager.Record, len(payload.Records))
+	for i, recordContent := range payload.Records {
+		recordPayload := recordPayload{
+			Name:       name,
+			TTL:        payload.TTL,
+			RecordType: recordType,
+			Content:    recordContent.Content,
+		}
+		record, validationErrors, contentErr := recordPayload.toRecord()
+		if contentErr != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("record %d: %v", i, contentErr), nil)
+			return
+		}
+		if len(validationErrors) > 0 {
+			respondWithError(w, http.StatusBadRequest, "Validation failed", withIndex(validationErrors, i))
+			return
+		}
+		records[i] = record
+	}
+
+	rrset, err := s.records.ReplaceRRSet(r.Context(), chi.URLParam(r, "zone"), getUserID(r),
+		name, recordType, payload.TTL, records)
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to replace RRset")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"rrset":  newRRSetResponse(rrset),
+	})
+}
+
+// handleRRSetDelete deletes the records of an RRset atomically
+func (s *Service) handleRRSetDelete(w http.ResponseWriter, r *http.Request) {
+	err := s.records.DeleteRRSet(r.Context(), chi.URLParam(r, "zone"), getUserID(r),
+		chi.URLParam(r, "name"), chi.URLParam(r, "type"))
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to delete RRset")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package frontend
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// searchResult is a record found by a search, with its content as shown on
+// the results page
+type searchResult struct {
+	*recordmanager.Record
+	ContentText string
+}
+
+// handleAPISearch searches the records of all of the user's zones for the
+// text of the q query parameter in their names and content. The limit and
+// cursor query parameters select the page.
+func (s *Service) handleAPISearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageSize, err := pageSizeParam(query)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid limit")
+		return
+	}
+	page, err := s.records.SearchAllRecords(r.Context(), getUserID(r), recordmanager.RecordSearch{
+		Text:     query.Get("q"),
+		Cursor:   query.Get("cursor"),
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to search records")
+		return
+	}
+
+	response := make([]RecordResponse, len(page.Records))
+	for i, record := range page.Records {
+		response[i] = newRecordResponse(record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listResponse("records", response, page.NextCursor))
+}
+
+// handleSearch renders the results of a search across the user's zones
+func (s *Service) handleSearch(w http.ResponseWriter, r *http.Request) {
+	text := r.URL.Query().Get("q")
+	cursor := r.URL.Query().Get("cursor")
+	data := map[string]interface{}{
+		"Query": text,
+	}
+
+	if text != "" {
+		page, err := s.records.SearchAllRecords(r.Context(), getUserID(r), recordmanager.RecordSearch{
+			Text:   text,
+			Cursor: cursor,
+		})
+		if validationErrors, ok := recordValidationErrors(err); ok {
+			data["Error"] = validationErrors[0].Message
+		} else if err != nil {
+			slog.Error("Failed to search records", "error", err)
+			http.Error(w, "Failed to search records", http.StatusInternalServerError)
+			return
+		} else {
+			results := make([]searchResult, len(page.Records))
+			for i, record := range page.Records {
+				content, err := recordmanager.ContentJSON(record)
+				if err != nil {
+					content = string(record.Content)
+				}
+				results[i] = searchResult{Record: record, ContentText: content}
+			}
+			data["Results"] = results
+			if cursor != "" {
+				data["FirstPageURL"] = "/search?" + url.Values{"q": {text}}.Encode()
+			}
+			if page.NextCursor != "" {
+				data["NextPageURL"] = "/search?" + url.Values{"q": {text}, "cursor": {page.NextCursor}}.Encode()
+			}
+		}
+	}
+
+	if err := s.templates.ExecuteTemplate(w, "search.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
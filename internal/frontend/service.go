@@ -1,20 +1,30 @@
 package frontend
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/jwtkeys"
 	"github.com/tofudns/tofudns/internal/recordmanager"
 	"github.com/tofudns/tofudns/internal/storage"
+	"github.com/tofudns/tofudns/internal/zoneverify"
 )
 
 //go:embed templates/*
@@ -22,24 +32,59 @@ var templateFS embed.FS
 
 // EmailService defines the interface for sending emails
 type EmailService interface {
-	SendOTP(email, otp string) error
+	SendOTP(email, locale, otp, magicLink string, expiresIn time.Duration) error
+	SendInvitation(email, locale, inviterEmail, inviteURL string) error
+	SendNotification(email, locale, event string) error
+	Templates() []string
+	Locales() []string
+	Preview(name, locale string) (email.Message, error)
+}
+
+// Config contains configuration for the frontend service
+type Config struct {
+	BaseURL string
+	// RequireTOTP requires users to enroll a second factor, an authenticator
+	// app or a passkey, before they can use anything but the account pages.
+	// Organizations can require it of their members only.
+	RequireTOTP       bool
+	RequireZoneVerify bool
+	AdminEmails       []string
+	WebAuthnRPID      string
+	WebAuthnRPOrigins []string
+	OIDC              *OIDCConfig
+	// Hostmaster is the mailbox of new zones' SOA records, as an email
+	// address or in its DNS form
+	Hostmaster string
+	// SOATimers are the timers of new zones' SOA records
+	SOATimers recordmanager.SOATimers
 }
 
 type Service struct {
-	logger       *slog.Logger
-	records      *recordmanager.RecordManager
-	templates    *template.Template
-	db           *storage.Queries
-	emailService EmailService
-	jwtSecret    string
+	logger            *slog.Logger
+	records           *recordmanager.RecordManager
+	templates         *template.Template
+	db                storage.Querier
+	emailService      EmailService
+	keys              *jwtkeys.KeyManager
+	requireTOTP       bool
+	requireZoneVerify bool
+	zoneChecker       *zoneverify.Checker
+	adminEmails       []string
+	baseURL           string
+	webAuthn          *webauthn.WebAuthn
+	oidc              *oidcClient
+	hostmaster        string
+	soaTimers         recordmanager.SOATimers
 }
 
 func New(
 	logger *slog.Logger,
 	records *recordmanager.RecordManager,
-	db *storage.Queries,
+	db storage.Querier,
 	emailService EmailService,
-	jwtSecret string,
+	keys *jwtkeys.KeyManager,
+	zoneChecker *zoneverify.Checker,
+	config Config,
 ) (*Service, error) {
 	tmpl, err := template.New("").Funcs(template.FuncMap{
 		"add": func(a, b int) int { return a + b },
@@ -53,6 +98,11 @@ func New(
 			case []string:
 				return len(v)
 			default:
+				rv := reflect.ValueOf(x)
+				switch rv.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					return rv.Len()
+				}
 				return 0
 			}
 		},
@@ -62,13 +112,45 @@ func New(
 		return nil, err
 	}
 
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          config.WebAuthnRPID,
+		RPDisplayName: "TofuDNS",
+		RPOrigins:     config.WebAuthnRPOrigins,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The mailbox's @ is the first dot of its DNS form
+	hostmaster, err := recordmanager.CanonicalHostname("hostmaster", strings.Replace(config.Hostmaster, "@", ".", 1))
+	if err != nil {
+		return nil, err
+	}
+	if err := recordmanager.CheckSOATimers(config.SOATimers); err != nil {
+		return nil, fmt.Errorf("invalid SOA timers: %w", err)
+	}
+
+	var oidc *oidcClient
+	if config.OIDC != nil {
+		oidc = &oidcClient{config: *config.OIDC}
+	}
+
 	return &Service{
-		logger:       logger,
-		records:      records,
-		templates:    tmpl,
-		db:           db,
-		emailService: emailService,
-		jwtSecret:    jwtSecret,
+		logger:            logger,
+		records:           records,
+		templates:         tmpl,
+		db:                db,
+		emailService:      emailService,
+		keys:              keys,
+		requireTOTP:       config.RequireTOTP,
+		requireZoneVerify: config.RequireZoneVerify,
+		zoneChecker:       zoneChecker,
+		adminEmails:       config.AdminEmails,
+		baseURL:           strings.TrimSuffix(config.BaseURL, "/"),
+		webAuthn:          webAuthn,
+		oidc:              oidc,
+		hostmaster:        hostmaster,
+		soaTimers:         config.SOATimers,
 	}, nil
 }
 
@@ -79,28 +161,64 @@ func (s *Service) Router(r chi.Router) {
 	// Set up auth routes
 	s.setupAuthRoutes(r)
 
+	// JSON API routes
+	s.setupAPIRoutes(r)
+
+	// Account routes
+	s.setupAccountRoutes(r)
+
+	// Administration routes
+	s.setupAdminRoutes(r)
+
 	// DNS management routes
 	r.Get("/", s.handleZoneList)
 	r.Post("/new/zone", s.handleNewZone)
+	r.Get("/search", s.handleSearch)
+	r.Get("/replace", s.handleReplace)
+	r.Post("/replace", s.handleReplaceApply)
+	r.Get("/templates", s.handleTemplateList)
+	r.Post("/templates", s.handleTemplateCreate)
+	r.Get("/templates/{templateId}", s.handleTemplateGet)
+	r.Post("/templates/{templateId}", s.handleTemplateUpdate)
+	r.Post("/templates/{templateId}/delete", s.handleTemplateDelete)
+	r.Get("/nameservers", s.handleNameservers)
+	r.Post("/nameservers", s.handleNameserversSet)
+	r.Post("/nameservers/delete", s.handleNameserversDelete)
 	r.Get("/zones/{zone}", s.handleZoneDetail)
+	r.Post("/zones/{zone}/verify", s.handleZoneVerify)
+	r.Post("/zones/{zone}/soa", s.handleSOAUpdate)
+	r.Get("/zones/{zone}/template", s.handleZoneTemplatePreview)
+	r.Post("/zones/{zone}/template", s.handleZoneTemplateApply)
 	r.Get("/zones/{zone}/records/{recordId}/delete", s.handleRecordDeleteForm)
 	r.Post("/zones/{zone}/records/{recordId}/delete", s.handleRecordDelete)
 	r.Post("/zones/{zone}/records/create", s.handleRecordCreate)
+	r.Get("/zones/{zone}/records/{recordId}", s.handleRecordGet)
 	r.Post("/zones/{zone}/records/{recordId}/update", s.handleRecordUpdate)
+	r.Post("/zones/{zone}/changes", s.handleChangeSet)
+	r.Post("/zones/{zone}/plan", s.handleZonePlan)
+	r.Post("/zones/{zone}/apply", s.handleZoneApply)
 }
 
 func (s *Service) handleZoneList(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := getUserID(r)
-	zones, err := s.records.ListZones(ctx, userID)
+	zones, err := s.records.ListUserZones(ctx, userID)
 	if err != nil {
 		slog.Error("Failed to retrieve zones", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
+	templates, err := s.records.ListZoneTemplates(ctx, userID)
+	if err != nil {
+		slog.Error("Failed to retrieve zone templates", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	data := map[string]interface{}{
-		"Zones": zones,
+		"Zones":         zones,
+		"ZoneTemplates": templates,
 	}
 	if err := s.templates.ExecuteTemplate(w, "zone_list.html", data); err != nil {
 		slog.Error("Failed to execute template", "error", err)
@@ -121,16 +239,64 @@ func (s *Service) handleNewZone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	zone := r.Form.Get("zone")
-	if zone == "" {
-		http.Error(w, "Zone is required", http.StatusBadRequest)
+	zone, err := recordmanager.CanonicalZone(r.Form.Get("zone"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	userID := getUserID(r)
-	record := recordmanager.Record{
-		UserID:     userID,
-		Zone:       zone,
+	// The zone may start from one of the user's templates
+	var zoneTemplate *recordmanager.ZoneTemplate
+	if id := r.Form.Get("template"); id != "" {
+		var ok bool
+		if zoneTemplate, ok = s.zoneTemplate(w, r, id); !ok {
+			return
+		}
+	}
+
+	_, err = s.createZone(r.Context(), zone, getUserID(r), zoneTemplate)
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		http.Error(w, validationErrors[0].Message, http.StatusBadRequest)
+		return
+	}
+	switch {
+	case errors.Is(err, recordmanager.ErrZoneExists):
+		http.Redirect(w, r, "/zones/"+zone, http.StatusSeeOther)
+		return
+	case errors.Is(err, recordmanager.ErrZoneTaken):
+		http.Error(w, "Zone is already in use", http.StatusConflict)
+		return
+	case err != nil:
+		slog.Error("Failed to create zone", "error", err)
+		http.Error(w, "Failed to create zone", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/zones/"+zone, http.StatusSeeOther)
+}
+
+// createZone claims the zone for the user and creates its SOA record, naming
+// the first of the user's nameservers as the primary, and an NS record per
+// nameserver. The records of the template, if any, are then
+// applied on top, replacing those RRsets they also have. The zone is created
+// with all of its records or not at all.
+func (s *Service) createZone(ctx context.Context, zone string, userID uuid.UUID, zoneTemplate *recordmanager.ZoneTemplate) (*recordmanager.Zone, error) {
+	// The template is parsed first, so an invalid template creates no zone
+	var records []*recordmanager.Record
+	if zoneTemplate != nil {
+		var err error
+		if records, err = templateRecords(zoneTemplate, zone); err != nil {
+			return nil, err
+		}
+	}
+
+	nameservers, err := s.zoneChecker.ZoneNameservers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The zone starts with its SOA record and an NS record per nameserver
+	apex := []*recordmanager.Record{{
 		Name:       "",
 		RecordType: "SOA",
 		Ttl: sql.NullInt32{
@@ -138,22 +304,55 @@ func (s *Service) handleNewZone(w http.ResponseWriter, r *http.Request) {
 			Valid: true,
 		},
 		SOA: &recordmanager.SOAData{
-			Ns:      "ns1.tofudns.net",   // primary nameserver (always one)
-			MBox:    "admin.tofudns.net", // admin@tofudns.net
-			Refresh: 86400,               // 24 hours
-			Retry:   7200,                // 2 hours
-			Expire:  604800,              // 1 week
-			MinTtl:  300,                 // 5 minutes
+			Ns:      nameservers[0],
+			MBox:    s.hostmaster,
+			Refresh: s.soaTimers.Refresh,
+			Retry:   s.soaTimers.Retry,
+			Expire:  s.soaTimers.Expire,
+			MinTtl:  s.soaTimers.MinTtl,
 		},
+	}}
+	for _, nameserver := range nameservers {
+		apex = append(apex, &recordmanager.Record{
+			Name:       "",
+			RecordType: "NS",
+			Ttl: sql.NullInt32{
+				Int32: 3600,
+				Valid: true,
+			},
+			NS: &recordmanager.NSData{Host: nameserver},
+		})
+	}
+
+	// New zones aren't served until their ownership has been verified
+	return s.records.CreateZone(ctx, zone, userID, !s.requireZoneVerify, apex, records)
+}
+
+// handleZoneVerify checks a pending zone's ownership immediately instead of
+// waiting for the background checker
+func (s *Service) handleZoneVerify(w http.ResponseWriter, r *http.Request) {
+	zone, err := recordmanager.CanonicalZone(chi.URLParam(r, "zone"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
 	}
 	ctx := r.Context()
-	_, err := s.records.CreateRecord(ctx, &record)
+
+	dbZone, err := s.db.GetZone(ctx, storage.GetZoneParams{
+		Zone:   zone,
+		UserID: getUserID(r),
+	})
 	if err != nil {
-		slog.Error("Failed to create SOA record", "error", err)
-		http.Error(w, "Failed to create zone", http.StatusInternalServerError)
+		http.NotFound(w, r)
 		return
 	}
 
+	if dbZone.Status == recordmanager.ZoneStatusPending {
+		if _, err := s.zoneChecker.Check(ctx, dbZone); err != nil {
+			slog.Error("Failed to verify zone", "error", err, "zone", zone)
+		}
+	}
+
 	http.Redirect(w, r, "/zones/"+zone, http.StatusSeeOther)
 }
 
@@ -166,16 +365,115 @@ func (s *Service) handleZoneDetail(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	userID := getUserID(r)
-	records, err := s.records.ListRecordsByZone(ctx, zone, userID)
+	zoneInfo, err := s.records.GetZone(ctx, zone, userID)
+	if errors.Is(err, recordmanager.ErrZoneNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to retrieve zone", "error", err, "zone", zone)
+		http.Error(w, "Failed to retrieve zone", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	for key, values := range query {
+		if len(values) == 0 || values[0] == "" {
+			query.Del(key)
+		}
+	}
+	filter := recordmanager.RecordFilter{
+		NamePrefix: query.Get("name_prefix"),
+		RecordType: query.Get("type"),
+		Content:    query.Get("content"),
+		Sort:       query.Get("sort"),
+		Cursor:     query.Get("cursor"),
+	}
+	page, err := s.records.SearchRecords(ctx, zoneInfo.Name, userID, filter)
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		http.Error(w, validationErrors[0].Message, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to retrieve zone records", "error", err, "zone", zone)
 		http.Error(w, "Failed to retrieve zone records", http.StatusInternalServerError)
 		return
 	}
 
+	// Records with corrupt content are listed on their own, as they have no
+	// content to edit, along with the records set aside as invalid, which
+	// are listed on the first page
+	var records, invalid []*recordmanager.Record
+	if filter.Cursor == "" {
+		invalid, err = s.records.ListInvalidRecords(ctx, zoneInfo.Name, userID)
+		if err != nil {
+			slog.Error("Failed to retrieve invalid records", "error", err, "zone", zone)
+			http.Error(w, "Failed to retrieve zone records", http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, record := range page.Records {
+		if record.ContentErr != nil {
+			invalid = append(invalid, record)
+		} else {
+			records = append(records, record)
+		}
+	}
+
+	// Pages keep the filter of the search, which is all of the query but the
+	// cursor
+	query.Del("cursor")
+	var firstPageURL, nextPageURL string
+	if filter.Cursor != "" {
+		firstPageURL = zonePageURL(zoneInfo.Name, query)
+	}
+	if page.NextCursor != "" {
+		query.Set("cursor", page.NextCursor)
+		nextPageURL = zonePageURL(zoneInfo.Name, query)
+		query.Del("cursor")
+	}
+
+	templates, err := s.records.ListZoneTemplates(ctx, userID)
+	if err != nil {
+		slog.Error("Failed to retrieve zone templates", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// The SOA timers are edited on their own, as the SOA record is managed
+	var soa *recordmanager.SOAData
+	soaSet, err := s.records.GetRRSet(ctx, zoneInfo.Name, userID, "", "SOA")
+	if err != nil && !errors.Is(err, recordmanager.ErrRRSetNotFound) {
+		slog.Error("Failed to retrieve SOA record", "error", err, "zone", zone)
+		http.Error(w, "Failed to retrieve zone records", http.StatusInternalServerError)
+		return
+	}
+	if err == nil {
+		soa = soaSet.Records[0].SOA
+	}
+
+	nameservers, err := s.zoneChecker.ZoneNameservers(ctx, userID)
+	if err != nil {
+		slog.Error("Failed to retrieve nameservers", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	data := map[string]interface{}{
-		"Zone":    zone,
-		"Records": records,
+		"Zone":           zoneInfo.Name,
+		"ZoneInfo":       zoneInfo,
+		"Nameservers":    nameservers,
+		"TXTName":        zoneverify.TXTRecordName(zoneInfo.Name),
+		"TXTValue":       zoneverify.TXTRecordValue(zoneInfo.VerificationToken),
+		"RRSets":         recordmanager.GroupRRSets(records),
+		"InvalidRecords": invalid,
+		"RecordTypes":    []string{"A", "CNAME", "MX", "TXT"},
+		"Filter":         filter,
+		"Filtered":       len(query) > 0,
+		"FirstPageURL":   firstPageURL,
+		"NextPageURL":    nextPageURL,
+		"ZoneTemplates":  templates,
+		"SOA":            soa,
 	}
 
 	if err := s.templates.ExecuteTemplate(w, "zone_detail.html", data); err != nil {
@@ -185,6 +483,14 @@ func (s *Service) handleZoneDetail(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// zonePageURL returns the URL of the zone page with the query
+func zonePageURL(zone string, query url.Values) string {
+	if len(query) == 0 {
+		return "/zones/" + zone
+	}
+	return "/zones/" + zone + "?" + query.Encode()
+}
+
 func (s *Service) handleRecordDeleteForm(w http.ResponseWriter, r *http.Request) {
 	zone := chi.URLParam(r, "zone")
 	if zone == "" {
@@ -241,14 +547,40 @@ func (s *Service) handleRecordDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := expectedVersion(r)
+	if err != nil {
+		respondWithVersionError(w, err)
+		return
+	}
+
 	ctx := r.Context()
 	userID := getUserID(r)
-	err = s.records.DeleteRecord(ctx, recordId, zone, userID)
+	err = s.records.DeleteRecord(ctx, recordId, zone, userID, version)
+	if errors.Is(err, recordmanager.ErrRecordChanged) {
+		s.respondWithRecordChanged(w, r, recordId, zone)
+		return
+	}
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		respondWithError(w, http.StatusBadRequest, validationErrors[0].Message, validationErrors)
+		return
+	}
+	if err != nil && isAPIRequest(r) {
+		respondWithRecordError(w, err, "Failed to delete record")
+		return
+	}
+	if errors.Is(err, recordmanager.ErrRecordNotFound) {
+		http.NotFound(w, r)
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to delete record", "error", err)
 		http.Error(w, "Failed to delete record", http.StatusInternalServerError)
 		return
 	}
+	if isAPIRequest(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	http.Redirect(w, r, "/zones/"+zone, http.StatusSeeOther)
 }
 
@@ -256,6 +588,8 @@ func (s *Service) handleRecordDelete(w http.ResponseWriter, r *http.Request) {
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+	// Index is the index of the failing change of a change set
+	Index *int `json:"index,omitempty"`
 }
 
 // ErrorResponse represents a structured error response
@@ -265,6 +599,46 @@ type ErrorResponse struct {
 	Errors  []ValidationError `json:"errors,omitempty"`
 }
 
+// RecordResponse is a record as returned by the API. Names are given in
+// their stored A-label form along with their Unicode form.
+type RecordResponse struct {
+	ID          int64           `json:"id"`
+	Zone        string          `json:"zone"`
+	ZoneUnicode string          `json:"zone_unicode"`
+	Name        string          `json:"name"`
+	NameUnicode string          `json:"name_unicode"`
+	RecordType  string          `json:"record_type"`
+	TTL         int32           `json:"ttl"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	Version     int32           `json:"version"`
+	// ContentError is set on records whose stored content can't be
+	// decoded. Their content is returned as stored.
+	ContentError string `json:"content_error,omitempty"`
+}
+
+// newRecordResponse converts a record to its API representation
+func newRecordResponse(record *recordmanager.Record) RecordResponse {
+	response := RecordResponse{
+		ID:          record.ID,
+		Zone:        record.Zone,
+		ZoneUnicode: recordmanager.UnicodeName(record.Zone),
+		Name:        record.Name,
+		NameUnicode: record.UnicodeName(),
+		RecordType:  record.RecordType,
+		TTL:         record.Ttl.Int32,
+		Version:     record.Version,
+	}
+	if len(record.Content) > 0 {
+		response.Content = record.Content
+	} else if content, err := recordmanager.ContentJSON(record); err == nil {
+		response.Content = json.RawMessage(content)
+	}
+	if record.ContentErr != nil {
+		response.ContentError = record.ContentErr.Error()
+	}
+	return response
+}
+
 // respondWithError writes a JSON error response
 func respondWithError(w http.ResponseWriter, code int, message string, errors []ValidationError) {
 	response := ErrorResponse{
@@ -278,6 +652,31 @@ func respondWithError(w http.ResponseWriter, code int, message string, errors []
 	json.NewEncoder(w).Encode(response)
 }
 
+// recordValidationErrors returns the validation errors of an error returned
+// by the record manager, if it is a validation error
+func recordValidationErrors(err error) ([]ValidationError, bool) {
+	var validationErrs recordmanager.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		result := make([]ValidationError, len(validationErrs))
+		for i, validationErr := range validationErrs {
+			result[i] = ValidationError{
+				Field:   validationErr.Field,
+				Message: validationErr.Message,
+			}
+		}
+		return result, true
+	}
+
+	var validationErr *recordmanager.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, false
+	}
+	return []ValidationError{{
+		Field:   validationErr.Field,
+		Message: validationErr.Message,
+	}}, true
+}
+
 // validateRecord performs validation on a record based on its type
 func validateRecord(record *recordmanager.Record) []ValidationError {
 	var errors []ValidationError
@@ -286,7 +685,7 @@ func validateRecord(record *recordmanager.Record) []ValidationError {
 	if record.Name == "" {
 		errors = append(errors, ValidationError{
 			Field:   "name",
-			Message: "Name is required",
+			Message: "Name is required, use @ for the zone apex",
 		})
 	}
 
@@ -312,6 +711,18 @@ func validateRecord(record *recordmanager.Record) []ValidationError {
 				Message: "IP must be a valid IPv4 address",
 			})
 		}
+	case "AAAA":
+		if record.AAAA == nil || record.AAAA.Ip.IP == nil {
+			errors = append(errors, ValidationError{
+				Field:   "ip",
+				Message: "Valid IP address is required",
+			})
+		} else if record.AAAA.Ip.IP.To4() != nil {
+			errors = append(errors, ValidationError{
+				Field:   "ip",
+				Message: "IP must be a valid IPv6 address",
+			})
+		}
 	case "CNAME":
 		if record.CNAME == nil || record.CNAME.Host == "" {
 			errors = append(errors, ValidationError{
@@ -319,6 +730,13 @@ func validateRecord(record *recordmanager.Record) []ValidationError {
 				Message: "Target host is required",
 			})
 		}
+	case "NS":
+		if record.NS == nil || record.NS.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "host",
+				Message: "Nameserver is required",
+			})
+		}
 	case "MX":
 		if record.MX == nil {
 			errors = append(errors, ValidationError{
@@ -347,6 +765,27 @@ func validateRecord(record *recordmanager.Record) []ValidationError {
 				Message: "Text value is required",
 			})
 		}
+	case "SRV":
+		if record.SRV == nil || record.SRV.Target == "" {
+			errors = append(errors, ValidationError{
+				Field:   "target",
+				Message: "Target is required",
+			})
+		}
+	case "CAA":
+		if record.CAA == nil || record.CAA.Tag == "" {
+			errors = append(errors, ValidationError{
+				Field:   "tag",
+				Message: "Tag is required",
+			})
+		}
+	case "SOA":
+		if record.SOA == nil || record.SOA.Ns == "" || record.SOA.MBox == "" {
+			errors = append(errors, ValidationError{
+				Field:   "ns",
+				Message: "Primary nameserver and mailbox are required",
+			})
+		}
 	default:
 		errors = append(errors, ValidationError{
 			Field:   "record_type",
@@ -357,99 +796,163 @@ func validateRecord(record *recordmanager.Record) []ValidationError {
 	return errors
 }
 
-func (s *Service) handleRecordCreate(w http.ResponseWriter, r *http.Request) {
-	zone := chi.URLParam(r, "zone")
-	if zone == "" {
-		respondWithError(w, http.StatusBadRequest, "Zone is required", nil)
-		return
-	}
-
-	var payload struct {
-		Name       string          `json:"name"`
-		TTL        int32           `json:"ttl"`
-		RecordType string          `json:"record_type"`
-		Content    json.RawMessage `json:"content"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
-		return
-	}
+// recordPayload is a record as submitted to the API
+type recordPayload struct {
+	Name       string          `json:"name"`
+	TTL        int32           `json:"ttl"`
+	RecordType string          `json:"record_type"`
+	Content    json.RawMessage `json:"content"`
+}
 
-	userID := getUserID(r)
-	record := recordmanager.Record{
-		UserID:     userID,
-		Zone:       zone,
-		Name:       strings.TrimSpace(payload.Name),
-		RecordType: payload.RecordType,
+// toRecord converts the payload to a record, parsing the content based on
+// the record type, and validates it
+func (p *recordPayload) toRecord() (*recordmanager.Record, []ValidationError, error) {
+	record := &recordmanager.Record{
+		Name:       strings.TrimSpace(p.Name),
+		RecordType: p.RecordType,
 		Ttl: sql.NullInt32{
-			Int32: payload.TTL,
+			Int32: p.TTL,
 			Valid: true,
 		},
 	}
 
 	// Parse content based on record type
-	var contentErr error
-	switch payload.RecordType {
+	switch p.RecordType {
 	case "A":
 		var aContent struct {
 			IP string `json:"ip"`
 		}
-		if err := json.Unmarshal(payload.Content, &aContent); err != nil {
-			contentErr = fmt.Errorf("invalid A record content: %w", err)
-		} else {
-			ip := net.ParseIP(strings.TrimSpace(aContent.IP))
-			record.A = &recordmanager.AData{
-				Ip: recordmanager.IPAddr{IP: ip},
-			}
+		if err := json.Unmarshal(p.Content, &aContent); err != nil {
+			return nil, nil, fmt.Errorf("invalid A record content: %w", err)
+		}
+		ip := net.ParseIP(strings.TrimSpace(aContent.IP))
+		record.A = &recordmanager.AData{
+			Ip: recordmanager.IPAddr{IP: ip},
+		}
+	case "AAAA":
+		var aaaaContent struct {
+			IP string `json:"ip"`
+		}
+		if err := json.Unmarshal(p.Content, &aaaaContent); err != nil {
+			return nil, nil, fmt.Errorf("invalid AAAA record content: %w", err)
+		}
+		ip := net.ParseIP(strings.TrimSpace(aaaaContent.IP))
+		record.AAAA = &recordmanager.AAAAData{
+			Ip: recordmanager.IPAddr{IP: ip},
 		}
 	case "CNAME":
 		record.CNAME = &recordmanager.CNAMEData{}
-		if err := json.Unmarshal(payload.Content, record.CNAME); err != nil {
-			contentErr = fmt.Errorf("invalid CNAME record content: %w", err)
-		} else {
-			record.CNAME.Host = strings.TrimSpace(record.CNAME.Host)
+		if err := json.Unmarshal(p.Content, record.CNAME); err != nil {
+			return nil, nil, fmt.Errorf("invalid CNAME record content: %w", err)
+		}
+		record.CNAME.Host = strings.TrimSpace(record.CNAME.Host)
+	case "NS":
+		record.NS = &recordmanager.NSData{}
+		if err := json.Unmarshal(p.Content, record.NS); err != nil {
+			return nil, nil, fmt.Errorf("invalid NS record content: %w", err)
 		}
+		record.NS.Host = strings.TrimSpace(record.NS.Host)
 	case "MX":
 		record.MX = &recordmanager.MXData{}
-		if err := json.Unmarshal(payload.Content, record.MX); err != nil {
-			contentErr = fmt.Errorf("invalid MX record content: %w", err)
-		} else {
-			record.MX.Host = strings.TrimSpace(record.MX.Host)
+		if err := json.Unmarshal(p.Content, record.MX); err != nil {
+			return nil, nil, fmt.Errorf("invalid MX record content: %w", err)
 		}
+		record.MX.Host = strings.TrimSpace(record.MX.Host)
 	case "TXT":
 		record.TXT = &recordmanager.TXTData{}
-		if err := json.Unmarshal(payload.Content, record.TXT); err != nil {
-			contentErr = fmt.Errorf("invalid TXT record content: %w", err)
-		} else {
-			record.TXT.Text = strings.TrimSpace(record.TXT.Text)
+		if err := json.Unmarshal(p.Content, record.TXT); err != nil {
+			return nil, nil, fmt.Errorf("invalid TXT record content: %w", err)
+		}
+		record.TXT.Text = strings.TrimSpace(record.TXT.Text)
+	case "SRV":
+		record.SRV = &recordmanager.SRVData{}
+		if err := json.Unmarshal(p.Content, record.SRV); err != nil {
+			return nil, nil, fmt.Errorf("invalid SRV record content: %w", err)
+		}
+		record.SRV.Target = strings.TrimSpace(record.SRV.Target)
+	case "CAA":
+		record.CAA = &recordmanager.CAAData{}
+		if err := json.Unmarshal(p.Content, record.CAA); err != nil {
+			return nil, nil, fmt.Errorf("invalid CAA record content: %w", err)
 		}
+		record.CAA.Tag = strings.TrimSpace(record.CAA.Tag)
+	case "SOA":
+		record.SOA = &recordmanager.SOAData{}
+		if err := json.Unmarshal(p.Content, record.SOA); err != nil {
+			return nil, nil, fmt.Errorf("invalid SOA record content: %w", err)
+		}
+		record.SOA.Ns = strings.TrimSpace(record.SOA.Ns)
+		record.SOA.MBox = strings.TrimSpace(record.SOA.MBox)
 	default:
-		contentErr = fmt.Errorf("unsupported record type: %s", payload.RecordType)
+		return nil, nil, fmt.Errorf("unsupported record type: %s", p.RecordType)
 	}
 
+	return record, validateRecord(record), nil
+}
+
+// respondWithRecordError writes the JSON error response for an error
+// returned by the record manager
+func respondWithRecordError(w http.ResponseWriter, err error, message string) {
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		respondWithError(w, http.StatusBadRequest, "Validation failed", validationErrors)
+		return
+	}
+
+	switch {
+	case errors.Is(err, recordmanager.ErrZoneNotFound):
+		respondWithError(w, http.StatusNotFound, "Zone not found", nil)
+	case errors.Is(err, recordmanager.ErrRecordNotFound):
+		respondWithError(w, http.StatusNotFound, "Record not found", nil)
+	case errors.Is(err, recordmanager.ErrRRSetNotFound):
+		respondWithError(w, http.StatusNotFound, "RRset not found", nil)
+	case errors.Is(err, recordmanager.ErrTemplateNotFound):
+		respondWithError(w, http.StatusNotFound, "Zone template not found", nil)
+	case errors.Is(err, recordmanager.ErrTemplateExists):
+		respondWithError(w, http.StatusConflict, "Zone template already exists", nil)
+	default:
+		slog.Error(message, "error", err)
+		respondWithError(w, http.StatusInternalServerError, message, nil)
+	}
+}
+
+func (s *Service) handleRecordCreate(w http.ResponseWriter, r *http.Request) {
+	zone := chi.URLParam(r, "zone")
+	if zone == "" {
+		respondWithError(w, http.StatusBadRequest, "Zone is required", nil)
+		return
+	}
+
+	var payload recordPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	record, validationErrors, contentErr := payload.toRecord()
 	if contentErr != nil {
 		respondWithError(w, http.StatusBadRequest, contentErr.Error(), nil)
 		return
 	}
-
-	// Validate record
-	if validationErrors := validateRecord(&record); len(validationErrors) > 0 {
+	if len(validationErrors) > 0 {
 		respondWithError(w, http.StatusBadRequest, "Validation failed", validationErrors)
 		return
 	}
+	record.UserID = getUserID(r)
+	record.Zone = zone
 
-	ctx := r.Context()
-	_, err := s.records.CreateRecord(ctx, &record)
+	created, err := s.records.CreateRecord(r.Context(), record)
 	if err != nil {
-		slog.Error("Failed to create record", "error", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to create record", nil)
+		respondWithRecordError(w, err, "Failed to create record")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", recordETag(created))
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"record": newRecordResponse(created),
+	})
 }
 
 func (s *Service) handleRecordUpdate(w http.ResponseWriter, r *http.Request) {
@@ -470,91 +973,47 @@ func (s *Service) handleRecordUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload struct {
-		Name       string          `json:"name"`
-		TTL        int32           `json:"ttl"`
-		RecordType string          `json:"record_type"`
-		Content    json.RawMessage `json:"content"`
+	version, err := expectedVersion(r)
+	if err != nil {
+		respondWithVersionError(w, err)
+		return
 	}
 
+	var payload recordPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
 		return
 	}
 
-	userID := getUserID(r)
-	record := recordmanager.Record{
-		ID:         recordId,
-		UserID:     userID,
-		Zone:       zone,
-		Name:       strings.TrimSpace(payload.Name),
-		RecordType: payload.RecordType,
-		Ttl: sql.NullInt32{
-			Int32: payload.TTL,
-			Valid: true,
-		},
-	}
-
-	// Parse content based on record type
-	var contentErr error
-	switch payload.RecordType {
-	case "A":
-		var aContent struct {
-			IP string `json:"ip"`
-		}
-		if err := json.Unmarshal(payload.Content, &aContent); err != nil {
-			contentErr = fmt.Errorf("invalid A record content: %w", err)
-		} else {
-			ip := net.ParseIP(strings.TrimSpace(aContent.IP))
-			record.A = &recordmanager.AData{
-				Ip: recordmanager.IPAddr{IP: ip},
-			}
-		}
-	case "CNAME":
-		record.CNAME = &recordmanager.CNAMEData{}
-		if err := json.Unmarshal(payload.Content, record.CNAME); err != nil {
-			contentErr = fmt.Errorf("invalid CNAME record content: %w", err)
-		} else {
-			record.CNAME.Host = strings.TrimSpace(record.CNAME.Host)
-		}
-	case "MX":
-		record.MX = &recordmanager.MXData{}
-		if err := json.Unmarshal(payload.Content, record.MX); err != nil {
-			contentErr = fmt.Errorf("invalid MX record content: %w", err)
-		} else {
-			record.MX.Host = strings.TrimSpace(record.MX.Host)
-		}
-	case "TXT":
-		record.TXT = &recordmanager.TXTData{}
-		if err := json.Unmarshal(payload.Content, record.TXT); err != nil {
-			contentErr = fmt.Errorf("invalid TXT record content: %w", err)
-		} else {
-			record.TXT.Text = strings.TrimSpace(record.TXT.Text)
-		}
-	default:
-		contentErr = fmt.Errorf("unsupported record type: %s", payload.RecordType)
-	}
-
+	record, validationErrors, contentErr := payload.toRecord()
 	if contentErr != nil {
 		respondWithError(w, http.StatusBadRequest, contentErr.Error(), nil)
 		return
 	}
-
-	// Validate record
-	if validationErrors := validateRecord(&record); len(validationErrors) > 0 {
+	if len(validationErrors) > 0 {
 		respondWithError(w, http.StatusBadRequest, "Validation failed", validationErrors)
 		return
 	}
+	record.ID = recordId
+	record.Version = version
+	record.UserID = getUserID(r)
+	record.Zone = zone
 
-	ctx := r.Context()
-	_, err = s.records.UpdateRecord(ctx, &record)
+	updated, err := s.records.UpdateRecord(r.Context(), record)
+	if errors.Is(err, recordmanager.ErrRecordChanged) {
+		s.respondWithRecordChanged(w, r, recordId, zone)
+		return
+	}
 	if err != nil {
-		slog.Error("Failed to update record", "error", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to update record", nil)
+		respondWithRecordError(w, err, "Failed to update record")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", recordETag(updated))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"record": newRecordResponse(updated),
+	})
 }
@@ -0,0 +1,122 @@
+package frontend
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/jwtkeys"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// testLogger discards the log output of tests
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testConfig is the configuration of services under test
+var testConfig = Config{
+	BaseURL:           "http://localhost",
+	WebAuthnRPID:      "localhost",
+	WebAuthnRPOrigins: []string{"http://localhost"},
+	Hostmaster:        "hostmaster@example.net",
+	SOATimers: recordmanager.SOATimers{
+		Refresh: 86400,
+		Retry:   7200,
+		Expire:  604800,
+		MinTtl:  300,
+	},
+}
+
+// newTestService returns a service backed by a mock querier, with its
+// signing keys kept in memory
+func newTestService(t *testing.T, config Config) (*Service, *storage.MockQuerier) {
+	t.Helper()
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	s, err := New(testLogger, nil, querier, nil, newTestKeys(t, querier), nil, config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s, querier
+}
+
+// newTestKeys returns a key manager with its keys kept in memory instead of
+// the database
+func newTestKeys(t *testing.T, querier *storage.MockQuerier) *jwtkeys.KeyManager {
+	t.Helper()
+
+	var mu sync.Mutex
+	var keys []storage.SigningKey
+	querier.EXPECT().DeleteExpiredSigningKeys(gomock.Any()).Return(nil).AnyTimes()
+	querier.EXPECT().ListSigningKeys(gomock.Any()).DoAndReturn(func(context.Context) ([]storage.SigningKey, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]storage.SigningKey(nil), keys...), nil
+	}).AnyTimes()
+	querier.EXPECT().CreateSigningKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateSigningKeyParams) (storage.SigningKey, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := storage.SigningKey{
+			Kid:        arg.Kid,
+			Algorithm:  arg.Algorithm,
+			PrivateKey: arg.PrivateKey,
+			ExpiresAt:  arg.ExpiresAt,
+			CreatedAt:  time.Now(),
+		}
+		keys = append(keys, key)
+		return key, nil
+	}).AnyTimes()
+
+	manager, err := jwtkeys.New(testLogger, querier, jwtkeys.Config{
+		Algorithm:        jwtkeys.AlgorithmEdDSA,
+		Issuer:           "http://localhost",
+		RotationInterval: time.Hour,
+		TokenLifetime:    MaxTokenLifetime,
+	})
+	if err != nil {
+		t.Fatalf("jwtkeys.New() error = %v", err)
+	}
+	if err := manager.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	return manager
+}
+
+// expectUser makes the querier know a user with the email, who is a member
+// of no organization
+func expectUser(querier *storage.MockQuerier, email string) storage.User {
+	user := storage.User{ID: uuid.New(), Email: email}
+	querier.EXPECT().GetUserByEmail(gomock.Any(), email).Return(user, nil).AnyTimes()
+	expectNoOrganization(querier, user.ID)
+	return user
+}
+
+// expectNoOrganization makes the user a member of no organization
+func expectNoOrganization(querier *storage.MockQuerier, userID uuid.UUID) {
+	querier.EXPECT().GetUserOrganization(gomock.Any(), userID).Return(storage.Organization{}, sql.ErrNoRows).AnyTimes()
+}
+
+// withSession adds a session cookie for the email to the request
+func withSession(t *testing.T, s *Service, r *http.Request, email string) *http.Request {
+	t.Helper()
+	token, err := s.createJWTToken(email)
+	if err != nil {
+		t.Fatalf("createJWTToken() error = %v", err)
+	}
+	r.AddCookie(&http.Cookie{Name: cookieName, Value: token})
+	return r
+}
+
+// serve sends the request through the handler and returns the response
+func serve(handler http.Handler, r *http.Request) *http.Response {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Result()
+}
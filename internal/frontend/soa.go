@@ -0,0 +1,90 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// soaTimersPayload is the timers of a zone's SOA record as submitted to the
+// API, named as in the SOA record's content
+type soaTimersPayload struct {
+	Refresh uint32 `json:"refresh"`
+	Retry   uint32 `json:"retry"`
+	Expire  uint32 `json:"expire"`
+	MinTtl  uint32 `json:"minttl"`
+}
+
+// handleAPISOAUpdate sets the timers of the zone's SOA record
+func (s *Service) handleAPISOAUpdate(w http.ResponseWriter, r *http.Request) {
+	var payload soaTimersPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	record, err := s.records.UpdateSOATimers(r.Context(), chi.URLParam(r, "zone"), getUserID(r), recordmanager.SOATimers{
+		Refresh: payload.Refresh,
+		Retry:   payload.Retry,
+		Expire:  payload.Expire,
+		MinTtl:  payload.MinTtl,
+	})
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to update SOA record")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"record": newRecordResponse(record),
+	})
+}
+
+// handleSOAUpdate sets the timers of the zone's SOA record from the form of
+// the zone page
+func (s *Service) handleSOAUpdate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	var timers recordmanager.SOATimers
+	for field, timer := range map[string]*uint32{
+		"refresh": &timers.Refresh,
+		"retry":   &timers.Retry,
+		"expire":  &timers.Expire,
+		"minttl":  &timers.MinTtl,
+	} {
+		value, err := strconv.ParseUint(r.Form.Get(field), 10, 32)
+		if err != nil {
+			http.Error(w, field+" must be a number of seconds", http.StatusBadRequest)
+			return
+		}
+		*timer = uint32(value)
+	}
+
+	zone := chi.URLParam(r, "zone")
+	record, err := s.records.UpdateSOATimers(r.Context(), zone, getUserID(r), timers)
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		http.Error(w, validationErrors[0].Message, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, recordmanager.ErrZoneNotFound) || errors.Is(err, recordmanager.ErrRRSetNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to update SOA record", "error", err, "zone", zone)
+		http.Error(w, "Failed to update SOA record", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/zones/"+record.Zone, http.StatusSeeOther)
+}
@@ -0,0 +1,148 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// syncPayload is the desired state of a zone as submitted to the API
+type syncPayload struct {
+	Records  []recordPayload `json:"records"`
+	PlanHash string          `json:"plan_hash,omitempty"`
+}
+
+// PlanResponse is a zone sync plan as returned by the API
+type PlanResponse struct {
+	Zone        string                  `json:"zone"`
+	ZoneUnicode string                  `json:"zone_unicode"`
+	Hash        string                  `json:"hash"`
+	Changes     []PlannedChangeResponse `json:"changes"`
+}
+
+// PlannedChangeResponse is a change of a plan as returned by the API. The
+// record is the desired record of creates and updates, the current record is
+// the existing record updated or deleted.
+type PlannedChangeResponse struct {
+	Action  string          `json:"action"`
+	ID      int64           `json:"id,omitempty"`
+	Record  *RecordResponse `json:"record,omitempty"`
+	Current *RecordResponse `json:"current,omitempty"`
+}
+
+// handleZonePlan plans the changes converging the zone to the submitted
+// records without applying them
+func (s *Service) handleZonePlan(w http.ResponseWriter, r *http.Request) {
+	desired, ok := decodeSyncPayload(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := s.records.PlanZoneSync(r.Context(), chi.URLParam(r, "zone"), getUserID(r), desired.records)
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to plan changes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newPlanResponse(plan))
+}
+
+// handleZoneApply converges the zone to the submitted records atomically,
+// provided the plan with the submitted hash still applies
+func (s *Service) handleZoneApply(w http.ResponseWriter, r *http.Request) {
+	desired, ok := decodeSyncPayload(w, r)
+	if !ok {
+		return
+	}
+	if desired.planHash == "" {
+		respondWithError(w, http.StatusBadRequest, "Plan hash is required", nil)
+		return
+	}
+
+	plan, results, err := s.records.ApplyZoneSync(r.Context(), chi.URLParam(r, "zone"), getUserID(r), desired.records, desired.planHash)
+	if errors.Is(err, recordmanager.ErrZoneChanged) {
+		respondWithError(w, http.StatusConflict, "Zone has changed since the plan was made, plan again", nil)
+		return
+	}
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to apply changes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"plan":    newPlanResponse(plan),
+		"results": newChangeResultResponses(results),
+	})
+}
+
+// desiredState is the parsed desired state of a zone
+type desiredState struct {
+	records  []*recordmanager.Record
+	planHash string
+}
+
+// decodeSyncPayload decodes and validates the desired records of a zone,
+// writing an error response if they are invalid
+func decodeSyncPayload(w http.ResponseWriter, r *http.Request) (desiredState, bool) {
+	var payload syncPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return desiredState{}, false
+	}
+
+	desired := desiredState{
+		records:  make([]*recordmanager.Record, len(payload.Records)),
+		planHash: payload.PlanHash,
+	}
+	for i, recordPayload := range payload.Records {
+		record, validationErrors, contentErr := recordPayload.toRecord()
+		if contentErr != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("record %d: %v", i, contentErr), nil)
+			return desiredState{}, false
+		}
+		if len(validationErrors) > 0 {
+			respondWithError(w, http.StatusBadRequest, "Validation failed", withIndex(validationErrors, i))
+			return desiredState{}, false
+		}
+		desired.records[i] = record
+	}
+	return desired, true
+}
+
+// newPlanResponse converts a plan to its API representation
+func newPlanResponse(plan *recordmanager.Plan) PlanResponse {
+	return PlanResponse{
+		Zone:        plan.Zone,
+		ZoneUnicode: recordmanager.UnicodeName(plan.Zone),
+		Hash:        plan.Hash,
+		Changes:     newPlannedChangeResponses(plan.Changes),
+	}
+}
+
+// newPlannedChangeResponses converts planned changes to their API
+// representation
+func newPlannedChangeResponses(changes []recordmanager.PlannedChange) []PlannedChangeResponse {
+	response := make([]PlannedChangeResponse, len(changes))
+	for i, change := range changes {
+		response[i] = PlannedChangeResponse{
+			Action: change.Action,
+			ID:     change.ID,
+		}
+		if change.Record != nil {
+			record := newRecordResponse(change.Record)
+			response[i].Record = &record
+		}
+		if change.Current != nil {
+			current := newRecordResponse(change.Current)
+			response[i].Current = &current
+		}
+	}
+	return response
+}
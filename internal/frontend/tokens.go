@@ -0,0 +1,331 @@
+package frontend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// apiTokenPrefix marks API tokens, so they can be recognized when leaked
+	apiTokenPrefix = "tofu_"
+	// apiTokenBytes is the number of random bytes of an API token
+	apiTokenBytes = 32
+	// maxAPITokenLifetimeDays is the longest lifetime of an expiring API token
+	maxAPITokenLifetimeDays = 3650
+)
+
+// APITokenResponse is an API token as returned by the API. The token itself
+// is only returned when it is created.
+type APITokenResponse struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// newAPITokenResponse converts an API token to its API representation
+func newAPITokenResponse(token storage.ApiToken) APITokenResponse {
+	response := APITokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.LastUsedAt.Valid {
+		response.LastUsedAt = &token.LastUsedAt.Time
+	}
+	if token.ExpiresAt.Valid {
+		response.ExpiresAt = &token.ExpiresAt.Time
+	}
+	return response
+}
+
+// isAPIRequest reports whether the request is for the JSON API
+func isAPIRequest(r *http.Request) bool {
+	return r.URL.Path == "/api" || strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// bearerToken returns the token of the request's Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// authenticateAPIToken serves an API request authenticated with an API token
+func (s *Service) authenticateAPIToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	ctx := r.Context()
+	tokenHash := hashAPIToken(token)
+
+	user, err := s.db.GetAPITokenUser(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or expired API token", nil)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to look up API token", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+	if err := s.db.UpdateAPITokenUsage(ctx, tokenHash); err != nil {
+		s.logger.Error("Failed to update API token usage", "error", err)
+	}
+
+	// The two-factor policy may have been enabled, or the user's second
+	// factors removed, since the token was created
+	enrolled, err := s.meetsTwoFactorPolicy(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to look up second factors", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+	if !enrolled {
+		respondWithError(w, http.StatusForbidden, "Two-factor authentication is required", nil)
+		return
+	}
+
+	ctx = context.WithValue(ctx, UserEmailKey, user.Email)
+	ctx = context.WithValue(ctx, UserIDKey, user.ID)
+	ctx = context.WithValue(ctx, APITokenKey, true)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// isAPITokenRequest reports whether the request is authenticated with an API
+// token rather than a session
+func isAPITokenRequest(r *http.Request) bool {
+	viaToken, _ := r.Context().Value(APITokenKey).(bool)
+	return viaToken
+}
+
+// generateAPIToken returns a new random API token
+func generateAPIToken() (string, error) {
+	b := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIToken returns the hash an API token is stored as
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// createAPIToken creates an API token for the user, expiring after the number
+// of days unless it is zero, and returns it along with the token itself
+func (s *Service) createAPIToken(ctx context.Context, userID uuid.UUID, name string, expiresInDays int) (storage.ApiToken, string, error) {
+	token, err := generateAPIToken()
+	if err != nil {
+		return storage.ApiToken{}, "", err
+	}
+
+	var expiresAt sql.NullTime
+	if expiresInDays > 0 {
+		expiresAt = sql.NullTime{
+			Time:  time.Now().AddDate(0, 0, expiresInDays),
+			Valid: true,
+		}
+	}
+
+	apiToken, err := s.db.CreateAPIToken(ctx, storage.CreateAPITokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashAPIToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return storage.ApiToken{}, "", err
+	}
+	return apiToken, token, nil
+}
+
+// validateAPIToken checks the name and lifetime of a new API token
+func validateAPIToken(name string, expiresInDays int) []ValidationError {
+	var errors []ValidationError
+	if name == "" {
+		errors = append(errors, ValidationError{Field: "name", Message: "Name is required"})
+	} else if len(name) > 255 {
+		errors = append(errors, ValidationError{Field: "name", Message: "Name must be at most 255 characters"})
+	}
+	if expiresInDays < 0 || expiresInDays > maxAPITokenLifetimeDays {
+		errors = append(errors, ValidationError{Field: "expires_in_days", Message: "Lifetime must be between 0 and 3650 days, 0 never expires"})
+	}
+	return errors
+}
+
+// handleAPITokenCreate creates an API token from the account page and shows
+// it once
+func (s *Service) handleAPITokenCreate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	name := strings.TrimSpace(r.Form.Get("name"))
+	var expiresInDays int
+	if value := r.Form.Get("expires_in_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil {
+			http.Redirect(w, r, "/account?error=Invalid+token+lifetime", http.StatusSeeOther)
+			return
+		}
+		expiresInDays = days
+	}
+	if validationErrors := validateAPIToken(name, expiresInDays); len(validationErrors) > 0 {
+		http.Redirect(w, r, "/account?error="+url.QueryEscape(validationErrors[0].Message), http.StatusSeeOther)
+		return
+	}
+
+	apiToken, token, err := s.createAPIToken(r.Context(), getUserID(r), name, expiresInDays)
+	if err != nil {
+		s.logger.Error("Failed to create API token", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Token":    token,
+		"APIToken": apiToken,
+	}
+	if err := s.templates.ExecuteTemplate(w, "api_token.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.notify(r, email.NotificationAPITokenCreated)
+}
+
+// handleAPITokenDelete deletes an API token from the account page
+func (s *Service) handleAPITokenDelete(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenId"), 10, 32)
+	if err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+token", http.StatusSeeOther)
+		return
+	}
+
+	deleted, err := s.db.DeleteAPIToken(r.Context(), storage.DeleteAPITokenParams{
+		ID:     int32(tokenID),
+		UserID: getUserID(r),
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete API token", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if deleted > 0 {
+		s.notify(r, email.NotificationAPITokenDeleted)
+	}
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// handleAPITokenList lists the user's API tokens
+func (s *Service) handleAPITokenList(w http.ResponseWriter, r *http.Request) {
+	tokens, err := s.db.ListAPITokensByUser(r.Context(), getUserID(r))
+	if err != nil {
+		s.logger.Error("Failed to list API tokens", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list API tokens", nil)
+		return
+	}
+
+	response := make([]APITokenResponse, len(tokens))
+	for i, token := range tokens {
+		response[i] = newAPITokenResponse(token)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tokens": response,
+	})
+}
+
+// handleAPITokenCreateJSON creates an API token, returning the token itself
+// once. Tokens are only created from a session, which passed the login and
+// two-factor checks, so a leaked token can't be used to mint others that
+// outlive its revocation.
+func (s *Service) handleAPITokenCreateJSON(w http.ResponseWriter, r *http.Request) {
+	if isAPITokenRequest(r) {
+		respondWithError(w, http.StatusForbidden, "API tokens can't create API tokens, sign in to create one", nil)
+		return
+	}
+
+	var payload struct {
+		Name          string `json:"name"`
+		ExpiresInDays int    `json:"expires_in_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	if validationErrors := validateAPIToken(name, payload.ExpiresInDays); len(validationErrors) > 0 {
+		respondWithError(w, http.StatusBadRequest, "Validation failed", validationErrors)
+		return
+	}
+
+	apiToken, token, err := s.createAPIToken(r.Context(), getUserID(r), name, payload.ExpiresInDays)
+	if err != nil {
+		s.logger.Error("Failed to create API token", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API token", nil)
+		return
+	}
+	s.notify(r, email.NotificationAPITokenCreated)
+
+	response := newAPITokenResponse(apiToken)
+	response.Token = token
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"token":  response,
+	})
+}
+
+// handleAPITokenDeleteJSON deletes an API token
+func (s *Service) handleAPITokenDeleteJSON(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenId"), 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Token ID is not a number", nil)
+		return
+	}
+
+	deleted, err := s.db.DeleteAPIToken(r.Context(), storage.DeleteAPITokenParams{
+		ID:     int32(tokenID),
+		UserID: getUserID(r),
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete API token", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete API token", nil)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Token not found", nil)
+		return
+	}
+	s.notify(r, email.NotificationAPITokenDeleted)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package frontend
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPITokenTwoFactorPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		requireTOTP bool
+		// organization is the user's organization, if any
+		organization *storage.Organization
+		passkeys     []storage.WebauthnCredential
+		want         int
+	}{
+		{name: "not required", want: http.StatusOK},
+		{name: "second factor", requireTOTP: true, passkeys: []storage.WebauthnCredential{{ID: 1}}, want: http.StatusOK},
+		{name: "no second factor", requireTOTP: true, want: http.StatusForbidden},
+		{name: "organization without policy", organization: &storage.Organization{Name: "Example"}, want: http.StatusOK},
+		{
+			name:         "organization second factor",
+			organization: &storage.Organization{Name: "Example", RequireTwoFactor: true},
+			passkeys:     []storage.WebauthnCredential{{ID: 1}},
+			want:         http.StatusOK,
+		},
+		{
+			name:         "organization no second factor",
+			organization: &storage.Organization{Name: "Example", RequireTwoFactor: true},
+			want:         http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			config.RequireTOTP = tt.requireTOTP
+			s, querier := newTestService(t, config)
+			user := storage.User{ID: uuid.New(), Email: "user@example.com"}
+			querier.EXPECT().GetAPITokenUser(gomock.Any(), hashAPIToken("tofu_token")).Return(user, nil)
+			querier.EXPECT().UpdateAPITokenUsage(gomock.Any(), gomock.Any()).Return(nil)
+			if tt.organization != nil {
+				querier.EXPECT().GetUserOrganization(gomock.Any(), user.ID).Return(*tt.organization, nil).AnyTimes()
+			} else {
+				expectNoOrganization(querier, user.ID)
+			}
+			if tt.requireTOTP || (tt.organization != nil && tt.organization.RequireTwoFactor) {
+				querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{}, sql.ErrNoRows)
+				querier.EXPECT().ListWebAuthnCredentialsByUser(gomock.Any(), user.ID).Return(tt.passkeys, nil)
+			}
+
+			handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !isAPITokenRequest(r) {
+					t.Errorf("request not marked as authenticated with a token")
+				}
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/api/zones", nil)
+			r.Header.Set("Authorization", "Bearer tofu_token")
+			if resp := serve(handler, r); resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITokenCreateRequiresSession(t *testing.T) {
+	s, querier := newTestService(t, testConfig)
+	user := storage.User{ID: uuid.New()}
+	querier.EXPECT().GetAPITokenUser(gomock.Any(), gomock.Any()).Return(user, nil)
+	querier.EXPECT().UpdateAPITokenUsage(gomock.Any(), gomock.Any()).Return(nil)
+	expectNoOrganization(querier, user.ID)
+
+	// The querier fails the test if a token is created
+	handler := s.authMiddleware(http.HandlerFunc(s.handleAPITokenCreateJSON))
+	r := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(`{"name":"minted"}`))
+	r.Header.Set("Authorization", "Bearer tofu_token")
+	if resp := serve(handler, r); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,611 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// Two-factor settings
+	twoFactorCookieName = "tofudns_2fa"
+	twoFactorAudience   = "2fa"
+	twoFactorExpiration = 5 * time.Minute
+	totpIssuer          = "TofuDNS"
+	totpPeriod          = 30
+	totpSkew            = 1
+	recoveryCodeCount   = 10
+
+	// Codes of a TOTP credential may be tried maxTwoFactorAttempts times in a
+	// row, and then once every twoFactorLockout until one is correct
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+// errTwoFactorLocked is returned while too many codes in a row were wrong
+var errTwoFactorLocked = errors.New("too many two-factor attempts")
+
+// twoFactorLockedError is the error shown while codes can't be tried
+const twoFactorLockedError = "Too+many+attempts,+try+again+later"
+
+// handleTOTPSetup starts TOTP enrollment by generating a new, unconfirmed secret
+func (s *Service) handleTOTPSetup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	enabled, err := s.hasTOTP(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to look up TOTP credential", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if enabled {
+		http.Redirect(w, r, "/account?error=Two-factor+authentication+is+already+enabled", http.StatusSeeOther)
+		return
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: getUserEmail(r),
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		s.logger.Error("Failed to generate TOTP secret", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	_, err = s.db.UpsertTOTPCredential(ctx, storage.UpsertTOTPCredentialParams{
+		UserID: userID,
+		Secret: key.Secret(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to store TOTP secret", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/account/totp/setup", http.StatusSeeOther)
+}
+
+// handleTOTPSetupPage displays the provisioning QR code for a pending enrollment
+func (s *Service) handleTOTPSetupPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	credential, err := s.db.GetTOTPCredential(ctx, userID)
+	if err != nil || credential.ConfirmedAt.Valid {
+		http.Redirect(w, r, "/account", http.StatusSeeOther)
+		return
+	}
+
+	key, err := totpKey(credential.Secret, getUserEmail(r))
+	if err != nil {
+		s.logger.Error("Failed to build TOTP key", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	// Render the provisioning URI as an inline QR code
+	img, err := key.Image(200, 200)
+	if err != nil {
+		s.logger.Error("Failed to render TOTP QR code", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		s.logger.Error("Failed to encode TOTP QR code", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Secret": key.Secret(),
+		"URI":    key.URL(),
+		"QRCode": template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())),
+		"Error":  r.URL.Query().Get("error"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "totp_setup.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleTOTPConfirm completes TOTP enrollment once the user proves possession
+// of the secret, and issues a fresh set of recovery codes
+func (s *Service) handleTOTPConfirm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account/totp/setup?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	credential, err := s.db.GetTOTPCredential(ctx, userID)
+	if err != nil || credential.ConfirmedAt.Valid {
+		http.Redirect(w, r, "/account", http.StatusSeeOther)
+		return
+	}
+
+	code := strings.TrimSpace(r.Form.Get("code"))
+	if !s.consumeTOTP(ctx, credential, code) {
+		http.Redirect(w, r, "/account/totp/setup?error=Invalid+code", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.db.ConfirmTOTPCredential(ctx, userID); err != nil {
+		s.logger.Error("Failed to confirm TOTP credential", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	s.notify(r, email.NotificationTOTPEnabled)
+
+	s.renderNewRecoveryCodes(w, r, userID)
+}
+
+// handleTOTPDisable removes the user's TOTP credential and recovery codes
+func (s *Service) handleTOTPDisable(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	// Passkeys are a second factor too
+	required, err := s.twoFactorRequired(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to look up the two-factor policy", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if required {
+		hasPasskey, err := s.hasPasskey(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to list passkeys", "error", err)
+			http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+			return
+		}
+		if !hasPasskey {
+			http.Redirect(w, r, "/account?error=Two-factor+authentication+is+required", http.StatusSeeOther)
+			return
+		}
+	}
+
+	ok, err := s.verifySecondFactor(ctx, userID, r.Form.Get("code"))
+	if errors.Is(err, errTwoFactorLocked) {
+		http.Redirect(w, r, "/account?error="+twoFactorLockedError, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to verify second factor", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !ok {
+		http.Redirect(w, r, "/account?error=Invalid+code", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.db.DeleteRecoveryCodes(ctx, userID); err != nil {
+		s.logger.Error("Failed to delete recovery codes", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if err := s.db.DeleteTOTPCredential(ctx, userID); err != nil {
+		s.logger.Error("Failed to delete TOTP credential", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	s.notify(r, email.NotificationTOTPDisabled)
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// handleRecoveryCodesRegenerate replaces the user's recovery codes
+func (s *Service) handleRecoveryCodesRegenerate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	enabled, err := s.hasTOTP(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to look up TOTP credential", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !enabled {
+		http.Redirect(w, r, "/account", http.StatusSeeOther)
+		return
+	}
+
+	// Only a current authenticator code can be used to regenerate
+	ok, err := s.verifyCode(ctx, userID, r.Form.Get("code"), false)
+	if errors.Is(err, errTwoFactorLocked) {
+		http.Redirect(w, r, "/account?error="+twoFactorLockedError, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to verify authenticator code", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !ok {
+		http.Redirect(w, r, "/account?error=Invalid+code", http.StatusSeeOther)
+		return
+	}
+
+	s.renderNewRecoveryCodes(w, r, userID)
+	s.notify(r, email.NotificationRecoveryCodesRegenerated)
+}
+
+// handleTwoFactorPage displays the second-factor form during login
+func (s *Service) handleTwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	email, err := s.pendingTwoFactorEmail(r)
+	if err != nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := s.db.GetUserByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("Failed to look up user", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	totpEnabled, err := s.hasTOTP(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to look up TOTP credential", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	passkeysEnabled, err := s.hasPasskey(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to list passkeys", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"TOTPEnabled":     totpEnabled,
+		"PasskeysEnabled": passkeysEnabled,
+		"Error":           r.URL.Query().Get("error"),
+	}
+	s.templates.ExecuteTemplate(w, "verify_2fa.html", data)
+}
+
+// handleVerifyTwoFactor checks the second factor and completes the login
+func (s *Service) handleVerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	email, err := s.pendingTwoFactorEmail(r)
+	if err != nil {
+		http.Redirect(w, r, "/auth/login?error=Login+expired", http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/auth/2fa?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := s.db.GetUserByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("Failed to look up user", "error", err)
+		http.Redirect(w, r, "/auth/login?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user.ID, r.Form.Get("code"))
+	if errors.Is(err, errTwoFactorLocked) {
+		http.Redirect(w, r, "/auth/2fa?error="+twoFactorLockedError, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to verify second factor", "error", err)
+		http.Redirect(w, r, "/auth/2fa?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !ok {
+		http.Redirect(w, r, "/auth/2fa?error=Invalid+code", http.StatusSeeOther)
+		return
+	}
+
+	clearTwoFactorCookie(w, r)
+	s.completeLogin(w, r, email)
+}
+
+// Helper functions
+
+// meetsTwoFactorPolicy reports whether the user may use the service under
+// the two-factor policy: either no second factor is required, or the user has
+// one. Passkeys count as a second factor.
+func (s *Service) meetsTwoFactorPolicy(ctx context.Context, userID uuid.UUID) (bool, error) {
+	required, err := s.twoFactorRequired(ctx, userID)
+	if err != nil || !required {
+		return !required, err
+	}
+	return s.hasSecondFactor(ctx, userID)
+}
+
+// twoFactorRequired reports whether the user must have a second factor,
+// because the service requires one of everyone or the user's organization
+// requires one of its members
+func (s *Service) twoFactorRequired(ctx context.Context, userID uuid.UUID) (bool, error) {
+	if s.requireTOTP {
+		return true, nil
+	}
+	organization, err := s.db.GetUserOrganization(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return organization.RequireTwoFactor, nil
+}
+
+// hasSecondFactor reports whether the user has enrolled any second factor.
+// Callers must fail closed on errors.
+func (s *Service) hasSecondFactor(ctx context.Context, userID uuid.UUID) (bool, error) {
+	enabled, err := s.hasTOTP(ctx, userID)
+	if err != nil || enabled {
+		return enabled, err
+	}
+	return s.hasPasskey(ctx, userID)
+}
+
+// hasTOTP reports whether the user has a confirmed TOTP credential
+func (s *Service) hasTOTP(ctx context.Context, userID uuid.UUID) (bool, error) {
+	credential, err := s.db.GetTOTPCredential(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return credential.ConfirmedAt.Valid, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. Wrong codes count towards the lockout of the TOTP credential, and
+// errTwoFactorLocked is returned while it is locked.
+func (s *Service) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	return s.verifyCode(ctx, userID, code, true)
+}
+
+// verifyCode accepts a current TOTP code, or an unused recovery code if
+// recovery is set, counting the attempt before the code is checked so that
+// concurrent attempts can't exceed the limit
+func (s *Service) verifyCode(ctx context.Context, userID uuid.UUID, code string, recovery bool) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	credential, err := s.db.GetTOTPCredential(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !credential.ConfirmedAt.Valid {
+		return false, nil
+	}
+
+	_, err = s.db.ClaimTOTPAttempt(ctx, storage.ClaimTOTPAttemptParams{
+		MaxAttempts:    maxTwoFactorAttempts,
+		LockoutSeconds: int32(twoFactorLockout.Seconds()),
+		UserID:         userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, errTwoFactorLocked
+	}
+	if err != nil {
+		return false, err
+	}
+
+	ok := s.consumeTOTP(ctx, credential, code)
+	if !ok && recovery {
+		// Fall back to recovery codes, which are single-use
+		_, err = s.db.ConsumeRecoveryCode(ctx, storage.ConsumeRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		ok = err == nil
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if err := s.db.ResetTOTPAttempts(ctx, userID); err != nil {
+		s.logger.Error("Failed to reset TOTP attempts", "error", err)
+	}
+	return true, nil
+}
+
+// consumeTOTP validates a TOTP code and records its time step so that the
+// same code cannot be replayed
+func (s *Service) consumeTOTP(ctx context.Context, credential storage.TotpCredential, code string) bool {
+	step, ok := validateTOTP(credential.Secret, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	rows, err := s.db.UpdateTOTPLastUsedStep(ctx, storage.UpdateTOTPLastUsedStepParams{
+		UserID:       credential.UserID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record TOTP step", "error", err)
+		return false
+	}
+	return rows == 1
+}
+
+// renderNewRecoveryCodes replaces the user's recovery codes and shows them once
+func (s *Service) renderNewRecoveryCodes(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	ctx := r.Context()
+
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		s.logger.Error("Failed to generate recovery codes", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashRecoveryCode(code)
+	}
+	err = s.db.ReplaceRecoveryCodes(ctx, storage.ReplaceRecoveryCodesParams{
+		UserID:     userID,
+		CodeHashes: hashes,
+	})
+	if err != nil {
+		s.logger.Error("Failed to store recovery codes", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Codes": codes,
+	}
+	if err := s.templates.ExecuteTemplate(w, "recovery_codes.html", data); err != nil {
+		s.logger.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// createTwoFactorToken creates a short-lived JWT that only identifies a login
+// awaiting its second factor
+func (s *Service) createTwoFactorToken(email string) (string, error) {
+	claims := &Claims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{twoFactorAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(twoFactorExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return s.keys.Sign(claims)
+}
+
+// pendingTwoFactorEmail returns the email from a valid two-factor cookie
+func (s *Service) pendingTwoFactorEmail(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(twoFactorCookieName)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &Claims{}
+	_, err = s.keys.Parse(r.Context(), cookie.Value, claims, jwt.WithAudience(twoFactorAudience))
+	if err != nil {
+		return "", err
+	}
+	return claims.Email, nil
+}
+
+// clearTwoFactorCookie removes the pending two-factor cookie
+func clearTwoFactorCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     twoFactorCookieName,
+		Value:    "",
+		Path:     "/auth/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// totpKey rebuilds the provisioning key for a stored base32 secret
+func totpKey(secret, accountName string) (*otp.Key, error) {
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, err
+	}
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+		Secret:      raw,
+	})
+}
+
+// validateTOTP checks a code against the secret, allowing for clock skew, and
+// returns the time step the code belongs to
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// generateRecoveryCodes generates random recovery codes formatted as xxxxx-xxxxx
+func generateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		randomBytes := make([]byte, 10)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(base32.StdEncoding.EncodeToString(randomBytes))[:10]
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes a recovery code and returns its SHA-256 hex digest
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,305 @@
+package frontend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// testTOTPSecret is the secret of the TOTP credentials of tests
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func TestVerifyTwoFactorLimitsAttempts(t *testing.T) {
+	const email = "user@example.com"
+
+	tests := []struct {
+		name     string
+		code     func(t *testing.T) string
+		locked   bool
+		valid    bool
+		location string
+	}{
+		{
+			name:     "locked",
+			code:     currentTOTPCode,
+			locked:   true,
+			location: "/auth/2fa?error=" + twoFactorLockedError,
+		},
+		{
+			name:     "wrong code",
+			code:     func(*testing.T) string { return "wrong-code" },
+			location: "/auth/2fa?error=Invalid+code",
+		},
+		{
+			name:     "correct code",
+			code:     currentTOTPCode,
+			valid:    true,
+			location: "/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			user := expectUser(querier, email)
+			querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{
+				UserID:      user.ID,
+				Secret:      testTOTPSecret,
+				ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			}, nil)
+
+			// The attempt is counted before the code is checked
+			claim := querier.EXPECT().ClaimTOTPAttempt(gomock.Any(), storage.ClaimTOTPAttemptParams{
+				MaxAttempts:    maxTwoFactorAttempts,
+				LockoutSeconds: int32(twoFactorLockout.Seconds()),
+				UserID:         user.ID,
+			})
+			switch {
+			case tt.locked:
+				claim.Return(int32(0), sql.ErrNoRows)
+			case tt.valid:
+				claim.Return(int32(1), nil)
+				querier.EXPECT().UpdateTOTPLastUsedStep(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				querier.EXPECT().ResetTOTPAttempts(gomock.Any(), user.ID).Return(nil)
+			default:
+				claim.Return(int32(1), nil)
+				querier.EXPECT().ConsumeRecoveryCode(gomock.Any(), gomock.Any()).Return(storage.RecoveryCode{}, sql.ErrNoRows)
+			}
+
+			token, err := s.createTwoFactorToken(email)
+			if err != nil {
+				t.Fatalf("createTwoFactorToken() error = %v", err)
+			}
+			form := url.Values{"code": {tt.code(t)}}
+			r := httptest.NewRequest(http.MethodPost, "/auth/2fa", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.AddCookie(&http.Cookie{Name: twoFactorCookieName, Value: token})
+
+			resp := serve(http.HandlerFunc(s.handleVerifyTwoFactor), r)
+			if resp.StatusCode != http.StatusSeeOther {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusSeeOther)
+			}
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+		})
+	}
+}
+
+func TestRequireTOTPAcceptsPasskeys(t *testing.T) {
+	const email = "user@example.com"
+
+	tests := []struct {
+		name     string
+		passkeys []storage.WebauthnCredential
+		err      error
+		want     int
+	}{
+		{
+			name:     "passkey",
+			passkeys: []storage.WebauthnCredential{{ID: 1}},
+			want:     http.StatusOK,
+		},
+		{
+			name: "no second factor",
+			want: http.StatusSeeOther,
+		},
+		{
+			name: "lookup error",
+			err:  errors.New("connection refused"),
+			want: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			config.RequireTOTP = true
+			s, querier := newTestService(t, config)
+			user := expectUser(querier, email)
+			querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{}, sql.ErrNoRows)
+			querier.EXPECT().ListWebAuthnCredentialsByUser(gomock.Any(), user.ID).Return(tt.passkeys, tt.err)
+
+			handler := s.authMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			resp := serve(handler, withSession(t, s, httptest.NewRequest(http.MethodGet, "/", nil), email))
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestBeginLoginFailsClosed(t *testing.T) {
+	const email = "user@example.com"
+	errDatabase := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		userErr  error
+		totp     storage.TotpCredential
+		totpErr  error
+		passkeys []storage.WebauthnCredential
+		keysErr  error
+		location string
+	}{
+		{
+			name:     "new user",
+			userErr:  sql.ErrNoRows,
+			location: "/",
+		},
+		{
+			name:     "no second factor",
+			totpErr:  sql.ErrNoRows,
+			location: "/",
+		},
+		{
+			name:     "totp",
+			totp:     storage.TotpCredential{ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+			location: "/auth/2fa",
+		},
+		{
+			name:     "passkey",
+			totpErr:  sql.ErrNoRows,
+			passkeys: []storage.WebauthnCredential{{ID: 1}},
+			location: "/auth/2fa",
+		},
+		{
+			name:     "user lookup error",
+			userErr:  errDatabase,
+			location: "/auth/login?error=Server+error",
+		},
+		{
+			name:     "totp lookup error",
+			totpErr:  errDatabase,
+			location: "/auth/login?error=Server+error",
+		},
+		{
+			name:     "passkey lookup error",
+			totpErr:  sql.ErrNoRows,
+			keysErr:  errDatabase,
+			location: "/auth/login?error=Server+error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			user := storage.User{ID: uuid.New(), Email: email}
+			querier.EXPECT().GetUserByEmail(gomock.Any(), email).Return(user, tt.userErr).AnyTimes()
+			querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(tt.totp, tt.totpErr).AnyTimes()
+			querier.EXPECT().ListWebAuthnCredentialsByUser(gomock.Any(), user.ID).Return(tt.passkeys, tt.keysErr).AnyTimes()
+
+			w := httptest.NewRecorder()
+			s.beginLogin(w, httptest.NewRequest(http.MethodPost, "/auth/verify", nil), email)
+			resp := w.Result()
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+
+			// Only a completed login sets the session cookie
+			var session bool
+			for _, cookie := range resp.Cookies() {
+				if cookie.Name == cookieName && cookie.Value != "" {
+					session = true
+				}
+			}
+			if want := tt.location == "/"; session != want {
+				t.Errorf("session cookie set = %v, want %v", session, want)
+			}
+		})
+	}
+}
+
+func TestRecoveryCodesRegenerate(t *testing.T) {
+	const email = "user@example.com"
+
+	tests := []struct {
+		name     string
+		err      error
+		location string
+	}{
+		{
+			name: "replaced",
+		},
+		{
+			name:     "store error",
+			err:      errors.New("connection refused"),
+			location: "/account?error=Server+error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			emails := &testEmailService{}
+			s.emailService = emails
+			user := storage.User{ID: uuid.New(), Email: email}
+			querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{
+				UserID:      user.ID,
+				Secret:      testTOTPSecret,
+				ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			}, nil).Times(2)
+			querier.EXPECT().ClaimTOTPAttempt(gomock.Any(), gomock.Any()).Return(int32(1), nil)
+			querier.EXPECT().UpdateTOTPLastUsedStep(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			querier.EXPECT().ResetTOTPAttempts(gomock.Any(), user.ID).Return(nil)
+
+			// All codes are replaced at once
+			var hashes []string
+			querier.EXPECT().ReplaceRecoveryCodes(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ReplaceRecoveryCodesParams) error {
+				if arg.UserID != user.ID {
+					t.Errorf("UserID = %v, want %v", arg.UserID, user.ID)
+				}
+				hashes = arg.CodeHashes
+				return tt.err
+			})
+
+			form := url.Values{"code": {currentTOTPCode(t)}}
+			r := httptest.NewRequest(http.MethodPost, "/account/recovery-codes", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			ctx := context.WithValue(r.Context(), UserIDKey, user.ID)
+			ctx = context.WithValue(ctx, UserEmailKey, email)
+			w := httptest.NewRecorder()
+			s.handleRecoveryCodesRegenerate(w, r.WithContext(ctx))
+
+			if location := w.Result().Header.Get("Location"); location != tt.location {
+				t.Fatalf("Location = %q, want %q", location, tt.location)
+			}
+			if tt.err != nil {
+				return
+			}
+			if len(hashes) != recoveryCodeCount {
+				t.Fatalf("stored %d codes, want %d", len(hashes), recoveryCodeCount)
+			}
+			// The page shows the codes that were stored
+			for _, hash := range hashes {
+				found := false
+				for _, code := range regexp.MustCompile(`[a-z2-7]{5}-[a-z2-7]{5}`).FindAllString(w.Body.String(), -1) {
+					if hashRecoveryCode(code) == hash {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("stored code %s is not shown", hash)
+				}
+			}
+		})
+	}
+}
+
+// currentTOTPCode returns the current code of the test TOTP secret
+func currentTOTPCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.GenerateCode(testTOTPSecret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode() error = %v", err)
+	}
+	return code
+}
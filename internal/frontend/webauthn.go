@@ -0,0 +1,599 @@
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// WebAuthn settings
+	webAuthnCookieName     = "tofudns_webauthn"
+	webAuthnSessionTimeout = 5 * time.Minute
+)
+
+// webAuthnUser adapts a storage user and its credentials to webauthn.User
+type webAuthnUser struct {
+	user        storage.User
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID returns the user handle, which is the raw user UUID
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+// WebAuthnName returns the user's email
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+// WebAuthnDisplayName returns the user's email
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Email
+}
+
+// WebAuthnCredentials returns the user's registered credentials
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// handlePasskeyRegisterBegin starts a passkey registration ceremony
+func (s *Service) handlePasskeyRegisterBegin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, err := s.loadWebAuthnUser(ctx, getUserEmail(r))
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start registration", nil)
+		return
+	}
+
+	// Exclude existing credentials so the same authenticator isn't registered twice
+	exclusions := webauthn.Credentials(user.credentials).CredentialDescriptors()
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		s.logger.Error("Failed to begin passkey registration", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start registration", nil)
+		return
+	}
+
+	if err := s.saveWebAuthnSession(w, r, session); err != nil {
+		s.logger.Error("Failed to store passkey session", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start registration", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(creation)
+}
+
+// handlePasskeyRegisterFinish verifies the attestation and stores the credential
+func (s *Service) handlePasskeyRegisterFinish(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		name = "Passkey"
+	}
+
+	session, err := s.consumeWebAuthnSession(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Registration expired, please try again", nil)
+		return
+	}
+
+	user, err := s.loadWebAuthnUser(ctx, getUserEmail(r))
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to register passkey", nil)
+		return
+	}
+
+	credential, err := s.webAuthn.FinishRegistration(user, session, r)
+	if err != nil {
+		s.logger.Error("Failed to finish passkey registration", "error", err)
+		respondWithError(w, http.StatusBadRequest, "Passkey registration failed", nil)
+		return
+	}
+
+	credentialJSON, err := json.Marshal(credential)
+	if err != nil {
+		s.logger.Error("Failed to marshal passkey", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to register passkey", nil)
+		return
+	}
+
+	_, err = s.db.CreateWebAuthnCredential(ctx, storage.CreateWebAuthnCredentialParams{
+		UserID:       user.user.ID,
+		CredentialID: credential.ID,
+		Name:         name,
+		Credential:   string(credentialJSON),
+	})
+	if err != nil {
+		s.logger.Error("Failed to store passkey", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to register passkey", nil)
+		return
+	}
+	s.notify(r, email.NotificationPasskeyAdded)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// handlePasskeyDelete removes one of the user's passkeys once the user has
+// entered a current authenticator or recovery code. Users may verify with a
+// passkey instead, through handlePasskeyDeleteBegin and
+// handlePasskeyDeleteFinish.
+func (s *Service) handlePasskeyDelete(w http.ResponseWriter, r *http.Request) {
+	passkeyID, err := passkeyIDParam(r)
+	if err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+passkey", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account?error=Invalid+request", http.StatusSeeOther)
+		return
+	}
+
+	ok, err := s.verifySecondFactor(r.Context(), getUserID(r), r.Form.Get("code"))
+	if errors.Is(err, errTwoFactorLocked) {
+		http.Redirect(w, r, "/account?error="+twoFactorLockedError, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to verify second factor", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+	if !ok {
+		http.Redirect(w, r, "/account?error=Invalid+code", http.StatusSeeOther)
+		return
+	}
+
+	err = s.deletePasskey(r, passkeyID)
+	if errors.Is(err, errSecondFactorRequired) {
+		http.Redirect(w, r, "/account?error=Two-factor+authentication+is+required", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to delete passkey", "error", err)
+		http.Redirect(w, r, "/account?error=Server+error", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// handlePasskeyDeleteBegin starts a passkey assertion verifying the user
+// before one of their passkeys is removed
+func (s *Service) handlePasskeyDeleteBegin(w http.ResponseWriter, r *http.Request) {
+	user, err := s.loadWebAuthnUser(r.Context(), getUserEmail(r))
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start verification", nil)
+		return
+	}
+
+	assertion, session, err := s.webAuthn.BeginLogin(user)
+	if err != nil {
+		s.logger.Error("Failed to begin passkey verification", "error", err)
+		respondWithError(w, http.StatusBadRequest, "No passkeys registered", nil)
+		return
+	}
+
+	if err := s.saveWebAuthnSession(w, r, session); err != nil {
+		s.logger.Error("Failed to store passkey session", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start verification", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assertion)
+}
+
+// handlePasskeyDeleteFinish verifies the passkey assertion and removes the
+// passkey
+func (s *Service) handlePasskeyDeleteFinish(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	passkeyID, err := passkeyIDParam(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid passkey", nil)
+		return
+	}
+
+	session, err := s.consumeWebAuthnSession(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Verification expired, please try again", nil)
+		return
+	}
+
+	user, err := s.loadWebAuthnUser(ctx, getUserEmail(r))
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+
+	credential, err := s.webAuthn.FinishLogin(user, session, r)
+	if err != nil {
+		s.logger.Error("Failed to finish passkey verification", "error", err)
+		respondWithError(w, http.StatusUnauthorized, "Passkey verification failed", nil)
+		return
+	}
+	if err := s.checkPasskeyUse(credential, user.WebAuthnName()); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Passkey verification failed", nil)
+		return
+	}
+	s.recordPasskeyUse(ctx, credential)
+
+	err = s.deletePasskey(r, passkeyID)
+	if errors.Is(err, errSecondFactorRequired) {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is required", nil)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to delete passkey", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "redirect": "/account"})
+}
+
+// handlePasskeyLoginBegin starts a discoverable passkey login, used as the
+// primary login method
+func (s *Service) handlePasskeyLoginBegin(w http.ResponseWriter, r *http.Request) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		s.logger.Error("Failed to begin passkey login", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start login", nil)
+		return
+	}
+
+	if err := s.saveWebAuthnSession(w, r, session); err != nil {
+		s.logger.Error("Failed to store passkey session", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start login", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assertion)
+}
+
+// handlePasskeyLoginFinish verifies a discoverable assertion and logs the user
+// in. A user-verified passkey satisfies both factors on its own.
+func (s *Service) handlePasskeyLoginFinish(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session, err := s.consumeWebAuthnSession(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Login expired, please try again", nil)
+		return
+	}
+
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		user, err := s.db.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadWebAuthnUser(ctx, user.Email)
+	}
+
+	user, credential, err := s.webAuthn.FinishPasskeyLogin(handler, session, r)
+	if err != nil {
+		s.logger.Error("Failed to finish passkey login", "error", err)
+		respondWithError(w, http.StatusUnauthorized, "Passkey login failed", nil)
+		return
+	}
+	if err := s.checkPasskeyUse(credential, user.WebAuthnName()); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Passkey login failed", nil)
+		return
+	}
+
+	s.recordPasskeyUse(ctx, credential)
+
+	if err := s.setSessionCookie(w, r, user.WebAuthnName()); err != nil {
+		s.logger.Error("Failed to create JWT token", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "redirect": "/"})
+}
+
+// handlePasskeyTwoFactorBegin starts a passkey assertion for a login that is
+// awaiting its second factor
+func (s *Service) handlePasskeyTwoFactorBegin(w http.ResponseWriter, r *http.Request) {
+	email, err := s.pendingTwoFactorEmail(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Login expired", nil)
+		return
+	}
+
+	user, err := s.loadWebAuthnUser(r.Context(), email)
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start verification", nil)
+		return
+	}
+
+	assertion, session, err := s.webAuthn.BeginLogin(user)
+	if err != nil {
+		s.logger.Error("Failed to begin passkey verification", "error", err)
+		respondWithError(w, http.StatusBadRequest, "No passkeys registered", nil)
+		return
+	}
+
+	if err := s.saveWebAuthnSession(w, r, session); err != nil {
+		s.logger.Error("Failed to store passkey session", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start verification", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assertion)
+}
+
+// handlePasskeyTwoFactorFinish verifies the passkey assertion and completes the login
+func (s *Service) handlePasskeyTwoFactorFinish(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	email, err := s.pendingTwoFactorEmail(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Login expired", nil)
+		return
+	}
+
+	session, err := s.consumeWebAuthnSession(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Verification expired, please try again", nil)
+		return
+	}
+
+	user, err := s.loadWebAuthnUser(ctx, email)
+	if err != nil {
+		s.logger.Error("Failed to load passkey user", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+
+	credential, err := s.webAuthn.FinishLogin(user, session, r)
+	if err != nil {
+		s.logger.Error("Failed to finish passkey verification", "error", err)
+		respondWithError(w, http.StatusUnauthorized, "Passkey verification failed", nil)
+		return
+	}
+	if err := s.checkPasskeyUse(credential, email); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Passkey verification failed", nil)
+		return
+	}
+
+	s.recordPasskeyUse(ctx, credential)
+
+	clearTwoFactorCookie(w, r)
+	if err := s.setSessionCookie(w, r, email); err != nil {
+		s.logger.Error("Failed to create JWT token", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Server error", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "redirect": "/"})
+}
+
+// Helper functions
+
+// errSecondFactorRequired is returned when removing a passkey would leave a
+// user required to have a second factor without one
+var errSecondFactorRequired = errors.New("two-factor authentication is required")
+
+// deletePasskey removes one of the authenticated user's passkeys and notifies
+// them, keeping the last second factor of users required to have one
+func (s *Service) deletePasskey(r *http.Request, passkeyID int32) error {
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	required, err := s.twoFactorRequired(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		totpEnabled, err := s.hasTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !totpEnabled {
+			passkeys, err := s.db.ListWebAuthnCredentialsByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if len(passkeys) <= 1 {
+				return errSecondFactorRequired
+			}
+		}
+	}
+
+	deleted, err := s.db.DeleteWebAuthnCredential(ctx, storage.DeleteWebAuthnCredentialParams{
+		ID:     passkeyID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.notify(r, email.NotificationPasskeyRemoved)
+	}
+	return nil
+}
+
+// passkeyIDParam returns the ID of the passkey in the URL
+func passkeyIDParam(r *http.Request) (int32, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "passkeyId"), 10, 32)
+	return int32(id), err
+}
+
+// hasPasskey reports whether the user has registered any passkeys
+func (s *Service) hasPasskey(ctx context.Context, userID uuid.UUID) (bool, error) {
+	credentials, err := s.db.ListWebAuthnCredentialsByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+// loadWebAuthnUser looks up a user by email along with their credentials
+func (s *Service) loadWebAuthnUser(ctx context.Context, email string) (*webAuthnUser, error) {
+	user, err := s.db.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.db.ListWebAuthnCredentialsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, c := range stored {
+		var credential webauthn.Credential
+		if err := json.Unmarshal([]byte(c.Credential), &credential); err != nil {
+			s.logger.Error("Failed to unmarshal passkey", "error", err, "id", c.ID)
+			continue
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+// errPasskeyCloned is returned when a passkey's signature counter hasn't
+// increased since its last use, which means another authenticator holds a
+// copy of its private key
+var errPasskeyCloned = errors.New("passkey signature counter did not increase, the authenticator may have been cloned")
+
+// checkPasskeyUse rejects an assertion whose authenticator may have been
+// cloned. Authenticators without a signature counter, such as synced
+// passkeys, always report zero and are never flagged. The stored counter is
+// kept, so the passkey keeps being rejected until the user removes it.
+func (s *Service) checkPasskeyUse(credential *webauthn.Credential, email string) error {
+	if credential.Authenticator.CloneWarning {
+		s.logger.Warn("Rejected passkey with a signature counter that did not increase",
+			"email", email, "sign_count", credential.Authenticator.SignCount)
+		return errPasskeyCloned
+	}
+	return nil
+}
+
+// recordPasskeyUse persists the updated sign counter and flags after a login
+func (s *Service) recordPasskeyUse(ctx context.Context, credential *webauthn.Credential) {
+	credentialJSON, err := json.Marshal(credential)
+	if err != nil {
+		s.logger.Error("Failed to marshal passkey", "error", err)
+		return
+	}
+
+	err = s.db.UpdateWebAuthnCredentialUsage(ctx, storage.UpdateWebAuthnCredentialUsageParams{
+		CredentialID: credential.ID,
+		Credential:   string(credentialJSON),
+	})
+	if err != nil {
+		s.logger.Error("Failed to update passkey usage", "error", err)
+	}
+}
+
+// saveWebAuthnSession stores ceremony state server-side and references it from a cookie
+func (s *Service) saveWebAuthnSession(w http.ResponseWriter, r *http.Request, session *webauthn.SessionData) error {
+	ctx := r.Context()
+
+	// Opportunistically clean up abandoned ceremonies
+	if err := s.db.DeleteExpiredWebAuthnSessions(ctx); err != nil {
+		s.logger.Error("Failed to delete expired passkey sessions", "error", err)
+	}
+
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(webAuthnSessionTimeout)
+	stored, err := s.db.CreateWebAuthnSession(ctx, storage.CreateWebAuthnSessionParams{
+		SessionData: string(sessionJSON),
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     webAuthnCookieName,
+		Value:    stored.ID.String(),
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// consumeWebAuthnSession loads and deletes the ceremony state, so each
+// challenge can only be answered once
+func (s *Service) consumeWebAuthnSession(w http.ResponseWriter, r *http.Request) (webauthn.SessionData, error) {
+	var session webauthn.SessionData
+
+	cookie, err := r.Cookie(webAuthnCookieName)
+	if err != nil {
+		return session, err
+	}
+
+	// Clear the cookie regardless of the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     webAuthnCookieName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	sessionID, err := uuid.Parse(cookie.Value)
+	if err != nil {
+		return session, err
+	}
+
+	stored, err := s.db.ConsumeWebAuthnSession(r.Context(), sessionID)
+	if err != nil {
+		return session, err
+	}
+
+	if err := json.Unmarshal([]byte(stored.SessionData), &session); err != nil {
+		return session, errors.New("invalid passkey session")
+	}
+	return session, nil
+}
@@ -0,0 +1,125 @@
+package frontend
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPasskeyDeleteRequiresSecondFactor(t *testing.T) {
+	const userEmail = "user@example.com"
+
+	tests := []struct {
+		name     string
+		code     func(t *testing.T) string
+		claimed  bool
+		deleted  bool
+		location string
+	}{
+		{
+			name:     "no code",
+			code:     func(*testing.T) string { return "" },
+			location: "/account?error=Invalid+code",
+		},
+		{
+			name:     "wrong code",
+			code:     func(*testing.T) string { return "wrong-code" },
+			claimed:  true,
+			location: "/account?error=Invalid+code",
+		},
+		{
+			name:     "authenticator code",
+			code:     currentTOTPCode,
+			claimed:  true,
+			deleted:  true,
+			location: "/account",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, querier := newTestService(t, testConfig)
+			emails := &testEmailService{}
+			s.emailService = emails
+			user := expectUser(querier, userEmail)
+			querier.EXPECT().GetTOTPCredential(gomock.Any(), user.ID).Return(storage.TotpCredential{
+				UserID:      user.ID,
+				Secret:      testTOTPSecret,
+				ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			}, nil).AnyTimes()
+			if tt.claimed {
+				querier.EXPECT().ClaimTOTPAttempt(gomock.Any(), gomock.Any()).Return(int32(1), nil)
+			}
+			if tt.claimed && !tt.deleted {
+				querier.EXPECT().ConsumeRecoveryCode(gomock.Any(), gomock.Any()).Return(storage.RecoveryCode{}, sql.ErrNoRows)
+			}
+			if tt.deleted {
+				querier.EXPECT().UpdateTOTPLastUsedStep(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				querier.EXPECT().ResetTOTPAttempts(gomock.Any(), user.ID).Return(nil)
+				querier.EXPECT().DeleteWebAuthnCredential(gomock.Any(), storage.DeleteWebAuthnCredentialParams{
+					ID:     7,
+					UserID: user.ID,
+				}).Return(int64(1), nil)
+			}
+
+			form := url.Values{"code": {tt.code(t)}}
+			r := httptest.NewRequest(http.MethodPost, "/account/passkeys/7/delete", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router := chi.NewRouter()
+			s.Router(router)
+			resp := serve(router, withSession(t, s, r, userEmail))
+
+			if resp.StatusCode != http.StatusSeeOther {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusSeeOther)
+			}
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+			if tt.deleted && (len(emails.notifications) != 1 || emails.notifications[0] != email.NotificationPasskeyRemoved) {
+				t.Errorf("notifications = %v, want %s", emails.notifications, email.NotificationPasskeyRemoved)
+			}
+		})
+	}
+}
+
+func TestCheckPasskeyUseRejectsClones(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  uint32
+		counter uint32
+		wantErr bool
+	}{
+		{name: "counter increased", stored: 5, counter: 6},
+		{name: "no counter", stored: 0, counter: 0},
+		{name: "counter repeated", stored: 5, counter: 5, wantErr: true},
+		{name: "counter went backwards", stored: 5, counter: 3, wantErr: true},
+		{name: "counter reset", stored: 5, counter: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, testConfig)
+
+			// FinishLogin updates the counter of the credential it returns
+			credential := &webauthn.Credential{Authenticator: webauthn.Authenticator{SignCount: tt.stored}}
+			credential.Authenticator.UpdateCounter(tt.counter)
+
+			err := s.checkPasskeyUse(credential, "user@example.com")
+			if tt.wantErr != errors.Is(err, errPasskeyCloned) {
+				t.Errorf("checkPasskeyUse() error = %v, want cloned %t", err, tt.wantErr)
+			}
+			if tt.wantErr && credential.Authenticator.SignCount != tt.stored {
+				t.Errorf("sign count = %d, want the stored %d kept", credential.Authenticator.SignCount, tt.stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,592 @@
+package frontend
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// templateCheckZone is the zone templates are parsed for when they are saved,
+// to catch errors before they are applied
+const templateCheckZone = "example.com."
+
+// ZoneTemplateResponse is a zone template as returned by the API
+type ZoneTemplateResponse struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// zoneTemplatePayload is a zone template as submitted to the API
+type zoneTemplatePayload struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// templateChangeRow is a change of applying a template as shown on the
+// template preview page
+type templateChangeRow struct {
+	Action     string
+	Name       string
+	RecordType string
+	Current    string
+	New        string
+}
+
+// newZoneTemplateResponse converts a zone template to its API representation
+func newZoneTemplateResponse(template *recordmanager.ZoneTemplate) ZoneTemplateResponse {
+	return ZoneTemplateResponse{
+		ID:        template.ID,
+		Name:      template.Name,
+		Content:   template.Content,
+		CreatedAt: template.CreatedAt,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
+
+// templateRecords parses the records of a zone template for the zone. The
+// template is a zone file relative to the zone.
+func templateRecords(template *recordmanager.ZoneTemplate, zone string) ([]*recordmanager.Record, error) {
+	parsed, err := zonefile.Parse(strings.NewReader(template.Expand(zone)), zone, template.Name, true)
+	if err != nil {
+		return nil, &recordmanager.ValidationError{Field: "content", Message: err.Error()}
+	}
+
+	records := make([]*recordmanager.Record, len(parsed))
+	for i, p := range parsed {
+		record := &recordmanager.Record{
+			Name:       p.Name,
+			RecordType: p.Type,
+			Ttl:        sql.NullInt32{Int32: p.TTL, Valid: true},
+			A:          p.A,
+			AAAA:       p.AAAA,
+			TXT:        p.TXT,
+			CNAME:      p.CNAME,
+			NS:         p.NS,
+			MX:         p.MX,
+			SRV:        p.SRV,
+			SOA:        p.SOA,
+			CAA:        p.CAA,
+		}
+		if validationErrors := validateRecord(record); len(validationErrors) > 0 {
+			return nil, &recordmanager.ValidationError{
+				Field:   "content",
+				Message: fmt.Sprintf("%s %s record: %s", p.Name, p.Type, validationErrors[0].Message),
+			}
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+// checkTemplateContent reports whether the content of a zone template parses
+func checkTemplateContent(content string) error {
+	_, err := templateRecords(&recordmanager.ZoneTemplate{Name: "template", Content: content}, templateCheckZone)
+	return err
+}
+
+// templateID parses the ID of a zone template
+func templateID(s string) (int32, error) {
+	id, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, &recordmanager.ValidationError{Field: "template_id", Message: "template ID is not a number"}
+	}
+	return int32(id), nil
+}
+
+// handleAPITemplateList lists the user's zone templates
+func (s *Service) handleAPITemplateList(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.records.ListZoneTemplates(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to list zone templates")
+		return
+	}
+
+	response := make([]ZoneTemplateResponse, len(templates))
+	for i, template := range templates {
+		response[i] = newZoneTemplateResponse(template)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": response,
+	})
+}
+
+// handleAPITemplateCreate creates a zone template
+func (s *Service) handleAPITemplateCreate(w http.ResponseWriter, r *http.Request) {
+	var payload zoneTemplatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+	if err := checkTemplateContent(payload.Content); err != nil {
+		respondWithRecordError(w, err, "Invalid zone template")
+		return
+	}
+
+	template, err := s.records.CreateZoneTemplate(r.Context(), getUserID(r), payload.Name, payload.Content)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to create zone template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"template": newZoneTemplateResponse(template),
+	})
+}
+
+// handleAPITemplateGet returns a zone template
+func (s *Service) handleAPITemplateGet(w http.ResponseWriter, r *http.Request) {
+	id, err := templateID(chi.URLParam(r, "templateId"))
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid template ID")
+		return
+	}
+	template, err := s.records.GetZoneTemplate(r.Context(), id, getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to get zone template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newZoneTemplateResponse(template))
+}
+
+// handleAPITemplateUpdate renames a zone template and replaces its content
+func (s *Service) handleAPITemplateUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := templateID(chi.URLParam(r, "templateId"))
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid template ID")
+		return
+	}
+	var payload zoneTemplatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+	if err := checkTemplateContent(payload.Content); err != nil {
+		respondWithRecordError(w, err, "Invalid zone template")
+		return
+	}
+
+	template, err := s.records.UpdateZoneTemplate(r.Context(), id, getUserID(r), payload.Name, payload.Content)
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to update zone template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"template": newZoneTemplateResponse(template),
+	})
+}
+
+// handleAPITemplateDelete deletes a zone template
+func (s *Service) handleAPITemplateDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := templateID(chi.URLParam(r, "templateId"))
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid template ID")
+		return
+	}
+	if err := s.records.DeleteZoneTemplate(r.Context(), id, getUserID(r)); err != nil {
+		respondWithRecordError(w, err, "Failed to delete zone template")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIZoneTemplatePlan plans the changes applying a zone template to the
+// zone would make, without applying them
+func (s *Service) handleAPIZoneTemplatePlan(w http.ResponseWriter, r *http.Request) {
+	zone := chi.URLParam(r, "zone")
+	records, ok := s.zoneTemplateRecords(w, r, zone)
+	if !ok {
+		return
+	}
+
+	plan, err := s.records.PlanZoneMerge(r.Context(), zone, getUserID(r), records)
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to plan changes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newPlanResponse(plan))
+}
+
+// handleAPIZoneTemplateApply applies a zone template to the zone atomically,
+// provided the plan with the submitted hash still applies
+func (s *Service) handleAPIZoneTemplateApply(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		PlanHash string `json:"plan_hash"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", nil)
+		return
+	}
+	if payload.PlanHash == "" {
+		respondWithError(w, http.StatusBadRequest, "Plan hash is required", nil)
+		return
+	}
+	zone := chi.URLParam(r, "zone")
+	records, ok := s.zoneTemplateRecords(w, r, zone)
+	if !ok {
+		return
+	}
+
+	plan, results, err := s.records.ApplyZoneMerge(r.Context(), zone, getUserID(r), records, payload.PlanHash)
+	if errors.Is(err, recordmanager.ErrZoneChanged) {
+		respondWithError(w, http.StatusConflict, "Zone has changed since the plan was made, plan again", nil)
+		return
+	}
+	if err != nil {
+		respondWithChangeError(w, err, "Failed to apply changes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"plan":    newPlanResponse(plan),
+		"results": newChangeResultResponses(results),
+	})
+}
+
+// zoneTemplateRecords returns the records of the zone template of the API
+// request for the zone, writing an error response if there are none
+func (s *Service) zoneTemplateRecords(w http.ResponseWriter, r *http.Request, zone string) ([]*recordmanager.Record, bool) {
+	id, err := templateID(chi.URLParam(r, "templateId"))
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid template ID")
+		return nil, false
+	}
+	zone, err = recordmanager.CanonicalZone(zone)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid zone")
+		return nil, false
+	}
+	template, err := s.records.GetZoneTemplate(r.Context(), id, getUserID(r))
+	if err != nil {
+		respondWithRecordError(w, err, "Failed to get zone template")
+		return nil, false
+	}
+	records, err := templateRecords(template, zone)
+	if err != nil {
+		respondWithRecordError(w, err, "Invalid zone template")
+		return nil, false
+	}
+	return records, true
+}
+
+// handleTemplateList renders the zone templates page
+func (s *Service) handleTemplateList(w http.ResponseWriter, r *http.Request) {
+	s.renderTemplateList(w, r, nil)
+}
+
+// handleTemplateCreate creates a zone template from the form of the zone
+// templates page
+func (s *Service) handleTemplateCreate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	name, content := r.Form.Get("name"), r.Form.Get("content")
+
+	err := checkTemplateContent(content)
+	if err == nil {
+		_, err = s.records.CreateZoneTemplate(r.Context(), getUserID(r), name, content)
+	}
+	if message, ok := templateErrorMessage(err); ok {
+		s.renderTemplateList(w, r, map[string]interface{}{
+			"Error":   message,
+			"Name":    name,
+			"Content": content,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to create zone template", "error", err)
+		http.Error(w, "Failed to create zone template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/templates", http.StatusSeeOther)
+}
+
+// renderTemplateList renders the zone templates page with the data
+func (s *Service) renderTemplateList(w http.ResponseWriter, r *http.Request, data map[string]interface{}) {
+	templates, err := s.records.ListZoneTemplates(r.Context(), getUserID(r))
+	if err != nil {
+		slog.Error("Failed to list zone templates", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["Templates"] = templates
+	data["Placeholder"] = recordmanager.ZonePlaceholder
+	if err := s.templates.ExecuteTemplate(w, "zone_templates.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleTemplateGet renders the page editing a zone template
+func (s *Service) handleTemplateGet(w http.ResponseWriter, r *http.Request) {
+	template, ok := s.zoneTemplate(w, r, chi.URLParam(r, "templateId"))
+	if !ok {
+		return
+	}
+	s.renderTemplateEdit(w, template, "")
+}
+
+// handleTemplateUpdate updates a zone template from the form of its page
+func (s *Service) handleTemplateUpdate(w http.ResponseWriter, r *http.Request) {
+	template, ok := s.zoneTemplate(w, r, chi.URLParam(r, "templateId"))
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	template.Name, template.Content = r.Form.Get("name"), r.Form.Get("content")
+
+	err := checkTemplateContent(template.Content)
+	if err == nil {
+		_, err = s.records.UpdateZoneTemplate(r.Context(), template.ID, getUserID(r), template.Name, template.Content)
+	}
+	if message, ok := templateErrorMessage(err); ok {
+		s.renderTemplateEdit(w, template, message)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to update zone template", "error", err)
+		http.Error(w, "Failed to update zone template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/templates", http.StatusSeeOther)
+}
+
+// handleTemplateDelete deletes a zone template
+func (s *Service) handleTemplateDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := templateID(chi.URLParam(r, "templateId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	err = s.records.DeleteZoneTemplate(r.Context(), id, getUserID(r))
+	if err != nil && !errors.Is(err, recordmanager.ErrTemplateNotFound) {
+		slog.Error("Failed to delete zone template", "error", err)
+		http.Error(w, "Failed to delete zone template", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/templates", http.StatusSeeOther)
+}
+
+// renderTemplateEdit renders the page editing a zone template
+func (s *Service) renderTemplateEdit(w http.ResponseWriter, template *recordmanager.ZoneTemplate, message string) {
+	data := map[string]interface{}{
+		"Template":    template,
+		"Placeholder": recordmanager.ZonePlaceholder,
+		"Error":       message,
+	}
+	if err := s.templates.ExecuteTemplate(w, "zone_template.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleZoneTemplatePreview renders the changes applying the zone template
+// of the template query parameter to the zone would make
+func (s *Service) handleZoneTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	s.renderZoneTemplatePreview(w, r, r.URL.Query().Get("template"), "")
+}
+
+// handleZoneTemplateApply applies the zone template previewed on the
+// template preview page to the zone
+func (s *Service) handleZoneTemplateApply(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		slog.Error("Failed to parse form", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	zone := chi.URLParam(r, "zone")
+	template, ok := s.zoneTemplate(w, r, r.Form.Get("template"))
+	if !ok {
+		return
+	}
+
+	records, err := templateRecords(template, lookupZoneName(zone))
+	if err == nil {
+		_, _, err = s.records.ApplyZoneMerge(r.Context(), zone, getUserID(r), records, r.Form.Get("plan_hash"))
+	}
+	var changeErr *recordmanager.ChangeError
+	switch {
+	case errors.Is(err, recordmanager.ErrZoneChanged):
+		s.renderZoneTemplatePreview(w, r, r.Form.Get("template"), "The zone has changed since the preview, review the changes again")
+		return
+	case errors.Is(err, recordmanager.ErrZoneNotFound):
+		http.NotFound(w, r)
+		return
+	case errors.As(err, &changeErr):
+		s.renderZoneTemplatePreview(w, r, r.Form.Get("template"), changeErr.Err.Error())
+		return
+	case err != nil:
+		if message, ok := templateErrorMessage(err); ok {
+			s.renderZoneTemplatePreview(w, r, r.Form.Get("template"), message)
+			return
+		}
+		slog.Error("Failed to apply zone template", "error", err, "zone", zone)
+		http.Error(w, "Failed to apply zone template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/zones/"+lookupZoneName(zone), http.StatusSeeOther)
+}
+
+// renderZoneTemplatePreview renders the changes applying the zone template
+// with the ID to the zone would make
+func (s *Service) renderZoneTemplatePreview(w http.ResponseWriter, r *http.Request, id, message string) {
+	ctx := r.Context()
+	userID := getUserID(r)
+	zoneInfo, err := s.records.GetZone(ctx, chi.URLParam(r, "zone"), userID)
+	if errors.Is(err, recordmanager.ErrZoneNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to retrieve zone", "error", err)
+		http.Error(w, "Failed to retrieve zone", http.StatusInternalServerError)
+		return
+	}
+	template, ok := s.zoneTemplate(w, r, id)
+	if !ok {
+		return
+	}
+
+	data := map[string]interface{}{
+		"Zone":     zoneInfo.Name,
+		"ZoneInfo": zoneInfo,
+		"Template": template,
+		"Error":    message,
+	}
+	records, err := templateRecords(template, zoneInfo.Name)
+	var plan *recordmanager.Plan
+	if err == nil {
+		plan, err = s.records.PlanZoneMerge(ctx, zoneInfo.Name, userID, records)
+	}
+	if message, ok := templateErrorMessage(err); ok {
+		data["Error"] = message
+	} else if err != nil {
+		slog.Error("Failed to plan zone template", "error", err)
+		http.Error(w, "Failed to plan zone template", http.StatusInternalServerError)
+		return
+	} else {
+		rows := make([]templateChangeRow, len(plan.Changes))
+		for i, change := range plan.Changes {
+			record := change.Record
+			if record == nil {
+				record = change.Current
+			}
+			name := record.UnicodeName()
+			if name == "" {
+				name = recordmanager.ApexName
+			}
+			rows[i] = templateChangeRow{
+				Action:     change.Action,
+				Name:       name,
+				RecordType: record.RecordType,
+				Current:    templateRowContent(change.Current),
+				New:        templateRowContent(change.Record),
+			}
+		}
+		data["Rows"] = rows
+		data["PlanHash"] = plan.Hash
+	}
+
+	if err := s.templates.ExecuteTemplate(w, "zone_template_apply.html", data); err != nil {
+		slog.Error("Failed to execute template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// templateRowContent returns the TTL and content of a record as shown on the
+// template preview page, or nothing if there is no record
+func templateRowContent(record *recordmanager.Record) string {
+	if record == nil {
+		return ""
+	}
+	content, err := recordmanager.ContentJSON(record)
+	if err != nil {
+		content = string(record.Content)
+	}
+	return fmt.Sprintf("%d %s", record.Ttl.Int32, content)
+}
+
+// zoneTemplate returns the user's zone template with the ID, writing an error
+// response if there is none
+func (s *Service) zoneTemplate(w http.ResponseWriter, r *http.Request, id string) (*recordmanager.ZoneTemplate, bool) {
+	templateID, err := templateID(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	template, err := s.records.GetZoneTemplate(r.Context(), templateID, getUserID(r))
+	if errors.Is(err, recordmanager.ErrTemplateNotFound) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("Failed to get zone template", "error", err)
+		http.Error(w, "Failed to get zone template", http.StatusInternalServerError)
+		return nil, false
+	}
+	return template, true
+}
+
+// templateErrorMessage returns the message shown for an error of saving or
+// applying a zone template, if it is caused by the user's input
+func templateErrorMessage(err error) (string, bool) {
+	if validationErrors, ok := recordValidationErrors(err); ok {
+		return validationErrors[0].Message, true
+	}
+	if errors.Is(err, recordmanager.ErrTemplateExists) {
+		return "A template with this name already exists", true
+	}
+	return "", false
+}
+
+// lookupZoneName returns the canonical form of a zone name, or the name
+// unchanged if it is invalid
+func lookupZoneName(zone string) string {
+	if canonical, err := recordmanager.CanonicalZone(zone); err == nil {
+		return canonical
+	}
+	return zone
+}
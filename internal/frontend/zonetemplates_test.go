@@ -0,0 +1,60 @@
+package frontend
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+func TestTemplateRecords(t *testing.T) {
+	template := &recordmanager.ZoneTemplate{
+		Name: "mail",
+		Content: `@ 300 IN MX 10 mail.{{zone}}.
+mail 300 IN A 192.0.2.1
+www IN CNAME {{zone}}.
+@ 300 IN TXT "v=spf1 include:_spf.{{zone}} -all"
+`,
+	}
+
+	records, err := templateRecords(template, "example.org.")
+	if err != nil {
+		t.Fatalf("templateRecords() error = %v", err)
+	}
+	var got []string
+	for _, record := range records {
+		content, err := recordmanager.ContentJSON(record)
+		if err != nil {
+			t.Fatalf("ContentJSON() error = %v", err)
+		}
+		got = append(got, record.Name+" "+record.RecordType+" "+content)
+		if !record.Ttl.Valid {
+			t.Errorf("%s %s record has no TTL", record.Name, record.RecordType)
+		}
+	}
+	want := []string{
+		`example.org. MX {"host":"mail.example.org.","preference":10}`,
+		`mail.example.org. A {"ip":"192.0.2.1"}`,
+		`www.example.org. CNAME {"host":"example.org."}`,
+		`example.org. TXT {"text":"v=spf1 include:_spf.example.org -all"}`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("templateRecords() = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateRecordsInvalid(t *testing.T) {
+	for _, content := range []string{
+		"www 300 IN A not-an-address",
+		"www 300 IN BOGUS value",
+		"www 300 IN MX mail.{{zone}}.",
+	} {
+		template := &recordmanager.ZoneTemplate{Name: "bad", Content: content}
+		_, err := templateRecords(template, "example.org.")
+		var validationErr *recordmanager.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "content" {
+			t.Errorf("templateRecords(%q) error = %v, want a content validation error", content, err)
+		}
+	}
+}
@@ -0,0 +1,551 @@
+// Package jwtkeys manages the asymmetric keys used to sign and verify JWTs.
+//
+// Keys are stored in the database so that every instance of the service signs
+// with the same key and verifies tokens issued by its peers. A new signing key
+// is generated every rotation interval; retired keys remain valid for
+// verification until every token they signed has expired.
+//
+// With an encryption key configured, private keys are sealed with AES-256-GCM
+// before they are stored, so a leaked database dump or backup doesn't let
+// anyone sign tokens. This doesn't protect against a compromised host of the
+// service, which holds the encryption key. Keys stored before encryption was
+// configured stay readable until they expire. Keys sealed with a lost or
+// replaced encryption key can't be read: they are skipped, a new signing key
+// is generated and the tokens they signed, such as sessions, must be issued
+// again.
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// AlgorithmEdDSA signs tokens with Ed25519 keys
+	AlgorithmEdDSA = "EdDSA"
+	// AlgorithmES256 signs tokens with ECDSA P-256 keys
+	AlgorithmES256 = "ES256"
+
+	// reloadInterval bounds how often an unknown key ID triggers a reload
+	reloadInterval = 10 * time.Second
+
+	// EncryptionKeySize is the size of the key encrypting stored private keys
+	EncryptionKeySize = 32
+
+	// privateKeyBlock and encryptedKeyBlock are the PEM block types of stored
+	// private keys. An encrypted block holds the GCM nonce followed by the
+	// sealed PKCS #8 key.
+	privateKeyBlock   = "PRIVATE KEY"
+	encryptedKeyBlock = "TOFUDNS ENCRYPTED PRIVATE KEY"
+)
+
+// Config contains configuration for the key manager
+type Config struct {
+	// Algorithm is the signing algorithm for newly generated keys
+	Algorithm string
+	// Issuer is set as the iss claim and required when verifying
+	Issuer string
+	// RotationInterval is how long a key is used for signing
+	RotationInterval time.Duration
+	// TokenLifetime is the longest lifetime of any token signed with a key,
+	// for which the key stays available for verification after rotation
+	TokenLifetime time.Duration
+	// LegacySecret optionally verifies tokens signed with the former HS256
+	// shared secret, so existing sessions survive the upgrade
+	LegacySecret string
+	// LegacyAudience is the audience tokens signed with the legacy secret are
+	// verified as having. They predate audiences, and were all issued for
+	// the same purpose.
+	LegacyAudience string
+	// EncryptionKey optionally encrypts private keys at rest. It must be
+	// EncryptionKeySize bytes; without it keys are stored in plain PEM.
+	EncryptionKey []byte
+}
+
+// KeyManager signs and verifies JWTs with rotating asymmetric keys
+type KeyManager struct {
+	logger  *slog.Logger
+	querier storage.Querier
+	config  Config
+	// aead seals stored private keys, nil if they are stored unencrypted
+	aead cipher.AEAD
+
+	mu         sync.RWMutex
+	signing    *signingKey
+	keys       map[string]*signingKey
+	lastReload time.Time
+}
+
+// signingKey is a parsed key pair
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+// JSONWebKey is a public key in JWK format
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y,omitempty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// JSONWebKeySet is a set of public keys in JWKS format
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// New creates a new KeyManager instance. Call Rotate before first use to
+// load or generate the signing key.
+func New(logger *slog.Logger, querier storage.Querier, config Config) (*KeyManager, error) {
+	switch config.Algorithm {
+	case AlgorithmEdDSA, AlgorithmES256:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", config.Algorithm)
+	}
+	if config.RotationInterval <= 0 {
+		return nil, errors.New("rotation interval must be positive")
+	}
+
+	var aead cipher.AEAD
+	if config.EncryptionKey != nil {
+		if len(config.EncryptionKey) != EncryptionKeySize {
+			return nil, fmt.Errorf("encryption key must be %d bytes", EncryptionKeySize)
+		}
+		block, err := aes.NewCipher(config.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		if aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return &KeyManager{
+		logger:  logger,
+		querier: querier,
+		config:  config,
+		aead:    aead,
+		keys:    make(map[string]*signingKey),
+	}, nil
+}
+
+// Issuer returns the issuer set on signed tokens
+func (m *KeyManager) Issuer() string {
+	return m.config.Issuer
+}
+
+// Rotate removes expired keys, generates a new signing key when the current
+// one is due for rotation and reloads the key set
+func (m *KeyManager) Rotate(ctx context.Context) error {
+	if err := m.querier.DeleteExpiredSigningKeys(ctx); err != nil {
+		return fmt.Errorf("failed to delete expired signing keys: %w", err)
+	}
+
+	if err := m.reload(ctx); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	current := m.signing
+	m.mu.RUnlock()
+	if current != nil && time.Since(current.createdAt) < m.config.RotationInterval {
+		return nil
+	}
+
+	key, err := m.generate(ctx)
+	if err != nil {
+		return err
+	}
+	m.logger.Info("Generated new JWT signing key", "kid", key.kid, "algorithm", m.config.Algorithm)
+
+	return m.reload(ctx)
+}
+
+// Run periodically rotates keys and picks up keys generated by other
+// instances until the context is canceled
+func (m *KeyManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Rotate(ctx); err != nil {
+				m.logger.Error("Failed to rotate JWT signing keys", "error", err)
+			}
+		}
+	}
+}
+
+// Sign signs the claims with the current signing key
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key := m.signing
+	m.mu.RUnlock()
+	if key == nil {
+		return "", errors.New("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// Parse verifies the token against the current verification keys and parses
+// its claims
+func (m *KeyManager) Parse(ctx context.Context, tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	verifyOptions := slices.Clone(options)
+	if m.config.Issuer != "" {
+		verifyOptions = append(verifyOptions, jwt.WithIssuer(m.config.Issuer))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key ID")
+		}
+
+		key := m.lookup(ctx, kid)
+		if key == nil {
+			return nil, fmt.Errorf("unknown key ID: %s", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.private.Public(), nil
+	}, verifyOptions...)
+	if err == nil || m.config.LegacySecret == "" {
+		return token, err
+	}
+
+	// Fall back to the legacy shared secret for tokens issued before the
+	// upgrade, which carry neither a key ID nor an issuer
+	legacyToken, legacyErr := m.parseLegacy(tokenString, claims, options)
+	if legacyErr != nil {
+		return token, err
+	}
+	return legacyToken, nil
+}
+
+// parseLegacy verifies a token signed with the legacy shared secret. Legacy
+// tokens carry no audience either, so they are validated as having the
+// legacy audience.
+func (m *KeyManager) parseLegacy(tokenString string, claims jwt.Claims, options []jwt.ParserOption) (*jwt.Token, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Header["kid"]; ok {
+			return nil, errors.New("legacy tokens have no key ID")
+		}
+		return []byte(m.config.LegacySecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := claims.GetAudience()
+	if err != nil {
+		return nil, err
+	}
+	if len(audience) > 0 {
+		return nil, errors.New("legacy tokens have no audience")
+	}
+	if m.config.LegacyAudience != "" {
+		legacyAudience, err := json.Marshal(map[string][]string{"aud": {m.config.LegacyAudience}})
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(legacyAudience, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := jwt.NewValidator(options...).Validate(claims); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// JWKS returns the public verification keys
+func (m *KeyManager) JWKS() JSONWebKeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range m.keys {
+		jwk, err := key.jwk()
+		if err != nil {
+			m.logger.Error("Failed to encode JWK", "error", err, "kid", key.kid)
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// HandleJWKS serves the public verification keys at /.well-known/jwks.json
+func (m *KeyManager) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(m.JWKS()); err != nil {
+		m.logger.Error("Failed to encode JWKS", "error", err)
+	}
+}
+
+// lookup returns the verification key with the given ID, reloading the key set
+// when the ID is unknown in case another instance has rotated
+func (m *KeyManager) lookup(ctx context.Context, kid string) *signingKey {
+	m.mu.RLock()
+	key, ok := m.keys[kid]
+	lastReload := m.lastReload
+	m.mu.RUnlock()
+	if ok {
+		return key
+	}
+
+	if time.Since(lastReload) < reloadInterval {
+		return nil
+	}
+
+	// Claim the reload, so that a burst of tokens with unknown key IDs
+	// reloads the key set once
+	m.mu.Lock()
+	if m.lastReload != lastReload {
+		m.mu.Unlock()
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.keys[kid]
+	}
+	m.lastReload = time.Now()
+	m.mu.Unlock()
+
+	if err := m.reload(ctx); err != nil {
+		m.logger.Error("Failed to reload JWT signing keys", "error", err)
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys[kid]
+}
+
+// reload replaces the cached key set with the unexpired keys from the
+// database. It switches to signing with the newest key, such as one generated
+// by another instance, but never drops the current signing key: only Rotate
+// retires it, once it is due.
+func (m *KeyManager) reload(ctx context.Context) error {
+	rows, err := m.querier.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	keys := make(map[string]*signingKey, len(rows))
+	var newest *signingKey
+	for _, row := range rows {
+		key, err := m.parseKey(row)
+		if err != nil {
+			m.logger.Error("Skipping invalid signing key", "error", err, "kid", row.Kid)
+			continue
+		}
+		keys[key.kid] = key
+		if newest == nil || key.createdAt.After(newest.createdAt) {
+			newest = key
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	signing := m.signing
+	if signing != nil {
+		// Tokens signed with the current key must stay verifiable even if it
+		// has gone from the database
+		if stored, ok := keys[signing.kid]; ok {
+			signing = stored
+		} else {
+			keys[signing.kid] = signing
+		}
+	}
+	if newest != nil && (signing == nil || newest.createdAt.After(signing.createdAt)) {
+		signing = newest
+	}
+	m.keys = keys
+	m.signing = signing
+	m.lastReload = time.Now()
+	return nil
+}
+
+// generate creates and stores a new signing key
+func (m *KeyManager) generate(ctx context.Context) (*signingKey, error) {
+	var private crypto.Signer
+	var err error
+	switch m.config.Algorithm {
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	kidBytes := make([]byte, 16)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	kid := base64.RawURLEncoding.EncodeToString(kidBytes)
+	block, err := m.seal(kid, der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	row, err := m.querier.CreateSigningKey(ctx, storage.CreateSigningKeyParams{
+		Kid:        kid,
+		Algorithm:  m.config.Algorithm,
+		PrivateKey: string(pem.EncodeToMemory(block)),
+		ExpiresAt:  time.Now().Add(m.config.RotationInterval + m.config.TokenLifetime),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	return m.parseKey(row)
+}
+
+// seal returns the PEM block storing a PKCS #8 private key, encrypted if an
+// encryption key is configured. The key ID is authenticated with it, so a
+// sealed key can't be passed off as another row's.
+func (m *KeyManager) seal(kid string, der []byte) (*pem.Block, error) {
+	if m.aead == nil {
+		return &pem.Block{Type: privateKeyBlock, Bytes: der}, nil
+	}
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: encryptedKeyBlock, Bytes: m.aead.Seal(nonce, nonce, der, []byte(kid))}, nil
+}
+
+// open returns the PKCS #8 private key stored in a PEM block
+func (m *KeyManager) open(kid string, block *pem.Block) ([]byte, error) {
+	switch block.Type {
+	case privateKeyBlock:
+		return block.Bytes, nil
+	case encryptedKeyBlock:
+		if m.aead == nil {
+			return nil, errors.New("key is encrypted but no encryption key is configured")
+		}
+		if len(block.Bytes) < m.aead.NonceSize() {
+			return nil, errors.New("encrypted key is too short")
+		}
+		nonce, sealed := block.Bytes[:m.aead.NonceSize()], block.Bytes[m.aead.NonceSize():]
+		der, err := m.aead.Open(nil, nonce, sealed, []byte(kid))
+		if err != nil {
+			return nil, errors.New("failed to decrypt key, was it encrypted with another encryption key?")
+		}
+		return der, nil
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+}
+
+// parseKey parses a stored signing key
+func (m *KeyManager) parseKey(row storage.SigningKey) (*signingKey, error) {
+	block, _ := pem.Decode([]byte(row.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	der, err := m.open(row.Kid, block)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{
+		kid:       row.Kid,
+		createdAt: row.CreatedAt,
+	}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		if row.Algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("key type does not match algorithm %s", row.Algorithm)
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.private = private
+	case *ecdsa.PrivateKey:
+		if row.Algorithm != AlgorithmES256 || private.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key type does not match algorithm %s", row.Algorithm)
+		}
+		key.method = jwt.SigningMethodES256
+		key.private = private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return key, nil
+}
+
+// jwk returns the public half of the key in JWK format
+func (k *signingKey) jwk() (JSONWebKey, error) {
+	jwk := JSONWebKey{
+		KeyID:     k.kid,
+		Algorithm: k.method.Alg(),
+		Use:       "sig",
+	}
+
+	switch public := k.private.Public().(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	case *ecdsa.PublicKey:
+		ecdhKey, err := public.ECDH()
+		if err != nil {
+			return JSONWebKey{}, err
+		}
+		// Uncompressed point encoding is 0x04 || X || Y
+		point := ecdhKey.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+	return jwk, nil
+}
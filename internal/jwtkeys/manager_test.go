@@ -0,0 +1,267 @@
+package jwtkeys
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// keyStore keeps signing keys in memory in place of the database
+type keyStore struct {
+	mu   sync.Mutex
+	keys []storage.SigningKey
+}
+
+// expect makes the querier store keys in the key store
+func (s *keyStore) expect(querier *storage.MockQuerier) {
+	querier.EXPECT().DeleteExpiredSigningKeys(gomock.Any()).Return(nil).AnyTimes()
+	querier.EXPECT().CreateSigningKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateSigningKeyParams) (storage.SigningKey, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		key := storage.SigningKey{
+			Kid:        arg.Kid,
+			Algorithm:  arg.Algorithm,
+			PrivateKey: arg.PrivateKey,
+			ExpiresAt:  arg.ExpiresAt,
+			CreatedAt:  time.Now(),
+		}
+		s.keys = append(s.keys, key)
+		return key, nil
+	}).AnyTimes()
+}
+
+// list returns the stored keys
+func (s *keyStore) list(context.Context) ([]storage.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]storage.SigningKey(nil), s.keys...), nil
+}
+
+// age makes the stored keys older by the duration
+func (s *keyStore) age(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		s.keys[i].CreatedAt = s.keys[i].CreatedAt.Add(-d)
+	}
+}
+
+// newTestManager returns a key manager with a signing key, storing its keys
+// in the key store
+func newTestManager(t *testing.T, querier *storage.MockQuerier, store *keyStore) *KeyManager {
+	t.Helper()
+	return newEncryptedManager(t, querier, store, nil)
+}
+
+// newEncryptedManager returns a key manager with a signing key, storing its
+// keys in the key store encrypted with the encryption key if it isn't nil
+func newEncryptedManager(t *testing.T, querier *storage.MockQuerier, store *keyStore, encryptionKey []byte) *KeyManager {
+	t.Helper()
+	store.expect(querier)
+	m, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), querier, Config{
+		Algorithm:        AlgorithmEdDSA,
+		Issuer:           "http://localhost",
+		RotationInterval: time.Hour,
+		TokenLifetime:    time.Hour,
+		EncryptionKey:    encryptionKey,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// Rotate lists the keys again after generating one
+	querier.EXPECT().ListSigningKeys(gomock.Any()).DoAndReturn(store.list).MinTimes(1).MaxTimes(2)
+	if err := m.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	return m
+}
+
+// unknownKeyToken returns a token signed with a key the manager doesn't know
+func unknownKeyToken(t *testing.T) string {
+	t.Helper()
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	other := newTestManager(t, querier, &keyStore{})
+	token, err := other.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return token
+}
+
+func TestReloadKeepsSigningKeyDueForRotation(t *testing.T) {
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &keyStore{}
+	m := newTestManager(t, querier, store)
+	token := unknownKeyToken(t)
+
+	// A token with an unknown key ID reloads keys that are all due for
+	// rotation, which must not leave the manager without a signing key
+	store.age(2 * time.Hour)
+	querier.EXPECT().ListSigningKeys(gomock.Any()).DoAndReturn(store.list)
+	m.mu.Lock()
+	m.lastReload = time.Time{}
+	m.mu.Unlock()
+	if _, err := m.Parse(context.Background(), token, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatal("Parse() error = nil, want an error for an unknown key")
+	}
+
+	signed, err := m.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := m.Parse(context.Background(), signed, &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+}
+
+func TestRotateReplacesSigningKeyDueForRotation(t *testing.T) {
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &keyStore{}
+	m := newTestManager(t, querier, store)
+	old, err := m.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	store.age(2 * time.Hour)
+	querier.EXPECT().ListSigningKeys(gomock.Any()).DoAndReturn(store.list).Times(2)
+	if err := m.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	signed, err := m.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	oldToken, _, _ := jwt.NewParser().ParseUnverified(old, &jwt.RegisteredClaims{})
+	newToken, _, _ := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+	if oldToken.Header["kid"] == newToken.Header["kid"] {
+		t.Error("Rotate() kept signing with the key due for rotation")
+	}
+	if _, err := m.Parse(context.Background(), old, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("Parse() of a token signed with the retired key error = %v", err)
+	}
+}
+
+func TestUnknownKeysReloadOnce(t *testing.T) {
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &keyStore{}
+	m := newTestManager(t, querier, store)
+	token := unknownKeyToken(t)
+
+	querier.EXPECT().ListSigningKeys(gomock.Any()).DoAndReturn(store.list).Times(1)
+	m.mu.Lock()
+	m.lastReload = time.Time{}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Parse(context.Background(), token, &jwt.RegisteredClaims{}); err == nil {
+				t.Error("Parse() error = nil, want an error for an unknown key")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEncryptedKeys(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte{1}, EncryptionKeySize)
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &keyStore{}
+	m := newEncryptedManager(t, querier, store, encryptionKey)
+	token, err := m.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// The stored key is sealed, not a PKCS #8 key anyone could read
+	block, _ := pem.Decode([]byte(store.keys[0].PrivateKey))
+	if block == nil || block.Type != encryptedKeyBlock {
+		t.Fatalf("stored key = %q, want an encrypted PEM block", store.keys[0].PrivateKey)
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		t.Fatal("stored key parses as an unencrypted private key")
+	}
+
+	tests := []struct {
+		name          string
+		encryptionKey []byte
+		// mutate changes the stored keys before they are loaded
+		mutate    func(keys []storage.SigningKey)
+		wantValid bool
+	}{
+		{name: "same encryption key", encryptionKey: encryptionKey, wantValid: true},
+		{name: "other encryption key", encryptionKey: bytes.Repeat([]byte{2}, EncryptionKeySize)},
+		{name: "no encryption key"},
+		{
+			// A sealed key can't be stored under another key ID
+			name:          "other key ID",
+			encryptionKey: encryptionKey,
+			mutate:        func(keys []storage.SigningKey) { keys[0].Kid = "other" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, _ := store.list(context.Background())
+			if tt.mutate != nil {
+				tt.mutate(keys)
+			}
+			querier := storage.NewMockQuerier(gomock.NewController(t))
+			other := &keyStore{keys: keys}
+			loaded := newEncryptedManager(t, querier, other, tt.encryptionKey)
+
+			// Keys that can't be decrypted are skipped, and a new one generated
+			if _, err := loaded.Parse(context.Background(), token, &jwt.RegisteredClaims{}); (err == nil) != tt.wantValid {
+				t.Errorf("Parse() error = %v, want valid %v", err, tt.wantValid)
+			}
+			if wantKeys := map[bool]int{true: 1, false: 2}[tt.wantValid]; len(other.keys) != wantKeys {
+				t.Errorf("stored %d keys, want %d", len(other.keys), wantKeys)
+			}
+		})
+	}
+}
+
+func TestUnencryptedKeysStayValid(t *testing.T) {
+	// Keys stored before an encryption key was configured are still read
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &keyStore{}
+	m := newTestManager(t, querier, store)
+	token, err := m.Sign(jwt.RegisteredClaims{Issuer: "http://localhost"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	querier = storage.NewMockQuerier(gomock.NewController(t))
+	encrypted := newEncryptedManager(t, querier, store, bytes.Repeat([]byte{1}, EncryptionKeySize))
+	if _, err := encrypted.Parse(context.Background(), token, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("Parse() error = %v", err)
+	}
+	if len(store.keys) != 1 {
+		t.Errorf("stored %d keys, want the unencrypted key kept in use", len(store.keys))
+	}
+}
+
+func TestNewRejectsEncryptionKeySize(t *testing.T) {
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	_, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), querier, Config{
+		Algorithm:        AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		EncryptionKey:    make([]byte, 16),
+	})
+	if err == nil {
+		t.Error("New() with a 16 byte encryption key succeeded")
+	}
+}
@@ -0,0 +1,299 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ReplaceTypes are the types of records whose values can be replaced in bulk.
+// The value of a record is its IP address, host, target, text or CAA value.
+var ReplaceTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "SRV", "TXT", "CAA"}
+
+// BulkReplace replaces a value of the records of a type, such as the IP
+// address of A records pointing to a load balancer, across zones
+type BulkReplace struct {
+	// Zone limits the replacement to one zone. Empty replaces the value in
+	// all of the user's zones.
+	Zone       string
+	RecordType string
+	// Value selects the records whose value is equal to it
+	Value string
+	// Replacement is the value the selected records are updated to
+	Replacement string
+}
+
+// BulkReplacePlan is the set of updates of a bulk replacement, ordered by
+// zone, name and ID
+type BulkReplacePlan struct {
+	// Hash identifies the plan's changes and the state of the records the
+	// plan was made against
+	Hash    string
+	Changes []PlannedChange
+}
+
+// PlanBulkReplace previews the updates of a bulk replacement without applying
+// them
+func (m *RecordManager) PlanBulkReplace(ctx context.Context, userID uuid.UUID, replace BulkReplace) (*BulkReplacePlan, error) {
+	replace, err := checkBulkReplace(replace)
+	if err != nil {
+		return nil, err
+	}
+	if replace.Zone != "" {
+		if _, err := m.GetZone(ctx, replace.Zone, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := m.listRecordsByType(ctx, m.querier, userID, replace)
+	if err != nil {
+		return nil, err
+	}
+	return planBulkReplace(records, replace), nil
+}
+
+// ApplyBulkReplace applies a bulk replacement to all selected records in a
+// single transaction. The updates are only applied if they and the state of
+// the records are still those of the plan with the hash, otherwise
+// ErrZoneChanged is returned.
+func (m *RecordManager) ApplyBulkReplace(ctx context.Context, userID uuid.UUID, replace BulkReplace, hash string) (*BulkReplacePlan, []ChangeResult, error) {
+	replace, err := checkBulkReplace(replace)
+	if err != nil {
+		return nil, nil, err
+	}
+	zones := []string{replace.Zone}
+	if replace.Zone == "" {
+		if zones, err = m.ListZones(ctx, userID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var plan *BulkReplacePlan
+	var results []ChangeResult
+	err = m.inZonesTx(ctx, zones, userID, func(querier storage.Querier) error {
+		records, err := m.listRecordsByType(ctx, querier, userID, replace)
+		if err != nil {
+			return err
+		}
+		plan = planBulkReplace(records, replace)
+		if plan.Hash != hash {
+			return ErrZoneChanged
+		}
+
+		// The changes are ordered by zone, so apply them zone by zone
+		for start := 0; start < len(plan.Changes); {
+			zone := plan.Changes[start].Current.Zone
+			var changes []Change
+			for _, change := range plan.Changes[start:] {
+				if change.Current.Zone != zone {
+					break
+				}
+				changes = append(changes, change.Change)
+			}
+
+			before, err := m.listRecords(ctx, querier, zone, userID)
+			if err != nil {
+				return err
+			}
+			zoneResults, err := m.applyChanges(ctx, querier, zone, userID, before, changes)
+			var changeErr *ChangeError
+			if errors.As(err, &changeErr) {
+				return &ChangeError{Index: start + changeErr.Index, Err: changeErr.Err}
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, zoneResults...)
+			start += len(changes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, results, nil
+}
+
+// listRecordsByType lists the records of the replacement's type in its zone,
+// or in all of the user's zones
+func (m *RecordManager) listRecordsByType(ctx context.Context, querier storage.Querier, userID uuid.UUID, replace BulkReplace) ([]*Record, error) {
+	records, err := querier.ListUserRecordsByType(ctx, storage.ListUserRecordsByTypeParams{
+		UserID:     userID,
+		RecordType: replace.RecordType,
+		Zone:       replace.Zone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+
+	result := make([]*Record, len(records))
+	for i := range records {
+		result[i] = m.storageToRecord(&records[i])
+	}
+	return result, nil
+}
+
+// planBulkReplace plans the updates replacing the value of the records
+func planBulkReplace(records []*Record, replace BulkReplace) *BulkReplacePlan {
+	var changes []PlannedChange
+	for _, record := range records {
+		if value, ok := recordValue(record); !ok || value != replace.Value {
+			continue
+		}
+		changes = append(changes, PlannedChange{
+			Change: Change{
+				Action:  ChangeUpdate,
+				ID:      record.ID,
+				Version: record.Version,
+				Record:  withValue(record, replace.Replacement),
+			},
+			Current: record,
+		})
+	}
+	return &BulkReplacePlan{
+		Hash:    planHash(records, changes),
+		Changes: changes,
+	}
+}
+
+// checkBulkReplace validates a bulk replacement and returns it with its zone,
+// type and values canonicalized
+func checkBulkReplace(replace BulkReplace) (BulkReplace, error) {
+	var err error
+	if replace.Zone != "" {
+		if replace.Zone, err = CanonicalZone(replace.Zone); err != nil {
+			return replace, err
+		}
+	}
+
+	replace.RecordType = strings.ToUpper(strings.TrimSpace(replace.RecordType))
+	if replace.RecordType == "" {
+		return replace, &ValidationError{Field: "record_type", Message: "record type is required"}
+	}
+	if !slices.Contains(ReplaceTypes, replace.RecordType) {
+		return replace, &ValidationError{
+			Field:   "record_type",
+			Message: fmt.Sprintf("values can only be replaced in %s records", strings.Join(ReplaceTypes, ", ")),
+		}
+	}
+
+	if replace.Value, err = canonicalValue("value", replace.RecordType, replace.Value); err != nil {
+		return replace, err
+	}
+	if replace.Replacement, err = canonicalValue("replacement", replace.RecordType, replace.Replacement); err != nil {
+		return replace, err
+	}
+	if replace.Value == replace.Replacement {
+		return replace, &ValidationError{Field: "replacement", Message: "replacement is the same as the value"}
+	}
+	return replace, nil
+}
+
+// canonicalValue validates a value of records of the type and returns it in
+// the form it is stored in
+func canonicalValue(field, recordType, value string) (string, error) {
+	if recordType != "TXT" {
+		value = strings.TrimSpace(value)
+	}
+	if value == "" {
+		return "", &ValidationError{Field: field, Message: field + " is required"}
+	}
+
+	switch recordType {
+	case "A":
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() == nil {
+			return "", &ValidationError{Field: field, Message: field + " must be a valid IPv4 address"}
+		}
+		return ip.String(), nil
+	case "AAAA":
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() != nil {
+			return "", &ValidationError{Field: field, Message: field + " must be a valid IPv6 address"}
+		}
+		return ip.String(), nil
+	case "CNAME", "MX", "NS":
+		return CanonicalHostname(field, value)
+	case "SRV":
+		// A target of "." means the service is not available
+		if value == "." {
+			return value, nil
+		}
+		return CanonicalHostname(field, value)
+	default:
+		return value, nil
+	}
+}
+
+// recordValue returns the value of a record replaced in bulk, and false if
+// the record has none
+func recordValue(record *Record) (string, bool) {
+	switch {
+	case record.A != nil && record.A.Ip.IP != nil:
+		return record.A.Ip.String(), true
+	case record.AAAA != nil && record.AAAA.Ip.IP != nil:
+		return record.AAAA.Ip.String(), true
+	case record.CNAME != nil:
+		return record.CNAME.Host, true
+	case record.MX != nil:
+		return record.MX.Host, true
+	case record.NS != nil:
+		return record.NS.Host, true
+	case record.SRV != nil:
+		return record.SRV.Target, true
+	case record.TXT != nil:
+		return record.TXT.Text, true
+	case record.CAA != nil:
+		return record.CAA.Value, true
+	default:
+		return "", false
+	}
+}
+
+// withValue returns a copy of the record with its value replaced
+func withValue(record *Record, value string) *Record {
+	updated := *record
+	// The stored content is that of the record before the replacement
+	updated.Content = nil
+	switch {
+	case record.A != nil:
+		data := *record.A
+		data.Ip = IPAddr{IP: net.ParseIP(value)}
+		updated.A = &data
+	case record.AAAA != nil:
+		data := *record.AAAA
+		data.Ip = IPAddr{IP: net.ParseIP(value)}
+		updated.AAAA = &data
+	case record.CNAME != nil:
+		data := *record.CNAME
+		data.Host = value
+		updated.CNAME = &data
+	case record.MX != nil:
+		data := *record.MX
+		data.Host = value
+		updated.MX = &data
+	case record.NS != nil:
+		data := *record.NS
+		data.Host = value
+		updated.NS = &data
+	case record.SRV != nil:
+		data := *record.SRV
+		data.Target = value
+		updated.SRV = &data
+	case record.TXT != nil:
+		data := *record.TXT
+		data.Text = value
+		updated.TXT = &data
+	case record.CAA != nil:
+		data := *record.CAA
+		data.Value = value
+		updated.CAA = &data
+	}
+	return &updated
+}
@@ -0,0 +1,245 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// otherZone is a second zone of the test user
+const otherZone = "example.net."
+
+func TestCheckBulkReplace(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace BulkReplace
+		want    BulkReplace
+		field   string
+	}{
+		{
+			name:    "IPv4 addresses",
+			replace: BulkReplace{Zone: "Example.org", RecordType: "a", Value: " 192.0.2.1 ", Replacement: "192.0.2.2"},
+			want:    BulkReplace{Zone: testZone, RecordType: "A", Value: "192.0.2.1", Replacement: "192.0.2.2"},
+		},
+		{
+			name:    "IPv6 addresses",
+			replace: BulkReplace{RecordType: "AAAA", Value: "2001:DB8::0001", Replacement: "2001:db8:0:0::2"},
+			want:    BulkReplace{RecordType: "AAAA", Value: "2001:db8::1", Replacement: "2001:db8::2"},
+		},
+		{
+			name:    "hosts",
+			replace: BulkReplace{RecordType: "CNAME", Value: "LB1.example.net", Replacement: "lb2.example.net."},
+			want:    BulkReplace{RecordType: "CNAME", Value: "lb1.example.net.", Replacement: "lb2.example.net."},
+		},
+		{
+			name:    "unavailable SRV service",
+			replace: BulkReplace{RecordType: "SRV", Value: "sip.example.net", Replacement: "."},
+			want:    BulkReplace{RecordType: "SRV", Value: "sip.example.net.", Replacement: "."},
+		},
+		{
+			name:    "text kept as is",
+			replace: BulkReplace{RecordType: "TXT", Value: " v=spf1 -all", Replacement: "v=spf1 ~all "},
+			want:    BulkReplace{RecordType: "TXT", Value: " v=spf1 -all", Replacement: "v=spf1 ~all "},
+		},
+		{
+			name:    "invalid zone",
+			replace: BulkReplace{Zone: "org", RecordType: "A", Value: "192.0.2.1", Replacement: "192.0.2.2"},
+			field:   "zone",
+		},
+		{
+			name:    "missing type",
+			replace: BulkReplace{Value: "192.0.2.1", Replacement: "192.0.2.2"},
+			field:   "record_type",
+		},
+		{
+			name:    "type without a value",
+			replace: BulkReplace{RecordType: "SOA", Value: "ns1.example.net", Replacement: "ns2.example.net"},
+			field:   "record_type",
+		},
+		{
+			name:    "IPv6 address of A records",
+			replace: BulkReplace{RecordType: "A", Value: "2001:db8::1", Replacement: "192.0.2.2"},
+			field:   "value",
+		},
+		{
+			name:    "IPv4 address of AAAA records",
+			replace: BulkReplace{RecordType: "AAAA", Value: "2001:db8::1", Replacement: "192.0.2.2"},
+			field:   "replacement",
+		},
+		{
+			name:    "missing replacement",
+			replace: BulkReplace{RecordType: "A", Value: "192.0.2.1"},
+			field:   "replacement",
+		},
+		{
+			name:    "same value",
+			replace: BulkReplace{RecordType: "MX", Value: "mail.example.net", Replacement: "MAIL.example.net."},
+			field:   "replacement",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkBulkReplace(tt.replace)
+			if tt.field != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+					t.Fatalf("checkBulkReplace() error = %v, want a %s validation error", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkBulkReplace() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("checkBulkReplace() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// bulkTestRecords returns A records in two zones, three of which point to
+// 192.0.2.1
+func bulkTestRecords() []*Record {
+	records := append(apexRecords(),
+		aRecord("www", "192.0.2.1", 300),
+		aRecord("mail", "192.0.2.1", 300),
+		aRecord("ftp", "192.0.2.9", 300),
+	)
+	for _, record := range append(apexRecords(), aRecord("www", "192.0.2.1", 300)) {
+		record.Zone = otherZone
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestPlanBulkReplace(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		want []string
+	}{
+		{
+			name: "all zones",
+			want: []string{"example.net. www", "example.org. mail", "example.org. www"},
+		},
+		{
+			name: "one zone",
+			zone: "example.org",
+			want: []string{"example.org. mail", "example.org. www"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newTestManager(t, bulkTestRecords()...)
+			plan, err := m.PlanBulkReplace(context.Background(), testUserID, BulkReplace{
+				Zone:        tt.zone,
+				RecordType:  "A",
+				Value:       "192.0.2.1",
+				Replacement: "192.0.2.2",
+			})
+			if err != nil {
+				t.Fatalf("PlanBulkReplace() error = %v", err)
+			}
+
+			var got []string
+			for _, change := range plan.Changes {
+				if change.Action != ChangeUpdate || change.Version != change.Current.Version {
+					t.Errorf("change = %s of version %d, want an update of version %d", change.Action, change.Version, change.Current.Version)
+				}
+				if change.Record.A.Ip.String() != "192.0.2.2" || change.Current.A.Ip.String() != "192.0.2.1" {
+					t.Errorf("change from %s to %s, want 192.0.2.1 to 192.0.2.2", change.Current.A.Ip, change.Record.A.Ip)
+				}
+				got = append(got, change.Current.Zone+" "+change.Current.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("planned records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanBulkReplaceUnknownZone(t *testing.T) {
+	m, _ := newTestManager(t, bulkTestRecords()...)
+
+	_, err := m.PlanBulkReplace(context.Background(), testUserID, BulkReplace{
+		Zone:        "example.com",
+		RecordType:  "A",
+		Value:       "192.0.2.1",
+		Replacement: "192.0.2.2",
+	})
+	if !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("PlanBulkReplace() error = %v, want ErrZoneNotFound", err)
+	}
+}
+
+func TestApplyBulkReplace(t *testing.T) {
+	m, store := newTestManager(t, bulkTestRecords()...)
+	replace := BulkReplace{RecordType: "A", Value: "192.0.2.1", Replacement: "192.0.2.2"}
+
+	plan, err := m.PlanBulkReplace(context.Background(), testUserID, replace)
+	if err != nil {
+		t.Fatalf("PlanBulkReplace() error = %v", err)
+	}
+
+	// A record changed since the plan invalidates it, even in another zone
+	stale := slices.Clone(store.records)
+	store.records[len(store.records)-1].Version++
+	if _, _, err := m.ApplyBulkReplace(context.Background(), testUserID, replace, plan.Hash); !errors.Is(err, ErrZoneChanged) {
+		t.Fatalf("ApplyBulkReplace() error = %v, want ErrZoneChanged", err)
+	}
+	store.records = stale
+
+	applied, results, err := m.ApplyBulkReplace(context.Background(), testUserID, replace, plan.Hash)
+	if err != nil {
+		t.Fatalf("ApplyBulkReplace() error = %v", err)
+	}
+	if applied.Hash != plan.Hash || len(results) != 3 {
+		t.Fatalf("applied plan %s with %d results, want %s with 3", applied.Hash, len(results), plan.Hash)
+	}
+
+	var got []string
+	for _, record := range store.list(func(record storage.CorednsRecord) bool { return record.RecordType == "A" }) {
+		got = append(got, record.Zone+" "+record.Name+" "+string(record.Content))
+	}
+	want := []string{
+		`example.net. www {"ip":"192.0.2.2"}`,
+		`example.org. ftp {"ip":"192.0.2.9"}`,
+		`example.org. mail {"ip":"192.0.2.2"}`,
+		`example.org. www {"ip":"192.0.2.2"}`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("stored records = %q, want %q", got, want)
+	}
+
+	plan, err = m.PlanBulkReplace(context.Background(), testUserID, replace)
+	if err != nil {
+		t.Fatalf("PlanBulkReplace() error = %v", err)
+	}
+	if len(plan.Changes) > 0 {
+		t.Errorf("planned changes after the replacement = %d, want none", len(plan.Changes))
+	}
+}
+
+func TestApplyBulkReplaceFailedWrite(t *testing.T) {
+	m, store := newTestManager(t, bulkTestRecords()...)
+	store.failName = "mail"
+	replace := BulkReplace{RecordType: "A", Value: "192.0.2.1", Replacement: "192.0.2.2"}
+
+	plan, err := m.PlanBulkReplace(context.Background(), testUserID, replace)
+	if err != nil {
+		t.Fatalf("PlanBulkReplace() error = %v", err)
+	}
+	_, _, err = m.ApplyBulkReplace(context.Background(), testUserID, replace, plan.Hash)
+	var changeErr *ChangeError
+	if !errors.As(err, &changeErr) || !errors.Is(err, errWriteFailed) {
+		t.Fatalf("ApplyBulkReplace() error = %v, want a change error", err)
+	}
+	// The index is that of the change in the plan, which updates the
+	// example.net. zone first
+	if changeErr.Index != 1 {
+		t.Errorf("failed change = %d, want 1", changeErr.Index)
+	}
+}
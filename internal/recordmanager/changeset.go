@@ -0,0 +1,376 @@
+package recordmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// Change actions
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+var (
+	// ErrRecordNotFound is returned when a change refers to a record that
+	// isn't in the zone
+	ErrRecordNotFound = errors.New("record not found")
+	// ErrRecordChanged is returned when a change expects a version of a
+	// record that has since been changed
+	ErrRecordChanged = errors.New("record has been changed")
+)
+
+// Change is a single change of a change set. Creates and updates carry the
+// record, deletes only the ID of the record to delete. Updates and deletes
+// with a version only apply to that version of the record.
+type Change struct {
+	Action  string
+	ID      int64
+	Version int32
+	Record  *Record
+}
+
+// ChangeResult is the outcome of a change. Deleted records have no record.
+type ChangeResult struct {
+	Action string
+	ID     int64
+	Record *Record
+}
+
+// ChangeError is returned when a change of a change set, or a record of a
+// zone sync, fails. None of the changes are applied.
+type ChangeError struct {
+	Index int
+	Err   error
+}
+
+// Error implements the error interface
+func (e *ChangeError) Error() string {
+	return fmt.Sprintf("change %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ChangeError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyChangeSet applies the changes to the zone in a single transaction. The
+// zone's RRsets are validated as they are after all changes, so a change set
+// may pass through states that single changes couldn't. Either all changes
+// are applied and their results returned, or none are.
+func (m *RecordManager) ApplyChangeSet(ctx context.Context, zone string, userID uuid.UUID, changes []Change) ([]ChangeResult, error) {
+	var results []ChangeResult
+	err := m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, records []*Record) error {
+		var err error
+		results, err = m.applyChanges(ctx, querier, zone, userID, records, changes)
+		return err
+	})
+	return results, err
+}
+
+// inZoneTx runs fn in a transaction with the zone locked, passing it the
+// canonical zone name and the zone's current records. The transaction is
+// committed if fn succeeds.
+func (m *RecordManager) inZoneTx(ctx context.Context, zone string, userID uuid.UUID, fn func(querier storage.Querier, zone string, records []*Record) error) error {
+	zone, err := CanonicalZone(zone)
+	if err != nil {
+		return err
+	}
+
+	return m.inZonesTx(ctx, []string{zone}, userID, func(querier storage.Querier) error {
+		records, err := m.listRecords(ctx, querier, zone, userID)
+		if err != nil {
+			return err
+		}
+		return fn(querier, zone, records)
+	})
+}
+
+// inZonesTx runs fn in a transaction with the zones, given by their canonical
+// names, locked. The transaction is committed if fn succeeds.
+func (m *RecordManager) inZonesTx(ctx context.Context, zones []string, userID uuid.UUID, fn func(querier storage.Querier) error) error {
+	return m.inTx(ctx, func(querier storage.Querier) error {
+		// Locking the zones serializes changes, so they are validated against
+		// the zones as they are when they are written. Zones are locked in
+		// order, so transactions locking several zones can't deadlock.
+		zones = slices.Clone(zones)
+		slices.Sort(zones)
+		for _, zone := range zones {
+			_, err := querier.LockZone(ctx, storage.LockZoneParams{
+				Zone:   zone,
+				UserID: userID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrZoneNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to lock zone: %w", err)
+			}
+		}
+		return fn(querier)
+	})
+}
+
+// inTx runs fn in a transaction, which is committed if fn succeeds
+func (m *RecordManager) inTx(ctx context.Context, fn func(querier storage.Querier) error) error {
+	if m.db == nil {
+		return fn(m.querier)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(m.queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// applyChanges validates the changes against the zone's records and writes
+// them using the querier
+func (m *RecordManager) applyChanges(ctx context.Context, querier storage.Querier, zone string, userID uuid.UUID, before []*Record, changes []Change) ([]ChangeResult, error) {
+	var err error
+	after := before
+	// previous holds the records as they are before each change, for the
+	// zone's history
+	previous := make([]*Record, len(changes))
+	for i, change := range changes {
+		if change.Action != ChangeCreate {
+			previous[i] = findRecord(after, change.ID)
+		}
+		after, err = applyChange(after, zone, userID, change)
+		if err != nil {
+			return nil, &ChangeError{Index: i, Err: err}
+		}
+	}
+	if err := checkZoneChange(before, after); err != nil {
+		return nil, err
+	}
+
+	results := make([]ChangeResult, len(changes))
+	for i, change := range changes {
+		results[i], err = m.writeChange(ctx, querier, zone, userID, change, previous[i])
+		if err != nil {
+			return nil, &ChangeError{Index: i, Err: err}
+		}
+	}
+
+	// Later changes may update the TTL and version of records written by
+	// earlier ones, so the results are read back as they are after all
+	// changes
+	for i := range results {
+		if results[i].Record == nil {
+			continue
+		}
+		dbRecord, err := querier.GetRecordByID(ctx, storage.GetRecordByIDParams{
+			ID:     results[i].ID,
+			Zone:   zone,
+			UserID: userID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted by a later change
+			results[i].Record = nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read back record %d: %w", results[i].ID, err)
+		}
+		results[i].Record = m.storageToRecord(&dbRecord)
+	}
+	return results, nil
+}
+
+// applyChange canonicalizes the change's record and returns the zone's
+// records with the change applied
+func applyChange(records []*Record, zone string, userID uuid.UUID, change Change) ([]*Record, error) {
+	if change.Action != ChangeCreate && change.Action != ChangeUpdate && change.Action != ChangeDelete {
+		return nil, &ValidationError{Field: "action", Message: fmt.Sprintf("unknown action %q", change.Action)}
+	}
+	if change.Action != ChangeCreate {
+		existing := findRecord(records, change.ID)
+		if existing == nil {
+			return nil, ErrRecordNotFound
+		}
+		if change.Version != 0 && change.Version != existing.Version {
+			return nil, ErrRecordChanged
+		}
+	}
+	if change.Action == ChangeDelete {
+		return withoutRecord(records, change.ID), nil
+	}
+
+	if change.Record == nil {
+		return nil, &ValidationError{Field: "record", Message: "record is required"}
+	}
+	record := change.Record
+	record.Zone = zone
+	record.UserID = userID
+	if change.Action == ChangeCreate {
+		record.ID = 0
+	} else {
+		record.ID = change.ID
+	}
+	if err := canonicalizeRecord(record); err != nil {
+		return nil, err
+	}
+
+	if change.Action == ChangeCreate {
+		return withRecord(records, record), nil
+	}
+	return withRRsetTTL(withRecord(records, record), record), nil
+}
+
+// writeChange writes a validated change, and its entry in the zone's history,
+// to the database. previous is the record updated or deleted by the change.
+func (m *RecordManager) writeChange(ctx context.Context, querier storage.Querier, zone string, userID uuid.UUID, change Change, previous *Record) (ChangeResult, error) {
+	result := ChangeResult{Action: change.Action, ID: change.ID}
+
+	switch change.Action {
+	case ChangeCreate:
+		record := change.Record
+		contentJSON, err := ContentJSON(record)
+		if err != nil {
+			return result, err
+		}
+
+		dbRecord, err := querier.CreateRecord(ctx, storage.CreateRecordParams{
+			UserID:     record.UserID,
+			Zone:       record.Zone,
+			Name:       record.Name,
+			Ttl:        record.Ttl,
+			Content:    json.RawMessage(contentJSON),
+			RecordType: record.RecordType,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to create record: %w", err)
+		}
+
+		if err := writeHistory(ctx, querier, change.Action, nil, &dbRecord); err != nil {
+			return result, err
+		}
+
+		result.ID = dbRecord.ID
+		result.Record = m.storageToRecord(&dbRecord)
+		return result, nil
+
+	case ChangeUpdate:
+		record := change.Record
+		contentJSON, err := ContentJSON(record)
+		if err != nil {
+			return result, err
+		}
+
+		dbRecord, err := querier.UpdateRecord(ctx, storage.UpdateRecordParams{
+			ID:         record.ID,
+			Zone:       record.Zone,
+			Name:       record.Name,
+			Ttl:        record.Ttl,
+			Content:    json.RawMessage(contentJSON),
+			RecordType: record.RecordType,
+			UserID:     record.UserID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to update record: %w", err)
+		}
+
+		// Changing the TTL of a record changes it for its whole RRset
+		err = querier.UpdateRRsetTTL(ctx, storage.UpdateRRsetTTLParams{
+			Zone:       record.Zone,
+			UserID:     record.UserID,
+			Name:       record.Name,
+			RecordType: record.RecordType,
+			Ttl:        record.Ttl,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to update RRset TTL: %w", err)
+		}
+
+		if err := writeHistory(ctx, querier, change.Action, previous, &dbRecord); err != nil {
+			return result, err
+		}
+
+		result.Record = m.storageToRecord(&dbRecord)
+		return result, nil
+
+	default:
+		err := querier.DeleteRecord(ctx, storage.DeleteRecordParams{
+			ID:     change.ID,
+			Zone:   zone,
+			UserID: userID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to delete record: %w", err)
+		}
+
+		if err := writeHistory(ctx, querier, change.Action, previous, nil); err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+}
+
+// writeHistory writes a change of a record to the zone's history: the record
+// as it was before the change, unless it is created, and as it is written,
+// unless it is deleted
+func writeHistory(ctx context.Context, querier storage.Querier, action string, previous *Record, written *storage.CorednsRecord) error {
+	params := storage.CreateRecordHistoryParams{Action: action}
+	if previous != nil {
+		params.Zone = previous.Zone
+		params.UserID = previous.UserID
+		params.RecordID = previous.ID
+		params.Name = previous.Name
+		params.RecordType = previous.RecordType
+		params.PreviousTtl = previous.Ttl
+		params.PreviousContent = pqtype.NullRawMessage{RawMessage: historyContent(previous), Valid: true}
+	}
+	if written != nil {
+		params.Zone = written.Zone
+		params.UserID = written.UserID
+		params.RecordID = written.ID
+		params.Name = written.Name
+		params.RecordType = written.RecordType
+		params.Ttl = written.Ttl
+		params.Content = pqtype.NullRawMessage{RawMessage: written.Content, Valid: true}
+	}
+
+	if err := querier.CreateRecordHistory(ctx, params); err != nil {
+		return fmt.Errorf("failed to write record history: %w", err)
+	}
+	return nil
+}
+
+// historyContent returns the content of a record as written to the history.
+// The stored content is kept for records whose content can't be decoded.
+func historyContent(record *Record) json.RawMessage {
+	content, err := ContentJSON(record)
+	if err != nil {
+		return record.Content
+	}
+	return json.RawMessage(content)
+}
+
+// findRecord returns the record with the ID, or nil if there is none
+func findRecord(records []*Record, id int64) *Record {
+	for _, record := range records {
+		if record.ID == id {
+			return record
+		}
+	}
+	return nil
+}
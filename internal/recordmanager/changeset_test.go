@@ -0,0 +1,191 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestApplyChangeSet(t *testing.T) {
+	records := append(apexRecords(), aRecord("www", "192.0.2.1", 300), txtRecord("www", "hello", 300))
+	m, store := newTestManager(t, records...)
+	www, txt := records[3], records[4]
+
+	// The A record is swapped for a CNAME in one change set, which no single
+	// change could do without breaking CNAME exclusivity
+	cname := cnameRecord("www", "www.example.net")
+	results, err := m.ApplyChangeSet(context.Background(), testZone, testUserID, []Change{
+		{Action: ChangeDelete, ID: www.ID, Version: www.Version},
+		{Action: ChangeDelete, ID: txt.ID, Version: txt.Version},
+		{Action: ChangeCreate, Record: cname},
+		{Action: ChangeCreate, Record: aRecord("mail", "192.0.2.2", 300)},
+		{Action: ChangeUpdate, ID: records[1].ID, Version: records[1].Version, Record: nsRecord("@", "ns3.example.net")},
+	})
+	if err != nil {
+		t.Fatalf("ApplyChangeSet() error = %v", err)
+	}
+
+	var actions []string
+	for _, result := range results {
+		actions = append(actions, result.Action)
+		if (result.Action == ChangeDelete) != (result.Record == nil) {
+			t.Errorf("%s result record = %v", result.Action, result.Record)
+		}
+	}
+	if want := []string{ChangeDelete, ChangeDelete, ChangeCreate, ChangeCreate, ChangeUpdate}; !slices.Equal(actions, want) {
+		t.Errorf("result actions = %q, want %q", actions, want)
+	}
+	if results[2].Record.CNAME.Host != "www.example.net." || results[4].Record.NS.Host != "ns3.example.net." {
+		t.Errorf("results = %+v %+v, want the written records", results[2].Record, results[4].Record)
+	}
+	if results[4].Record.Version != 2 {
+		t.Errorf("updated record version = %d, want 2", results[4].Record.Version)
+	}
+
+	var stored []string
+	for _, record := range store.storedRecords() {
+		stored = append(stored, displayName(record.Name)+" "+record.RecordType+" "+string(record.Content))
+	}
+	want := []string{
+		`@ NS {"host":"ns3.example.net."}`,
+		`@ NS {"host":"ns2.example.net."}`,
+		`@ SOA {"ns":"ns1.example.net.","mbox":"hostmaster.example.net.","refresh":86400,"retry":7200,"expire":604800,"minttl":300}`,
+		`mail A {"ip":"192.0.2.2"}`,
+		`www CNAME {"host":"www.example.net."}`,
+	}
+	if !slices.Equal(stored, want) {
+		t.Errorf("stored records = %q, want %q", stored, want)
+	}
+	if len(store.history) != len(results) {
+		t.Errorf("history entries = %d, want %d", len(store.history), len(results))
+	}
+}
+
+func TestApplyChangeSetRollsBack(t *testing.T) {
+	tests := []struct {
+		name string
+		// changes returns the change set, of which the change with the index
+		// fails, given the zone's records
+		changes  func(records []*Record) []Change
+		failName string
+		index    int
+		wantErr  error
+	}{
+		{
+			name: "changed record",
+			changes: func(records []*Record) []Change {
+				return []Change{
+					{Action: ChangeCreate, Record: aRecord("mail", "192.0.2.2", 300)},
+					{Action: ChangeDelete, ID: records[3].ID, Version: records[3].Version + 1},
+				}
+			},
+			index:   1,
+			wantErr: ErrRecordChanged,
+		},
+		{
+			name: "unknown record",
+			changes: func(records []*Record) []Change {
+				return []Change{
+					{Action: ChangeDelete, ID: records[3].ID, Version: records[3].Version},
+					{Action: ChangeUpdate, ID: 100, Record: aRecord("www", "192.0.2.3", 300)},
+				}
+			},
+			index:   1,
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "record deleted twice",
+			changes: func(records []*Record) []Change {
+				return []Change{
+					{Action: ChangeDelete, ID: records[3].ID},
+					{Action: ChangeDelete, ID: records[3].ID},
+				}
+			},
+			index:   1,
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "invalid record",
+			changes: func([]*Record) []Change {
+				return []Change{
+					{Action: ChangeCreate, Record: aRecord("mail", "192.0.2.2", 300)},
+					{Action: ChangeCreate, Record: aRecord("bad name", "192.0.2.3", 300)},
+				}
+			},
+			index: 1,
+		},
+		{
+			name: "unknown action",
+			changes: func([]*Record) []Change {
+				return []Change{{Action: "upsert", Record: aRecord("mail", "192.0.2.2", 300)}}
+			},
+			index: 0,
+		},
+		{
+			name: "failed write",
+			changes: func([]*Record) []Change {
+				return []Change{
+					{Action: ChangeCreate, Record: aRecord("mail", "192.0.2.2", 300)},
+					{Action: ChangeCreate, Record: aRecord("ftp", "192.0.2.3", 300)},
+					{Action: ChangeCreate, Record: aRecord("smtp", "192.0.2.4", 300)},
+				}
+			},
+			failName: "ftp",
+			index:    1,
+			wantErr:  errWriteFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := append(apexRecords(), aRecord("www", "192.0.2.1", 300))
+			m, store := newTestManager(t, records...)
+			store.failName = tt.failName
+			before := slices.Clone(store.storedRecords())
+
+			results, err := m.ApplyChangeSet(context.Background(), testZone, testUserID, tt.changes(records))
+			var changeErr *ChangeError
+			if !errors.As(err, &changeErr) {
+				t.Fatalf("ApplyChangeSet() error = %v, want a change error", err)
+			}
+			if changeErr.Index != tt.index {
+				t.Errorf("failed change = %d, want %d", changeErr.Index, tt.index)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ApplyChangeSet() error = %v, want %v", err, tt.wantErr)
+			}
+			if results != nil {
+				t.Errorf("ApplyChangeSet() results = %v, want none", results)
+			}
+
+			if tt.failName != "" {
+				// Writes stop at the failing change, and the transaction
+				// rolls back the ones before it
+				for _, record := range store.storedRecords() {
+					if record.Name == "smtp" {
+						t.Errorf("change after the failed write was written")
+					}
+				}
+				return
+			}
+			// Changes are validated before any is written
+			if !slices.EqualFunc(store.storedRecords(), before, recordsEqual) {
+				t.Errorf("records changed by a failed change set")
+			}
+			if len(store.history) > 0 {
+				t.Errorf("history written by a failed change set")
+			}
+		})
+	}
+}
+
+func TestApplyChangeSetUnknownZone(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	_, err := m.ApplyChangeSet(context.Background(), "example.net", testUserID, []Change{
+		{Action: ChangeCreate, Record: aRecord("www", "192.0.2.1", 300)},
+	})
+	if !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("ApplyChangeSet() error = %v, want ErrZoneNotFound", err)
+	}
+}
@@ -0,0 +1,65 @@
+package recordmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ListInvalidRecords lists the records of a zone that were set aside when
+// record content became JSON, as their content wasn't a valid object of
+// their type. They aren't served, and are listed with their content as it
+// was stored so they can be added again and deleted.
+func (m *RecordManager) ListInvalidRecords(ctx context.Context, zone string, userID uuid.UUID) ([]*Record, error) {
+	records, err := m.querier.ListInvalidRecordsByZone(ctx, storage.ListInvalidRecordsByZoneParams{
+		Zone:   lookupZone(zone),
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invalid records: %w", err)
+	}
+
+	result := make([]*Record, len(records))
+	for i := range records {
+		result[i] = invalidToRecord(&records[i])
+	}
+	return result, nil
+}
+
+// deleteInvalidRecord deletes a record set aside as invalid, returning
+// ErrRecordNotFound if there is none with the ID
+func (m *RecordManager) deleteInvalidRecord(ctx context.Context, id int64, zone string, userID uuid.UUID) error {
+	deleted, err := m.querier.DeleteInvalidRecord(ctx, storage.DeleteInvalidRecordParams{
+		ID:     id,
+		Zone:   lookupZone(zone),
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete invalid record: %w", err)
+	}
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// invalidToRecord converts a record set aside as invalid to a Record. Its
+// content, which needn't be JSON, is given as a JSON string.
+func invalidToRecord(dbRecord *storage.CorednsRecordsInvalid) *Record {
+	content, _ := json.Marshal(dbRecord.Content.String)
+	return &Record{
+		ID:         dbRecord.ID,
+		UserID:     dbRecord.UserID,
+		Zone:       dbRecord.Zone,
+		Name:       dbRecord.Name,
+		RecordType: dbRecord.RecordType,
+		Ttl:        dbRecord.Ttl,
+		Content:    content,
+		Version:    dbRecord.Version,
+		ContentErr: fmt.Errorf("invalid %s record content, not served since %s",
+			dbRecord.RecordType, dbRecord.MovedAt.Format("2006-01-02")),
+	}
+}
@@ -0,0 +1,110 @@
+package recordmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// corruptRecords are stored records whose content can't be decoded
+var corruptRecords = []storage.CorednsRecord{
+	{Name: "bad-json", RecordType: "A", Content: json.RawMessage(`{"ip":`)},
+	{Name: "bad-ip", RecordType: "A", Content: json.RawMessage(`{"ip":"not an address"}`)},
+	{Name: "bad-shape", RecordType: "MX", Content: json.RawMessage(`["mail.example.net."]`)},
+	{Name: "unknown", RecordType: "HINFO", Content: json.RawMessage(`{"cpu":"x86"}`)},
+}
+
+func TestListRecordsWithCorruptContent(t *testing.T) {
+	m, store := newTestManager(t, append(apexRecords(), aRecord("www", "192.0.2.1", 300))...)
+	for _, corrupt := range corruptRecords {
+		corrupt.ID = store.nextID
+		corrupt.UserID = testUserID
+		corrupt.Zone = testZone
+		corrupt.Ttl = ttl(300)
+		corrupt.Version = 1
+		store.nextID++
+		store.records = append(store.records, corrupt)
+	}
+
+	// Corrupt records are listed on their own rather than failing the zone
+	records := listTestZone(t, m)
+	if len(records) != len(store.records) {
+		t.Fatalf("ListRecordsByZone() = %d records, want %d", len(records), len(store.records))
+	}
+	contentErrs := make(map[string]bool)
+	for _, record := range records {
+		stored := store.records[store.find(record.ID, testZone)]
+		if string(record.Content) != string(stored.Content) {
+			t.Errorf("%s content = %s, want %s as stored", record.Name, record.Content, stored.Content)
+		}
+		if record.ContentErr == nil {
+			if _, err := ContentJSON(record); err != nil {
+				t.Errorf("%s content error = %v", record.Name, err)
+			}
+			continue
+		}
+		contentErrs[record.Name] = true
+		if record.A != nil || record.MX != nil {
+			t.Errorf("%s has partially decoded content", record.Name)
+		}
+		if _, err := ContentJSON(record); err == nil {
+			t.Errorf("ContentJSON(%s) succeeded, want the content error", record.Name)
+		}
+	}
+	for _, corrupt := range corruptRecords {
+		if !contentErrs[corrupt.Name] {
+			t.Errorf("%s has no content error", corrupt.Name)
+		}
+	}
+	if len(contentErrs) != len(corruptRecords) {
+		t.Errorf("records with content errors = %v, want those of %d corrupt records", contentErrs, len(corruptRecords))
+	}
+
+	// The zone's other records can still be changed
+	_, err := m.ApplyChangeSet(context.Background(), testZone, testUserID, []Change{
+		{Action: ChangeCreate, Record: aRecord("mail", "192.0.2.2", 300)},
+	})
+	if err != nil {
+		t.Errorf("ApplyChangeSet() error = %v", err)
+	}
+}
+
+func TestListInvalidRecords(t *testing.T) {
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	m := NewWithQuerier(querier)
+	movedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	querier.EXPECT().ListInvalidRecordsByZone(gomock.Any(), storage.ListInvalidRecordsByZoneParams{
+		Zone:   testZone,
+		UserID: testUserID,
+	}).Return([]storage.CorednsRecordsInvalid{{
+		ID:         7,
+		UserID:     testUserID,
+		Zone:       testZone,
+		Name:       "www",
+		Ttl:        ttl(300),
+		Content:    sql.NullString{String: "192.0.2.1", Valid: true},
+		RecordType: "A",
+		Version:    1,
+		MovedAt:    movedAt,
+	}}, nil)
+
+	records, err := m.ListInvalidRecords(context.Background(), "example.org", testUserID)
+	if err != nil {
+		t.Fatalf("ListInvalidRecords() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("ListInvalidRecords() = %d records, want 1", len(records))
+	}
+	record := records[0]
+	if string(record.Content) != `"192.0.2.1"` {
+		t.Errorf("content = %s, want the stored content as a JSON string", record.Content)
+	}
+	if record.ContentErr == nil || record.ContentErr.Error() != "invalid A record content, not served since 2026-03-01" {
+		t.Errorf("content error = %v", record.ContentErr)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -12,144 +13,99 @@ import (
 
 // RecordManager handles CRUD operations for DNS records
 type RecordManager struct {
+	db      *sql.DB
+	queries *storage.Queries
 	querier storage.Querier
 }
 
 // New creates a new RecordManager instance
-func New(querier storage.Querier) *RecordManager {
+func New(db *sql.DB, queries *storage.Queries) *RecordManager {
 	return &RecordManager{
-		querier: querier,
+		db:      db,
+		queries: queries,
+		querier: queries,
 	}
 }
 
+// NewWithQuerier creates a RecordManager making all its queries with the
+// querier, such as a mock in tests. Without a database, changes aren't made
+// in transactions.
+func NewWithQuerier(querier storage.Querier) *RecordManager {
+	return &RecordManager{querier: querier}
+}
+
 // CreateRecord creates a new DNS record
 func (m *RecordManager) CreateRecord(ctx context.Context, record *Record) (*Record, error) {
-	// Marshal the content based on record type
-	var content interface{}
-	switch record.RecordType {
-	case "A":
-		content = record.A
-	case "AAAA":
-		content = record.AAAA
-	case "TXT":
-		content = record.TXT
-	case "CNAME":
-		content = record.CNAME
-	case "NS":
-		content = record.NS
-	case "MX":
-		content = record.MX
-	case "SRV":
-		content = record.SRV
-	case "SOA":
-		content = record.SOA
-	case "CAA":
-		content = record.CAA
-	default:
-		return nil, fmt.Errorf("unknown record type: %s", record.RecordType)
-	}
-
-	contentJSON, err := json.Marshal(content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal content: %w", err)
-	}
-
-	// Create the record
-	dbRecord, err := m.querier.CreateRecord(ctx, storage.CreateRecordParams{
-		UserID:     record.UserID,
-		Zone:       record.Zone,
-		Name:       record.Name,
-		Ttl:        record.Ttl,
-		Content:    sql.NullString{String: string(contentJSON), Valid: true},
-		RecordType: record.RecordType,
+	return m.applyOne(ctx, record.Zone, record.UserID, Change{
+		Action: ChangeCreate,
+		Record: record,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create record: %w", err)
-	}
-
-	return m.storageToRecord(&dbRecord)
 }
 
 // GetRecord retrieves a DNS record by ID and zone
 func (m *RecordManager) GetRecord(ctx context.Context, id int64, zone string, userID uuid.UUID) (*Record, error) {
 	record, err := m.querier.GetRecordByID(ctx, storage.GetRecordByIDParams{
 		ID:     id,
-		Zone:   zone,
+		Zone:   lookupZone(zone),
 		UserID: userID,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRecordNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get record: %w", err)
 	}
 
-	return m.storageToRecord(&record)
+	return m.storageToRecord(&record), nil
 }
 
-// UpdateRecord updates a DNS record
+// UpdateRecord updates a DNS record. If the record has a version, it is only
+// updated if it hasn't been changed since that version.
 func (m *RecordManager) UpdateRecord(ctx context.Context, record *Record) (*Record, error) {
-	// Marshal the content based on record type
-	var content interface{}
-	switch record.RecordType {
-	case "A":
-		content = record.A
-	case "AAAA":
-		content = record.AAAA
-	case "TXT":
-		content = record.TXT
-	case "CNAME":
-		content = record.CNAME
-	case "NS":
-		content = record.NS
-	case "MX":
-		content = record.MX
-	case "SRV":
-		content = record.SRV
-	case "SOA":
-		content = record.SOA
-	case "CAA":
-		content = record.CAA
-	default:
-		return nil, fmt.Errorf("unknown record type: %s", record.RecordType)
-	}
-
-	contentJSON, err := json.Marshal(content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal content: %w", err)
-	}
+	return m.applyOne(ctx, record.Zone, record.UserID, Change{
+		Action:  ChangeUpdate,
+		ID:      record.ID,
+		Version: record.Version,
+		Record:  record,
+	})
+}
 
-	// Update the record
-	dbRecord, err := m.querier.UpdateRecord(ctx, storage.UpdateRecordParams{
-		ID:         record.ID,
-		Zone:       record.Zone,
-		Name:       record.Name,
-		Ttl:        record.Ttl,
-		Content:    sql.NullString{String: string(contentJSON), Valid: true},
-		RecordType: record.RecordType,
-		UserID:     record.UserID,
+// DeleteRecord deletes a DNS record. If version isn't zero, the record is
+// only deleted if it hasn't been changed since that version. Records set
+// aside as invalid are deleted by their ID too.
+func (m *RecordManager) DeleteRecord(ctx context.Context, id int64, zone string, userID uuid.UUID, version int32) error {
+	_, err := m.applyOne(ctx, zone, userID, Change{
+		Action:  ChangeDelete,
+		ID:      id,
+		Version: version,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to update record: %w", err)
+	if errors.Is(err, ErrRecordNotFound) {
+		return m.deleteInvalidRecord(ctx, id, zone, userID)
 	}
-
-	return m.storageToRecord(&dbRecord)
+	return err
 }
 
-// DeleteRecord deletes a DNS record
-func (m *RecordManager) DeleteRecord(ctx context.Context, id int64, zone string, userID uuid.UUID) error {
-	err := m.querier.DeleteRecord(ctx, storage.DeleteRecordParams{
-		ID:     id,
-		Zone:   zone,
-		UserID: userID,
-	})
+// applyOne applies a single change as a change set
+func (m *RecordManager) applyOne(ctx context.Context, zone string, userID uuid.UUID, change Change) (*Record, error) {
+	results, err := m.ApplyChangeSet(ctx, zone, userID, []Change{change})
+	var changeErr *ChangeError
+	if errors.As(err, &changeErr) {
+		return nil, changeErr.Err
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete record: %w", err)
+		return nil, err
 	}
-
-	return nil
+	return results[0].Record, nil
 }
 
 // ListRecordsByZone lists all records in a zone
 func (m *RecordManager) ListRecordsByZone(ctx context.Context, zone string, userID uuid.UUID) ([]*Record, error) {
-	records, err := m.querier.ListRecordsByZone(ctx, storage.ListRecordsByZoneParams{
+	return m.listRecords(ctx, m.querier, lookupZone(zone), userID)
+}
+
+// listRecords lists all records in a zone using the querier
+func (m *RecordManager) listRecords(ctx context.Context, querier storage.Querier, zone string, userID uuid.UUID) ([]*Record, error) {
+	records, err := querier.ListRecordsByZone(ctx, storage.ListRecordsByZoneParams{
 		Zone:   zone,
 		UserID: userID,
 	})
@@ -158,11 +114,8 @@ func (m *RecordManager) ListRecordsByZone(ctx context.Context, zone string, user
 	}
 
 	result := make([]*Record, len(records))
-	for i, record := range records {
-		result[i], err = m.storageToRecord(&record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert record %d: %w", record.ID, err)
-		}
+	for i := range records {
+		result[i] = m.storageToRecord(&records[i])
 	}
 
 	return result, nil
@@ -178,8 +131,38 @@ func (m *RecordManager) ListZones(ctx context.Context, userID uuid.UUID) ([]stri
 	return zones, nil
 }
 
-// storageToRecord converts a storage.CorednsRecord to a Record
-func (m *RecordManager) storageToRecord(dbRecord *storage.CorednsRecord) (*Record, error) {
+// recordContent returns the type specific data of the record
+func recordContent(record *Record) (interface{}, error) {
+	if record.ContentErr != nil {
+		return nil, record.ContentErr
+	}
+	switch record.RecordType {
+	case "A":
+		return record.A, nil
+	case "AAAA":
+		return record.AAAA, nil
+	case "TXT":
+		return record.TXT, nil
+	case "CNAME":
+		return record.CNAME, nil
+	case "NS":
+		return record.NS, nil
+	case "MX":
+		return record.MX, nil
+	case "SRV":
+		return record.SRV, nil
+	case "SOA":
+		return record.SOA, nil
+	case "CAA":
+		return record.CAA, nil
+	default:
+		return nil, fmt.Errorf("unknown record type: %s", record.RecordType)
+	}
+}
+
+// storageToRecord converts a storage.CorednsRecord to a Record. Content
+// that can't be decoded is reported in the record's ContentErr.
+func (m *RecordManager) storageToRecord(dbRecord *storage.CorednsRecord) *Record {
 	record := &Record{
 		ID:         dbRecord.ID,
 		UserID:     dbRecord.UserID,
@@ -188,71 +171,48 @@ func (m *RecordManager) storageToRecord(dbRecord *storage.CorednsRecord) (*Recor
 		RecordType: dbRecord.RecordType,
 		Ttl:        dbRecord.Ttl,
 		Content:    dbRecord.Content,
-	}
-
-	if !dbRecord.Content.Valid {
-		return record, nil
+		Version:    dbRecord.Version,
 	}
 
 	// Unmarshal the content based on record type
+	var content interface{}
 	switch dbRecord.RecordType {
 	case "A":
 		record.A = &AData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.A)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal A record: %w", err)
-		}
+		content = record.A
 	case "AAAA":
 		record.AAAA = &AAAAData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.AAAA)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal AAAA record: %w", err)
-		}
+		content = record.AAAA
 	case "TXT":
 		record.TXT = &TXTData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.TXT)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal TXT record: %w", err)
-		}
+		content = record.TXT
 	case "CNAME":
 		record.CNAME = &CNAMEData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.CNAME)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal CNAME record: %w", err)
-		}
+		content = record.CNAME
 	case "NS":
 		record.NS = &NSData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.NS)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal NS record: %w", err)
-		}
+		content = record.NS
 	case "MX":
 		record.MX = &MXData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.MX)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal MX record: %w", err)
-		}
+		content = record.MX
 	case "SRV":
 		record.SRV = &SRVData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.SRV)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal SRV record: %w", err)
-		}
+		content = record.SRV
 	case "SOA":
 		record.SOA = &SOAData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.SOA)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal SOA record: %w", err)
-		}
+		content = record.SOA
 	case "CAA":
 		record.CAA = &CAAData{}
-		err := json.Unmarshal([]byte(dbRecord.Content.String), record.CAA)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal CAA record: %w", err)
-		}
+		content = record.CAA
 	default:
-		return nil, fmt.Errorf("unknown record type: %s", dbRecord.RecordType)
+		record.ContentErr = fmt.Errorf("unknown record type: %s", dbRecord.RecordType)
+		return record
 	}
 
-	return record, nil
+	if err := json.Unmarshal(dbRecord.Content, content); err != nil {
+		record.A, record.AAAA, record.TXT, record.CNAME, record.NS = nil, nil, nil, nil, nil
+		record.MX, record.SRV, record.SOA, record.CAA = nil, nil, nil, nil
+		record.ContentErr = fmt.Errorf("invalid %s record content: %w", dbRecord.RecordType, err)
+	}
+	return record
 }
@@ -0,0 +1,232 @@
+package recordmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	// ApexName is the owner name users give for the zone apex. It is stored
+	// as the empty name.
+	ApexName = "@"
+
+	// DNS name limits per RFC 1035
+	maxLabelLength = 63
+	maxNameLength  = 253
+)
+
+// ValidationError describes an invalid field of a zone or record
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// CanonicalZone returns the canonical form of a zone name: lowercase,
+// IDNA-encoded and fully qualified with a trailing dot
+func CanonicalZone(zone string) (string, error) {
+	labels, err := canonicalLabels(zone, false)
+	if err != nil {
+		return "", &ValidationError{Field: "zone", Message: err.Error()}
+	}
+	if len(labels) < 2 {
+		return "", &ValidationError{Field: "zone", Message: "zone must have at least two labels"}
+	}
+	return strings.Join(labels, ".") + ".", nil
+}
+
+// CanonicalName returns the canonical owner name of a record in the zone,
+// relative to the zone and empty for the apex. The name is given as in a zone
+// file: "@" is the apex, names with a trailing dot are absolute and must be
+// inside the zone, and other names are relative to the zone.
+func CanonicalName(name, zone string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == ApexName || name == "" {
+		return "", nil
+	}
+
+	labels, err := canonicalLabels(name, true)
+	if err != nil {
+		return "", &ValidationError{Field: "name", Message: err.Error()}
+	}
+	relative := strings.Join(labels, ".")
+
+	if strings.HasSuffix(name, ".") {
+		// Absolute names must be the apex or below it
+		apex := strings.TrimSuffix(zone, ".")
+		switch {
+		case relative == apex:
+			return "", nil
+		case strings.HasSuffix(relative, "."+apex):
+			relative = strings.TrimSuffix(relative, "."+apex)
+		default:
+			return "", &ValidationError{Field: "name", Message: fmt.Sprintf("name %s is outside the zone %s", name, zone)}
+		}
+	}
+
+	if len(relative)+1+len(zone) > maxNameLength+1 {
+		return "", &ValidationError{Field: "name", Message: "name is too long"}
+	}
+	return relative, nil
+}
+
+// CanonicalHostname returns the canonical form of a hostname used as record
+// content, such as a CNAME or MX target. Targets are always absolute, so a
+// missing trailing dot is added.
+func CanonicalHostname(field, host string) (string, error) {
+	labels, err := canonicalLabels(host, false)
+	if err != nil {
+		return "", &ValidationError{Field: field, Message: err.Error()}
+	}
+	return strings.Join(labels, ".") + ".", nil
+}
+
+// UnicodeName returns the name with IDNA A-labels (xn--) converted to their
+// Unicode U-label form for display. Labels that can't be decoded are left as
+// they are.
+func UnicodeName(name string) string {
+	if !strings.Contains(name, "xn--") {
+		return name
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(label, "xn--") {
+			continue
+		}
+		if decoded, err := idna.Display.ToUnicode(label); err == nil {
+			labels[i] = decoded
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// canonicalLabels lowercases and IDNA-encodes the labels of a name and
+// validates them. Underscores are permitted for service labels such as
+// _dmarc, and a wildcard is permitted as the first owner name label.
+func canonicalLabels(name string, owner bool) ([]string, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("name %q contains an empty label", name)
+		}
+
+		if !isASCII(label) {
+			encoded, err := idna.Lookup.ToASCII(label)
+			if err != nil {
+				return nil, fmt.Errorf("label %q is not a valid internationalized name: %w", label, err)
+			}
+			label = encoded
+		}
+		label = strings.ToLower(label)
+
+		if label == "*" && owner && i == 0 {
+			labels[i] = label
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return nil, err
+		}
+		labels[i] = label
+	}
+
+	if len(strings.Join(labels, ".")) > maxNameLength {
+		return nil, fmt.Errorf("name is longer than %d characters", maxNameLength)
+	}
+	return labels, nil
+}
+
+// validateLabel checks a label's length and characters per RFC 1035 and
+// RFC 1123, additionally permitting underscores
+func validateLabel(label string) error {
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", label, maxLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q must not start or end with a hyphen", label)
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return fmt.Errorf("label %q contains invalid character %q", label, c)
+		}
+	}
+	return nil
+}
+
+// isASCII reports whether the string contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalizeRecord canonicalizes the record's zone, owner name and
+// hostname content in place. The timers of SOA records must be within
+// bounds.
+func canonicalizeRecord(record *Record) error {
+	zone, err := CanonicalZone(record.Zone)
+	if err != nil {
+		return err
+	}
+	record.Zone = zone
+
+	name, err := CanonicalName(record.Name, zone)
+	if err != nil {
+		return err
+	}
+	record.Name = name
+
+	switch record.RecordType {
+	case "CNAME":
+		if record.CNAME != nil {
+			record.CNAME.Host, err = CanonicalHostname("host", record.CNAME.Host)
+		}
+	case "NS":
+		if record.NS != nil {
+			record.NS.Host, err = CanonicalHostname("host", record.NS.Host)
+		}
+	case "MX":
+		if record.MX != nil {
+			record.MX.Host, err = CanonicalHostname("host", record.MX.Host)
+		}
+	case "SRV":
+		// A target of "." means the service is not available
+		if record.SRV != nil && strings.TrimSpace(record.SRV.Target) != "." {
+			record.SRV.Target, err = CanonicalHostname("target", record.SRV.Target)
+		}
+	case "SOA":
+		if record.SOA != nil {
+			if record.SOA.Ns, err = CanonicalHostname("ns", record.SOA.Ns); err != nil {
+				return err
+			}
+			if record.SOA.MBox, err = CanonicalHostname("mbox", record.SOA.MBox); err != nil {
+				return err
+			}
+			err = CheckSOATimers(record.SOA.Timers())
+		}
+	}
+	return err
+}
+
+// lookupZone returns the canonical form of a zone used to look up existing
+// data. Invalid names are returned unchanged and simply won't match.
+func lookupZone(zone string) string {
+	if canonical, err := CanonicalZone(zone); err == nil {
+		return canonical
+	}
+	return zone
+}
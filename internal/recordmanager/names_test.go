@@ -0,0 +1,294 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalZone(t *testing.T) {
+	tests := []struct {
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{zone: "example.org", want: "example.org."},
+		{zone: "example.org.", want: "example.org."},
+		{zone: " Example.ORG. ", want: "example.org."},
+		{zone: "sub.example.co.uk", want: "sub.example.co.uk."},
+		{zone: "_tcp.example.org", want: "_tcp.example.org."},
+		{zone: "org", wantErr: true},
+		{zone: "", wantErr: true},
+		{zone: ".", wantErr: true},
+		{zone: "example..org", wantErr: true},
+		{zone: "-example.org", wantErr: true},
+		{zone: "example-.org", wantErr: true},
+		{zone: "exa mple.org", wantErr: true},
+		{zone: "*.example.org", wantErr: true},
+		{zone: strings.Repeat("a", 64) + ".org", wantErr: true},
+		{zone: strings.Repeat("a", 63) + ".org", want: strings.Repeat("a", 63) + ".org."},
+		{zone: strings.Repeat("abcdefghi.", 26) + "org", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			got, err := CanonicalZone(tt.zone)
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != "zone" {
+					t.Fatalf("CanonicalZone() error = %v, want a zone validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalZone() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalZone() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: ""},
+		{name: "@", want: ""},
+		{name: " @ ", want: ""},
+		{name: "www", want: "www"},
+		{name: "WWW", want: "www"},
+		{name: "a.b", want: "a.b"},
+		{name: "*", want: "*"},
+		{name: "*.dev", want: "*.dev"},
+		{name: "_dmarc", want: "_dmarc"},
+		{name: "_sip._tcp", want: "_sip._tcp"},
+		{name: "example.org.", want: ""},
+		{name: "WWW.Example.org.", want: "www"},
+		{name: "a.b.example.org.", want: "a.b"},
+		{name: "www.example.net.", wantErr: true},
+		{name: "badexample.org.", wantErr: true},
+		{name: "dev.*", wantErr: true},
+		{name: "a..b", wantErr: true},
+		{name: "-www", wantErr: true},
+		{name: "www!", wantErr: true},
+		{name: strings.Repeat("a", 64), wantErr: true},
+		{name: strings.Repeat("abcdefghi.", 24) + "abcdefgh", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalName(tt.name, testZone)
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != "name" {
+					t.Fatalf("CanonicalName() error = %v, want a name validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalHostname(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{host: "mail.example.net", want: "mail.example.net."},
+		{host: "Mail.Example.NET.", want: "mail.example.net."},
+		{host: "localhost", want: "localhost."},
+		{host: "*.example.net", wantErr: true},
+		{host: "", wantErr: true},
+		{host: "mail..example.net", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := CanonicalHostname("host", tt.host)
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != "host" {
+					t.Fatalf("CanonicalHostname() error = %v, want a host validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalHostname() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalHostname() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  *Record
+		want    func(record *Record) string
+		wantVal string
+	}{
+		{
+			name:    "owner name",
+			record:  &Record{Zone: "Example.org", Name: "WWW.example.org.", RecordType: "A", A: &AData{}},
+			want:    func(record *Record) string { return record.Zone + " " + record.Name },
+			wantVal: "example.org. www",
+		},
+		{
+			name:    "CNAME target",
+			record:  cnameRecord("www", "Target.Example.NET"),
+			want:    func(record *Record) string { return record.CNAME.Host },
+			wantVal: "target.example.net.",
+		},
+		{
+			name:    "MX host",
+			record:  &Record{RecordType: "MX", MX: &MXData{Host: "mail.example.org", Preference: 10}},
+			want:    func(record *Record) string { return record.MX.Host },
+			wantVal: "mail.example.org.",
+		},
+		{
+			name:    "SRV target",
+			record:  &Record{RecordType: "SRV", SRV: &SRVData{Target: "sip.example.org"}},
+			want:    func(record *Record) string { return record.SRV.Target },
+			wantVal: "sip.example.org.",
+		},
+		{
+			name:    "unavailable SRV service",
+			record:  &Record{RecordType: "SRV", SRV: &SRVData{Target: "."}},
+			want:    func(record *Record) string { return record.SRV.Target },
+			wantVal: ".",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.record.Zone == "" {
+				tt.record.Zone = testZone
+			}
+			if err := canonicalizeRecord(tt.record); err != nil {
+				t.Fatalf("canonicalizeRecord() error = %v", err)
+			}
+			if got := tt.want(tt.record); got != tt.wantVal {
+				t.Errorf("canonicalized = %q, want %q", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestApplyChangeSetStoresCanonicalNames(t *testing.T) {
+	m, store := newTestManager(t, apexRecords()...)
+
+	_, err := m.ApplyChangeSet(context.Background(), "Example.ORG", testUserID, []Change{
+		{Action: ChangeCreate, Record: aRecord("WWW.Example.org.", "192.0.2.1", 300)},
+		{Action: ChangeCreate, Record: cnameRecord("Alias", "WWW.Example.org")},
+	})
+	if err != nil {
+		t.Fatalf("ApplyChangeSet() error = %v", err)
+	}
+
+	var got []string
+	for _, record := range store.storedRecords() {
+		if record.RecordType == "A" || record.RecordType == "CNAME" {
+			got = append(got, record.Zone+" "+record.Name+" "+string(record.Content))
+		}
+	}
+	want := []string{
+		`example.org. alias {"host":"www.example.org."}`,
+		`example.org. www {"ip":"192.0.2.1"}`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("stored records = %q, want %q", got, want)
+	}
+
+	// Names outside the zone are rejected
+	_, err = m.ApplyChangeSet(context.Background(), testZone, testUserID, []Change{
+		{Action: ChangeCreate, Record: aRecord("www.example.net.", "192.0.2.1", 300)},
+	})
+	var changeErr *ChangeError
+	if !errors.As(err, &changeErr) || changeErr.Index != 0 {
+		t.Fatalf("ApplyChangeSet() error = %v, want a change error for change 0", err)
+	}
+}
+
+func TestInternationalizedNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		zone    string
+		want    string
+		unicode string
+	}{
+		{name: "Greek", zone: "ελλάδα.gr", want: "xn--hxakic4aa.gr.", unicode: "ελλάδα.gr."},
+		{name: "uppercase Greek", zone: "ΕΛΛΆΔΑ.gr", want: "xn--hxakic4aa.gr.", unicode: "ελλάδα.gr."},
+		{name: "Latin", zone: "münchen.de", want: "xn--mnchen-3ya.de.", unicode: "münchen.de."},
+		{name: "A-label", zone: "xn--mnchen-3ya.de", want: "xn--mnchen-3ya.de.", unicode: "münchen.de."},
+		{name: "CJK", zone: "日本.jp", want: "xn--wgv71a.jp.", unicode: "日本.jp."},
+		{name: "ASCII", zone: "example.org", want: "example.org.", unicode: "example.org."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalZone(tt.zone)
+			if err != nil {
+				t.Fatalf("CanonicalZone() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalZone() = %q, want %q", got, tt.want)
+			}
+			if unicode := UnicodeName(got); unicode != tt.unicode {
+				t.Errorf("UnicodeName() = %q, want %q", unicode, tt.unicode)
+			}
+			// The Unicode form is canonicalized to the same A-label form
+			if again, err := CanonicalZone(UnicodeName(got)); err != nil || again != got {
+				t.Errorf("CanonicalZone(UnicodeName()) = %q, %v, want %q", again, err, got)
+			}
+		})
+	}
+}
+
+func TestInternationalizedOwnerNames(t *testing.T) {
+	const zone = "xn--hxakic4aa.gr."
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "καλημέρα", want: "xn--ixahbwnhi5b"},
+		{name: "Καλημέρα.ελλάδα.gr.", want: "xn--ixahbwnhi5b"},
+		{name: "www.ελλάδα.gr.", want: "www"},
+		{name: "ελλάδα.gr.", want: ""},
+		{name: "καλημέρα.example.gr.", wantErr: true},
+		{name: "a\u200db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalName(tt.name, zone)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CanonicalName() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnicodeNameKeepsUndecodableLabels(t *testing.T) {
+	if got := UnicodeName("xn--zz.xn--mnchen-3ya.de."); got != "xn--zz.münchen.de." {
+		t.Errorf("UnicodeName() = %q, want %q", got, "xn--zz.münchen.de.")
+	}
+}
@@ -0,0 +1,410 @@
+package recordmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ErrRRSetNotFound is returned when a zone has no records with an owner name
+// and type
+var ErrRRSetNotFound = errors.New("RRset not found")
+
+// RRSet is the records of a zone sharing an owner name and type. Resolvers
+// cache an RRset as a whole, so its records share a TTL.
+type RRSet struct {
+	Zone       string
+	Name       string
+	RecordType string
+	Ttl        int32
+	Records    []*Record
+}
+
+// UnicodeName returns the RRset's owner name in its Unicode form
+func (s *RRSet) UnicodeName() string {
+	return UnicodeName(s.Name)
+}
+
+// GetRRSet returns the RRset of the zone with the owner name and type
+func (m *RecordManager) GetRRSet(ctx context.Context, zone string, userID uuid.UUID, name, recordType string) (*RRSet, error) {
+	key, zone, err := canonicalRRsetKey(zone, name, recordType)
+	if err != nil {
+		return nil, err
+	}
+	rrset, err := m.getRRSet(ctx, m.querier, zone, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rrset.Records) == 0 {
+		return nil, ErrRRSetNotFound
+	}
+	return rrset, nil
+}
+
+// ReplaceRRSet replaces the records of the RRset with the records, given by
+// their content, in a single transaction. All records get the TTL. Existing
+// records whose content is unchanged are kept and other existing records are
+// updated in place, so they keep their IDs. Replacing an RRset with no
+// records deletes it.
+func (m *RecordManager) ReplaceRRSet(ctx context.Context, zone string, userID uuid.UUID, name, recordType string, ttl int32, records []*Record) (*RRSet, error) {
+	key, zone, err := canonicalRRsetKey(zone, name, recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrset *RRSet
+	err = m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, current []*Record) error {
+		for i, record := range records {
+			record.Zone = zone
+			record.UserID = userID
+			record.Name = key.name
+			record.RecordType = key.recordType
+			record.Ttl = sql.NullInt32{Int32: ttl, Valid: true}
+			if err := canonicalizeRecord(record); err != nil {
+				return &ChangeError{Index: i, Err: err}
+			}
+		}
+
+		existing := slices.DeleteFunc(slices.Clone(current), func(record *Record) bool {
+			return record.Name != key.name || record.RecordType != key.recordType
+		})
+		deletes, updates, creates, err := planRRsetChanges(existing, records)
+		if err != nil {
+			return err
+		}
+		var changes []Change
+		for _, planned := range append(append(deletes, updates...), creates...) {
+			changes = append(changes, planned.Change)
+		}
+		if _, err := m.applyChanges(ctx, querier, zone, userID, current, changes); err != nil {
+			return err
+		}
+
+		rrset, err = m.getRRSet(ctx, querier, zone, userID, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rrset, nil
+}
+
+// DeleteRRSet deletes the records of the RRset in a single transaction
+func (m *RecordManager) DeleteRRSet(ctx context.Context, zone string, userID uuid.UUID, name, recordType string) error {
+	key, zone, err := canonicalRRsetKey(zone, name, recordType)
+	if err != nil {
+		return err
+	}
+
+	return m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, current []*Record) error {
+		var changes []Change
+		for _, record := range current {
+			if record.Name == key.name && record.RecordType == key.recordType {
+				changes = append(changes, Change{
+					Action:  ChangeDelete,
+					ID:      record.ID,
+					Version: record.Version,
+				})
+			}
+		}
+		if len(changes) == 0 {
+			return ErrRRSetNotFound
+		}
+		_, err := m.applyChanges(ctx, querier, zone, userID, current, changes)
+		return err
+	})
+}
+
+// GroupRRSets groups records into RRsets, in the order the RRsets first
+// appear in the records
+func GroupRRSets(records []*Record) []*RRSet {
+	var rrsets []*RRSet
+	byKey := make(map[rrsetKey]*RRSet)
+	for _, record := range records {
+		key := rrsetKey{name: record.Name, recordType: record.RecordType}
+		rrset, ok := byKey[key]
+		if !ok {
+			rrset = &RRSet{
+				Zone:       record.Zone,
+				Name:       record.Name,
+				RecordType: record.RecordType,
+				Ttl:        record.Ttl.Int32,
+			}
+			byKey[key] = rrset
+			rrsets = append(rrsets, rrset)
+		}
+		rrset.Records = append(rrset.Records, record)
+	}
+	return rrsets
+}
+
+// getRRSet reads the records of the RRset using the querier
+func (m *RecordManager) getRRSet(ctx context.Context, querier storage.Querier, zone string, userID uuid.UUID, key rrsetKey) (*RRSet, error) {
+	dbRecords, err := querier.ListRecordsByRRset(ctx, storage.ListRecordsByRRsetParams{
+		Zone:       zone,
+		Name:       key.name,
+		RecordType: key.recordType,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RRset records: %w", err)
+	}
+
+	rrset := &RRSet{
+		Zone:       zone,
+		Name:       key.name,
+		RecordType: key.recordType,
+		Records:    make([]*Record, len(dbRecords)),
+	}
+	for i := range dbRecords {
+		rrset.Records[i] = m.storageToRecord(&dbRecords[i])
+	}
+	if len(rrset.Records) > 0 {
+		rrset.Ttl = rrset.Records[0].Ttl.Int32
+	}
+	return rrset, nil
+}
+
+// canonicalRRsetKey returns the canonical key of the RRset with the owner
+// name and type, and the canonical zone
+func canonicalRRsetKey(zone, name, recordType string) (rrsetKey, string, error) {
+	zone, err := CanonicalZone(zone)
+	if err != nil {
+		return rrsetKey{}, "", err
+	}
+	name, err = CanonicalName(name, zone)
+	if err != nil {
+		return rrsetKey{}, "", err
+	}
+	return rrsetKey{name: name, recordType: strings.ToUpper(strings.TrimSpace(recordType))}, zone, nil
+}
+
+// ValidationErrors is a list of validation errors returned together
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// rrsetKey identifies an RRset, the records sharing an owner name and type
+type rrsetKey struct {
+	name       string
+	recordType string
+}
+
+// checkZoneChange validates the RRsets of the zone as they will be after the
+// change, given the zone's current records. Violations are only reported for
+// the RRsets the change touches, so zones that predate a rule can still be
+// edited elsewhere, but a change can't make an existing violation worse.
+func checkZoneChange(before, after []*Record) error {
+	names, rrsets := changedRRsets(before, after)
+
+	var errs ValidationErrors
+	for _, violation := range rrsetViolations(after) {
+		touched := rrsets[violation.key]
+		if violation.key.recordType == "" {
+			touched = names[violation.key.name]
+		}
+		if touched {
+			errs = append(errs, violation.ValidationError)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// changedRRsets returns the owner names and RRsets whose records differ
+// between the zone's records before and after a change
+func changedRRsets(before, after []*Record) (map[string]bool, map[rrsetKey]bool) {
+	names := make(map[string]bool)
+	rrsets := make(map[rrsetKey]bool)
+	mark := func(records, others []*Record) {
+		unchanged := make(map[string]bool, len(others))
+		for _, record := range others {
+			unchanged[recordState(record)] = true
+		}
+		for _, record := range records {
+			if !unchanged[recordState(record)] {
+				names[record.Name] = true
+				rrsets[rrsetKey{name: record.Name, recordType: record.RecordType}] = true
+			}
+		}
+	}
+	mark(after, before)
+	mark(before, after)
+	return names, rrsets
+}
+
+// recordState identifies a record as it is, so that changed records can be
+// told apart from unchanged ones
+func recordState(record *Record) string {
+	content, err := ContentJSON(record)
+	if err != nil {
+		content = err.Error()
+	}
+	return fmt.Sprintf("%d %s %s %v %s", record.ID, record.Name, record.RecordType, record.Ttl, content)
+}
+
+// rrsetViolation is a violation of the RRset rules and the RRset it concerns,
+// or all RRsets at the name if the key has no record type
+type rrsetViolation struct {
+	*ValidationError
+	key rrsetKey
+}
+
+// rrsetViolations checks the records of a zone against the RRset rules:
+// CNAME exclusivity, exactly one SOA at the apex, at least one NS at the apex,
+// a consistent TTL within an RRset and no duplicate records
+func rrsetViolations(records []*Record) []rrsetViolation {
+	var violations []rrsetViolation
+	violate := func(key rrsetKey, field, message string) {
+		violations = append(violations, rrsetViolation{
+			ValidationError: &ValidationError{Field: field, Message: message},
+			key:             key,
+		})
+	}
+
+	var names []string
+	byName := make(map[string][]*Record)
+	var keys []rrsetKey
+	rrsets := make(map[rrsetKey][]*Record)
+	for _, record := range records {
+		if _, ok := byName[record.Name]; !ok {
+			names = append(names, record.Name)
+		}
+		byName[record.Name] = append(byName[record.Name], record)
+
+		key := rrsetKey{name: record.Name, recordType: record.RecordType}
+		if _, ok := rrsets[key]; !ok {
+			keys = append(keys, key)
+		}
+		rrsets[key] = append(rrsets[key], record)
+	}
+
+	// A CNAME must be the only record at its name, and can't be at the apex
+	for _, name := range names {
+		if !slices.ContainsFunc(byName[name], isRecordType("CNAME")) {
+			continue
+		}
+		if name == "" {
+			violate(rrsetKey{recordType: "CNAME"}, "record_type", "CNAME records are not allowed at the zone apex")
+		} else if len(byName[name]) > 1 {
+			violate(rrsetKey{name: name}, "name", fmt.Sprintf("a CNAME record at %s can't coexist with other records", name))
+		}
+	}
+
+	// The zone has exactly one SOA and at least one NS record at the apex
+	for _, name := range names {
+		if name != "" && slices.ContainsFunc(byName[name], isRecordType("SOA")) {
+			violate(rrsetKey{name: name, recordType: "SOA"}, "name", fmt.Sprintf("SOA record at %s must be at the zone apex", name))
+		}
+	}
+	if count := len(rrsets[rrsetKey{recordType: "SOA"}]); count != 1 {
+		violate(rrsetKey{recordType: "SOA"}, "record_type", fmt.Sprintf("the zone must have exactly one SOA record, it has %d", count))
+	}
+	if len(rrsets[rrsetKey{recordType: "NS"}]) == 0 {
+		violate(rrsetKey{recordType: "NS"}, "record_type", "the zone must have at least one NS record at the apex, it has none")
+	}
+
+	for _, key := range keys {
+		rrset := rrsets[key]
+
+		// Resolvers cache an RRset as a whole, so its records share one TTL
+		for _, record := range rrset[1:] {
+			if record.Ttl != rrset[0].Ttl {
+				violate(key, "ttl", fmt.Sprintf("all %s records at %s must have the same TTL", key.recordType, displayName(key.name)))
+				break
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, record := range rrset {
+			content, err := ContentJSON(record)
+			if err != nil {
+				continue
+			}
+			if seen[content] {
+				violate(key, "content", fmt.Sprintf("duplicate %s record at %s", key.recordType, displayName(key.name)))
+				break
+			}
+			seen[content] = true
+		}
+	}
+
+	return violations
+}
+
+// withRecord returns the records with the record added, or replacing the
+// record with the same ID
+func withRecord(records []*Record, record *Record) []*Record {
+	result := withoutRecord(records, record.ID)
+	return append(result, record)
+}
+
+// withoutRecord returns the records without the record with the ID
+func withoutRecord(records []*Record, id int64) []*Record {
+	result := make([]*Record, 0, len(records)+1)
+	for _, existing := range records {
+		if id == 0 || existing.ID != id {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
+
+// withRRsetTTL returns the records with the TTL of the record applied to the
+// other records of its RRset
+func withRRsetTTL(records []*Record, record *Record) []*Record {
+	result := make([]*Record, len(records))
+	for i, existing := range records {
+		if existing.ID != record.ID && existing.Name == record.Name && existing.RecordType == record.RecordType {
+			updated := *existing
+			updated.Ttl = record.Ttl
+			existing = &updated
+		}
+		result[i] = existing
+	}
+	return result
+}
+
+// ContentJSON returns the record's content in its normalized JSON form
+func ContentJSON(record *Record) (string, error) {
+	content, err := recordContent(record)
+	if err != nil {
+		return "", err
+	}
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal content: %w", err)
+	}
+	return string(contentJSON), nil
+}
+
+// isRecordType returns a function reporting whether a record has the type
+func isRecordType(recordType string) func(*Record) bool {
+	return func(record *Record) bool {
+		return record.RecordType == recordType
+	}
+}
+
+// displayName returns the owner name as written in a zone file
+func displayName(name string) string {
+	if name == "" {
+		return ApexName
+	}
+	return name
+}
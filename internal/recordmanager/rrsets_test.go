@@ -0,0 +1,424 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// numbered gives the records IDs in order, as stored records have
+func numbered(records ...*Record) []*Record {
+	for i, record := range records {
+		record.ID = int64(i + 1)
+	}
+	return records
+}
+
+func TestCheckZoneChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []*Record
+		// change returns the records after the change
+		change func(before []*Record) []*Record
+		want   []string
+	}{
+		{
+			name:   "valid change",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.2", 300))
+			},
+		},
+		{
+			name:   "CNAME next to other records",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, cnameRecord("www", "example.net."))
+			},
+			want: []string{"name: a CNAME record at www can't coexist with other records"},
+		},
+		{
+			name:   "CNAME at the apex",
+			before: numbered(apexRecords()...),
+			change: func(before []*Record) []*Record {
+				return append(before, cnameRecord("", "example.net."))
+			},
+			want: []string{
+				"record_type: CNAME records are not allowed at the zone apex",
+			},
+		},
+		{
+			name:   "existing CNAME conflict elsewhere",
+			before: numbered(append(apexRecords(), cnameRecord("www", "example.net."), txtRecord("www", "legacy", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("mail", "192.0.2.1", 300))
+			},
+		},
+		{
+			name:   "existing CNAME conflict made worse",
+			before: numbered(append(apexRecords(), cnameRecord("www", "example.net."), txtRecord("www", "legacy", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.1", 300))
+			},
+			want: []string{"name: a CNAME record at www can't coexist with other records"},
+		},
+		{
+			name:   "existing SOA violation elsewhere",
+			before: numbered(soaRecord(""), soaRecord(""), nsRecord("", "ns1.example.net.")),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.1", 300))
+			},
+		},
+		{
+			name:   "existing SOA violation made worse",
+			before: numbered(soaRecord(""), soaRecord(""), nsRecord("", "ns1.example.net.")),
+			change: func(before []*Record) []*Record {
+				return append(before, soaRecord(""))
+			},
+			want: []string{
+				"record_type: the zone must have exactly one SOA record, it has 3",
+				"content: duplicate SOA record at @",
+			},
+		},
+		{
+			name:   "existing SOA violation fixed",
+			before: numbered(soaRecord(""), soaRecord(""), nsRecord("", "ns1.example.net.")),
+			change: func(before []*Record) []*Record {
+				return withoutRecord(before, 2)
+			},
+		},
+		{
+			name:   "last SOA deleted",
+			before: numbered(apexRecords()...),
+			change: func(before []*Record) []*Record {
+				return withoutRecord(before, 1)
+			},
+			want: []string{"record_type: the zone must have exactly one SOA record, it has 0"},
+		},
+		{
+			name:   "SOA below the apex",
+			before: numbered(apexRecords()...),
+			change: func(before []*Record) []*Record {
+				return append(before, soaRecord("sub"))
+			},
+			want: []string{"name: SOA record at sub must be at the zone apex"},
+		},
+		{
+			name:   "missing NS elsewhere",
+			before: numbered(soaRecord("")),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.1", 300))
+			},
+		},
+		{
+			name:   "last NS deleted",
+			before: numbered(soaRecord(""), nsRecord("", "ns1.example.net.")),
+			change: func(before []*Record) []*Record {
+				return withoutRecord(before, 2)
+			},
+			want: []string{"record_type: the zone must have at least one NS record at the apex, it has none"},
+		},
+		{
+			name:   "TTL differing within an RRset",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.2", 600))
+			},
+			want: []string{"ttl: all A records at www must have the same TTL"},
+		},
+		{
+			name:   "existing TTL mismatch elsewhere",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300), aRecord("www", "192.0.2.2", 600))...),
+			change: func(before []*Record) []*Record {
+				return append(before, txtRecord("www", "hello", 300))
+			},
+		},
+		{
+			name:   "existing TTL mismatch made worse",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300), aRecord("www", "192.0.2.2", 600))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.3", 900))
+			},
+			want: []string{"ttl: all A records at www must have the same TTL"},
+		},
+		{
+			name:   "duplicate record",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.1", 300))
+			},
+			want: []string{"content: duplicate A record at www"},
+		},
+		{
+			name:   "existing duplicate made worse",
+			before: numbered(append(apexRecords(), aRecord("www", "192.0.2.1", 300), aRecord("www", "192.0.2.1", 300))...),
+			change: func(before []*Record) []*Record {
+				return append(before, aRecord("www", "192.0.2.1", 300))
+			},
+			want: []string{"content: duplicate A record at www"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := tt.change(slices.Clone(tt.before))
+			err := checkZoneChange(tt.before, after)
+
+			var got []string
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				for _, violation := range errs {
+					got = append(got, violation.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("checkZoneChange() error = %v, want validation errors", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkZoneChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyChangeSetEnforcesRRsetRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes func(records []*Record) []Change
+		wantErr bool
+	}{
+		{
+			name: "CNAME replacing an A record",
+			changes: func(records []*Record) []Change {
+				www := records[len(records)-1]
+				return []Change{
+					{Action: ChangeDelete, ID: www.ID, Version: www.Version},
+					{Action: ChangeCreate, Record: cnameRecord("www", "example.net")},
+				}
+			},
+		},
+		{
+			name: "CNAME next to an A record",
+			changes: func([]*Record) []Change {
+				return []Change{{Action: ChangeCreate, Record: cnameRecord("www", "example.net")}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "SOA deleted",
+			changes: func(records []*Record) []Change {
+				return []Change{{Action: ChangeDelete, ID: records[0].ID, Version: records[0].Version}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "TTL of a new record differing from its RRset",
+			changes: func([]*Record) []Change {
+				return []Change{{Action: ChangeCreate, Record: aRecord("www", "192.0.2.2", 600)}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "TTL of an updated record applied to its RRset",
+			changes: func(records []*Record) []Change {
+				ns := nsRecord("@", "ns1.example.net.")
+				ns.Ttl = ttl(7200)
+				return []Change{{Action: ChangeUpdate, ID: records[1].ID, Version: records[1].Version, Record: ns}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := append(apexRecords(), aRecord("www", "192.0.2.1", 300))
+			m, store := newTestManager(t, records...)
+			before := slices.Clone(store.storedRecords())
+
+			_, err := m.ApplyChangeSet(context.Background(), testZone, testUserID, tt.changes(records))
+			var errs ValidationErrors
+			if tt.wantErr {
+				if !errors.As(err, &errs) {
+					t.Fatalf("ApplyChangeSet() error = %v, want validation errors", err)
+				}
+				if !slices.EqualFunc(store.storedRecords(), before, recordsEqual) {
+					t.Errorf("records changed by a change set with validation errors")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyChangeSet() error = %v", err)
+			}
+			if violations := rrsetViolations(listTestZone(t, m)); len(violations) > 0 {
+				t.Errorf("zone violations after the change set = %v", violations[0].ValidationError)
+			}
+		})
+	}
+}
+
+// listTestZone lists the records of the test zone
+func listTestZone(t *testing.T, m *RecordManager) []*Record {
+	t.Helper()
+	records, err := m.ListRecordsByZone(context.Background(), testZone, testUserID)
+	if err != nil {
+		t.Fatalf("ListRecordsByZone() error = %v", err)
+	}
+	return records
+}
+
+func TestReplaceRRSet(t *testing.T) {
+	tests := []struct {
+		name string
+		ips  []string
+		ttl  int32
+		// want is the IPs of the stored records by ID, of which IDs up to 5
+		// are the existing ones
+		want map[int64]string
+	}{
+		{
+			name: "unchanged",
+			ips:  []string{"192.0.2.2", "192.0.2.1"},
+			ttl:  300,
+			want: map[int64]string{4: "192.0.2.1", 5: "192.0.2.2"},
+		},
+		{
+			name: "record added",
+			ips:  []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			ttl:  300,
+			want: map[int64]string{4: "192.0.2.1", 5: "192.0.2.2", 6: "192.0.2.3"},
+		},
+		{
+			name: "record updated in place",
+			ips:  []string{"192.0.2.1", "192.0.2.3"},
+			ttl:  300,
+			want: map[int64]string{4: "192.0.2.1", 5: "192.0.2.3"},
+		},
+		{
+			name: "record removed",
+			ips:  []string{"192.0.2.2"},
+			ttl:  300,
+			want: map[int64]string{5: "192.0.2.2"},
+		},
+		{
+			name: "TTL shared",
+			ips:  []string{"192.0.2.1", "192.0.2.2"},
+			ttl:  600,
+			want: map[int64]string{4: "192.0.2.1", 5: "192.0.2.2"},
+		},
+		{
+			name: "all records removed",
+			want: map[int64]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, store := newTestManager(t, append(apexRecords(),
+				aRecord("www", "192.0.2.1", 300),
+				aRecord("www", "192.0.2.2", 300),
+			)...)
+
+			var records []*Record
+			for _, ip := range tt.ips {
+				records = append(records, aRecord("", ip, 0))
+			}
+			rrset, err := m.ReplaceRRSet(context.Background(), "Example.org", testUserID, "WWW", "a", tt.ttl, records)
+			if err != nil {
+				t.Fatalf("ReplaceRRSet() error = %v", err)
+			}
+			if rrset.Name != "www" || rrset.RecordType != "A" || len(rrset.Records) != len(tt.want) {
+				t.Errorf("ReplaceRRSet() = %s %s with %d records, want www A with %d", rrset.Name, rrset.RecordType, len(rrset.Records), len(tt.want))
+			}
+			if len(rrset.Records) > 0 && rrset.Ttl != tt.ttl {
+				t.Errorf("RRset TTL = %d, want %d", rrset.Ttl, tt.ttl)
+			}
+
+			got := make(map[int64]string)
+			for _, record := range rrset.Records {
+				got[record.ID] = record.A.Ip.String()
+				if record.Ttl.Int32 != tt.ttl {
+					t.Errorf("record %d TTL = %d, want %d", record.ID, record.Ttl.Int32, tt.ttl)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("RRset records = %v, want %v", got, tt.want)
+			}
+			for id, ip := range tt.want {
+				if got[id] != ip {
+					t.Errorf("RRset records = %v, want %v", got, tt.want)
+					break
+				}
+			}
+			if n := len(store.list(func(record storage.CorednsRecord) bool { return record.RecordType == "NS" })); n != 2 {
+				t.Errorf("NS records = %d, want the 2 other records kept", n)
+			}
+		})
+	}
+}
+
+func TestReplaceRRSetInvalid(t *testing.T) {
+	m, store := newTestManager(t, append(apexRecords(), aRecord("www", "192.0.2.1", 300))...)
+	before := slices.Clone(store.storedRecords())
+
+	// A CNAME can't be added next to the A record, and the apex can't lose
+	// its NS records
+	_, err := m.ReplaceRRSet(context.Background(), testZone, testUserID, "www", "CNAME", 300, []*Record{
+		cnameRecord("", "example.net"),
+	})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Errorf("ReplaceRRSet() error = %v, want validation errors", err)
+	}
+	_, err = m.ReplaceRRSet(context.Background(), testZone, testUserID, "@", "NS", 3600, nil)
+	if !errors.As(err, &errs) {
+		t.Errorf("ReplaceRRSet() error = %v, want validation errors", err)
+	}
+	if !slices.EqualFunc(store.storedRecords(), before, recordsEqual) {
+		t.Errorf("records changed by an invalid replacement")
+	}
+}
+
+func TestDeleteRRSet(t *testing.T) {
+	m, store := newTestManager(t, append(apexRecords(),
+		aRecord("www", "192.0.2.1", 300),
+		aRecord("www", "192.0.2.2", 300),
+		txtRecord("www", "hello", 300),
+	)...)
+
+	if err := m.DeleteRRSet(context.Background(), testZone, testUserID, "www.example.org.", "A"); err != nil {
+		t.Fatalf("DeleteRRSet() error = %v", err)
+	}
+	var got []string
+	for _, record := range store.storedRecords() {
+		got = append(got, displayName(record.Name)+" "+record.RecordType)
+	}
+	if want := []string{"@ NS", "@ NS", "@ SOA", "www TXT"}; !slices.Equal(got, want) {
+		t.Errorf("stored records = %q, want %q", got, want)
+	}
+	if len(store.history) != 2 {
+		t.Errorf("history entries = %d, want 2", len(store.history))
+	}
+
+	if err := m.DeleteRRSet(context.Background(), testZone, testUserID, "www", "A"); !errors.Is(err, ErrRRSetNotFound) {
+		t.Errorf("DeleteRRSet() error = %v, want ErrRRSetNotFound", err)
+	}
+	if _, err := m.GetRRSet(context.Background(), testZone, testUserID, "www", "A"); !errors.Is(err, ErrRRSetNotFound) {
+		t.Errorf("GetRRSet() error = %v, want ErrRRSetNotFound", err)
+	}
+}
+
+func TestGroupRRSets(t *testing.T) {
+	records := []*Record{
+		aRecord("www", "192.0.2.1", 300),
+		txtRecord("www", "hello", 600),
+		aRecord("mail", "192.0.2.3", 60),
+		aRecord("www", "192.0.2.2", 300),
+	}
+
+	var got []string
+	for _, rrset := range GroupRRSets(records) {
+		got = append(got, fmt.Sprintf("%s %s %d %d", rrset.Name, rrset.RecordType, rrset.Ttl, len(rrset.Records)))
+	}
+	if want := []string{"www A 300 2", "www TXT 600 1", "mail A 60 1"}; !slices.Equal(got, want) {
+		t.Errorf("GroupRRSets() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,355 @@
+package recordmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+	"golang.org/x/net/idna"
+)
+
+// Page sizes of searches
+const (
+	// DefaultPageSize is the page size of searches that don't set one
+	DefaultPageSize = 100
+	// MaxPageSize is the largest page size of a search
+	MaxPageSize = 1000
+)
+
+// MinSearchLength is the length of the shortest text records are searched
+// for across zones, as shorter text can't be looked up in a trigram index
+const MinSearchLength = 3
+
+// RecordSorts are the orders records can be sorted in: by name and type, by
+// type and name, by TTL or by ID, which is the order records were created
+// in. A - prefix sorts in descending order.
+var RecordSorts = []string{"name", "type", "ttl", "id"}
+
+// ZoneSorts are the orders zones can be sorted in: by name or by creation
+// time. A - prefix sorts in descending order.
+var ZoneSorts = []string{"name", "created"}
+
+// searchOrder is the order of searches across zones, by zone, name and type
+const searchOrder = "zone"
+
+// RecordFilter selects and orders the records of a search. Empty fields
+// match any record.
+type RecordFilter struct {
+	// Name only matches records with the owner name, given as in a record
+	Name string
+	// NamePrefix matches records whose relative owner name starts with it
+	NamePrefix string
+	RecordType string
+	// Content matches records whose content contains it, ignoring case
+	Content string
+	// Sort is one of RecordSorts, name by default
+	Sort string
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	// PageSize is the maximum number of records of the page, DefaultPageSize
+	// by default
+	PageSize int
+}
+
+// RecordPage is a page of the records of a search. NextCursor continues the
+// search with the next page, and is empty on the last page.
+type RecordPage struct {
+	Records    []*Record
+	NextCursor string
+}
+
+// RecordSearch searches the records of all of a user's zones for text in
+// their owner name or content, such as an IP address or a hostname
+type RecordSearch struct {
+	// Text is searched for in owner names, as a relative name and zone
+	// joined by a dot, and in the values of the content, ignoring case
+	Text     string
+	Cursor   string
+	PageSize int
+}
+
+// ZoneFilter selects and orders the zones of a search. Empty fields match
+// any zone.
+type ZoneFilter struct {
+	// NamePrefix matches zones whose name starts with it
+	NamePrefix string
+	// Status is ZoneStatusPending or ZoneStatusActive
+	Status string
+	// Sort is one of ZoneSorts, name by default
+	Sort     string
+	Cursor   string
+	PageSize int
+}
+
+// ZonePage is a page of the zones of a search
+type ZonePage struct {
+	Zones      []*Zone
+	NextCursor string
+}
+
+// cursor is the position of a page in a search: the sort key and ID of the
+// last item of the previous page, and the search's order, so a cursor isn't
+// used with another order
+type cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i,omitempty"`
+}
+
+// SearchRecords returns a page of the records of a zone matching the filter
+func (m *RecordManager) SearchRecords(ctx context.Context, zone string, userID uuid.UUID, filter RecordFilter) (*RecordPage, error) {
+	zone, err := CanonicalZone(zone)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Name != "" {
+		if filter.Name, err = CanonicalName(filter.Name, zone); err != nil {
+			return nil, err
+		}
+	}
+	sort, descending, err := parseSort(filter.Sort, RecordSorts)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(filter.Cursor, sortOrder(sort, descending))
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := checkPageSize(filter.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request one more record than the page holds to learn if there is a
+	// next page
+	rows, err := m.querier.SearchRecords(ctx, storage.SearchRecordsParams{
+		Sort:       sort,
+		Zone:       zone,
+		UserID:     userID,
+		Name:       filter.Name,
+		NamePrefix: escapeLike(strings.ToLower(strings.TrimSpace(filter.NamePrefix))),
+		RecordType: strings.ToUpper(strings.TrimSpace(filter.RecordType)),
+		Content:    escapeLike(strings.TrimSpace(filter.Content)),
+		CursorID:   after.ID,
+		CursorKey:  after.Key,
+		Descending: descending,
+		PageSize:   int32(pageSize + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search records: %w", err)
+	}
+
+	page := &RecordPage{}
+	if len(rows) > pageSize {
+		rows = rows[:pageSize]
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeCursor(cursor{Sort: sortOrder(sort, descending), Key: last.SortKey, ID: last.ID})
+	}
+	page.Records = make([]*Record, len(rows))
+	for i, row := range rows {
+		page.Records[i] = m.storageToRecord(&storage.CorednsRecord{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			Zone:       row.Zone,
+			Name:       row.Name,
+			Ttl:        row.Ttl,
+			Content:    row.Content,
+			RecordType: row.RecordType,
+			Version:    row.Version,
+		})
+	}
+	return page, nil
+}
+
+// SearchAllRecords returns a page of the records of all of the user's zones
+// containing the text, ordered by zone, name and type
+func (m *RecordManager) SearchAllRecords(ctx context.Context, userID uuid.UUID, search RecordSearch) (*RecordPage, error) {
+	text := strings.TrimSpace(search.Text)
+	if !isASCII(text) {
+		// Names are stored as A-labels, so search for those of IDNs
+		if encoded, err := idna.Lookup.ToASCII(text); err == nil {
+			text = encoded
+		}
+	}
+	if len([]rune(text)) < MinSearchLength {
+		return nil, &ValidationError{
+			Field:   "q",
+			Message: fmt.Sprintf("search for at least %d characters", MinSearchLength),
+		}
+	}
+	after, err := decodeCursor(search.Cursor, searchOrder)
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := checkPageSize(search.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.querier.SearchUserRecords(ctx, storage.SearchUserRecordsParams{
+		UserID:    userID,
+		Pattern:   escapeLike(text),
+		CursorID:  after.ID,
+		CursorKey: after.Key,
+		PageSize:  int32(pageSize + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search records: %w", err)
+	}
+
+	page := &RecordPage{}
+	if len(rows) > pageSize {
+		rows = rows[:pageSize]
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeCursor(cursor{Sort: searchOrder, Key: last.SortKey, ID: last.ID})
+	}
+	page.Records = make([]*Record, len(rows))
+	for i, row := range rows {
+		page.Records[i] = m.storageToRecord(&storage.CorednsRecord{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			Zone:       row.Zone,
+			Name:       row.Name,
+			Ttl:        row.Ttl,
+			Content:    row.Content,
+			RecordType: row.RecordType,
+			Version:    row.Version,
+		})
+	}
+	return page, nil
+}
+
+// SearchZones returns a page of the user's zones matching the filter
+func (m *RecordManager) SearchZones(ctx context.Context, userID uuid.UUID, filter ZoneFilter) (*ZonePage, error) {
+	if filter.Status != "" && filter.Status != ZoneStatusPending && filter.Status != ZoneStatusActive {
+		return nil, &ValidationError{
+			Field:   "status",
+			Message: fmt.Sprintf("status must be %s or %s", ZoneStatusPending, ZoneStatusActive),
+		}
+	}
+	sort, descending, err := parseSort(filter.Sort, ZoneSorts)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(filter.Cursor, sortOrder(sort, descending))
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := checkPageSize(filter.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.querier.SearchZones(ctx, storage.SearchZonesParams{
+		Sort:       sort,
+		UserID:     userID,
+		NamePrefix: escapeLike(strings.ToLower(strings.TrimSpace(filter.NamePrefix))),
+		Status:     filter.Status,
+		CursorKey:  after.Key,
+		Descending: descending,
+		PageSize:   int32(pageSize + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search zones: %w", err)
+	}
+
+	page := &ZonePage{}
+	if len(rows) > pageSize {
+		rows = rows[:pageSize]
+		page.NextCursor = encodeCursor(cursor{Sort: sortOrder(sort, descending), Key: rows[len(rows)-1].SortKey})
+	}
+	page.Zones = make([]*Zone, len(rows))
+	for i, row := range rows {
+		page.Zones[i] = storageToZone(&storage.Zone{
+			Zone:               row.Zone,
+			UserID:             row.UserID,
+			Status:             row.Status,
+			VerificationToken:  row.VerificationToken,
+			VerificationMethod: row.VerificationMethod,
+			VerifiedAt:         row.VerifiedAt,
+			LastCheckedAt:      row.LastCheckedAt,
+			LastCheckError:     row.LastCheckError,
+			CreatedAt:          row.CreatedAt,
+		})
+	}
+	return page, nil
+}
+
+// parseSort returns the field and direction of a sort order, which is one of
+// the sorts, optionally prefixed with - for descending order. An empty order
+// is the first of the sorts.
+func parseSort(order string, sorts []string) (string, bool, error) {
+	field, descending := strings.CutPrefix(order, "-")
+	if field == "" && !descending {
+		return sorts[0], false, nil
+	}
+	if !slices.Contains(sorts, field) {
+		return "", false, &ValidationError{
+			Field:   "sort",
+			Message: fmt.Sprintf("sort must be one of %s, optionally prefixed with -", strings.Join(sorts, ", ")),
+		}
+	}
+	return field, descending, nil
+}
+
+// sortOrder returns the sort order of a field and direction, as parsed by
+// parseSort
+func sortOrder(field string, descending bool) string {
+	if descending {
+		return "-" + field
+	}
+	return field
+}
+
+// checkPageSize returns the page size of a search, DefaultPageSize if it is
+// zero
+func checkPageSize(pageSize int) (int, error) {
+	switch {
+	case pageSize == 0:
+		return DefaultPageSize, nil
+	case pageSize < 0 || pageSize > MaxPageSize:
+		return 0, &ValidationError{
+			Field:   "limit",
+			Message: fmt.Sprintf("limit must be between 1 and %d", MaxPageSize),
+		}
+	}
+	return pageSize, nil
+}
+
+// encodeCursor encodes a cursor as an opaque string
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor of a search in the sort order. An empty
+// cursor starts at the first page.
+func decodeCursor(s, sort string) (cursor, error) {
+	if s == "" {
+		return cursor{}, nil
+	}
+	invalid := &ValidationError{Field: "cursor", Message: "invalid cursor"}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, invalid
+	}
+	if c.Sort != sort {
+		return cursor{}, &ValidationError{Field: "cursor", Message: "the cursor is of a search with another sort order"}
+	}
+	return c, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so it matches the
+// string literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,189 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// searchTestRecords returns records of the test zone, some of which have the
+// same sort keys
+func searchTestRecords() []*Record {
+	return append(apexRecords(),
+		aRecord("www", "192.0.2.1", 300),
+		aRecord("www", "192.0.2.2", 300),
+		txtRecord("www", "hello", 600),
+		aRecord("mail", "192.0.2.3", 60),
+		txtRecord("", "v=spf1 -all", 300),
+	)
+}
+
+func TestSearchRecordsPages(t *testing.T) {
+	records := searchTestRecords()
+	for _, sort := range []string{"", "name", "-name", "type", "-type", "ttl", "-ttl", "id", "-id"} {
+		m, _ := newTestManager(t, searchTestRecords()...)
+		all, err := m.SearchRecords(context.Background(), testZone, testUserID, RecordFilter{Sort: sort})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) error = %v", sort, err)
+		}
+		if len(all.Records) != len(records) || all.NextCursor != "" {
+			t.Fatalf("SearchRecords(%q) = %d records, cursor %q, want %d records on one page", sort, len(all.Records), all.NextCursor, len(records))
+		}
+
+		// Any page size pages through the same records in the same order,
+		// without an empty last page when the pages are full
+		for _, pageSize := range []int{1, 2, 3, len(records) - 1, len(records), len(records) + 1} {
+			t.Run(fmt.Sprintf("%s/%d", sort, pageSize), func(t *testing.T) {
+				var got []*Record
+				var pages int
+				filter := RecordFilter{Sort: sort, PageSize: pageSize}
+				for {
+					page, err := m.SearchRecords(context.Background(), testZone, testUserID, filter)
+					if err != nil {
+						t.Fatalf("SearchRecords() error = %v", err)
+					}
+					if len(page.Records) > pageSize || len(page.Records) == 0 {
+						t.Fatalf("page %d has %d records, want 1 to %d", pages, len(page.Records), pageSize)
+					}
+					got = append(got, page.Records...)
+					pages++
+					if page.NextCursor == "" {
+						break
+					}
+					filter.Cursor = page.NextCursor
+				}
+
+				if wantPages := (len(records) + pageSize - 1) / pageSize; pages != wantPages {
+					t.Errorf("pages = %d, want %d", pages, wantPages)
+				}
+				if !slices.EqualFunc(got, all.Records, func(a, b *Record) bool { return a.ID == b.ID }) {
+					t.Errorf("paged records = %v, want %v", recordIDs(got), recordIDs(all.Records))
+				}
+			})
+		}
+	}
+}
+
+func TestSearchRecordsOrder(t *testing.T) {
+	m, _ := newTestManager(t, searchTestRecords()...)
+	tests := []struct {
+		sort string
+		want []int64
+	}{
+		{sort: "name", want: []int64{2, 3, 1, 8, 7, 4, 5, 6}},
+		{sort: "-name", want: []int64{6, 5, 4, 7, 8, 1, 3, 2}},
+		{sort: "type", want: []int64{7, 4, 5, 2, 3, 1, 8, 6}},
+		{sort: "ttl", want: []int64{7, 8, 4, 5, 6, 2, 3, 1}},
+		{sort: "-id", want: []int64{8, 7, 6, 5, 4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			page, err := m.SearchRecords(context.Background(), testZone, testUserID, RecordFilter{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("SearchRecords() error = %v", err)
+			}
+			if got := recordIDs(page.Records); !slices.Equal(got, tt.want) {
+				t.Errorf("SearchRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRecordsFilter(t *testing.T) {
+	m, _ := newTestManager(t, searchTestRecords()...)
+
+	page, err := m.SearchRecords(context.Background(), testZone, testUserID, RecordFilter{Name: "WWW.example.org.", RecordType: "a", PageSize: 1})
+	if err != nil {
+		t.Fatalf("SearchRecords() error = %v", err)
+	}
+	if got := recordIDs(page.Records); !slices.Equal(got, []int64{4}) || page.NextCursor == "" {
+		t.Fatalf("SearchRecords() = %v, cursor %q, want [4] and a next page", got, page.NextCursor)
+	}
+	page, err = m.SearchRecords(context.Background(), testZone, testUserID, RecordFilter{Name: "www", RecordType: "A", PageSize: 1, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("SearchRecords() error = %v", err)
+	}
+	if got := recordIDs(page.Records); !slices.Equal(got, []int64{5}) || page.NextCursor != "" {
+		t.Errorf("SearchRecords() = %v, cursor %q, want [5] on the last page", got, page.NextCursor)
+	}
+}
+
+func TestSearchRecordsInvalid(t *testing.T) {
+	m, _ := newTestManager(t, searchTestRecords()...)
+	first, err := m.SearchRecords(context.Background(), testZone, testUserID, RecordFilter{Sort: "name", PageSize: 1})
+	if err != nil {
+		t.Fatalf("SearchRecords() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter RecordFilter
+		field  string
+	}{
+		{name: "unknown sort", filter: RecordFilter{Sort: "content"}, field: "sort"},
+		{name: "bare descending sort", filter: RecordFilter{Sort: "-"}, field: "sort"},
+		{name: "negative page size", filter: RecordFilter{PageSize: -1}, field: "limit"},
+		{name: "page size too large", filter: RecordFilter{PageSize: MaxPageSize + 1}, field: "limit"},
+		{name: "cursor of another order", filter: RecordFilter{Sort: "-name", Cursor: first.NextCursor}, field: "cursor"},
+		{name: "malformed cursor", filter: RecordFilter{Cursor: "not a cursor"}, field: "cursor"},
+		{name: "cursor not JSON", filter: RecordFilter{Cursor: "bm90IGpzb24"}, field: "cursor"},
+		{name: "name outside the zone", filter: RecordFilter{Name: "www.example.net."}, field: "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.SearchRecords(context.Background(), testZone, testUserID, tt.filter)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("SearchRecords() error = %v, want a %s validation error", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestCheckPageSize(t *testing.T) {
+	tests := []struct {
+		pageSize int
+		want     int
+		wantErr  bool
+	}{
+		{pageSize: 0, want: DefaultPageSize},
+		{pageSize: 1, want: 1},
+		{pageSize: MaxPageSize, want: MaxPageSize},
+		{pageSize: MaxPageSize + 1, wantErr: true},
+		{pageSize: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := checkPageSize(tt.pageSize)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("checkPageSize(%d) = %d, %v, want %d, error %t", tt.pageSize, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, c := range []cursor{
+		{Sort: "name", Key: "www A", ID: 4},
+		{Sort: "-ttl", Key: "0000000300 www A", ID: 1},
+		{Sort: "id", ID: 1 << 40},
+		{Sort: "created", Key: "2026-10-19T12:00:00Z"},
+	} {
+		got, err := decodeCursor(encodeCursor(c), c.Sort)
+		if err != nil || got != c {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v, %v", c, got, err)
+		}
+	}
+	if got, err := decodeCursor("", "name"); err != nil || got != (cursor{}) {
+		t.Errorf("decodeCursor(\"\") = %+v, %v, want the first page", got, err)
+	}
+}
+
+// recordIDs returns the IDs of the records
+func recordIDs(records []*Record) []int64 {
+	ids := make([]int64, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+package recordmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// SOA timer bounds, in seconds. Secondaries refreshing more often than every
+// few minutes load the primary for nothing, and zones expiring or negative
+// answers cached for longer than the maximums make outages and mistakes last.
+const (
+	minSOARefresh = 300     // 5 minutes
+	maxSOARefresh = 604800  // 1 week
+	minSOARetry   = 60      // 1 minute
+	maxSOARetry   = 86400   // 1 day
+	minSOAExpire  = 86400   // 1 day
+	maxSOAExpire  = 2419200 // 4 weeks
+	minSOAMinTtl  = 60      // 1 minute
+	maxSOAMinTtl  = 86400   // 1 day
+)
+
+// SOATimers are the timers of a zone's SOA record, in seconds
+type SOATimers struct {
+	// Refresh is how often secondaries check the zone for changes
+	Refresh uint32
+	// Retry is how soon secondaries check again after a failed refresh
+	Retry uint32
+	// Expire is how long secondaries keep answering without a refresh
+	Expire uint32
+	// MinTtl is how long resolvers cache negative answers
+	MinTtl uint32
+}
+
+// Timers returns the timers of the SOA record
+func (d *SOAData) Timers() SOATimers {
+	return SOATimers{
+		Refresh: d.Refresh,
+		Retry:   d.Retry,
+		Expire:  d.Expire,
+		MinTtl:  d.MinTtl,
+	}
+}
+
+// CheckSOATimers reports whether the SOA timers are within sane bounds, and
+// whether retries come before the next refresh and the zone doesn't expire
+// before it is refreshed
+func CheckSOATimers(timers SOATimers) error {
+	var violations ValidationErrors
+	check := func(field string, value, min, max uint32) {
+		if value < min || value > max {
+			violations = append(violations, &ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("%s must be between %d and %d seconds", field, min, max),
+			})
+		}
+	}
+	check("refresh", timers.Refresh, minSOARefresh, maxSOARefresh)
+	check("retry", timers.Retry, minSOARetry, maxSOARetry)
+	check("expire", timers.Expire, minSOAExpire, maxSOAExpire)
+	check("minttl", timers.MinTtl, minSOAMinTtl, maxSOAMinTtl)
+
+	if timers.Retry >= timers.Refresh {
+		violations = append(violations, &ValidationError{Field: "retry", Message: "retry must be shorter than refresh"})
+	}
+	if timers.Expire <= timers.Refresh {
+		violations = append(violations, &ValidationError{Field: "expire", Message: "expire must be longer than refresh"})
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// UpdateSOATimers sets the timers of the zone's SOA record, keeping its
+// nameserver and mailbox
+func (m *RecordManager) UpdateSOATimers(ctx context.Context, zone string, userID uuid.UUID, timers SOATimers) (*Record, error) {
+	if err := CheckSOATimers(timers); err != nil {
+		return nil, err
+	}
+
+	var updated *Record
+	err := m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, records []*Record) error {
+		i := slices.IndexFunc(records, func(record *Record) bool {
+			return record.Name == "" && record.RecordType == "SOA" && record.SOA != nil
+		})
+		if i < 0 {
+			return ErrRRSetNotFound
+		}
+		soa := records[i]
+
+		record := *soa
+		record.Content = nil
+		data := *soa.SOA
+		data.Refresh, data.Retry, data.Expire, data.MinTtl = timers.Refresh, timers.Retry, timers.Expire, timers.MinTtl
+		record.SOA = &data
+
+		results, err := m.applyChanges(ctx, querier, zone, userID, records, []Change{{
+			Action:  ChangeUpdate,
+			ID:      soa.ID,
+			Version: soa.Version,
+			Record:  &record,
+		}})
+		if err != nil {
+			return err
+		}
+		updated = results[0].Record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
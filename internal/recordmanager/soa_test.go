@@ -0,0 +1,142 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCheckSOATimers(t *testing.T) {
+	valid := SOATimers{Refresh: 86400, Retry: 7200, Expire: 604800, MinTtl: 300}
+	tests := []struct {
+		name   string
+		change func(timers *SOATimers)
+		want   []string
+	}{
+		{name: "valid", change: func(*SOATimers) {}},
+		{
+			name:   "minimums",
+			change: func(timers *SOATimers) { *timers = SOATimers{Refresh: 300, Retry: 60, Expire: 86400, MinTtl: 60} },
+		},
+		{
+			name: "maximums",
+			change: func(timers *SOATimers) {
+				*timers = SOATimers{Refresh: 604800, Retry: 86400, Expire: 2419200, MinTtl: 86400}
+			},
+		},
+		{
+			name:   "refresh too short",
+			change: func(timers *SOATimers) { timers.Refresh, timers.Retry = 299, 60 },
+			want:   []string{"refresh"},
+		},
+		{
+			name:   "refresh too long",
+			change: func(timers *SOATimers) { timers.Refresh, timers.Expire = 604801, 2419200 },
+			want:   []string{"refresh"},
+		},
+		{
+			name:   "retry too short",
+			change: func(timers *SOATimers) { timers.Retry = 59 },
+			want:   []string{"retry"},
+		},
+		{
+			name:   "retry too long",
+			change: func(timers *SOATimers) { timers.Refresh, timers.Retry, timers.Expire = 604800, 86401, 2419200 },
+			want:   []string{"retry"},
+		},
+		{
+			name:   "expire too short",
+			change: func(timers *SOATimers) { timers.Refresh, timers.Retry, timers.Expire = 3600, 600, 86399 },
+			want:   []string{"expire"},
+		},
+		{
+			name:   "expire too long",
+			change: func(timers *SOATimers) { timers.Expire = 2419201 },
+			want:   []string{"expire"},
+		},
+		{
+			name:   "minimum TTL too short",
+			change: func(timers *SOATimers) { timers.MinTtl = 59 },
+			want:   []string{"minttl"},
+		},
+		{
+			name:   "minimum TTL too long",
+			change: func(timers *SOATimers) { timers.MinTtl = 86401 },
+			want:   []string{"minttl"},
+		},
+		{
+			name:   "retry as long as refresh",
+			change: func(timers *SOATimers) { timers.Retry = timers.Refresh },
+			want:   []string{"retry"},
+		},
+		{
+			name:   "expire as long as refresh",
+			change: func(timers *SOATimers) { timers.Refresh, timers.Expire = 86400, 86400 },
+			want:   []string{"expire"},
+		},
+		{
+			name:   "unset",
+			change: func(timers *SOATimers) { *timers = SOATimers{} },
+			want:   []string{"refresh", "retry", "expire", "minttl", "retry", "expire"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timers := valid
+			tt.change(&timers)
+			err := CheckSOATimers(timers)
+
+			var got []string
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				for _, violation := range errs {
+					got = append(got, violation.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("CheckSOATimers() error = %v, want validation errors", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CheckSOATimers() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSOATimers(t *testing.T) {
+	m, store := newTestManager(t, apexRecords()...)
+	timers := SOATimers{Refresh: 3600, Retry: 600, Expire: 1209600, MinTtl: 60}
+
+	updated, err := m.UpdateSOATimers(context.Background(), testZone, testUserID, timers)
+	if err != nil {
+		t.Fatalf("UpdateSOATimers() error = %v", err)
+	}
+	if updated.SOA.Timers() != timers {
+		t.Errorf("updated timers = %+v, want %+v", updated.SOA.Timers(), timers)
+	}
+	// The nameserver and mailbox are kept
+	if updated.SOA.Ns != "ns1.example.net." || updated.SOA.MBox != "hostmaster.example.net." {
+		t.Errorf("updated SOA = %+v, want the nameserver and mailbox kept", updated.SOA)
+	}
+	if updated.Version != 2 || len(store.history) != 1 {
+		t.Errorf("updated version %d with %d history entries, want 2 with 1", updated.Version, len(store.history))
+	}
+
+	// Invalid timers are rejected before the zone is read
+	before := slices.Clone(store.storedRecords())
+	if _, err := m.UpdateSOATimers(context.Background(), testZone, testUserID, SOATimers{}); err == nil {
+		t.Fatalf("UpdateSOATimers() succeeded with unset timers")
+	}
+	if !slices.EqualFunc(store.storedRecords(), before, recordsEqual) {
+		t.Errorf("records changed by invalid timers")
+	}
+}
+
+func TestUpdateSOATimersWithoutSOA(t *testing.T) {
+	m, _ := newTestManager(t, nsRecord("", "ns1.example.net."))
+
+	_, err := m.UpdateSOATimers(context.Background(), testZone, testUserID, SOATimers{Refresh: 3600, Retry: 600, Expire: 1209600, MinTtl: 60})
+	if !errors.Is(err, ErrRRSetNotFound) {
+		t.Errorf("UpdateSOATimers() error = %v, want ErrRRSetNotFound", err)
+	}
+}
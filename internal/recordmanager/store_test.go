@@ -0,0 +1,330 @@
+package recordmanager
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// testUserID is the user owning the zones of tests
+var testUserID = uuid.MustParse("a89ecaee-799e-47f4-a483-3f14475e365d")
+
+// testZone is the zone of tests
+const testZone = "example.org."
+
+// errWriteFailed is returned by the test store for writes it is told to fail
+var errWriteFailed = errors.New("write failed")
+
+// testStore is an in-memory database of zones and records behind a mock
+// querier, standing in for Postgres in tests
+type testStore struct {
+	zones   []string
+	records []storage.CorednsRecord
+	nextID  int64
+	history []storage.CreateRecordHistoryParams
+	// failName makes writes of records with the owner name fail, unless it
+	// is empty
+	failName string
+}
+
+// newTestManager returns a record manager without transactions over an
+// in-memory store with the test zone and the records
+func newTestManager(t *testing.T, records ...*Record) (*RecordManager, *testStore) {
+	t.Helper()
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	store := &testStore{zones: []string{testZone}, nextID: 1}
+	for _, record := range records {
+		store.add(t, record)
+	}
+	store.expect(querier)
+	return NewWithQuerier(querier), store
+}
+
+// add stores the record, in the test zone unless it has a zone, and sets its
+// ID and version
+func (s *testStore) add(t *testing.T, record *Record) {
+	t.Helper()
+	if record.Zone == "" {
+		record.Zone = testZone
+	}
+	if !slices.Contains(s.zones, record.Zone) {
+		s.zones = append(s.zones, record.Zone)
+	}
+	content, err := ContentJSON(record)
+	if err != nil {
+		t.Fatalf("ContentJSON() error = %v", err)
+	}
+	record.ID = s.nextID
+	record.UserID = testUserID
+	record.Version = 1
+	s.nextID++
+	s.records = append(s.records, storage.CorednsRecord{
+		ID:         record.ID,
+		UserID:     testUserID,
+		Zone:       record.Zone,
+		Name:       record.Name,
+		Ttl:        record.Ttl,
+		Content:    json.RawMessage(content),
+		RecordType: record.RecordType,
+		Version:    record.Version,
+	})
+}
+
+// fails reports whether writes of records with the owner name fail
+func (s *testStore) fails(name string) bool {
+	return s.failName != "" && name == s.failName
+}
+
+// find returns the index of the stored record with the ID, or -1
+func (s *testStore) find(id int64, zone string) int {
+	return slices.IndexFunc(s.records, func(record storage.CorednsRecord) bool {
+		return record.ID == id && record.Zone == zone
+	})
+}
+
+// list returns the stored records matching the filter, ordered by zone, name,
+// type and ID
+func (s *testStore) list(match func(storage.CorednsRecord) bool) []storage.CorednsRecord {
+	var records []storage.CorednsRecord
+	for _, record := range s.records {
+		if match(record) {
+			records = append(records, record)
+		}
+	}
+	slices.SortStableFunc(records, func(a, b storage.CorednsRecord) int {
+		if c := strings.Compare(a.Zone, b.Zone); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.RecordType, b.RecordType); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	return records
+}
+
+// expect makes the querier read and write the store
+func (s *testStore) expect(querier *storage.MockQuerier) {
+	zone := func(name string) (storage.Zone, error) {
+		if !slices.Contains(s.zones, name) {
+			return storage.Zone{}, sql.ErrNoRows
+		}
+		return storage.Zone{Zone: name, UserID: testUserID, Status: ZoneStatusActive}, nil
+	}
+	querier.EXPECT().LockZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.LockZoneParams) (storage.Zone, error) {
+		return zone(arg.Zone)
+	}).AnyTimes()
+	querier.EXPECT().GetZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.GetZoneParams) (storage.Zone, error) {
+		return zone(arg.Zone)
+	}).AnyTimes()
+	querier.EXPECT().ListZones(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, uuid.UUID) ([]string, error) {
+		return slices.Sorted(slices.Values(s.zones)), nil
+	}).AnyTimes()
+	querier.EXPECT().ListRecordsByZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ListRecordsByZoneParams) ([]storage.CorednsRecord, error) {
+		return s.list(func(record storage.CorednsRecord) bool {
+			return record.Zone == arg.Zone && record.UserID == arg.UserID
+		}), nil
+	}).AnyTimes()
+	querier.EXPECT().ListRecordsByRRset(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ListRecordsByRRsetParams) ([]storage.CorednsRecord, error) {
+		return s.list(func(record storage.CorednsRecord) bool {
+			return record.Zone == arg.Zone && record.Name == arg.Name && record.RecordType == arg.RecordType
+		}), nil
+	}).AnyTimes()
+	querier.EXPECT().ListUserRecordsByType(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ListUserRecordsByTypeParams) ([]storage.CorednsRecord, error) {
+		return s.list(func(record storage.CorednsRecord) bool {
+			return record.UserID == arg.UserID && record.RecordType == arg.RecordType && (arg.Zone == "" || record.Zone == arg.Zone)
+		}), nil
+	}).AnyTimes()
+	querier.EXPECT().SearchRecords(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.SearchRecordsParams) ([]storage.SearchRecordsRow, error) {
+		return s.search(arg), nil
+	}).AnyTimes()
+	querier.EXPECT().GetRecordByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.GetRecordByIDParams) (storage.CorednsRecord, error) {
+		i := s.find(arg.ID, arg.Zone)
+		if i < 0 {
+			return storage.CorednsRecord{}, sql.ErrNoRows
+		}
+		return s.records[i], nil
+	}).AnyTimes()
+	querier.EXPECT().CreateRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateRecordParams) (storage.CorednsRecord, error) {
+		if s.fails(arg.Name) {
+			return storage.CorednsRecord{}, errWriteFailed
+		}
+		record := storage.CorednsRecord{
+			ID:         s.nextID,
+			UserID:     arg.UserID,
+			Zone:       arg.Zone,
+			Name:       arg.Name,
+			Ttl:        arg.Ttl,
+			Content:    arg.Content,
+			RecordType: arg.RecordType,
+			Version:    1,
+		}
+		s.nextID++
+		s.records = append(s.records, record)
+		return record, nil
+	}).AnyTimes()
+	querier.EXPECT().UpdateRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.UpdateRecordParams) (storage.CorednsRecord, error) {
+		if s.fails(arg.Name) {
+			return storage.CorednsRecord{}, errWriteFailed
+		}
+		i := s.find(arg.ID, arg.Zone)
+		if i < 0 {
+			return storage.CorednsRecord{}, sql.ErrNoRows
+		}
+		record := &s.records[i]
+		record.Name = arg.Name
+		record.Ttl = arg.Ttl
+		record.Content = arg.Content
+		record.RecordType = arg.RecordType
+		record.Version++
+		return *record, nil
+	}).AnyTimes()
+	querier.EXPECT().UpdateRRsetTTL(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.UpdateRRsetTTLParams) error {
+		for i := range s.records {
+			record := &s.records[i]
+			if record.Zone == arg.Zone && record.Name == arg.Name && record.RecordType == arg.RecordType && record.Ttl != arg.Ttl {
+				record.Ttl = arg.Ttl
+				record.Version++
+			}
+		}
+		return nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.DeleteRecordParams) error {
+		if i := s.find(arg.ID, arg.Zone); i >= 0 {
+			if s.fails(s.records[i].Name) {
+				return errWriteFailed
+			}
+			s.records = slices.Delete(s.records, i, i+1)
+		}
+		return nil
+	}).AnyTimes()
+	querier.EXPECT().CreateRecordHistory(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateRecordHistoryParams) error {
+		s.history = append(s.history, arg)
+		return nil
+	}).AnyTimes()
+}
+
+// search pages through the stored records as the SearchRecords query does,
+// filtering by owner name and type only
+func (s *testStore) search(arg storage.SearchRecordsParams) []storage.SearchRecordsRow {
+	var rows []storage.SearchRecordsRow
+	for _, record := range s.records {
+		if record.Zone != arg.Zone || record.UserID != arg.UserID ||
+			arg.Name != "" && record.Name != arg.Name ||
+			arg.RecordType != "" && record.RecordType != arg.RecordType {
+			continue
+		}
+		var key string
+		switch arg.Sort {
+		case "type":
+			key = record.RecordType + " " + record.Name
+		case "ttl":
+			key = fmt.Sprintf("%010d %s %s", record.Ttl.Int32, record.Name, record.RecordType)
+		case "id":
+		default:
+			key = record.Name + " " + record.RecordType
+		}
+		rows = append(rows, storage.SearchRecordsRow{
+			ID:         record.ID,
+			UserID:     record.UserID,
+			Zone:       record.Zone,
+			Name:       record.Name,
+			Ttl:        record.Ttl,
+			Content:    record.Content,
+			RecordType: record.RecordType,
+			Version:    record.Version,
+			SortKey:    key,
+		})
+	}
+
+	compare := func(a storage.SearchRecordsRow, key string, id int64) int {
+		if c := strings.Compare(a.SortKey, key); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, id)
+	}
+	if arg.CursorID != 0 {
+		rows = slices.DeleteFunc(rows, func(row storage.SearchRecordsRow) bool {
+			c := compare(row, arg.CursorKey, arg.CursorID)
+			return arg.Descending && c >= 0 || !arg.Descending && c <= 0
+		})
+	}
+	slices.SortFunc(rows, func(a, b storage.SearchRecordsRow) int {
+		if arg.Descending {
+			return compare(b, a.SortKey, a.ID)
+		}
+		return compare(a, b.SortKey, b.ID)
+	})
+	return rows[:min(len(rows), int(arg.PageSize))]
+}
+
+// ttl returns a record TTL
+func ttl(seconds int32) sql.NullInt32 {
+	return sql.NullInt32{Int32: seconds, Valid: true}
+}
+
+// aRecord returns an A record
+func aRecord(name, ip string, seconds int32) *Record {
+	return &Record{Name: name, RecordType: "A", Ttl: ttl(seconds), A: &AData{Ip: IPAddr{IP: net.ParseIP(ip)}}}
+}
+
+// txtRecord returns a TXT record
+func txtRecord(name, text string, seconds int32) *Record {
+	return &Record{Name: name, RecordType: "TXT", Ttl: ttl(seconds), TXT: &TXTData{Text: text}}
+}
+
+// cnameRecord returns a CNAME record
+func cnameRecord(name, host string) *Record {
+	return &Record{Name: name, RecordType: "CNAME", Ttl: ttl(300), CNAME: &CNAMEData{Host: host}}
+}
+
+// nsRecord returns an NS record
+func nsRecord(name, host string) *Record {
+	return &Record{Name: name, RecordType: "NS", Ttl: ttl(3600), NS: &NSData{Host: host}}
+}
+
+// soaRecord returns an SOA record with valid timers
+func soaRecord(name string) *Record {
+	return &Record{Name: name, RecordType: "SOA", Ttl: ttl(3600), SOA: &SOAData{
+		Ns:      "ns1.example.net.",
+		MBox:    "hostmaster.example.net.",
+		Refresh: 86400,
+		Retry:   7200,
+		Expire:  604800,
+		MinTtl:  300,
+	}}
+}
+
+// apexRecords returns the SOA and NS records a valid zone has at its apex
+func apexRecords() []*Record {
+	return []*Record{
+		soaRecord(""),
+		nsRecord("", "ns1.example.net."),
+		nsRecord("", "ns2.example.net."),
+	}
+}
+
+// storedRecords returns the stored records of the test zone
+func (s *testStore) storedRecords() []storage.CorednsRecord {
+	return s.list(func(record storage.CorednsRecord) bool { return record.Zone == testZone })
+}
+
+// recordsEqual reports whether two stored records are the same
+func recordsEqual(a, b storage.CorednsRecord) bool {
+	return a.ID == b.ID && a.Zone == b.Zone && a.Name == b.Name && a.RecordType == b.RecordType &&
+		a.Ttl == b.Ttl && string(a.Content) == string(b.Content) && a.Version == b.Version
+}
@@ -0,0 +1,260 @@
+package recordmanager
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ErrZoneChanged is returned when a plan is applied to a zone that has
+// changed since the plan was made
+var ErrZoneChanged = errors.New("zone has changed since the plan was made")
+
+// Plan is the set of changes converging a zone to a desired state
+type Plan struct {
+	Zone string
+	// Hash identifies the plan's changes and the state of the zone the plan
+	// was made against
+	Hash    string
+	Changes []PlannedChange
+}
+
+// PlannedChange is a change of a plan along with the existing record it
+// updates or deletes
+type PlannedChange struct {
+	Change
+	Current *Record
+}
+
+// PlanZoneSync plans the changes converging the zone to the desired records.
+// Existing records are matched to desired records by name, type and content.
+func (m *RecordManager) PlanZoneSync(ctx context.Context, zone string, userID uuid.UUID, desired []*Record) (*Plan, error) {
+	return m.planZone(ctx, zone, userID, desired, false)
+}
+
+// ApplyZoneSync converges the zone to the desired records in a single
+// transaction. The changes are only applied if they and the state of the zone
+// are still those of the plan with the hash, otherwise ErrZoneChanged is
+// returned.
+func (m *RecordManager) ApplyZoneSync(ctx context.Context, zone string, userID uuid.UUID, desired []*Record, hash string) (*Plan, []ChangeResult, error) {
+	return m.applyZonePlan(ctx, zone, userID, desired, hash, false)
+}
+
+// PlanZoneMerge plans the changes converging the RRsets of the desired
+// records, such as those of a zone template, to the desired records. The
+// zone's other RRsets are kept.
+func (m *RecordManager) PlanZoneMerge(ctx context.Context, zone string, userID uuid.UUID, desired []*Record) (*Plan, error) {
+	return m.planZone(ctx, zone, userID, desired, true)
+}
+
+// ApplyZoneMerge converges the RRsets of the desired records to the desired
+// records in a single transaction, keeping the zone's other RRsets. The
+// changes are only applied if they and the state of the zone are still those
+// of the plan with the hash, otherwise ErrZoneChanged is returned.
+func (m *RecordManager) ApplyZoneMerge(ctx context.Context, zone string, userID uuid.UUID, desired []*Record, hash string) (*Plan, []ChangeResult, error) {
+	return m.applyZonePlan(ctx, zone, userID, desired, hash, true)
+}
+
+// planZone plans the changes of a zone sync, or merge, against the zone's
+// current records
+func (m *RecordManager) planZone(ctx context.Context, zone string, userID uuid.UUID, desired []*Record, merge bool) (*Plan, error) {
+	zone, err := CanonicalZone(zone)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.GetZone(ctx, zone, userID); err != nil {
+		return nil, err
+	}
+
+	records, err := m.listRecords(ctx, m.querier, zone, userID)
+	if err != nil {
+		return nil, err
+	}
+	return planZoneSync(zone, userID, records, desired, merge)
+}
+
+// applyZonePlan applies the changes of a zone sync, or merge, if the plan
+// still has the hash
+func (m *RecordManager) applyZonePlan(ctx context.Context, zone string, userID uuid.UUID, desired []*Record, hash string, merge bool) (*Plan, []ChangeResult, error) {
+	var plan *Plan
+	var results []ChangeResult
+	err := m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, records []*Record) error {
+		var err error
+		plan, err = planZoneSync(zone, userID, records, desired, merge)
+		if err != nil {
+			return err
+		}
+		if plan.Hash != hash {
+			return ErrZoneChanged
+		}
+
+		changes := make([]Change, len(plan.Changes))
+		for i, change := range plan.Changes {
+			changes[i] = change.Change
+		}
+		results, err = m.applyChanges(ctx, querier, zone, userID, records, changes)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, results, nil
+}
+
+// planZoneSync computes the changes converging the records to the desired
+// records, RRset by RRset. The zone's SOA and apex NS records are managed
+// by TofuDNS, so they are kept if the desired records have none. A merge
+// keeps all RRsets the desired records have none of.
+func planZoneSync(zone string, userID uuid.UUID, records, desired []*Record, merge bool) (*Plan, error) {
+	var keys []rrsetKey
+	desiredSets := make(map[rrsetKey][]*Record)
+	for i, record := range desired {
+		record.Zone = zone
+		record.UserID = userID
+		if err := canonicalizeRecord(record); err != nil {
+			return nil, &ChangeError{Index: i, Err: err}
+		}
+
+		key := rrsetKey{name: record.Name, recordType: record.RecordType}
+		if _, ok := desiredSets[key]; !ok {
+			keys = append(keys, key)
+		}
+		desiredSets[key] = append(desiredSets[key], record)
+	}
+
+	existingSets := make(map[rrsetKey][]*Record)
+	for _, record := range records {
+		key := rrsetKey{name: record.Name, recordType: record.RecordType}
+		if _, ok := existingSets[key]; !ok && desiredSets[key] == nil {
+			keys = append(keys, key)
+		}
+		existingSets[key] = append(existingSets[key], record)
+	}
+
+	var deletes, updates, creates []PlannedChange
+	for _, key := range keys {
+		wanted := desiredSets[key]
+		if wanted == nil && (merge || key.name == "" && (key.recordType == "SOA" || key.recordType == "NS")) {
+			continue
+		}
+
+		rrsetDeletes, rrsetUpdates, rrsetCreates, err := planRRsetChanges(existingSets[key], wanted)
+		if err != nil {
+			return nil, err
+		}
+		deletes = append(deletes, rrsetDeletes...)
+		updates = append(updates, rrsetUpdates...)
+		creates = append(creates, rrsetCreates...)
+	}
+
+	changes := append(append(deletes, updates...), creates...)
+	return &Plan{
+		Zone:    zone,
+		Hash:    planHash(records, changes),
+		Changes: changes,
+	}, nil
+}
+
+// planRRsetChanges plans the changes converging the existing records of an
+// RRset to the wanted records. Records whose content is unchanged are kept,
+// updating the TTL if it differs, remaining records are updated in place, and
+// the rest are deleted or created.
+func planRRsetChanges(existing, wanted []*Record) (deletes, updates, creates []PlannedChange, err error) {
+	existing = slices.Clone(existing)
+
+	// Keep records whose content is unchanged
+	var unmatched []*Record
+	for _, record := range wanted {
+		content, err := ContentJSON(record)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		i := slices.IndexFunc(existing, func(current *Record) bool {
+			currentContent, err := ContentJSON(current)
+			return err == nil && currentContent == content
+		})
+		if i < 0 {
+			unmatched = append(unmatched, record)
+			continue
+		}
+
+		current := existing[i]
+		existing = slices.Delete(existing, i, i+1)
+		if current.Ttl != record.Ttl {
+			updates = append(updates, plannedUpdate(current, record))
+		}
+	}
+
+	// Update the remaining existing records in place, then delete or create
+	// the rest
+	for len(existing) > 0 && len(unmatched) > 0 {
+		updates = append(updates, plannedUpdate(existing[0], unmatched[0]))
+		existing, unmatched = existing[1:], unmatched[1:]
+	}
+	for _, current := range existing {
+		deletes = append(deletes, PlannedChange{
+			Change: Change{
+				Action:  ChangeDelete,
+				ID:      current.ID,
+				Version: current.Version,
+			},
+			Current: current,
+		})
+	}
+	for _, record := range unmatched {
+		creates = append(creates, PlannedChange{
+			Change: Change{
+				Action: ChangeCreate,
+				Record: record,
+			},
+		})
+	}
+	return deletes, updates, creates, nil
+}
+
+// plannedUpdate returns the change updating the current record to the record
+func plannedUpdate(current, record *Record) PlannedChange {
+	updated := *record
+	updated.ID = current.ID
+	return PlannedChange{
+		Change: Change{
+			Action:  ChangeUpdate,
+			ID:      current.ID,
+			Version: current.Version,
+			Record:  &updated,
+		},
+		Current: current,
+	}
+}
+
+// planHash returns a hash identifying the changes and the state of the
+// zone's records. Every change of a record increments its version, so the IDs
+// and versions of the records identify the state.
+func planHash(records []*Record, changes []PlannedChange) string {
+	sorted := slices.Clone(records)
+	slices.SortFunc(sorted, func(a, b *Record) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	hash := sha256.New()
+	for _, record := range sorted {
+		fmt.Fprintf(hash, "%d:%d\n", record.ID, record.Version)
+	}
+	for _, change := range changes {
+		fmt.Fprintf(hash, "%s:%d", change.Action, change.ID)
+		if change.Record != nil {
+			content, _ := ContentJSON(change.Record)
+			fmt.Fprintf(hash, ":%s:%s:%d:%s", change.Record.Name, change.Record.RecordType, change.Record.Ttl.Int32, content)
+		}
+		fmt.Fprintln(hash)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
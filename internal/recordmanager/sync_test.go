@@ -0,0 +1,185 @@
+package recordmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// describeChanges describes the planned changes as action, owner name, type,
+// TTL and content
+func describeChanges(t *testing.T, changes []PlannedChange) []string {
+	t.Helper()
+	var described []string
+	for _, change := range changes {
+		record := change.Record
+		if record == nil {
+			record = change.Current
+		}
+		content, err := ContentJSON(record)
+		if err != nil {
+			t.Fatalf("ContentJSON() error = %v", err)
+		}
+		described = append(described, fmt.Sprintf("%s %s %s %d %s", change.Action, displayName(record.Name), record.RecordType, record.Ttl.Int32, content))
+	}
+	return described
+}
+
+func TestPlanZoneSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired []*Record
+		merge   bool
+		want    []string
+	}{
+		{
+			name:    "unchanged",
+			desired: []*Record{aRecord("www", "192.0.2.1", 300), txtRecord("www", "hello", 300)},
+		},
+		{
+			name:    "SOA and apex NS kept",
+			desired: []*Record{aRecord("www", "192.0.2.1", 300)},
+			want:    []string{`delete www TXT 300 {"text":"hello"}`},
+		},
+		{
+			name:    "RRsets merged",
+			desired: []*Record{aRecord("mail", "192.0.2.5", 300)},
+			merge:   true,
+			want:    []string{`create mail A 300 {"ip":"192.0.2.5"}`},
+		},
+		{
+			name:    "merged RRset converged",
+			desired: []*Record{aRecord("www", "192.0.2.2", 300)},
+			merge:   true,
+			want:    []string{`update www A 300 {"ip":"192.0.2.2"}`},
+		},
+		{
+			name: "TTL changed",
+			desired: []*Record{
+				aRecord("www", "192.0.2.1", 600),
+				txtRecord("www", "hello", 300),
+			},
+			want: []string{`update www A 600 {"ip":"192.0.2.1"}`},
+		},
+		{
+			name: "records added and removed",
+			desired: []*Record{
+				aRecord("www", "192.0.2.1", 300),
+				aRecord("www", "192.0.2.2", 300),
+				aRecord("WWW", "192.0.2.3", 300),
+				aRecord("mail.example.org.", "192.0.2.5", 300),
+			},
+			want: []string{
+				`delete www TXT 300 {"text":"hello"}`,
+				`create www A 300 {"ip":"192.0.2.2"}`,
+				`create www A 300 {"ip":"192.0.2.3"}`,
+				`create mail A 300 {"ip":"192.0.2.5"}`,
+			},
+		},
+		{
+			name: "apex NS managed",
+			desired: []*Record{
+				nsRecord("@", "ns1.example.net."),
+				nsRecord("@", "ns3.example.net."),
+				aRecord("www", "192.0.2.1", 300),
+				txtRecord("www", "hello", 300),
+			},
+			want: []string{`update @ NS 3600 {"host":"ns3.example.net."}`},
+		},
+		{
+			name:    "desired records replaced by the sync",
+			desired: []*Record{cnameRecord("www", "www.example.net")},
+			want: []string{
+				`delete www A 300 {"ip":"192.0.2.1"}`,
+				`delete www TXT 300 {"text":"hello"}`,
+				`create www CNAME 300 {"host":"www.example.net."}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := append(apexRecords(), aRecord("www", "192.0.2.1", 300), txtRecord("www", "hello", 300))
+			m, _ := newTestManager(t, records...)
+
+			plan, err := m.planZone(context.Background(), testZone, testUserID, tt.desired, tt.merge)
+			if err != nil {
+				t.Fatalf("planZone() error = %v", err)
+			}
+			if got := describeChanges(t, plan.Changes); !slices.Equal(got, tt.want) {
+				t.Errorf("planned changes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanZoneSyncInvalidRecord(t *testing.T) {
+	m, _ := newTestManager(t, apexRecords()...)
+
+	_, err := m.PlanZoneSync(context.Background(), testZone, testUserID, []*Record{
+		aRecord("www", "192.0.2.1", 300),
+		aRecord("www.example.net.", "192.0.2.2", 300),
+	})
+	var changeErr *ChangeError
+	if !errors.As(err, &changeErr) || changeErr.Index != 1 {
+		t.Fatalf("PlanZoneSync() error = %v, want a change error for record 1", err)
+	}
+}
+
+func TestApplyZoneSync(t *testing.T) {
+	records := append(apexRecords(), aRecord("www", "192.0.2.1", 300))
+	m, store := newTestManager(t, records...)
+	desired := func() []*Record {
+		return []*Record{aRecord("www", "192.0.2.2", 300), txtRecord("www", "hello", 300)}
+	}
+
+	plan, err := m.PlanZoneSync(context.Background(), testZone, testUserID, desired())
+	if err != nil {
+		t.Fatalf("PlanZoneSync() error = %v", err)
+	}
+	again, err := m.PlanZoneSync(context.Background(), testZone, testUserID, desired())
+	if err != nil {
+		t.Fatalf("PlanZoneSync() error = %v", err)
+	}
+	if again.Hash != plan.Hash {
+		t.Errorf("plan hash = %s, then %s, want it stable", plan.Hash, again.Hash)
+	}
+	merged, err := m.PlanZoneMerge(context.Background(), testZone, testUserID, []*Record{aRecord("www", "192.0.2.3", 300)})
+	if err != nil {
+		t.Fatalf("PlanZoneMerge() error = %v", err)
+	}
+	if merged.Hash == plan.Hash {
+		t.Errorf("plans with different changes have the same hash")
+	}
+
+	// A change of the zone since the plan invalidates it
+	store.records[3].Version++
+	if _, _, err := m.ApplyZoneSync(context.Background(), testZone, testUserID, desired(), plan.Hash); !errors.Is(err, ErrZoneChanged) {
+		t.Fatalf("ApplyZoneSync() error = %v, want ErrZoneChanged", err)
+	}
+	if len(store.history) > 0 {
+		t.Errorf("history written by a plan of a changed zone")
+	}
+
+	plan, err = m.PlanZoneSync(context.Background(), testZone, testUserID, desired())
+	if err != nil {
+		t.Fatalf("PlanZoneSync() error = %v", err)
+	}
+	applied, results, err := m.ApplyZoneSync(context.Background(), testZone, testUserID, desired(), plan.Hash)
+	if err != nil {
+		t.Fatalf("ApplyZoneSync() error = %v", err)
+	}
+	if applied.Hash != plan.Hash || len(results) != len(plan.Changes) {
+		t.Errorf("applied plan %s with %d results, want %s with %d", applied.Hash, len(results), plan.Hash, len(plan.Changes))
+	}
+
+	// The zone has converged
+	plan, err = m.PlanZoneSync(context.Background(), testZone, testUserID, desired())
+	if err != nil {
+		t.Fatalf("PlanZoneSync() error = %v", err)
+	}
+	if len(plan.Changes) > 0 {
+		t.Errorf("planned changes after the sync = %q, want none", describeChanges(t, plan.Changes))
+	}
+}
@@ -0,0 +1,164 @@
+package recordmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ZonePlaceholder stands for the zone's name, without the trailing dot, in
+// the content of a zone template
+const ZonePlaceholder = "{{zone}}"
+
+var (
+	// ErrTemplateNotFound is returned when the user has no such zone template
+	ErrTemplateNotFound = errors.New("zone template not found")
+	// ErrTemplateExists is returned when the user already has a zone template
+	// with the name
+	ErrTemplateExists = errors.New("zone template already exists")
+)
+
+// ZoneTemplate is a user's template of the records zones start with. Its
+// content is a zone file, relative to the zone, in which ZonePlaceholder
+// stands for the zone's name.
+type ZoneTemplate struct {
+	ID        int32
+	Name      string
+	Content   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Expand returns the template's content with its placeholders substituted
+// for the zone
+func (t *ZoneTemplate) Expand(zone string) string {
+	return strings.ReplaceAll(t.Content, ZonePlaceholder, strings.TrimSuffix(zone, "."))
+}
+
+// ListZoneTemplates lists the user's zone templates by name
+func (m *RecordManager) ListZoneTemplates(ctx context.Context, userID uuid.UUID) ([]*ZoneTemplate, error) {
+	templates, err := m.querier.ListZoneTemplates(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zone templates: %w", err)
+	}
+
+	result := make([]*ZoneTemplate, len(templates))
+	for i := range templates {
+		result[i] = storageToZoneTemplate(&templates[i])
+	}
+	return result, nil
+}
+
+// GetZoneTemplate returns one of the user's zone templates
+func (m *RecordManager) GetZoneTemplate(ctx context.Context, id int32, userID uuid.UUID) (*ZoneTemplate, error) {
+	template, err := m.querier.GetZoneTemplate(ctx, storage.GetZoneTemplateParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone template: %w", err)
+	}
+	return storageToZoneTemplate(&template), nil
+}
+
+// CreateZoneTemplate creates a zone template for the user. The content is
+// stored as given, it is parsed when the template is applied.
+func (m *RecordManager) CreateZoneTemplate(ctx context.Context, userID uuid.UUID, name, content string) (*ZoneTemplate, error) {
+	name, err := m.checkTemplateName(ctx, userID, 0, name)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := m.querier.CreateZoneTemplate(ctx, storage.CreateZoneTemplateParams{
+		UserID:  userID,
+		Name:    name,
+		Content: content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zone template: %w", err)
+	}
+	return storageToZoneTemplate(&template), nil
+}
+
+// UpdateZoneTemplate renames a zone template and replaces its content
+func (m *RecordManager) UpdateZoneTemplate(ctx context.Context, id int32, userID uuid.UUID, name, content string) (*ZoneTemplate, error) {
+	name, err := m.checkTemplateName(ctx, userID, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := m.querier.UpdateZoneTemplate(ctx, storage.UpdateZoneTemplateParams{
+		ID:      id,
+		UserID:  userID,
+		Name:    name,
+		Content: content,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update zone template: %w", err)
+	}
+	return storageToZoneTemplate(&template), nil
+}
+
+// DeleteZoneTemplate deletes one of the user's zone templates. Zones created
+// from it keep their records.
+func (m *RecordManager) DeleteZoneTemplate(ctx context.Context, id int32, userID uuid.UUID) error {
+	deleted, err := m.querier.DeleteZoneTemplate(ctx, storage.DeleteZoneTemplateParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete zone template: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// checkTemplateName validates the name of a zone template and returns it
+// trimmed. No other template of the user, than the one with the ID, may have
+// the name.
+func (m *RecordManager) checkTemplateName(ctx context.Context, userID uuid.UUID, id int32, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if len(name) > 255 {
+		return "", &ValidationError{Field: "name", Message: "name must be at most 255 characters"}
+	}
+
+	existing, err := m.querier.GetZoneTemplateByName(ctx, storage.GetZoneTemplateByNameParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err == nil && existing.ID != id {
+		return "", ErrTemplateExists
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get zone template: %w", err)
+	}
+	return name, nil
+}
+
+// storageToZoneTemplate converts a storage.ZoneTemplate to a ZoneTemplate
+func storageToZoneTemplate(dbTemplate *storage.ZoneTemplate) *ZoneTemplate {
+	return &ZoneTemplate{
+		ID:        dbTemplate.ID,
+		Name:      dbTemplate.Name,
+		Content:   dbTemplate.Content,
+		CreatedAt: dbTemplate.CreatedAt,
+		UpdatedAt: dbTemplate.UpdatedAt,
+	}
+}
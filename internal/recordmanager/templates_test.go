@@ -0,0 +1,57 @@
+package recordmanager
+
+import "testing"
+
+func TestZoneTemplateExpand(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		zone    string
+		want    string
+	}{
+		{
+			name:    "owner name and content",
+			content: "www 300 IN CNAME {{zone}}.\n@ 300 IN MX 10 mail.{{zone}}.\n",
+			zone:    "example.org.",
+			want:    "www 300 IN CNAME example.org.\n@ 300 IN MX 10 mail.example.org.\n",
+		},
+		{
+			name:    "zone without a trailing dot",
+			content: "@ 300 IN TXT \"v=spf1 include:_spf.{{zone}} -all\"",
+			zone:    "example.org",
+			want:    "@ 300 IN TXT \"v=spf1 include:_spf.example.org -all\"",
+		},
+		{
+			name:    "several placeholders on a line",
+			content: "_dmarc 300 IN TXT \"v=DMARC1; rua=mailto:dmarc@{{zone}}; ruf=mailto:dmarc@{{zone}}\"",
+			zone:    "example.org.",
+			want:    "_dmarc 300 IN TXT \"v=DMARC1; rua=mailto:dmarc@example.org; ruf=mailto:dmarc@example.org\"",
+		},
+		{
+			name:    "internationalized zone",
+			content: "www 300 IN CNAME {{zone}}.",
+			zone:    "xn--mnchen-3ya.de.",
+			want:    "www 300 IN CNAME xn--mnchen-3ya.de.",
+		},
+		{
+			name:    "no placeholders",
+			content: "www 300 IN A 192.0.2.1",
+			zone:    "example.org.",
+			want:    "www 300 IN A 192.0.2.1",
+		},
+		{
+			name:    "other braces kept",
+			content: "www 300 IN TXT \"{{ zone }} {{Zone}} {zone}\"",
+			zone:    "example.org.",
+			want:    "www 300 IN TXT \"{{ zone }} {{Zone}} {zone}\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &ZoneTemplate{Name: "test", Content: tt.content}
+			if got := template.Expand(tt.zone); got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package recordmanager
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net"
 
 	"github.com/google/uuid"
@@ -15,7 +16,16 @@ type Record struct {
 	Name       string
 	RecordType string
 	Ttl        sql.NullInt32
-	Content    sql.NullString
+	// Content is the stored JSON content, set on records read from the
+	// database
+	Content json.RawMessage
+	// Version is incremented on every change of the record
+	Version int32
+	// ContentErr is set on records read from the database whose content
+	// can't be decoded, leaving the type specific data nil. Such records are
+	// still listed, so one corrupt record can be found and fixed or deleted
+	// rather than failing its whole zone.
+	ContentErr error
 
 	// Type specific data
 	A     *AData
@@ -29,6 +39,11 @@ type Record struct {
 	CAA   *CAAData
 }
 
+// UnicodeName returns the record's owner name in its Unicode form
+func (r *Record) UnicodeName() string {
+	return UnicodeName(r.Name)
+}
+
 // IPAddr wraps net.IP to provide custom JSON marshaling
 type IPAddr struct {
 	net.IP
@@ -53,6 +68,9 @@ func (ip *IPAddr) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	ip.IP = net.ParseIP(s)
+	if ip.IP == nil {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
 	return nil
 }
 
@@ -0,0 +1,204 @@
+package recordmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// Zone statuses
+const (
+	// ZoneStatusPending zones are claimed but not served until verified
+	ZoneStatusPending = "pending"
+	// ZoneStatusActive zones are verified and served
+	ZoneStatusActive = "active"
+)
+
+var (
+	// ErrZoneNotFound is returned when the user has no such zone
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrZoneExists is returned when the user has already claimed the zone
+	ErrZoneExists = errors.New("zone already exists")
+	// ErrZoneTaken is returned when another user owns the active zone
+	ErrZoneTaken = errors.New("zone is already in use")
+)
+
+// Zone is a zone claimed by a user
+type Zone struct {
+	Name               string
+	UserID             uuid.UUID
+	Status             string
+	VerificationToken  string
+	VerificationMethod string
+	VerifiedAt         sql.NullTime
+	LastCheckedAt      sql.NullTime
+	LastCheckError     string
+	CreatedAt          time.Time
+}
+
+// Pending reports whether the zone still awaits verification
+func (z *Zone) Pending() bool {
+	return z.Status == ZoneStatusPending
+}
+
+// UnicodeName returns the zone name in its Unicode form
+func (z *Zone) UnicodeName() string {
+	return UnicodeName(z.Name)
+}
+
+// CreateZone claims a zone for the user. Unless active is set, the zone is
+// pending until its ownership has been verified. The zone is created with
+// the records, and the records of the template are then merged into them,
+// replacing the RRsets they have, all in one transaction: either the zone is
+// created with all its records, or not at all.
+func (m *RecordManager) CreateZone(ctx context.Context, zone string, userID uuid.UUID, active bool, records, template []*Record) (*Zone, error) {
+	zone, err := CanonicalZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	status := ZoneStatusPending
+	if active {
+		status = ZoneStatusActive
+	}
+
+	var created *Zone
+	err = m.inTx(ctx, func(querier storage.Querier) error {
+		if existing, err := querier.GetActiveZone(ctx, zone); err == nil {
+			if existing.UserID != userID {
+				return ErrZoneTaken
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get active zone: %w", err)
+		}
+
+		_, err := querier.GetZone(ctx, storage.GetZoneParams{Zone: zone, UserID: userID})
+		if err == nil {
+			return ErrZoneExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get zone: %w", err)
+		}
+
+		dbZone, err := querier.CreateZone(ctx, storage.CreateZoneParams{
+			Zone:              zone,
+			UserID:            userID,
+			Status:            status,
+			VerificationToken: hex.EncodeToString(tokenBytes),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create zone: %w", err)
+		}
+		created = storageToZone(&dbZone)
+
+		changes := make([]Change, len(records))
+		for i, record := range records {
+			changes[i] = Change{Action: ChangeCreate, Record: record}
+		}
+		results, err := m.applyChanges(ctx, querier, zone, userID, nil, changes)
+		if err != nil {
+			return fmt.Errorf("failed to create zone records: %w", err)
+		}
+		if len(template) == 0 {
+			return nil
+		}
+
+		current := make([]*Record, 0, len(results))
+		for _, result := range results {
+			current = append(current, result.Record)
+		}
+		plan, err := planZoneSync(zone, userID, current, template, true)
+		if err != nil {
+			return fmt.Errorf("failed to apply zone template: %w", err)
+		}
+		changes = make([]Change, len(plan.Changes))
+		for i, change := range plan.Changes {
+			changes[i] = change.Change
+		}
+		if _, err := m.applyChanges(ctx, querier, zone, userID, current, changes); err != nil {
+			return fmt.Errorf("failed to apply zone template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteZone deletes one of the user's zones along with its records
+func (m *RecordManager) DeleteZone(ctx context.Context, zone string, userID uuid.UUID) error {
+	return m.inZoneTx(ctx, zone, userID, func(querier storage.Querier, zone string, _ []*Record) error {
+		params := storage.DeleteRecordsByZoneParams{Zone: zone, UserID: userID}
+		if err := querier.DeleteRecordsByZone(ctx, params); err != nil {
+			return fmt.Errorf("failed to delete zone records: %w", err)
+		}
+		invalidParams := storage.DeleteInvalidRecordsByZoneParams{Zone: zone, UserID: userID}
+		if err := querier.DeleteInvalidRecordsByZone(ctx, invalidParams); err != nil {
+			return fmt.Errorf("failed to delete invalid zone records: %w", err)
+		}
+		err := querier.DeleteZone(ctx, storage.DeleteZoneParams{Zone: zone, UserID: userID})
+		if err != nil {
+			return fmt.Errorf("failed to delete zone: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetZone retrieves one of the user's zones
+func (m *RecordManager) GetZone(ctx context.Context, zone string, userID uuid.UUID) (*Zone, error) {
+	dbZone, err := m.querier.GetZone(ctx, storage.GetZoneParams{
+		Zone:   lookupZone(zone),
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrZoneNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone: %w", err)
+	}
+
+	return storageToZone(&dbZone), nil
+}
+
+// ListUserZones lists the user's zones with their verification state
+func (m *RecordManager) ListUserZones(ctx context.Context, userID uuid.UUID) ([]*Zone, error) {
+	zones, err := m.querier.ListZonesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	result := make([]*Zone, len(zones))
+	for i := range zones {
+		result[i] = storageToZone(&zones[i])
+	}
+
+	return result, nil
+}
+
+// storageToZone converts a storage.Zone to a Zone
+func storageToZone(dbZone *storage.Zone) *Zone {
+	return &Zone{
+		Name:               dbZone.Zone,
+		UserID:             dbZone.UserID,
+		Status:             dbZone.Status,
+		VerificationToken:  dbZone.VerificationToken,
+		VerificationMethod: dbZone.VerificationMethod.String,
+		VerifiedAt:         dbZone.VerifiedAt,
+		LastCheckedAt:      dbZone.LastCheckedAt,
+		LastCheckError:     dbZone.LastCheckError.String,
+		CreatedAt:          dbZone.CreatedAt,
+	}
+}
@@ -0,0 +1,5 @@
+-- Drop recovery codes table
+DROP TABLE IF EXISTS recovery_codes;
+
+-- Drop TOTP credentials table
+DROP TABLE IF EXISTS totp_credentials;
@@ -0,0 +1,22 @@
+-- Create TOTP credentials table
+CREATE TABLE totp_credentials (
+    user_id UUID PRIMARY KEY,
+    secret VARCHAR(255) NOT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create recovery codes table
+CREATE TABLE recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Add index for recovery code lookups by user
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes(user_id);
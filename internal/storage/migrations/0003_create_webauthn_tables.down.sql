@@ -0,0 +1,5 @@
+-- Drop WebAuthn ceremony sessions table
+DROP TABLE IF EXISTS webauthn_sessions;
+
+-- Drop WebAuthn credentials table
+DROP TABLE IF EXISTS webauthn_credentials;
@@ -0,0 +1,22 @@
+-- Create WebAuthn credentials table
+CREATE TABLE webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    credential_id BYTEA UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    credential TEXT NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Add index for credential lookups by user
+CREATE INDEX idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+
+-- Create WebAuthn ceremony sessions table
+CREATE TABLE webauthn_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_data TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
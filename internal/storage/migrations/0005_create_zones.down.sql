@@ -0,0 +1,4 @@
+-- Drop the served records view and zones table
+DROP VIEW IF EXISTS coredns.coredns_records;
+DROP SCHEMA IF EXISTS coredns;
+DROP TABLE IF EXISTS zones;
@@ -0,0 +1,34 @@
+-- Create zones table. A zone is claimed by a user in the pending state and
+-- only becomes active, and served, once its ownership has been verified.
+CREATE TABLE zones (
+    zone VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active')),
+    verification_token VARCHAR(64) NOT NULL,
+    verification_method VARCHAR(16),
+    verified_at TIMESTAMPTZ,
+    last_checked_at TIMESTAMPTZ,
+    last_check_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (zone, user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Only one user can own an active zone
+CREATE UNIQUE INDEX idx_zones_active_zone ON zones(zone) WHERE status = 'active';
+CREATE INDEX idx_zones_status ON zones(status);
+
+-- Existing zones were already being served, so keep them active
+INSERT INTO zones (zone, user_id, status, verification_token, verification_method, verified_at)
+SELECT DISTINCT ON (zone) zone, user_id, 'active', md5(random()::text), 'existing', NOW()
+FROM coredns_records
+ORDER BY zone, user_id;
+
+-- CoreDNS reads coredns_records from the coredns schema (via search_path in
+-- its datasource), which only exposes records of active zones
+CREATE SCHEMA coredns;
+CREATE VIEW coredns.coredns_records AS
+SELECT r.*
+FROM public.coredns_records r
+JOIN public.zones z ON z.zone = r.zone AND z.user_id = r.user_id
+WHERE z.status = 'active';
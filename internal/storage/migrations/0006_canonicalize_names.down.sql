@@ -0,0 +1,2 @@
+-- Canonicalized names can't be restored to their original form
+SELECT 1;
@@ -0,0 +1,34 @@
+-- Zone and record names are now canonicalized on write: zones are lowercase
+-- with a trailing dot and record names are lowercase, relative to the zone
+-- and empty for the apex. Bring existing data into the same form.
+
+-- Drop zone claims that collide with another claim of the same user once
+-- canonicalized, preferring active claims
+DELETE FROM zones z
+USING zones o
+WHERE z.user_id = o.user_id
+  AND z.zone <> o.zone
+  AND lower(rtrim(z.zone, '.')) = lower(rtrim(o.zone, '.'))
+  AND (z.status, z.zone) > (o.status, o.zone);
+
+-- Only one active claim per zone can remain, keep the earliest verified
+UPDATE zones z
+SET status = 'pending'
+FROM zones o
+WHERE z.status = 'active'
+  AND o.status = 'active'
+  AND z.user_id <> o.user_id
+  AND lower(rtrim(z.zone, '.')) = lower(rtrim(o.zone, '.'))
+  AND (z.verified_at, z.user_id) > (o.verified_at, o.user_id);
+
+UPDATE zones
+SET zone = lower(rtrim(zone, '.')) || '.'
+WHERE zone <> lower(rtrim(zone, '.')) || '.';
+
+UPDATE coredns_records
+SET zone = lower(rtrim(zone, '.')) || '.'
+WHERE zone <> lower(rtrim(zone, '.')) || '.';
+
+UPDATE coredns_records
+SET name = CASE WHEN name = '@' THEN '' ELSE lower(rtrim(name, '.')) END
+WHERE name <> CASE WHEN name = '@' THEN '' ELSE lower(rtrim(name, '.')) END;
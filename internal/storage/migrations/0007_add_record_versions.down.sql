@@ -0,0 +1,2 @@
+-- Drop record versions
+ALTER TABLE coredns_records DROP COLUMN IF EXISTS version;
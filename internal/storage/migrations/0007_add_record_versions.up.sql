@@ -0,0 +1,3 @@
+-- Records carry a version that is incremented on every change, so concurrent
+-- edits can be detected
+ALTER TABLE coredns_records ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Drop API tokens table
+DROP TABLE IF EXISTS api_tokens;
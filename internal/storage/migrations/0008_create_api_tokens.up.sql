@@ -0,0 +1,15 @@
+-- Create API tokens table. Only the SHA-256 hash of a token is stored, the
+-- token itself is shown once when it is created.
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Add index for token lookups by user
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
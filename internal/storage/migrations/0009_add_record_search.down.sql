@@ -0,0 +1,4 @@
+-- Drop the record search indexes
+DROP INDEX IF EXISTS idx_coredns_records_content_trgm;
+DROP INDEX IF EXISTS idx_coredns_records_name_trgm;
+DROP FUNCTION IF EXISTS record_search_text(TEXT);
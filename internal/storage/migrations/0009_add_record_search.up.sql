@@ -0,0 +1,20 @@
+-- Records are searched across zones by a substring of their owner name,
+-- relative name and zone joined by a dot, or of the values of their content,
+-- such as an IP address or a hostname. Trigram indexes let such searches use
+-- an index.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- record_search_text returns the values of a record's content JSON separated
+-- by spaces, leaving out the keys so searching for "ip" doesn't match every
+-- A record. Content that isn't a JSON object is searched as it is.
+CREATE FUNCTION record_search_text(content TEXT) RETURNS TEXT
+LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE AS $$
+BEGIN
+    RETURN (SELECT string_agg(value, ' ') FROM jsonb_each_text(content::jsonb));
+EXCEPTION WHEN others THEN
+    RETURN content;
+END;
+$$;
+
+CREATE INDEX idx_coredns_records_name_trgm ON coredns_records USING GIN ((name || '.' || zone) gin_trgm_ops);
+CREATE INDEX idx_coredns_records_content_trgm ON coredns_records USING GIN (record_search_text(content) gin_trgm_ops);
@@ -0,0 +1,40 @@
+-- Store record content as text again, restoring the rows moved aside
+DROP VIEW coredns.coredns_records;
+DROP INDEX idx_coredns_records_content_trgm;
+DROP FUNCTION record_search_text(JSONB);
+
+ALTER TABLE coredns_records
+    DROP CONSTRAINT coredns_records_content_object,
+    DROP CONSTRAINT coredns_records_a_content,
+    DROP CONSTRAINT coredns_records_aaaa_content,
+    DROP CONSTRAINT coredns_records_txt_content,
+    DROP CONSTRAINT coredns_records_cname_content,
+    DROP CONSTRAINT coredns_records_ns_content,
+    DROP CONSTRAINT coredns_records_mx_content,
+    DROP CONSTRAINT coredns_records_srv_content,
+    DROP CONSTRAINT coredns_records_soa_content,
+    DROP CONSTRAINT coredns_records_caa_content,
+    ALTER COLUMN content DROP NOT NULL,
+    ALTER COLUMN content TYPE TEXT USING content::text;
+
+INSERT INTO coredns_records (id, user_id, zone, name, ttl, content, record_type, version)
+SELECT id, user_id, zone, name, ttl, content, record_type, version
+FROM coredns_records_invalid;
+DROP TABLE coredns_records_invalid;
+
+CREATE FUNCTION record_search_text(content TEXT) RETURNS TEXT
+LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE AS $$
+BEGIN
+    RETURN (SELECT string_agg(value, ' ') FROM jsonb_each_text(content::jsonb));
+EXCEPTION WHEN others THEN
+    RETURN content;
+END;
+$$;
+
+CREATE INDEX idx_coredns_records_content_trgm ON coredns_records USING GIN (record_search_text(content) gin_trgm_ops);
+
+CREATE VIEW coredns.coredns_records AS
+SELECT r.*
+FROM public.coredns_records r
+JOIN public.zones z ON z.zone = r.zone AND z.user_id = r.user_id
+WHERE z.status = 'active';
@@ -0,0 +1,78 @@
+-- Record content becomes a JSONB object holding the keys of the record's
+-- type, so Postgres validates it on write.
+
+-- The CoreDNS view and the search index depend on the content column
+DROP VIEW coredns.coredns_records;
+DROP INDEX idx_coredns_records_content_trgm;
+DROP FUNCTION record_search_text(TEXT);
+
+-- Rows whose content isn't a JSON object with the keys of their type can't
+-- be served. Move them aside, with their content as it was, so they can be
+-- repaired by hand rather than failing the migration.
+CREATE TABLE coredns_records_invalid (LIKE coredns_records);
+ALTER TABLE coredns_records_invalid ADD COLUMN moved_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE FUNCTION try_jsonb(content TEXT) RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+    RETURN content::jsonb;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$;
+
+WITH invalid AS (
+    DELETE FROM coredns_records
+    WHERE NOT COALESCE(
+        jsonb_typeof(try_jsonb(content)) = 'object'
+        AND try_jsonb(content) ?& CASE record_type
+            WHEN 'A' THEN ARRAY['ip']
+            WHEN 'AAAA' THEN ARRAY['ip']
+            WHEN 'TXT' THEN ARRAY['text']
+            WHEN 'CNAME' THEN ARRAY['host']
+            WHEN 'NS' THEN ARRAY['host']
+            WHEN 'MX' THEN ARRAY['host', 'preference']
+            WHEN 'SRV' THEN ARRAY['priority', 'weight', 'port', 'target']
+            WHEN 'SOA' THEN ARRAY['ns', 'mbox', 'refresh', 'retry', 'expire', 'minttl']
+            WHEN 'CAA' THEN ARRAY['flag', 'tag', 'value']
+            ELSE ARRAY[]::TEXT[]
+        END,
+        FALSE)
+    RETURNING *
+)
+INSERT INTO coredns_records_invalid SELECT * FROM invalid;
+
+ALTER TABLE coredns_records
+    ALTER COLUMN content TYPE JSONB USING content::jsonb,
+    ALTER COLUMN content SET NOT NULL;
+
+DROP FUNCTION try_jsonb(TEXT);
+
+-- Content holds the keys of its type
+ALTER TABLE coredns_records
+    ADD CONSTRAINT coredns_records_content_object CHECK (jsonb_typeof(content) = 'object'),
+    ADD CONSTRAINT coredns_records_a_content CHECK (record_type <> 'A' OR content ? 'ip'),
+    ADD CONSTRAINT coredns_records_aaaa_content CHECK (record_type <> 'AAAA' OR content ? 'ip'),
+    ADD CONSTRAINT coredns_records_txt_content CHECK (record_type <> 'TXT' OR content ? 'text'),
+    ADD CONSTRAINT coredns_records_cname_content CHECK (record_type <> 'CNAME' OR content ? 'host'),
+    ADD CONSTRAINT coredns_records_ns_content CHECK (record_type <> 'NS' OR content ? 'host'),
+    ADD CONSTRAINT coredns_records_mx_content CHECK (record_type <> 'MX' OR content ?& ARRAY['host', 'preference']),
+    ADD CONSTRAINT coredns_records_srv_content CHECK (record_type <> 'SRV' OR content ?& ARRAY['priority', 'weight', 'port', 'target']),
+    ADD CONSTRAINT coredns_records_soa_content CHECK (record_type <> 'SOA' OR content ?& ARRAY['ns', 'mbox', 'refresh', 'retry', 'expire', 'minttl']),
+    ADD CONSTRAINT coredns_records_caa_content CHECK (record_type <> 'CAA' OR content ?& ARRAY['flag', 'tag', 'value']);
+
+-- record_search_text returns the values of a record's content separated by
+-- spaces, leaving out the keys so searching for "ip" doesn't match every A
+-- record
+CREATE FUNCTION record_search_text(content JSONB) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT string_agg(value, ' ') FROM jsonb_each_text(content)
+$$;
+
+CREATE INDEX idx_coredns_records_content_trgm ON coredns_records USING GIN (record_search_text(content) gin_trgm_ops);
+
+CREATE VIEW coredns.coredns_records AS
+SELECT r.*
+FROM public.coredns_records r
+JOIN public.zones z ON z.zone = r.zone AND z.user_id = r.user_id
+WHERE z.status = 'active';
@@ -0,0 +1,2 @@
+-- Drop record history table
+DROP TABLE IF EXISTS record_history;
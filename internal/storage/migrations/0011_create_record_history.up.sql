@@ -0,0 +1,21 @@
+-- Create record history table. Every change of a record is written to it in
+-- the transaction making the change, with the record as it was before and
+-- after. The changes of a transaction share their changed_at time.
+CREATE TABLE record_history (
+    id BIGSERIAL PRIMARY KEY,
+    zone VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL,
+    record_id BIGINT NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    name VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    previous_ttl INT,
+    previous_content JSONB,
+    ttl INT,
+    content JSONB,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (zone, user_id) REFERENCES zones(zone, user_id) ON DELETE CASCADE
+);
+
+-- Add index for history lookups by zone
+CREATE INDEX idx_record_history_zone ON record_history(user_id, zone, changed_at);
@@ -0,0 +1,2 @@
+-- Drop zone templates table
+DROP TABLE IF EXISTS zone_templates;
@@ -0,0 +1,2 @@
+-- Drop vanity nameservers table
+DROP TABLE IF EXISTS vanity_nameservers;
@@ -0,0 +1,10 @@
+-- Create vanity nameservers table. A user's vanity nameservers are their own
+-- hostnames for the service's nameservers, in the same order, which their
+-- new zones name in the SOA and apex NS records.
+CREATE TABLE vanity_nameservers (
+    user_id UUID PRIMARY KEY,
+    hostnames TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
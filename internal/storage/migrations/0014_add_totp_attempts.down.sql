@@ -0,0 +1,4 @@
+-- Drop TOTP attempt counts
+ALTER TABLE totp_credentials
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS failed_attempts;
@@ -0,0 +1,5 @@
+-- TOTP credentials count the codes tried since the last correct one, and are
+-- locked for a while once too many were wrong, so codes can't be guessed
+ALTER TABLE totp_credentials
+    ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Drop organizations tables
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
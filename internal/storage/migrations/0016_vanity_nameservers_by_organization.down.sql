@@ -0,0 +1,18 @@
+-- Give every member of an organization its vanity nameservers. Organizations
+-- created for the vanity nameservers of a single user are kept.
+CREATE TABLE user_vanity_nameservers (
+    user_id UUID PRIMARY KEY,
+    hostnames TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO user_vanity_nameservers (user_id, hostnames, created_at, updated_at)
+SELECT organization_members.user_id, vanity_nameservers.hostnames, vanity_nameservers.created_at, vanity_nameservers.updated_at
+FROM vanity_nameservers
+JOIN organization_members ON organization_members.organization_id = vanity_nameservers.organization_id;
+
+DROP TABLE vanity_nameservers;
+ALTER TABLE user_vanity_nameservers RENAME TO vanity_nameservers;
+ALTER INDEX user_vanity_nameservers_pkey RENAME TO vanity_nameservers_pkey;
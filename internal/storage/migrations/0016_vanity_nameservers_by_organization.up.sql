@@ -0,0 +1,37 @@
+-- Vanity nameservers belong to an organization, and the zones of all of its
+-- members name them. Users who set vanity nameservers keep them: users outside
+-- any organization get one of their own, named after their email, and the
+-- most recently updated set of an organization's members becomes its set.
+INSERT INTO organizations (name)
+SELECT users.email FROM vanity_nameservers
+JOIN users ON users.id = vanity_nameservers.user_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM organization_members
+    WHERE organization_members.user_id = vanity_nameservers.user_id
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO organization_members (organization_id, user_id)
+SELECT organizations.id, vanity_nameservers.user_id FROM vanity_nameservers
+JOIN users ON users.id = vanity_nameservers.user_id
+JOIN organizations ON organizations.name = users.email
+ON CONFLICT (user_id) DO NOTHING;
+
+ALTER TABLE vanity_nameservers ADD COLUMN organization_id UUID;
+
+UPDATE vanity_nameservers
+SET organization_id = organization_members.organization_id
+FROM organization_members
+WHERE organization_members.user_id = vanity_nameservers.user_id;
+
+DELETE FROM vanity_nameservers
+USING vanity_nameservers newer
+WHERE vanity_nameservers.organization_id = newer.organization_id
+    AND (vanity_nameservers.updated_at, vanity_nameservers.user_id) < (newer.updated_at, newer.user_id);
+
+-- Dropping the user column drops its primary key and foreign key too
+ALTER TABLE vanity_nameservers
+    DROP COLUMN user_id,
+    ALTER COLUMN organization_id SET NOT NULL,
+    ADD PRIMARY KEY (organization_id),
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
@@ -6,12 +6,46 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
+type ApiToken struct {
+	ID         int32
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	LastUsedAt sql.NullTime
+	ExpiresAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+type CorednsCorednsRecord struct {
+	ID         int64
+	UserID     uuid.UUID
+	Zone       string
+	Name       string
+	Ttl        sql.NullInt32
+	Content    json.RawMessage
+	RecordType string
+	Version    int32
+}
+
 type CorednsRecord struct {
+	ID         int64
+	UserID     uuid.UUID
+	Zone       string
+	Name       string
+	Ttl        sql.NullInt32
+	Content    json.RawMessage
+	RecordType string
+	Version    int32
+}
+
+type CorednsRecordsInvalid struct {
 	ID         int64
 	UserID     uuid.UUID
 	Zone       string
@@ -19,6 +53,22 @@ type CorednsRecord struct {
 	Ttl        sql.NullInt32
 	Content    sql.NullString
 	RecordType string
+	Version    int32
+	MovedAt    time.Time
+}
+
+type Organization struct {
+	ID               uuid.UUID
+	Name             string
+	RequireTwoFactor bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type OrganizationMember struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	CreatedAt      time.Time
 }
 
 type OtpCode struct {
@@ -30,9 +80,95 @@ type OtpCode struct {
 	CreatedAt  time.Time
 }
 
+type RecordHistory struct {
+	ID              int64
+	Zone            string
+	UserID          uuid.UUID
+	RecordID        int64
+	Action          string
+	Name            string
+	RecordType      string
+	PreviousTtl     sql.NullInt32
+	PreviousContent pqtype.NullRawMessage
+	Ttl             sql.NullInt32
+	Content         pqtype.NullRawMessage
+	ChangedAt       time.Time
+}
+
+type RecoveryCode struct {
+	ID         int32
+	UserID     uuid.UUID
+	CodeHash   string
+	ConsumedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type SigningKey struct {
+	Kid        string
+	Algorithm  string
+	PrivateKey string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+type TotpCredential struct {
+	UserID         uuid.UUID
+	Secret         string
+	LastUsedStep   int64
+	ConfirmedAt    sql.NullTime
+	CreatedAt      time.Time
+	FailedAttempts int32
+	LockedUntil    sql.NullTime
+}
+
 type User struct {
 	ID        uuid.UUID
 	Email     string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+type VanityNameserver struct {
+	Hostnames      []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	OrganizationID uuid.UUID
+}
+
+type WebauthnCredential struct {
+	ID           int32
+	UserID       uuid.UUID
+	CredentialID []byte
+	Name         string
+	Credential   string
+	LastUsedAt   sql.NullTime
+	CreatedAt    time.Time
+}
+
+type WebauthnSession struct {
+	ID          uuid.UUID
+	SessionData string
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+type Zone struct {
+	Zone               string
+	UserID             uuid.UUID
+	Status             string
+	VerificationToken  string
+	VerificationMethod sql.NullString
+	VerifiedAt         sql.NullTime
+	LastCheckedAt      sql.NullTime
+	LastCheckError     sql.NullString
+	CreatedAt          time.Time
+}
+
+type ZoneTemplate struct {
+	ID        int32
+	UserID    uuid.UUID
+	Name      string
+	Content   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
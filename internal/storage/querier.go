@@ -11,23 +11,116 @@ import (
 )
 
 type Querier interface {
+	ActivateZone(ctx context.Context, arg ActivateZoneParams) (Zone, error)
+	// Adds the user to the organization, unless the user already belongs to one
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (int64, error)
+	// Counts an attempt at a code, unless the credential is locked. Once the
+	// attempts since the last correct code reach the limit, each one locks the
+	// credential for the lockout.
+	ClaimTOTPAttempt(ctx context.Context, arg ClaimTOTPAttemptParams) (int32, error)
+	ConfirmTOTPCredential(ctx context.Context, userID uuid.UUID) error
+	ConsumeOTPByID(ctx context.Context, arg ConsumeOTPByIDParams) (OtpCode, error)
+	// Recovery Code Queries
+	ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error)
+	ConsumeWebAuthnSession(ctx context.Context, id uuid.UUID) (WebauthnSession, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Counts the users claiming the zone, whether their claim is pending or active
+	CountZoneClaims(ctx context.Context, zone string) (int64, error)
+	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error)
 	// OTP Authentication Queries
 	CreateOTP(ctx context.Context, arg CreateOTPParams) (OtpCode, error)
+	CreateOrganization(ctx context.Context, name string) (Organization, error)
 	CreateRecord(ctx context.Context, arg CreateRecordParams) (CorednsRecord, error)
+	CreateRecordHistory(ctx context.Context, arg CreateRecordHistoryParams) error
+	CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) (SigningKey, error)
 	CreateUser(ctx context.Context, email string) (User, error)
+	CreateWebAuthnCredential(ctx context.Context, arg CreateWebAuthnCredentialParams) (WebauthnCredential, error)
+	CreateWebAuthnSession(ctx context.Context, arg CreateWebAuthnSessionParams) (WebauthnSession, error)
+	// Zone Queries
+	CreateZone(ctx context.Context, arg CreateZoneParams) (Zone, error)
+	CreateZoneTemplate(ctx context.Context, arg CreateZoneTemplateParams) (ZoneTemplate, error)
+	DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error)
+	DeleteExpiredSigningKeys(ctx context.Context) error
+	DeleteExpiredWebAuthnSessions(ctx context.Context) error
+	DeleteInvalidRecord(ctx context.Context, arg DeleteInvalidRecordParams) (int64, error)
+	DeleteInvalidRecordsByZone(ctx context.Context, arg DeleteInvalidRecordsByZoneParams) error
+	DeleteOrganization(ctx context.Context, id uuid.UUID) (int64, error)
 	DeleteRecord(ctx context.Context, arg DeleteRecordParams) error
+	DeleteRecordsByZone(ctx context.Context, arg DeleteRecordsByZoneParams) error
+	DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteTOTPCredential(ctx context.Context, userID uuid.UUID) error
+	DeleteVanityNameservers(ctx context.Context, organizationID uuid.UUID) (int64, error)
+	DeleteWebAuthnCredential(ctx context.Context, arg DeleteWebAuthnCredentialParams) (int64, error)
+	DeleteZone(ctx context.Context, arg DeleteZoneParams) error
+	DeleteZoneTemplate(ctx context.Context, arg DeleteZoneTemplateParams) (int64, error)
+	GetAPITokenUser(ctx context.Context, tokenHash string) (User, error)
+	GetActiveZone(ctx context.Context, zone string) (Zone, error)
 	GetLatestOTPByEmail(ctx context.Context, email string) (OtpCode, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error)
+	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
 	// Records Queries
 	GetRecordByID(ctx context.Context, arg GetRecordByIDParams) (CorednsRecord, error)
+	// TOTP Queries
+	GetTOTPCredential(ctx context.Context, userID uuid.UUID) (TotpCredential, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	// User Queries
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	GetUserOrganization(ctx context.Context, userID uuid.UUID) (Organization, error)
+	// Vanity Nameserver Queries
+	// Gets the vanity nameservers of the user's organization
+	GetUserVanityNameservers(ctx context.Context, userID uuid.UUID) (VanityNameserver, error)
+	GetZone(ctx context.Context, arg GetZoneParams) (Zone, error)
+	GetZoneTemplate(ctx context.Context, arg GetZoneTemplateParams) (ZoneTemplate, error)
+	GetZoneTemplateByName(ctx context.Context, arg GetZoneTemplateByNameParams) (ZoneTemplate, error)
+	// API Token Queries
+	ListAPITokensByUser(ctx context.Context, userID uuid.UUID) ([]ApiToken, error)
+	ListInvalidRecordsByZone(ctx context.Context, arg ListInvalidRecordsByZoneParams) ([]CorednsRecordsInvalid, error)
+	ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error)
+	// Organization Queries
+	ListOrganizations(ctx context.Context) ([]Organization, error)
+	ListPendingZones(ctx context.Context, limit int32) ([]Zone, error)
 	ListRecords(ctx context.Context, arg ListRecordsParams) ([]CorednsRecord, error)
-	ListRecordsByName(ctx context.Context, arg ListRecordsByNameParams) ([]CorednsRecord, error)
-	ListRecordsByType(ctx context.Context, arg ListRecordsByTypeParams) ([]CorednsRecord, error)
+	ListRecordsByRRset(ctx context.Context, arg ListRecordsByRRsetParams) ([]CorednsRecord, error)
 	ListRecordsByZone(ctx context.Context, arg ListRecordsByZoneParams) ([]CorednsRecord, error)
+	// Signing Key Queries
+	ListSigningKeys(ctx context.Context) ([]SigningKey, error)
+	// Lists the records of a type of all of the user's zones, or of one zone if
+	// the zone isn't empty
+	ListUserRecordsByType(ctx context.Context, arg ListUserRecordsByTypeParams) ([]CorednsRecord, error)
+	// WebAuthn Queries
+	ListWebAuthnCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]WebauthnCredential, error)
+	// Zone Template Queries
+	ListZoneTemplates(ctx context.Context, userID uuid.UUID) ([]ZoneTemplate, error)
 	ListZones(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ListZonesByUser(ctx context.Context, userID uuid.UUID) ([]Zone, error)
+	LockZone(ctx context.Context, arg LockZoneParams) (Zone, error)
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error)
+	// Replaces all of a user's recovery codes in one statement, so a failure
+	// can't leave them without codes
+	ReplaceRecoveryCodes(ctx context.Context, arg ReplaceRecoveryCodesParams) error
+	ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error
+	// Lists a page of the records of a zone matching the filters, which are
+	// ignored when empty. Records are ordered by sort_key and ID, and the page
+	// starts after the cursor's sort key and ID unless the cursor ID is zero.
+	SearchRecords(ctx context.Context, arg SearchRecordsParams) ([]SearchRecordsRow, error)
+	// Lists a page of the records of all of the user's zones whose owner name or
+	// content values contain the pattern, ignoring case. Records are ordered by
+	// zone, name and type, and paginated as in SearchRecords.
+	SearchUserRecords(ctx context.Context, arg SearchUserRecordsParams) ([]SearchUserRecordsRow, error)
+	// Lists a page of the user's zones matching the filters, which are ignored
+	// when empty. Zones are ordered by sort_key, which is unique, and the page
+	// starts after the cursor's sort key unless it is empty.
+	SearchZones(ctx context.Context, arg SearchZonesParams) ([]SearchZonesRow, error)
+	SetOrganizationTwoFactor(ctx context.Context, arg SetOrganizationTwoFactorParams) (Organization, error)
+	SetVanityNameservers(ctx context.Context, arg SetVanityNameserversParams) (VanityNameserver, error)
+	UpdateAPITokenUsage(ctx context.Context, tokenHash string) error
+	UpdateRRsetTTL(ctx context.Context, arg UpdateRRsetTTLParams) error
 	UpdateRecord(ctx context.Context, arg UpdateRecordParams) (CorednsRecord, error)
+	UpdateTOTPLastUsedStep(ctx context.Context, arg UpdateTOTPLastUsedStepParams) (int64, error)
+	UpdateWebAuthnCredentialUsage(ctx context.Context, arg UpdateWebAuthnCredentialUsageParams) error
+	UpdateZoneCheck(ctx context.Context, arg UpdateZoneCheckParams) error
+	UpdateZoneTemplate(ctx context.Context, arg UpdateZoneTemplateParams) (ZoneTemplate, error)
+	UpsertTOTPCredential(ctx context.Context, arg UpsertTOTPCredentialParams) (TotpCredential, error)
 	ValidateAndConsumeOTP(ctx context.Context, arg ValidateAndConsumeOTPParams) (OtpCode, error)
 }
 
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,313 +41,2835 @@ func (m *MockQuerier) EXPECT() *MockQuerierMockRecorder {
 	return m.recorder
 }
 
+// ActivateZone mocks base method.
+func (m *MockQuerier) ActivateZone(ctx context.Context, arg ActivateZoneParams) (Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateZone", ctx, arg)
+	ret0, _ := ret[0].(Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateZone indicates an expected call of ActivateZone.
+func (mr *MockQuerierMockRecorder) ActivateZone(ctx, arg any) *MockQuerierActivateZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateZone", reflect.TypeOf((*MockQuerier)(nil).ActivateZone), ctx, arg)
+	return &MockQuerierActivateZoneCall{Call: call}
+}
+
+// MockQuerierActivateZoneCall wrap *gomock.Call
+type MockQuerierActivateZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierActivateZoneCall) Return(arg0 Zone, arg1 error) *MockQuerierActivateZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierActivateZoneCall) Do(f func(context.Context, ActivateZoneParams) (Zone, error)) *MockQuerierActivateZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierActivateZoneCall) DoAndReturn(f func(context.Context, ActivateZoneParams) (Zone, error)) *MockQuerierActivateZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// AddOrganizationMember mocks base method.
+func (m *MockQuerier) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrganizationMember", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrganizationMember indicates an expected call of AddOrganizationMember.
+func (mr *MockQuerierMockRecorder) AddOrganizationMember(ctx, arg any) *MockQuerierAddOrganizationMemberCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrganizationMember", reflect.TypeOf((*MockQuerier)(nil).AddOrganizationMember), ctx, arg)
+	return &MockQuerierAddOrganizationMemberCall{Call: call}
+}
+
+// MockQuerierAddOrganizationMemberCall wrap *gomock.Call
+type MockQuerierAddOrganizationMemberCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierAddOrganizationMemberCall) Return(arg0 int64, arg1 error) *MockQuerierAddOrganizationMemberCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierAddOrganizationMemberCall) Do(f func(context.Context, AddOrganizationMemberParams) (int64, error)) *MockQuerierAddOrganizationMemberCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierAddOrganizationMemberCall) DoAndReturn(f func(context.Context, AddOrganizationMemberParams) (int64, error)) *MockQuerierAddOrganizationMemberCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ClaimTOTPAttempt mocks base method.
+func (m *MockQuerier) ClaimTOTPAttempt(ctx context.Context, arg ClaimTOTPAttemptParams) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimTOTPAttempt", ctx, arg)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimTOTPAttempt indicates an expected call of ClaimTOTPAttempt.
+func (mr *MockQuerierMockRecorder) ClaimTOTPAttempt(ctx, arg any) *MockQuerierClaimTOTPAttemptCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimTOTPAttempt", reflect.TypeOf((*MockQuerier)(nil).ClaimTOTPAttempt), ctx, arg)
+	return &MockQuerierClaimTOTPAttemptCall{Call: call}
+}
+
+// MockQuerierClaimTOTPAttemptCall wrap *gomock.Call
+type MockQuerierClaimTOTPAttemptCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierClaimTOTPAttemptCall) Return(arg0 int32, arg1 error) *MockQuerierClaimTOTPAttemptCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierClaimTOTPAttemptCall) Do(f func(context.Context, ClaimTOTPAttemptParams) (int32, error)) *MockQuerierClaimTOTPAttemptCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierClaimTOTPAttemptCall) DoAndReturn(f func(context.Context, ClaimTOTPAttemptParams) (int32, error)) *MockQuerierClaimTOTPAttemptCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ConfirmTOTPCredential mocks base method.
+func (m *MockQuerier) ConfirmTOTPCredential(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTPCredential", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTPCredential indicates an expected call of ConfirmTOTPCredential.
+func (mr *MockQuerierMockRecorder) ConfirmTOTPCredential(ctx, userID any) *MockQuerierConfirmTOTPCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTPCredential", reflect.TypeOf((*MockQuerier)(nil).ConfirmTOTPCredential), ctx, userID)
+	return &MockQuerierConfirmTOTPCredentialCall{Call: call}
+}
+
+// MockQuerierConfirmTOTPCredentialCall wrap *gomock.Call
+type MockQuerierConfirmTOTPCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierConfirmTOTPCredentialCall) Return(arg0 error) *MockQuerierConfirmTOTPCredentialCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierConfirmTOTPCredentialCall) Do(f func(context.Context, uuid.UUID) error) *MockQuerierConfirmTOTPCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierConfirmTOTPCredentialCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockQuerierConfirmTOTPCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ConsumeOTPByID mocks base method.
+func (m *MockQuerier) ConsumeOTPByID(ctx context.Context, arg ConsumeOTPByIDParams) (OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOTPByID", ctx, arg)
+	ret0, _ := ret[0].(OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOTPByID indicates an expected call of ConsumeOTPByID.
+func (mr *MockQuerierMockRecorder) ConsumeOTPByID(ctx, arg any) *MockQuerierConsumeOTPByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOTPByID", reflect.TypeOf((*MockQuerier)(nil).ConsumeOTPByID), ctx, arg)
+	return &MockQuerierConsumeOTPByIDCall{Call: call}
+}
+
+// MockQuerierConsumeOTPByIDCall wrap *gomock.Call
+type MockQuerierConsumeOTPByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierConsumeOTPByIDCall) Return(arg0 OtpCode, arg1 error) *MockQuerierConsumeOTPByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierConsumeOTPByIDCall) Do(f func(context.Context, ConsumeOTPByIDParams) (OtpCode, error)) *MockQuerierConsumeOTPByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierConsumeOTPByIDCall) DoAndReturn(f func(context.Context, ConsumeOTPByIDParams) (OtpCode, error)) *MockQuerierConsumeOTPByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockQuerier) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", ctx, arg)
+	ret0, _ := ret[0].(RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockQuerierMockRecorder) ConsumeRecoveryCode(ctx, arg any) *MockQuerierConsumeRecoveryCodeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockQuerier)(nil).ConsumeRecoveryCode), ctx, arg)
+	return &MockQuerierConsumeRecoveryCodeCall{Call: call}
+}
+
+// MockQuerierConsumeRecoveryCodeCall wrap *gomock.Call
+type MockQuerierConsumeRecoveryCodeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierConsumeRecoveryCodeCall) Return(arg0 RecoveryCode, arg1 error) *MockQuerierConsumeRecoveryCodeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierConsumeRecoveryCodeCall) Do(f func(context.Context, ConsumeRecoveryCodeParams) (RecoveryCode, error)) *MockQuerierConsumeRecoveryCodeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierConsumeRecoveryCodeCall) DoAndReturn(f func(context.Context, ConsumeRecoveryCodeParams) (RecoveryCode, error)) *MockQuerierConsumeRecoveryCodeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ConsumeWebAuthnSession mocks base method.
+func (m *MockQuerier) ConsumeWebAuthnSession(ctx context.Context, id uuid.UUID) (WebauthnSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeWebAuthnSession", ctx, id)
+	ret0, _ := ret[0].(WebauthnSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeWebAuthnSession indicates an expected call of ConsumeWebAuthnSession.
+func (mr *MockQuerierMockRecorder) ConsumeWebAuthnSession(ctx, id any) *MockQuerierConsumeWebAuthnSessionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeWebAuthnSession", reflect.TypeOf((*MockQuerier)(nil).ConsumeWebAuthnSession), ctx, id)
+	return &MockQuerierConsumeWebAuthnSessionCall{Call: call}
+}
+
+// MockQuerierConsumeWebAuthnSessionCall wrap *gomock.Call
+type MockQuerierConsumeWebAuthnSessionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierConsumeWebAuthnSessionCall) Return(arg0 WebauthnSession, arg1 error) *MockQuerierConsumeWebAuthnSessionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierConsumeWebAuthnSessionCall) Do(f func(context.Context, uuid.UUID) (WebauthnSession, error)) *MockQuerierConsumeWebAuthnSessionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierConsumeWebAuthnSessionCall) DoAndReturn(f func(context.Context, uuid.UUID) (WebauthnSession, error)) *MockQuerierConsumeWebAuthnSessionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CountUnusedRecoveryCodes mocks base method.
+func (m *MockQuerier) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnusedRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnusedRecoveryCodes indicates an expected call of CountUnusedRecoveryCodes.
+func (mr *MockQuerierMockRecorder) CountUnusedRecoveryCodes(ctx, userID any) *MockQuerierCountUnusedRecoveryCodesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnusedRecoveryCodes", reflect.TypeOf((*MockQuerier)(nil).CountUnusedRecoveryCodes), ctx, userID)
+	return &MockQuerierCountUnusedRecoveryCodesCall{Call: call}
+}
+
+// MockQuerierCountUnusedRecoveryCodesCall wrap *gomock.Call
+type MockQuerierCountUnusedRecoveryCodesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCountUnusedRecoveryCodesCall) Return(arg0 int64, arg1 error) *MockQuerierCountUnusedRecoveryCodesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCountUnusedRecoveryCodesCall) Do(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierCountUnusedRecoveryCodesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCountUnusedRecoveryCodesCall) DoAndReturn(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierCountUnusedRecoveryCodesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CountZoneClaims mocks base method.
+func (m *MockQuerier) CountZoneClaims(ctx context.Context, zone string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountZoneClaims", ctx, zone)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountZoneClaims indicates an expected call of CountZoneClaims.
+func (mr *MockQuerierMockRecorder) CountZoneClaims(ctx, zone any) *MockQuerierCountZoneClaimsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountZoneClaims", reflect.TypeOf((*MockQuerier)(nil).CountZoneClaims), ctx, zone)
+	return &MockQuerierCountZoneClaimsCall{Call: call}
+}
+
+// MockQuerierCountZoneClaimsCall wrap *gomock.Call
+type MockQuerierCountZoneClaimsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCountZoneClaimsCall) Return(arg0 int64, arg1 error) *MockQuerierCountZoneClaimsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCountZoneClaimsCall) Do(f func(context.Context, string) (int64, error)) *MockQuerierCountZoneClaimsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCountZoneClaimsCall) DoAndReturn(f func(context.Context, string) (int64, error)) *MockQuerierCountZoneClaimsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateAPIToken mocks base method.
+func (m *MockQuerier) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", ctx, arg)
+	ret0, _ := ret[0].(ApiToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockQuerierMockRecorder) CreateAPIToken(ctx, arg any) *MockQuerierCreateAPITokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockQuerier)(nil).CreateAPIToken), ctx, arg)
+	return &MockQuerierCreateAPITokenCall{Call: call}
+}
+
+// MockQuerierCreateAPITokenCall wrap *gomock.Call
+type MockQuerierCreateAPITokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateAPITokenCall) Return(arg0 ApiToken, arg1 error) *MockQuerierCreateAPITokenCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateAPITokenCall) Do(f func(context.Context, CreateAPITokenParams) (ApiToken, error)) *MockQuerierCreateAPITokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateAPITokenCall) DoAndReturn(f func(context.Context, CreateAPITokenParams) (ApiToken, error)) *MockQuerierCreateAPITokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateOTP mocks base method.
+func (m *MockQuerier) CreateOTP(ctx context.Context, arg CreateOTPParams) (OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOTP", ctx, arg)
+	ret0, _ := ret[0].(OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOTP indicates an expected call of CreateOTP.
+func (mr *MockQuerierMockRecorder) CreateOTP(ctx, arg any) *MockQuerierCreateOTPCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOTP", reflect.TypeOf((*MockQuerier)(nil).CreateOTP), ctx, arg)
+	return &MockQuerierCreateOTPCall{Call: call}
+}
+
+// MockQuerierCreateOTPCall wrap *gomock.Call
+type MockQuerierCreateOTPCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateOTPCall) Return(arg0 OtpCode, arg1 error) *MockQuerierCreateOTPCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateOTPCall) Do(f func(context.Context, CreateOTPParams) (OtpCode, error)) *MockQuerierCreateOTPCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateOTPCall) DoAndReturn(f func(context.Context, CreateOTPParams) (OtpCode, error)) *MockQuerierCreateOTPCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateOrganization mocks base method.
+func (m *MockQuerier) CreateOrganization(ctx context.Context, name string) (Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, name)
+	ret0, _ := ret[0].(Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockQuerierMockRecorder) CreateOrganization(ctx, name any) *MockQuerierCreateOrganizationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockQuerier)(nil).CreateOrganization), ctx, name)
+	return &MockQuerierCreateOrganizationCall{Call: call}
+}
+
+// MockQuerierCreateOrganizationCall wrap *gomock.Call
+type MockQuerierCreateOrganizationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateOrganizationCall) Return(arg0 Organization, arg1 error) *MockQuerierCreateOrganizationCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateOrganizationCall) Do(f func(context.Context, string) (Organization, error)) *MockQuerierCreateOrganizationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateOrganizationCall) DoAndReturn(f func(context.Context, string) (Organization, error)) *MockQuerierCreateOrganizationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CreateRecord mocks base method.
 func (m *MockQuerier) CreateRecord(ctx context.Context, arg CreateRecordParams) (CorednsRecord, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRecord", ctx, arg)
-	ret0, _ := ret[0].(CorednsRecord)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateRecord", ctx, arg)
+	ret0, _ := ret[0].(CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecord indicates an expected call of CreateRecord.
+func (mr *MockQuerierMockRecorder) CreateRecord(ctx, arg any) *MockQuerierCreateRecordCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecord", reflect.TypeOf((*MockQuerier)(nil).CreateRecord), ctx, arg)
+	return &MockQuerierCreateRecordCall{Call: call}
+}
+
+// MockQuerierCreateRecordCall wrap *gomock.Call
+type MockQuerierCreateRecordCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateRecordCall) Return(arg0 CorednsRecord, arg1 error) *MockQuerierCreateRecordCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateRecordCall) Do(f func(context.Context, CreateRecordParams) (CorednsRecord, error)) *MockQuerierCreateRecordCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateRecordCall) DoAndReturn(f func(context.Context, CreateRecordParams) (CorednsRecord, error)) *MockQuerierCreateRecordCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateRecordHistory mocks base method.
+func (m *MockQuerier) CreateRecordHistory(ctx context.Context, arg CreateRecordHistoryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecordHistory", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecordHistory indicates an expected call of CreateRecordHistory.
+func (mr *MockQuerierMockRecorder) CreateRecordHistory(ctx, arg any) *MockQuerierCreateRecordHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecordHistory", reflect.TypeOf((*MockQuerier)(nil).CreateRecordHistory), ctx, arg)
+	return &MockQuerierCreateRecordHistoryCall{Call: call}
+}
+
+// MockQuerierCreateRecordHistoryCall wrap *gomock.Call
+type MockQuerierCreateRecordHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateRecordHistoryCall) Return(arg0 error) *MockQuerierCreateRecordHistoryCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateRecordHistoryCall) Do(f func(context.Context, CreateRecordHistoryParams) error) *MockQuerierCreateRecordHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateRecordHistoryCall) DoAndReturn(f func(context.Context, CreateRecordHistoryParams) error) *MockQuerierCreateRecordHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateSigningKey mocks base method.
+func (m *MockQuerier) CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) (SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSigningKey", ctx, arg)
+	ret0, _ := ret[0].(SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSigningKey indicates an expected call of CreateSigningKey.
+func (mr *MockQuerierMockRecorder) CreateSigningKey(ctx, arg any) *MockQuerierCreateSigningKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSigningKey", reflect.TypeOf((*MockQuerier)(nil).CreateSigningKey), ctx, arg)
+	return &MockQuerierCreateSigningKeyCall{Call: call}
+}
+
+// MockQuerierCreateSigningKeyCall wrap *gomock.Call
+type MockQuerierCreateSigningKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateSigningKeyCall) Return(arg0 SigningKey, arg1 error) *MockQuerierCreateSigningKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateSigningKeyCall) Do(f func(context.Context, CreateSigningKeyParams) (SigningKey, error)) *MockQuerierCreateSigningKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateSigningKeyCall) DoAndReturn(f func(context.Context, CreateSigningKeyParams) (SigningKey, error)) *MockQuerierCreateSigningKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateUser mocks base method.
+func (m *MockQuerier) CreateUser(ctx context.Context, email string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, email)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockQuerierMockRecorder) CreateUser(ctx, email any) *MockQuerierCreateUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockQuerier)(nil).CreateUser), ctx, email)
+	return &MockQuerierCreateUserCall{Call: call}
+}
+
+// MockQuerierCreateUserCall wrap *gomock.Call
+type MockQuerierCreateUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateUserCall) Return(arg0 User, arg1 error) *MockQuerierCreateUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateUserCall) Do(f func(context.Context, string) (User, error)) *MockQuerierCreateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateUserCall) DoAndReturn(f func(context.Context, string) (User, error)) *MockQuerierCreateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateWebAuthnCredential mocks base method.
+func (m *MockQuerier) CreateWebAuthnCredential(ctx context.Context, arg CreateWebAuthnCredentialParams) (WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCredential", ctx, arg)
+	ret0, _ := ret[0].(WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebAuthnCredential indicates an expected call of CreateWebAuthnCredential.
+func (mr *MockQuerierMockRecorder) CreateWebAuthnCredential(ctx, arg any) *MockQuerierCreateWebAuthnCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCredential", reflect.TypeOf((*MockQuerier)(nil).CreateWebAuthnCredential), ctx, arg)
+	return &MockQuerierCreateWebAuthnCredentialCall{Call: call}
+}
+
+// MockQuerierCreateWebAuthnCredentialCall wrap *gomock.Call
+type MockQuerierCreateWebAuthnCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateWebAuthnCredentialCall) Return(arg0 WebauthnCredential, arg1 error) *MockQuerierCreateWebAuthnCredentialCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateWebAuthnCredentialCall) Do(f func(context.Context, CreateWebAuthnCredentialParams) (WebauthnCredential, error)) *MockQuerierCreateWebAuthnCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateWebAuthnCredentialCall) DoAndReturn(f func(context.Context, CreateWebAuthnCredentialParams) (WebauthnCredential, error)) *MockQuerierCreateWebAuthnCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateWebAuthnSession mocks base method.
+func (m *MockQuerier) CreateWebAuthnSession(ctx context.Context, arg CreateWebAuthnSessionParams) (WebauthnSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnSession", ctx, arg)
+	ret0, _ := ret[0].(WebauthnSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebAuthnSession indicates an expected call of CreateWebAuthnSession.
+func (mr *MockQuerierMockRecorder) CreateWebAuthnSession(ctx, arg any) *MockQuerierCreateWebAuthnSessionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnSession", reflect.TypeOf((*MockQuerier)(nil).CreateWebAuthnSession), ctx, arg)
+	return &MockQuerierCreateWebAuthnSessionCall{Call: call}
+}
+
+// MockQuerierCreateWebAuthnSessionCall wrap *gomock.Call
+type MockQuerierCreateWebAuthnSessionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateWebAuthnSessionCall) Return(arg0 WebauthnSession, arg1 error) *MockQuerierCreateWebAuthnSessionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateWebAuthnSessionCall) Do(f func(context.Context, CreateWebAuthnSessionParams) (WebauthnSession, error)) *MockQuerierCreateWebAuthnSessionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateWebAuthnSessionCall) DoAndReturn(f func(context.Context, CreateWebAuthnSessionParams) (WebauthnSession, error)) *MockQuerierCreateWebAuthnSessionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateZone mocks base method.
+func (m *MockQuerier) CreateZone(ctx context.Context, arg CreateZoneParams) (Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateZone", ctx, arg)
+	ret0, _ := ret[0].(Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateZone indicates an expected call of CreateZone.
+func (mr *MockQuerierMockRecorder) CreateZone(ctx, arg any) *MockQuerierCreateZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateZone", reflect.TypeOf((*MockQuerier)(nil).CreateZone), ctx, arg)
+	return &MockQuerierCreateZoneCall{Call: call}
+}
+
+// MockQuerierCreateZoneCall wrap *gomock.Call
+type MockQuerierCreateZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateZoneCall) Return(arg0 Zone, arg1 error) *MockQuerierCreateZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateZoneCall) Do(f func(context.Context, CreateZoneParams) (Zone, error)) *MockQuerierCreateZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateZoneCall) DoAndReturn(f func(context.Context, CreateZoneParams) (Zone, error)) *MockQuerierCreateZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateZoneTemplate mocks base method.
+func (m *MockQuerier) CreateZoneTemplate(ctx context.Context, arg CreateZoneTemplateParams) (ZoneTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateZoneTemplate", ctx, arg)
+	ret0, _ := ret[0].(ZoneTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateZoneTemplate indicates an expected call of CreateZoneTemplate.
+func (mr *MockQuerierMockRecorder) CreateZoneTemplate(ctx, arg any) *MockQuerierCreateZoneTemplateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateZoneTemplate", reflect.TypeOf((*MockQuerier)(nil).CreateZoneTemplate), ctx, arg)
+	return &MockQuerierCreateZoneTemplateCall{Call: call}
+}
+
+// MockQuerierCreateZoneTemplateCall wrap *gomock.Call
+type MockQuerierCreateZoneTemplateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierCreateZoneTemplateCall) Return(arg0 ZoneTemplate, arg1 error) *MockQuerierCreateZoneTemplateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierCreateZoneTemplateCall) Do(f func(context.Context, CreateZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierCreateZoneTemplateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierCreateZoneTemplateCall) DoAndReturn(f func(context.Context, CreateZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierCreateZoneTemplateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteAPIToken mocks base method.
+func (m *MockQuerier) DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIToken", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAPIToken indicates an expected call of DeleteAPIToken.
+func (mr *MockQuerierMockRecorder) DeleteAPIToken(ctx, arg any) *MockQuerierDeleteAPITokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIToken", reflect.TypeOf((*MockQuerier)(nil).DeleteAPIToken), ctx, arg)
+	return &MockQuerierDeleteAPITokenCall{Call: call}
+}
+
+// MockQuerierDeleteAPITokenCall wrap *gomock.Call
+type MockQuerierDeleteAPITokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteAPITokenCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteAPITokenCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteAPITokenCall) Do(f func(context.Context, DeleteAPITokenParams) (int64, error)) *MockQuerierDeleteAPITokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteAPITokenCall) DoAndReturn(f func(context.Context, DeleteAPITokenParams) (int64, error)) *MockQuerierDeleteAPITokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteExpiredSigningKeys mocks base method.
+func (m *MockQuerier) DeleteExpiredSigningKeys(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSigningKeys", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredSigningKeys indicates an expected call of DeleteExpiredSigningKeys.
+func (mr *MockQuerierMockRecorder) DeleteExpiredSigningKeys(ctx any) *MockQuerierDeleteExpiredSigningKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSigningKeys", reflect.TypeOf((*MockQuerier)(nil).DeleteExpiredSigningKeys), ctx)
+	return &MockQuerierDeleteExpiredSigningKeysCall{Call: call}
+}
+
+// MockQuerierDeleteExpiredSigningKeysCall wrap *gomock.Call
+type MockQuerierDeleteExpiredSigningKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteExpiredSigningKeysCall) Return(arg0 error) *MockQuerierDeleteExpiredSigningKeysCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteExpiredSigningKeysCall) Do(f func(context.Context) error) *MockQuerierDeleteExpiredSigningKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteExpiredSigningKeysCall) DoAndReturn(f func(context.Context) error) *MockQuerierDeleteExpiredSigningKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteExpiredWebAuthnSessions mocks base method.
+func (m *MockQuerier) DeleteExpiredWebAuthnSessions(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredWebAuthnSessions", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredWebAuthnSessions indicates an expected call of DeleteExpiredWebAuthnSessions.
+func (mr *MockQuerierMockRecorder) DeleteExpiredWebAuthnSessions(ctx any) *MockQuerierDeleteExpiredWebAuthnSessionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredWebAuthnSessions", reflect.TypeOf((*MockQuerier)(nil).DeleteExpiredWebAuthnSessions), ctx)
+	return &MockQuerierDeleteExpiredWebAuthnSessionsCall{Call: call}
+}
+
+// MockQuerierDeleteExpiredWebAuthnSessionsCall wrap *gomock.Call
+type MockQuerierDeleteExpiredWebAuthnSessionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteExpiredWebAuthnSessionsCall) Return(arg0 error) *MockQuerierDeleteExpiredWebAuthnSessionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteExpiredWebAuthnSessionsCall) Do(f func(context.Context) error) *MockQuerierDeleteExpiredWebAuthnSessionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteExpiredWebAuthnSessionsCall) DoAndReturn(f func(context.Context) error) *MockQuerierDeleteExpiredWebAuthnSessionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteInvalidRecord mocks base method.
+func (m *MockQuerier) DeleteInvalidRecord(ctx context.Context, arg DeleteInvalidRecordParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInvalidRecord", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteInvalidRecord indicates an expected call of DeleteInvalidRecord.
+func (mr *MockQuerierMockRecorder) DeleteInvalidRecord(ctx, arg any) *MockQuerierDeleteInvalidRecordCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInvalidRecord", reflect.TypeOf((*MockQuerier)(nil).DeleteInvalidRecord), ctx, arg)
+	return &MockQuerierDeleteInvalidRecordCall{Call: call}
+}
+
+// MockQuerierDeleteInvalidRecordCall wrap *gomock.Call
+type MockQuerierDeleteInvalidRecordCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteInvalidRecordCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteInvalidRecordCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteInvalidRecordCall) Do(f func(context.Context, DeleteInvalidRecordParams) (int64, error)) *MockQuerierDeleteInvalidRecordCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteInvalidRecordCall) DoAndReturn(f func(context.Context, DeleteInvalidRecordParams) (int64, error)) *MockQuerierDeleteInvalidRecordCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteInvalidRecordsByZone mocks base method.
+func (m *MockQuerier) DeleteInvalidRecordsByZone(ctx context.Context, arg DeleteInvalidRecordsByZoneParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInvalidRecordsByZone", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInvalidRecordsByZone indicates an expected call of DeleteInvalidRecordsByZone.
+func (mr *MockQuerierMockRecorder) DeleteInvalidRecordsByZone(ctx, arg any) *MockQuerierDeleteInvalidRecordsByZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInvalidRecordsByZone", reflect.TypeOf((*MockQuerier)(nil).DeleteInvalidRecordsByZone), ctx, arg)
+	return &MockQuerierDeleteInvalidRecordsByZoneCall{Call: call}
+}
+
+// MockQuerierDeleteInvalidRecordsByZoneCall wrap *gomock.Call
+type MockQuerierDeleteInvalidRecordsByZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteInvalidRecordsByZoneCall) Return(arg0 error) *MockQuerierDeleteInvalidRecordsByZoneCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteInvalidRecordsByZoneCall) Do(f func(context.Context, DeleteInvalidRecordsByZoneParams) error) *MockQuerierDeleteInvalidRecordsByZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteInvalidRecordsByZoneCall) DoAndReturn(f func(context.Context, DeleteInvalidRecordsByZoneParams) error) *MockQuerierDeleteInvalidRecordsByZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockQuerier) DeleteOrganization(ctx context.Context, id uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockQuerierMockRecorder) DeleteOrganization(ctx, id any) *MockQuerierDeleteOrganizationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockQuerier)(nil).DeleteOrganization), ctx, id)
+	return &MockQuerierDeleteOrganizationCall{Call: call}
+}
+
+// MockQuerierDeleteOrganizationCall wrap *gomock.Call
+type MockQuerierDeleteOrganizationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteOrganizationCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteOrganizationCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteOrganizationCall) Do(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierDeleteOrganizationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteOrganizationCall) DoAndReturn(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierDeleteOrganizationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteRecord mocks base method.
+func (m *MockQuerier) DeleteRecord(ctx context.Context, arg DeleteRecordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecord", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecord indicates an expected call of DeleteRecord.
+func (mr *MockQuerierMockRecorder) DeleteRecord(ctx, arg any) *MockQuerierDeleteRecordCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecord", reflect.TypeOf((*MockQuerier)(nil).DeleteRecord), ctx, arg)
+	return &MockQuerierDeleteRecordCall{Call: call}
+}
+
+// MockQuerierDeleteRecordCall wrap *gomock.Call
+type MockQuerierDeleteRecordCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteRecordCall) Return(arg0 error) *MockQuerierDeleteRecordCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteRecordCall) Do(f func(context.Context, DeleteRecordParams) error) *MockQuerierDeleteRecordCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteRecordCall) DoAndReturn(f func(context.Context, DeleteRecordParams) error) *MockQuerierDeleteRecordCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteRecordsByZone mocks base method.
+func (m *MockQuerier) DeleteRecordsByZone(ctx context.Context, arg DeleteRecordsByZoneParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecordsByZone", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecordsByZone indicates an expected call of DeleteRecordsByZone.
+func (mr *MockQuerierMockRecorder) DeleteRecordsByZone(ctx, arg any) *MockQuerierDeleteRecordsByZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecordsByZone", reflect.TypeOf((*MockQuerier)(nil).DeleteRecordsByZone), ctx, arg)
+	return &MockQuerierDeleteRecordsByZoneCall{Call: call}
+}
+
+// MockQuerierDeleteRecordsByZoneCall wrap *gomock.Call
+type MockQuerierDeleteRecordsByZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteRecordsByZoneCall) Return(arg0 error) *MockQuerierDeleteRecordsByZoneCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteRecordsByZoneCall) Do(f func(context.Context, DeleteRecordsByZoneParams) error) *MockQuerierDeleteRecordsByZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteRecordsByZoneCall) DoAndReturn(f func(context.Context, DeleteRecordsByZoneParams) error) *MockQuerierDeleteRecordsByZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockQuerier) DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockQuerierMockRecorder) DeleteRecoveryCodes(ctx, userID any) *MockQuerierDeleteRecoveryCodesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockQuerier)(nil).DeleteRecoveryCodes), ctx, userID)
+	return &MockQuerierDeleteRecoveryCodesCall{Call: call}
+}
+
+// MockQuerierDeleteRecoveryCodesCall wrap *gomock.Call
+type MockQuerierDeleteRecoveryCodesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteRecoveryCodesCall) Return(arg0 error) *MockQuerierDeleteRecoveryCodesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteRecoveryCodesCall) Do(f func(context.Context, uuid.UUID) error) *MockQuerierDeleteRecoveryCodesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteRecoveryCodesCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockQuerierDeleteRecoveryCodesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteTOTPCredential mocks base method.
+func (m *MockQuerier) DeleteTOTPCredential(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTPCredential", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTPCredential indicates an expected call of DeleteTOTPCredential.
+func (mr *MockQuerierMockRecorder) DeleteTOTPCredential(ctx, userID any) *MockQuerierDeleteTOTPCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPCredential", reflect.TypeOf((*MockQuerier)(nil).DeleteTOTPCredential), ctx, userID)
+	return &MockQuerierDeleteTOTPCredentialCall{Call: call}
+}
+
+// MockQuerierDeleteTOTPCredentialCall wrap *gomock.Call
+type MockQuerierDeleteTOTPCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteTOTPCredentialCall) Return(arg0 error) *MockQuerierDeleteTOTPCredentialCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteTOTPCredentialCall) Do(f func(context.Context, uuid.UUID) error) *MockQuerierDeleteTOTPCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteTOTPCredentialCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockQuerierDeleteTOTPCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteVanityNameservers mocks base method.
+func (m *MockQuerier) DeleteVanityNameservers(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVanityNameservers", ctx, organizationID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVanityNameservers indicates an expected call of DeleteVanityNameservers.
+func (mr *MockQuerierMockRecorder) DeleteVanityNameservers(ctx, organizationID any) *MockQuerierDeleteVanityNameserversCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVanityNameservers", reflect.TypeOf((*MockQuerier)(nil).DeleteVanityNameservers), ctx, organizationID)
+	return &MockQuerierDeleteVanityNameserversCall{Call: call}
+}
+
+// MockQuerierDeleteVanityNameserversCall wrap *gomock.Call
+type MockQuerierDeleteVanityNameserversCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteVanityNameserversCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteVanityNameserversCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteVanityNameserversCall) Do(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierDeleteVanityNameserversCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteVanityNameserversCall) DoAndReturn(f func(context.Context, uuid.UUID) (int64, error)) *MockQuerierDeleteVanityNameserversCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockQuerier) DeleteWebAuthnCredential(ctx context.Context, arg DeleteWebAuthnCredentialParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockQuerierMockRecorder) DeleteWebAuthnCredential(ctx, arg any) *MockQuerierDeleteWebAuthnCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockQuerier)(nil).DeleteWebAuthnCredential), ctx, arg)
+	return &MockQuerierDeleteWebAuthnCredentialCall{Call: call}
+}
+
+// MockQuerierDeleteWebAuthnCredentialCall wrap *gomock.Call
+type MockQuerierDeleteWebAuthnCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteWebAuthnCredentialCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteWebAuthnCredentialCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteWebAuthnCredentialCall) Do(f func(context.Context, DeleteWebAuthnCredentialParams) (int64, error)) *MockQuerierDeleteWebAuthnCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteWebAuthnCredentialCall) DoAndReturn(f func(context.Context, DeleteWebAuthnCredentialParams) (int64, error)) *MockQuerierDeleteWebAuthnCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteZone mocks base method.
+func (m *MockQuerier) DeleteZone(ctx context.Context, arg DeleteZoneParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteZone", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteZone indicates an expected call of DeleteZone.
+func (mr *MockQuerierMockRecorder) DeleteZone(ctx, arg any) *MockQuerierDeleteZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteZone", reflect.TypeOf((*MockQuerier)(nil).DeleteZone), ctx, arg)
+	return &MockQuerierDeleteZoneCall{Call: call}
+}
+
+// MockQuerierDeleteZoneCall wrap *gomock.Call
+type MockQuerierDeleteZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteZoneCall) Return(arg0 error) *MockQuerierDeleteZoneCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteZoneCall) Do(f func(context.Context, DeleteZoneParams) error) *MockQuerierDeleteZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteZoneCall) DoAndReturn(f func(context.Context, DeleteZoneParams) error) *MockQuerierDeleteZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteZoneTemplate mocks base method.
+func (m *MockQuerier) DeleteZoneTemplate(ctx context.Context, arg DeleteZoneTemplateParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteZoneTemplate", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteZoneTemplate indicates an expected call of DeleteZoneTemplate.
+func (mr *MockQuerierMockRecorder) DeleteZoneTemplate(ctx, arg any) *MockQuerierDeleteZoneTemplateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteZoneTemplate", reflect.TypeOf((*MockQuerier)(nil).DeleteZoneTemplate), ctx, arg)
+	return &MockQuerierDeleteZoneTemplateCall{Call: call}
+}
+
+// MockQuerierDeleteZoneTemplateCall wrap *gomock.Call
+type MockQuerierDeleteZoneTemplateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierDeleteZoneTemplateCall) Return(arg0 int64, arg1 error) *MockQuerierDeleteZoneTemplateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierDeleteZoneTemplateCall) Do(f func(context.Context, DeleteZoneTemplateParams) (int64, error)) *MockQuerierDeleteZoneTemplateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierDeleteZoneTemplateCall) DoAndReturn(f func(context.Context, DeleteZoneTemplateParams) (int64, error)) *MockQuerierDeleteZoneTemplateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAPITokenUser mocks base method.
+func (m *MockQuerier) GetAPITokenUser(ctx context.Context, tokenHash string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokenUser", ctx, tokenHash)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokenUser indicates an expected call of GetAPITokenUser.
+func (mr *MockQuerierMockRecorder) GetAPITokenUser(ctx, tokenHash any) *MockQuerierGetAPITokenUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokenUser", reflect.TypeOf((*MockQuerier)(nil).GetAPITokenUser), ctx, tokenHash)
+	return &MockQuerierGetAPITokenUserCall{Call: call}
+}
+
+// MockQuerierGetAPITokenUserCall wrap *gomock.Call
+type MockQuerierGetAPITokenUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetAPITokenUserCall) Return(arg0 User, arg1 error) *MockQuerierGetAPITokenUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetAPITokenUserCall) Do(f func(context.Context, string) (User, error)) *MockQuerierGetAPITokenUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetAPITokenUserCall) DoAndReturn(f func(context.Context, string) (User, error)) *MockQuerierGetAPITokenUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetActiveZone mocks base method.
+func (m *MockQuerier) GetActiveZone(ctx context.Context, zone string) (Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveZone", ctx, zone)
+	ret0, _ := ret[0].(Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveZone indicates an expected call of GetActiveZone.
+func (mr *MockQuerierMockRecorder) GetActiveZone(ctx, zone any) *MockQuerierGetActiveZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveZone", reflect.TypeOf((*MockQuerier)(nil).GetActiveZone), ctx, zone)
+	return &MockQuerierGetActiveZoneCall{Call: call}
+}
+
+// MockQuerierGetActiveZoneCall wrap *gomock.Call
+type MockQuerierGetActiveZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetActiveZoneCall) Return(arg0 Zone, arg1 error) *MockQuerierGetActiveZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetActiveZoneCall) Do(f func(context.Context, string) (Zone, error)) *MockQuerierGetActiveZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetActiveZoneCall) DoAndReturn(f func(context.Context, string) (Zone, error)) *MockQuerierGetActiveZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetLatestOTPByEmail mocks base method.
+func (m *MockQuerier) GetLatestOTPByEmail(ctx context.Context, email string) (OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestOTPByEmail", ctx, email)
+	ret0, _ := ret[0].(OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestOTPByEmail indicates an expected call of GetLatestOTPByEmail.
+func (mr *MockQuerierMockRecorder) GetLatestOTPByEmail(ctx, email any) *MockQuerierGetLatestOTPByEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestOTPByEmail", reflect.TypeOf((*MockQuerier)(nil).GetLatestOTPByEmail), ctx, email)
+	return &MockQuerierGetLatestOTPByEmailCall{Call: call}
+}
+
+// MockQuerierGetLatestOTPByEmailCall wrap *gomock.Call
+type MockQuerierGetLatestOTPByEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetLatestOTPByEmailCall) Return(arg0 OtpCode, arg1 error) *MockQuerierGetLatestOTPByEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetLatestOTPByEmailCall) Do(f func(context.Context, string) (OtpCode, error)) *MockQuerierGetLatestOTPByEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetLatestOTPByEmailCall) DoAndReturn(f func(context.Context, string) (OtpCode, error)) *MockQuerierGetLatestOTPByEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetOrganization mocks base method.
+func (m *MockQuerier) GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, id)
+	ret0, _ := ret[0].(Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockQuerierMockRecorder) GetOrganization(ctx, id any) *MockQuerierGetOrganizationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockQuerier)(nil).GetOrganization), ctx, id)
+	return &MockQuerierGetOrganizationCall{Call: call}
+}
+
+// MockQuerierGetOrganizationCall wrap *gomock.Call
+type MockQuerierGetOrganizationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetOrganizationCall) Return(arg0 Organization, arg1 error) *MockQuerierGetOrganizationCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetOrganizationCall) Do(f func(context.Context, uuid.UUID) (Organization, error)) *MockQuerierGetOrganizationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetOrganizationCall) DoAndReturn(f func(context.Context, uuid.UUID) (Organization, error)) *MockQuerierGetOrganizationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetOrganizationByName mocks base method.
+func (m *MockQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationByName", ctx, name)
+	ret0, _ := ret[0].(Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationByName indicates an expected call of GetOrganizationByName.
+func (mr *MockQuerierMockRecorder) GetOrganizationByName(ctx, name any) *MockQuerierGetOrganizationByNameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationByName", reflect.TypeOf((*MockQuerier)(nil).GetOrganizationByName), ctx, name)
+	return &MockQuerierGetOrganizationByNameCall{Call: call}
+}
+
+// MockQuerierGetOrganizationByNameCall wrap *gomock.Call
+type MockQuerierGetOrganizationByNameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetOrganizationByNameCall) Return(arg0 Organization, arg1 error) *MockQuerierGetOrganizationByNameCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetOrganizationByNameCall) Do(f func(context.Context, string) (Organization, error)) *MockQuerierGetOrganizationByNameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetOrganizationByNameCall) DoAndReturn(f func(context.Context, string) (Organization, error)) *MockQuerierGetOrganizationByNameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetRecordByID mocks base method.
+func (m *MockQuerier) GetRecordByID(ctx context.Context, arg GetRecordByIDParams) (CorednsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordByID", ctx, arg)
+	ret0, _ := ret[0].(CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordByID indicates an expected call of GetRecordByID.
+func (mr *MockQuerierMockRecorder) GetRecordByID(ctx, arg any) *MockQuerierGetRecordByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordByID", reflect.TypeOf((*MockQuerier)(nil).GetRecordByID), ctx, arg)
+	return &MockQuerierGetRecordByIDCall{Call: call}
+}
+
+// MockQuerierGetRecordByIDCall wrap *gomock.Call
+type MockQuerierGetRecordByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetRecordByIDCall) Return(arg0 CorednsRecord, arg1 error) *MockQuerierGetRecordByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetRecordByIDCall) Do(f func(context.Context, GetRecordByIDParams) (CorednsRecord, error)) *MockQuerierGetRecordByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetRecordByIDCall) DoAndReturn(f func(context.Context, GetRecordByIDParams) (CorednsRecord, error)) *MockQuerierGetRecordByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetTOTPCredential mocks base method.
+func (m *MockQuerier) GetTOTPCredential(ctx context.Context, userID uuid.UUID) (TotpCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPCredential", ctx, userID)
+	ret0, _ := ret[0].(TotpCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPCredential indicates an expected call of GetTOTPCredential.
+func (mr *MockQuerierMockRecorder) GetTOTPCredential(ctx, userID any) *MockQuerierGetTOTPCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPCredential", reflect.TypeOf((*MockQuerier)(nil).GetTOTPCredential), ctx, userID)
+	return &MockQuerierGetTOTPCredentialCall{Call: call}
+}
+
+// MockQuerierGetTOTPCredentialCall wrap *gomock.Call
+type MockQuerierGetTOTPCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetTOTPCredentialCall) Return(arg0 TotpCredential, arg1 error) *MockQuerierGetTOTPCredentialCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetTOTPCredentialCall) Do(f func(context.Context, uuid.UUID) (TotpCredential, error)) *MockQuerierGetTOTPCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetTOTPCredentialCall) DoAndReturn(f func(context.Context, uuid.UUID) (TotpCredential, error)) *MockQuerierGetTOTPCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockQuerier) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockQuerierMockRecorder) GetUserByEmail(ctx, email any) *MockQuerierGetUserByEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockQuerier)(nil).GetUserByEmail), ctx, email)
+	return &MockQuerierGetUserByEmailCall{Call: call}
+}
+
+// MockQuerierGetUserByEmailCall wrap *gomock.Call
+type MockQuerierGetUserByEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetUserByEmailCall) Return(arg0 User, arg1 error) *MockQuerierGetUserByEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetUserByEmailCall) Do(f func(context.Context, string) (User, error)) *MockQuerierGetUserByEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetUserByEmailCall) DoAndReturn(f func(context.Context, string) (User, error)) *MockQuerierGetUserByEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByID mocks base method.
+func (m *MockQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockQuerierMockRecorder) GetUserByID(ctx, id any) *MockQuerierGetUserByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockQuerier)(nil).GetUserByID), ctx, id)
+	return &MockQuerierGetUserByIDCall{Call: call}
+}
+
+// MockQuerierGetUserByIDCall wrap *gomock.Call
+type MockQuerierGetUserByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetUserByIDCall) Return(arg0 User, arg1 error) *MockQuerierGetUserByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (User, error)) *MockQuerierGetUserByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (User, error)) *MockQuerierGetUserByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserOrganization mocks base method.
+func (m *MockQuerier) GetUserOrganization(ctx context.Context, userID uuid.UUID) (Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrganization", ctx, userID)
+	ret0, _ := ret[0].(Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrganization indicates an expected call of GetUserOrganization.
+func (mr *MockQuerierMockRecorder) GetUserOrganization(ctx, userID any) *MockQuerierGetUserOrganizationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrganization", reflect.TypeOf((*MockQuerier)(nil).GetUserOrganization), ctx, userID)
+	return &MockQuerierGetUserOrganizationCall{Call: call}
+}
+
+// MockQuerierGetUserOrganizationCall wrap *gomock.Call
+type MockQuerierGetUserOrganizationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetUserOrganizationCall) Return(arg0 Organization, arg1 error) *MockQuerierGetUserOrganizationCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetUserOrganizationCall) Do(f func(context.Context, uuid.UUID) (Organization, error)) *MockQuerierGetUserOrganizationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetUserOrganizationCall) DoAndReturn(f func(context.Context, uuid.UUID) (Organization, error)) *MockQuerierGetUserOrganizationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserVanityNameservers mocks base method.
+func (m *MockQuerier) GetUserVanityNameservers(ctx context.Context, userID uuid.UUID) (VanityNameserver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserVanityNameservers", ctx, userID)
+	ret0, _ := ret[0].(VanityNameserver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVanityNameservers indicates an expected call of GetUserVanityNameservers.
+func (mr *MockQuerierMockRecorder) GetUserVanityNameservers(ctx, userID any) *MockQuerierGetUserVanityNameserversCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVanityNameservers", reflect.TypeOf((*MockQuerier)(nil).GetUserVanityNameservers), ctx, userID)
+	return &MockQuerierGetUserVanityNameserversCall{Call: call}
+}
+
+// MockQuerierGetUserVanityNameserversCall wrap *gomock.Call
+type MockQuerierGetUserVanityNameserversCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetUserVanityNameserversCall) Return(arg0 VanityNameserver, arg1 error) *MockQuerierGetUserVanityNameserversCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetUserVanityNameserversCall) Do(f func(context.Context, uuid.UUID) (VanityNameserver, error)) *MockQuerierGetUserVanityNameserversCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetUserVanityNameserversCall) DoAndReturn(f func(context.Context, uuid.UUID) (VanityNameserver, error)) *MockQuerierGetUserVanityNameserversCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetZone mocks base method.
+func (m *MockQuerier) GetZone(ctx context.Context, arg GetZoneParams) (Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetZone", ctx, arg)
+	ret0, _ := ret[0].(Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetZone indicates an expected call of GetZone.
+func (mr *MockQuerierMockRecorder) GetZone(ctx, arg any) *MockQuerierGetZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZone", reflect.TypeOf((*MockQuerier)(nil).GetZone), ctx, arg)
+	return &MockQuerierGetZoneCall{Call: call}
+}
+
+// MockQuerierGetZoneCall wrap *gomock.Call
+type MockQuerierGetZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetZoneCall) Return(arg0 Zone, arg1 error) *MockQuerierGetZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetZoneCall) Do(f func(context.Context, GetZoneParams) (Zone, error)) *MockQuerierGetZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetZoneCall) DoAndReturn(f func(context.Context, GetZoneParams) (Zone, error)) *MockQuerierGetZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetZoneTemplate mocks base method.
+func (m *MockQuerier) GetZoneTemplate(ctx context.Context, arg GetZoneTemplateParams) (ZoneTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetZoneTemplate", ctx, arg)
+	ret0, _ := ret[0].(ZoneTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetZoneTemplate indicates an expected call of GetZoneTemplate.
+func (mr *MockQuerierMockRecorder) GetZoneTemplate(ctx, arg any) *MockQuerierGetZoneTemplateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZoneTemplate", reflect.TypeOf((*MockQuerier)(nil).GetZoneTemplate), ctx, arg)
+	return &MockQuerierGetZoneTemplateCall{Call: call}
+}
+
+// MockQuerierGetZoneTemplateCall wrap *gomock.Call
+type MockQuerierGetZoneTemplateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetZoneTemplateCall) Return(arg0 ZoneTemplate, arg1 error) *MockQuerierGetZoneTemplateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetZoneTemplateCall) Do(f func(context.Context, GetZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierGetZoneTemplateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetZoneTemplateCall) DoAndReturn(f func(context.Context, GetZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierGetZoneTemplateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetZoneTemplateByName mocks base method.
+func (m *MockQuerier) GetZoneTemplateByName(ctx context.Context, arg GetZoneTemplateByNameParams) (ZoneTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetZoneTemplateByName", ctx, arg)
+	ret0, _ := ret[0].(ZoneTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetZoneTemplateByName indicates an expected call of GetZoneTemplateByName.
+func (mr *MockQuerierMockRecorder) GetZoneTemplateByName(ctx, arg any) *MockQuerierGetZoneTemplateByNameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZoneTemplateByName", reflect.TypeOf((*MockQuerier)(nil).GetZoneTemplateByName), ctx, arg)
+	return &MockQuerierGetZoneTemplateByNameCall{Call: call}
+}
+
+// MockQuerierGetZoneTemplateByNameCall wrap *gomock.Call
+type MockQuerierGetZoneTemplateByNameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierGetZoneTemplateByNameCall) Return(arg0 ZoneTemplate, arg1 error) *MockQuerierGetZoneTemplateByNameCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierGetZoneTemplateByNameCall) Do(f func(context.Context, GetZoneTemplateByNameParams) (ZoneTemplate, error)) *MockQuerierGetZoneTemplateByNameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierGetZoneTemplateByNameCall) DoAndReturn(f func(context.Context, GetZoneTemplateByNameParams) (ZoneTemplate, error)) *MockQuerierGetZoneTemplateByNameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListAPITokensByUser mocks base method.
+func (m *MockQuerier) ListAPITokensByUser(ctx context.Context, userID uuid.UUID) ([]ApiToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPITokensByUser", ctx, userID)
+	ret0, _ := ret[0].([]ApiToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPITokensByUser indicates an expected call of ListAPITokensByUser.
+func (mr *MockQuerierMockRecorder) ListAPITokensByUser(ctx, userID any) *MockQuerierListAPITokensByUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokensByUser", reflect.TypeOf((*MockQuerier)(nil).ListAPITokensByUser), ctx, userID)
+	return &MockQuerierListAPITokensByUserCall{Call: call}
+}
+
+// MockQuerierListAPITokensByUserCall wrap *gomock.Call
+type MockQuerierListAPITokensByUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListAPITokensByUserCall) Return(arg0 []ApiToken, arg1 error) *MockQuerierListAPITokensByUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListAPITokensByUserCall) Do(f func(context.Context, uuid.UUID) ([]ApiToken, error)) *MockQuerierListAPITokensByUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListAPITokensByUserCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]ApiToken, error)) *MockQuerierListAPITokensByUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListInvalidRecordsByZone mocks base method.
+func (m *MockQuerier) ListInvalidRecordsByZone(ctx context.Context, arg ListInvalidRecordsByZoneParams) ([]CorednsRecordsInvalid, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvalidRecordsByZone", ctx, arg)
+	ret0, _ := ret[0].([]CorednsRecordsInvalid)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvalidRecordsByZone indicates an expected call of ListInvalidRecordsByZone.
+func (mr *MockQuerierMockRecorder) ListInvalidRecordsByZone(ctx, arg any) *MockQuerierListInvalidRecordsByZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvalidRecordsByZone", reflect.TypeOf((*MockQuerier)(nil).ListInvalidRecordsByZone), ctx, arg)
+	return &MockQuerierListInvalidRecordsByZoneCall{Call: call}
+}
+
+// MockQuerierListInvalidRecordsByZoneCall wrap *gomock.Call
+type MockQuerierListInvalidRecordsByZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListInvalidRecordsByZoneCall) Return(arg0 []CorednsRecordsInvalid, arg1 error) *MockQuerierListInvalidRecordsByZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListInvalidRecordsByZoneCall) Do(f func(context.Context, ListInvalidRecordsByZoneParams) ([]CorednsRecordsInvalid, error)) *MockQuerierListInvalidRecordsByZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListInvalidRecordsByZoneCall) DoAndReturn(f func(context.Context, ListInvalidRecordsByZoneParams) ([]CorednsRecordsInvalid, error)) *MockQuerierListInvalidRecordsByZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListOrganizationMembers mocks base method.
+func (m *MockQuerier) ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationMembers", ctx, organizationID)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationMembers indicates an expected call of ListOrganizationMembers.
+func (mr *MockQuerierMockRecorder) ListOrganizationMembers(ctx, organizationID any) *MockQuerierListOrganizationMembersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationMembers", reflect.TypeOf((*MockQuerier)(nil).ListOrganizationMembers), ctx, organizationID)
+	return &MockQuerierListOrganizationMembersCall{Call: call}
+}
+
+// MockQuerierListOrganizationMembersCall wrap *gomock.Call
+type MockQuerierListOrganizationMembersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListOrganizationMembersCall) Return(arg0 []User, arg1 error) *MockQuerierListOrganizationMembersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListOrganizationMembersCall) Do(f func(context.Context, uuid.UUID) ([]User, error)) *MockQuerierListOrganizationMembersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListOrganizationMembersCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]User, error)) *MockQuerierListOrganizationMembersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListOrganizations mocks base method.
+func (m *MockQuerier) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations", ctx)
+	ret0, _ := ret[0].([]Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockQuerierMockRecorder) ListOrganizations(ctx any) *MockQuerierListOrganizationsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockQuerier)(nil).ListOrganizations), ctx)
+	return &MockQuerierListOrganizationsCall{Call: call}
+}
+
+// MockQuerierListOrganizationsCall wrap *gomock.Call
+type MockQuerierListOrganizationsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListOrganizationsCall) Return(arg0 []Organization, arg1 error) *MockQuerierListOrganizationsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListOrganizationsCall) Do(f func(context.Context) ([]Organization, error)) *MockQuerierListOrganizationsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListOrganizationsCall) DoAndReturn(f func(context.Context) ([]Organization, error)) *MockQuerierListOrganizationsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListPendingZones mocks base method.
+func (m *MockQuerier) ListPendingZones(ctx context.Context, limit int32) ([]Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingZones", ctx, limit)
+	ret0, _ := ret[0].([]Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingZones indicates an expected call of ListPendingZones.
+func (mr *MockQuerierMockRecorder) ListPendingZones(ctx, limit any) *MockQuerierListPendingZonesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingZones", reflect.TypeOf((*MockQuerier)(nil).ListPendingZones), ctx, limit)
+	return &MockQuerierListPendingZonesCall{Call: call}
+}
+
+// MockQuerierListPendingZonesCall wrap *gomock.Call
+type MockQuerierListPendingZonesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListPendingZonesCall) Return(arg0 []Zone, arg1 error) *MockQuerierListPendingZonesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListPendingZonesCall) Do(f func(context.Context, int32) ([]Zone, error)) *MockQuerierListPendingZonesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListPendingZonesCall) DoAndReturn(f func(context.Context, int32) ([]Zone, error)) *MockQuerierListPendingZonesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListRecords mocks base method.
+func (m *MockQuerier) ListRecords(ctx context.Context, arg ListRecordsParams) ([]CorednsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecords", ctx, arg)
+	ret0, _ := ret[0].([]CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecords indicates an expected call of ListRecords.
+func (mr *MockQuerierMockRecorder) ListRecords(ctx, arg any) *MockQuerierListRecordsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecords", reflect.TypeOf((*MockQuerier)(nil).ListRecords), ctx, arg)
+	return &MockQuerierListRecordsCall{Call: call}
+}
+
+// MockQuerierListRecordsCall wrap *gomock.Call
+type MockQuerierListRecordsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListRecordsCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListRecordsCall) Do(f func(context.Context, ListRecordsParams) ([]CorednsRecord, error)) *MockQuerierListRecordsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListRecordsCall) DoAndReturn(f func(context.Context, ListRecordsParams) ([]CorednsRecord, error)) *MockQuerierListRecordsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListRecordsByRRset mocks base method.
+func (m *MockQuerier) ListRecordsByRRset(ctx context.Context, arg ListRecordsByRRsetParams) ([]CorednsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecordsByRRset", ctx, arg)
+	ret0, _ := ret[0].([]CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecordsByRRset indicates an expected call of ListRecordsByRRset.
+func (mr *MockQuerierMockRecorder) ListRecordsByRRset(ctx, arg any) *MockQuerierListRecordsByRRsetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordsByRRset", reflect.TypeOf((*MockQuerier)(nil).ListRecordsByRRset), ctx, arg)
+	return &MockQuerierListRecordsByRRsetCall{Call: call}
+}
+
+// MockQuerierListRecordsByRRsetCall wrap *gomock.Call
+type MockQuerierListRecordsByRRsetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListRecordsByRRsetCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsByRRsetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListRecordsByRRsetCall) Do(f func(context.Context, ListRecordsByRRsetParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByRRsetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListRecordsByRRsetCall) DoAndReturn(f func(context.Context, ListRecordsByRRsetParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByRRsetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListRecordsByZone mocks base method.
+func (m *MockQuerier) ListRecordsByZone(ctx context.Context, arg ListRecordsByZoneParams) ([]CorednsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecordsByZone", ctx, arg)
+	ret0, _ := ret[0].([]CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecordsByZone indicates an expected call of ListRecordsByZone.
+func (mr *MockQuerierMockRecorder) ListRecordsByZone(ctx, arg any) *MockQuerierListRecordsByZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordsByZone", reflect.TypeOf((*MockQuerier)(nil).ListRecordsByZone), ctx, arg)
+	return &MockQuerierListRecordsByZoneCall{Call: call}
+}
+
+// MockQuerierListRecordsByZoneCall wrap *gomock.Call
+type MockQuerierListRecordsByZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListRecordsByZoneCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsByZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListRecordsByZoneCall) Do(f func(context.Context, ListRecordsByZoneParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListRecordsByZoneCall) DoAndReturn(f func(context.Context, ListRecordsByZoneParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListSigningKeys mocks base method.
+func (m *MockQuerier) ListSigningKeys(ctx context.Context) ([]SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSigningKeys", ctx)
+	ret0, _ := ret[0].([]SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSigningKeys indicates an expected call of ListSigningKeys.
+func (mr *MockQuerierMockRecorder) ListSigningKeys(ctx any) *MockQuerierListSigningKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSigningKeys", reflect.TypeOf((*MockQuerier)(nil).ListSigningKeys), ctx)
+	return &MockQuerierListSigningKeysCall{Call: call}
+}
+
+// MockQuerierListSigningKeysCall wrap *gomock.Call
+type MockQuerierListSigningKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListSigningKeysCall) Return(arg0 []SigningKey, arg1 error) *MockQuerierListSigningKeysCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListSigningKeysCall) Do(f func(context.Context) ([]SigningKey, error)) *MockQuerierListSigningKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListSigningKeysCall) DoAndReturn(f func(context.Context) ([]SigningKey, error)) *MockQuerierListSigningKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListUserRecordsByType mocks base method.
+func (m *MockQuerier) ListUserRecordsByType(ctx context.Context, arg ListUserRecordsByTypeParams) ([]CorednsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRecordsByType", ctx, arg)
+	ret0, _ := ret[0].([]CorednsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserRecordsByType indicates an expected call of ListUserRecordsByType.
+func (mr *MockQuerierMockRecorder) ListUserRecordsByType(ctx, arg any) *MockQuerierListUserRecordsByTypeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRecordsByType", reflect.TypeOf((*MockQuerier)(nil).ListUserRecordsByType), ctx, arg)
+	return &MockQuerierListUserRecordsByTypeCall{Call: call}
+}
+
+// MockQuerierListUserRecordsByTypeCall wrap *gomock.Call
+type MockQuerierListUserRecordsByTypeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListUserRecordsByTypeCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListUserRecordsByTypeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListUserRecordsByTypeCall) Do(f func(context.Context, ListUserRecordsByTypeParams) ([]CorednsRecord, error)) *MockQuerierListUserRecordsByTypeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListUserRecordsByTypeCall) DoAndReturn(f func(context.Context, ListUserRecordsByTypeParams) ([]CorednsRecord, error)) *MockQuerierListUserRecordsByTypeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWebAuthnCredentialsByUser mocks base method.
+func (m *MockQuerier) ListWebAuthnCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebAuthnCredentialsByUser", ctx, userID)
+	ret0, _ := ret[0].([]WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebAuthnCredentialsByUser indicates an expected call of ListWebAuthnCredentialsByUser.
+func (mr *MockQuerierMockRecorder) ListWebAuthnCredentialsByUser(ctx, userID any) *MockQuerierListWebAuthnCredentialsByUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebAuthnCredentialsByUser", reflect.TypeOf((*MockQuerier)(nil).ListWebAuthnCredentialsByUser), ctx, userID)
+	return &MockQuerierListWebAuthnCredentialsByUserCall{Call: call}
+}
+
+// MockQuerierListWebAuthnCredentialsByUserCall wrap *gomock.Call
+type MockQuerierListWebAuthnCredentialsByUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListWebAuthnCredentialsByUserCall) Return(arg0 []WebauthnCredential, arg1 error) *MockQuerierListWebAuthnCredentialsByUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListWebAuthnCredentialsByUserCall) Do(f func(context.Context, uuid.UUID) ([]WebauthnCredential, error)) *MockQuerierListWebAuthnCredentialsByUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListWebAuthnCredentialsByUserCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]WebauthnCredential, error)) *MockQuerierListWebAuthnCredentialsByUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListZoneTemplates mocks base method.
+func (m *MockQuerier) ListZoneTemplates(ctx context.Context, userID uuid.UUID) ([]ZoneTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListZoneTemplates", ctx, userID)
+	ret0, _ := ret[0].([]ZoneTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListZoneTemplates indicates an expected call of ListZoneTemplates.
+func (mr *MockQuerierMockRecorder) ListZoneTemplates(ctx, userID any) *MockQuerierListZoneTemplatesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZoneTemplates", reflect.TypeOf((*MockQuerier)(nil).ListZoneTemplates), ctx, userID)
+	return &MockQuerierListZoneTemplatesCall{Call: call}
+}
+
+// MockQuerierListZoneTemplatesCall wrap *gomock.Call
+type MockQuerierListZoneTemplatesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListZoneTemplatesCall) Return(arg0 []ZoneTemplate, arg1 error) *MockQuerierListZoneTemplatesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListZoneTemplatesCall) Do(f func(context.Context, uuid.UUID) ([]ZoneTemplate, error)) *MockQuerierListZoneTemplatesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListZoneTemplatesCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]ZoneTemplate, error)) *MockQuerierListZoneTemplatesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListZones mocks base method.
+func (m *MockQuerier) ListZones(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListZones", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListZones indicates an expected call of ListZones.
+func (mr *MockQuerierMockRecorder) ListZones(ctx, userID any) *MockQuerierListZonesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZones", reflect.TypeOf((*MockQuerier)(nil).ListZones), ctx, userID)
+	return &MockQuerierListZonesCall{Call: call}
+}
+
+// MockQuerierListZonesCall wrap *gomock.Call
+type MockQuerierListZonesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListZonesCall) Return(arg0 []string, arg1 error) *MockQuerierListZonesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListZonesCall) Do(f func(context.Context, uuid.UUID) ([]string, error)) *MockQuerierListZonesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListZonesCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]string, error)) *MockQuerierListZonesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListZonesByUser mocks base method.
+func (m *MockQuerier) ListZonesByUser(ctx context.Context, userID uuid.UUID) ([]Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListZonesByUser", ctx, userID)
+	ret0, _ := ret[0].([]Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListZonesByUser indicates an expected call of ListZonesByUser.
+func (mr *MockQuerierMockRecorder) ListZonesByUser(ctx, userID any) *MockQuerierListZonesByUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZonesByUser", reflect.TypeOf((*MockQuerier)(nil).ListZonesByUser), ctx, userID)
+	return &MockQuerierListZonesByUserCall{Call: call}
+}
+
+// MockQuerierListZonesByUserCall wrap *gomock.Call
+type MockQuerierListZonesByUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierListZonesByUserCall) Return(arg0 []Zone, arg1 error) *MockQuerierListZonesByUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierListZonesByUserCall) Do(f func(context.Context, uuid.UUID) ([]Zone, error)) *MockQuerierListZonesByUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierListZonesByUserCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]Zone, error)) *MockQuerierListZonesByUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LockZone mocks base method.
+func (m *MockQuerier) LockZone(ctx context.Context, arg LockZoneParams) (Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockZone", ctx, arg)
+	ret0, _ := ret[0].(Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockZone indicates an expected call of LockZone.
+func (mr *MockQuerierMockRecorder) LockZone(ctx, arg any) *MockQuerierLockZoneCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockZone", reflect.TypeOf((*MockQuerier)(nil).LockZone), ctx, arg)
+	return &MockQuerierLockZoneCall{Call: call}
+}
+
+// MockQuerierLockZoneCall wrap *gomock.Call
+type MockQuerierLockZoneCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierLockZoneCall) Return(arg0 Zone, arg1 error) *MockQuerierLockZoneCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierLockZoneCall) Do(f func(context.Context, LockZoneParams) (Zone, error)) *MockQuerierLockZoneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierLockZoneCall) DoAndReturn(f func(context.Context, LockZoneParams) (Zone, error)) *MockQuerierLockZoneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemoveOrganizationMember mocks base method.
+func (m *MockQuerier) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrganizationMember", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveOrganizationMember indicates an expected call of RemoveOrganizationMember.
+func (mr *MockQuerierMockRecorder) RemoveOrganizationMember(ctx, arg any) *MockQuerierRemoveOrganizationMemberCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrganizationMember", reflect.TypeOf((*MockQuerier)(nil).RemoveOrganizationMember), ctx, arg)
+	return &MockQuerierRemoveOrganizationMemberCall{Call: call}
+}
+
+// MockQuerierRemoveOrganizationMemberCall wrap *gomock.Call
+type MockQuerierRemoveOrganizationMemberCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierRemoveOrganizationMemberCall) Return(arg0 int64, arg1 error) *MockQuerierRemoveOrganizationMemberCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierRemoveOrganizationMemberCall) Do(f func(context.Context, RemoveOrganizationMemberParams) (int64, error)) *MockQuerierRemoveOrganizationMemberCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierRemoveOrganizationMemberCall) DoAndReturn(f func(context.Context, RemoveOrganizationMemberParams) (int64, error)) *MockQuerierRemoveOrganizationMemberCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockQuerier) ReplaceRecoveryCodes(ctx context.Context, arg ReplaceRecoveryCodesParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// CreateRecord indicates an expected call of CreateRecord.
-func (mr *MockQuerierMockRecorder) CreateRecord(ctx, arg any) *MockQuerierCreateRecordCall {
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockQuerierMockRecorder) ReplaceRecoveryCodes(ctx, arg any) *MockQuerierReplaceRecoveryCodesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecord", reflect.TypeOf((*MockQuerier)(nil).CreateRecord), ctx, arg)
-	return &MockQuerierCreateRecordCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockQuerier)(nil).ReplaceRecoveryCodes), ctx, arg)
+	return &MockQuerierReplaceRecoveryCodesCall{Call: call}
 }
 
-// MockQuerierCreateRecordCall wrap *gomock.Call
-type MockQuerierCreateRecordCall struct {
+// MockQuerierReplaceRecoveryCodesCall wrap *gomock.Call
+type MockQuerierReplaceRecoveryCodesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierCreateRecordCall) Return(arg0 CorednsRecord, arg1 error) *MockQuerierCreateRecordCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQuerierReplaceRecoveryCodesCall) Return(arg0 error) *MockQuerierReplaceRecoveryCodesCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierCreateRecordCall) Do(f func(context.Context, CreateRecordParams) (CorednsRecord, error)) *MockQuerierCreateRecordCall {
+func (c *MockQuerierReplaceRecoveryCodesCall) Do(f func(context.Context, ReplaceRecoveryCodesParams) error) *MockQuerierReplaceRecoveryCodesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierCreateRecordCall) DoAndReturn(f func(context.Context, CreateRecordParams) (CorednsRecord, error)) *MockQuerierCreateRecordCall {
+func (c *MockQuerierReplaceRecoveryCodesCall) DoAndReturn(f func(context.Context, ReplaceRecoveryCodesParams) error) *MockQuerierReplaceRecoveryCodesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// DeleteRecord mocks base method.
-func (m *MockQuerier) DeleteRecord(ctx context.Context, arg DeleteRecordParams) error {
+// ResetTOTPAttempts mocks base method.
+func (m *MockQuerier) ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRecord", ctx, arg)
+	ret := m.ctrl.Call(m, "ResetTOTPAttempts", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteRecord indicates an expected call of DeleteRecord.
-func (mr *MockQuerierMockRecorder) DeleteRecord(ctx, arg any) *MockQuerierDeleteRecordCall {
+// ResetTOTPAttempts indicates an expected call of ResetTOTPAttempts.
+func (mr *MockQuerierMockRecorder) ResetTOTPAttempts(ctx, userID any) *MockQuerierResetTOTPAttemptsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecord", reflect.TypeOf((*MockQuerier)(nil).DeleteRecord), ctx, arg)
-	return &MockQuerierDeleteRecordCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTOTPAttempts", reflect.TypeOf((*MockQuerier)(nil).ResetTOTPAttempts), ctx, userID)
+	return &MockQuerierResetTOTPAttemptsCall{Call: call}
 }
 
-// MockQuerierDeleteRecordCall wrap *gomock.Call
-type MockQuerierDeleteRecordCall struct {
+// MockQuerierResetTOTPAttemptsCall wrap *gomock.Call
+type MockQuerierResetTOTPAttemptsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierDeleteRecordCall) Return(arg0 error) *MockQuerierDeleteRecordCall {
+func (c *MockQuerierResetTOTPAttemptsCall) Return(arg0 error) *MockQuerierResetTOTPAttemptsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierDeleteRecordCall) Do(f func(context.Context, DeleteRecordParams) error) *MockQuerierDeleteRecordCall {
+func (c *MockQuerierResetTOTPAttemptsCall) Do(f func(context.Context, uuid.UUID) error) *MockQuerierResetTOTPAttemptsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierDeleteRecordCall) DoAndReturn(f func(context.Context, DeleteRecordParams) error) *MockQuerierDeleteRecordCall {
+func (c *MockQuerierResetTOTPAttemptsCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockQuerierResetTOTPAttemptsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetRecordByID mocks base method.
-func (m *MockQuerier) GetRecordByID(ctx context.Context, arg GetRecordByIDParams) (CorednsRecord, error) {
+// SearchRecords mocks base method.
+func (m *MockQuerier) SearchRecords(ctx context.Context, arg SearchRecordsParams) ([]SearchRecordsRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRecordByID", ctx, arg)
-	ret0, _ := ret[0].(CorednsRecord)
+	ret := m.ctrl.Call(m, "SearchRecords", ctx, arg)
+	ret0, _ := ret[0].([]SearchRecordsRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRecordByID indicates an expected call of GetRecordByID.
-func (mr *MockQuerierMockRecorder) GetRecordByID(ctx, arg any) *MockQuerierGetRecordByIDCall {
+// SearchRecords indicates an expected call of SearchRecords.
+func (mr *MockQuerierMockRecorder) SearchRecords(ctx, arg any) *MockQuerierSearchRecordsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordByID", reflect.TypeOf((*MockQuerier)(nil).GetRecordByID), ctx, arg)
-	return &MockQuerierGetRecordByIDCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchRecords", reflect.TypeOf((*MockQuerier)(nil).SearchRecords), ctx, arg)
+	return &MockQuerierSearchRecordsCall{Call: call}
 }
 
-// MockQuerierGetRecordByIDCall wrap *gomock.Call
-type MockQuerierGetRecordByIDCall struct {
+// MockQuerierSearchRecordsCall wrap *gomock.Call
+type MockQuerierSearchRecordsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierGetRecordByIDCall) Return(arg0 CorednsRecord, arg1 error) *MockQuerierGetRecordByIDCall {
+func (c *MockQuerierSearchRecordsCall) Return(arg0 []SearchRecordsRow, arg1 error) *MockQuerierSearchRecordsCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierGetRecordByIDCall) Do(f func(context.Context, GetRecordByIDParams) (CorednsRecord, error)) *MockQuerierGetRecordByIDCall {
+func (c *MockQuerierSearchRecordsCall) Do(f func(context.Context, SearchRecordsParams) ([]SearchRecordsRow, error)) *MockQuerierSearchRecordsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierGetRecordByIDCall) DoAndReturn(f func(context.Context, GetRecordByIDParams) (CorednsRecord, error)) *MockQuerierGetRecordByIDCall {
+func (c *MockQuerierSearchRecordsCall) DoAndReturn(f func(context.Context, SearchRecordsParams) ([]SearchRecordsRow, error)) *MockQuerierSearchRecordsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ListRecords mocks base method.
-func (m *MockQuerier) ListRecords(ctx context.Context, zone string) ([]CorednsRecord, error) {
+// SearchUserRecords mocks base method.
+func (m *MockQuerier) SearchUserRecords(ctx context.Context, arg SearchUserRecordsParams) ([]SearchUserRecordsRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListRecords", ctx, zone)
-	ret0, _ := ret[0].([]CorednsRecord)
+	ret := m.ctrl.Call(m, "SearchUserRecords", ctx, arg)
+	ret0, _ := ret[0].([]SearchUserRecordsRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListRecords indicates an expected call of ListRecords.
-func (mr *MockQuerierMockRecorder) ListRecords(ctx, zone any) *MockQuerierListRecordsCall {
+// SearchUserRecords indicates an expected call of SearchUserRecords.
+func (mr *MockQuerierMockRecorder) SearchUserRecords(ctx, arg any) *MockQuerierSearchUserRecordsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecords", reflect.TypeOf((*MockQuerier)(nil).ListRecords), ctx, zone)
-	return &MockQuerierListRecordsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUserRecords", reflect.TypeOf((*MockQuerier)(nil).SearchUserRecords), ctx, arg)
+	return &MockQuerierSearchUserRecordsCall{Call: call}
 }
 
-// MockQuerierListRecordsCall wrap *gomock.Call
-type MockQuerierListRecordsCall struct {
+// MockQuerierSearchUserRecordsCall wrap *gomock.Call
+type MockQuerierSearchUserRecordsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierListRecordsCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsCall {
+func (c *MockQuerierSearchUserRecordsCall) Return(arg0 []SearchUserRecordsRow, arg1 error) *MockQuerierSearchUserRecordsCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierListRecordsCall) Do(f func(context.Context, string) ([]CorednsRecord, error)) *MockQuerierListRecordsCall {
+func (c *MockQuerierSearchUserRecordsCall) Do(f func(context.Context, SearchUserRecordsParams) ([]SearchUserRecordsRow, error)) *MockQuerierSearchUserRecordsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierListRecordsCall) DoAndReturn(f func(context.Context, string) ([]CorednsRecord, error)) *MockQuerierListRecordsCall {
+func (c *MockQuerierSearchUserRecordsCall) DoAndReturn(f func(context.Context, SearchUserRecordsParams) ([]SearchUserRecordsRow, error)) *MockQuerierSearchUserRecordsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ListRecordsByName mocks base method.
-func (m *MockQuerier) ListRecordsByName(ctx context.Context, arg ListRecordsByNameParams) ([]CorednsRecord, error) {
+// SearchZones mocks base method.
+func (m *MockQuerier) SearchZones(ctx context.Context, arg SearchZonesParams) ([]SearchZonesRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListRecordsByName", ctx, arg)
-	ret0, _ := ret[0].([]CorednsRecord)
+	ret := m.ctrl.Call(m, "SearchZones", ctx, arg)
+	ret0, _ := ret[0].([]SearchZonesRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListRecordsByName indicates an expected call of ListRecordsByName.
-func (mr *MockQuerierMockRecorder) ListRecordsByName(ctx, arg any) *MockQuerierListRecordsByNameCall {
+// SearchZones indicates an expected call of SearchZones.
+func (mr *MockQuerierMockRecorder) SearchZones(ctx, arg any) *MockQuerierSearchZonesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordsByName", reflect.TypeOf((*MockQuerier)(nil).ListRecordsByName), ctx, arg)
-	return &MockQuerierListRecordsByNameCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchZones", reflect.TypeOf((*MockQuerier)(nil).SearchZones), ctx, arg)
+	return &MockQuerierSearchZonesCall{Call: call}
 }
 
-// MockQuerierListRecordsByNameCall wrap *gomock.Call
-type MockQuerierListRecordsByNameCall struct {
+// MockQuerierSearchZonesCall wrap *gomock.Call
+type MockQuerierSearchZonesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierListRecordsByNameCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsByNameCall {
+func (c *MockQuerierSearchZonesCall) Return(arg0 []SearchZonesRow, arg1 error) *MockQuerierSearchZonesCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierListRecordsByNameCall) Do(f func(context.Context, ListRecordsByNameParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByNameCall {
+func (c *MockQuerierSearchZonesCall) Do(f func(context.Context, SearchZonesParams) ([]SearchZonesRow, error)) *MockQuerierSearchZonesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierListRecordsByNameCall) DoAndReturn(f func(context.Context, ListRecordsByNameParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByNameCall {
+func (c *MockQuerierSearchZonesCall) DoAndReturn(f func(context.Context, SearchZonesParams) ([]SearchZonesRow, error)) *MockQuerierSearchZonesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ListRecordsByType mocks base method.
-func (m *MockQuerier) ListRecordsByType(ctx context.Context, arg ListRecordsByTypeParams) ([]CorednsRecord, error) {
+// SetOrganizationTwoFactor mocks base method.
+func (m *MockQuerier) SetOrganizationTwoFactor(ctx context.Context, arg SetOrganizationTwoFactorParams) (Organization, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListRecordsByType", ctx, arg)
-	ret0, _ := ret[0].([]CorednsRecord)
+	ret := m.ctrl.Call(m, "SetOrganizationTwoFactor", ctx, arg)
+	ret0, _ := ret[0].(Organization)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListRecordsByType indicates an expected call of ListRecordsByType.
-func (mr *MockQuerierMockRecorder) ListRecordsByType(ctx, arg any) *MockQuerierListRecordsByTypeCall {
+// SetOrganizationTwoFactor indicates an expected call of SetOrganizationTwoFactor.
+func (mr *MockQuerierMockRecorder) SetOrganizationTwoFactor(ctx, arg any) *MockQuerierSetOrganizationTwoFactorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordsByType", reflect.TypeOf((*MockQuerier)(nil).ListRecordsByType), ctx, arg)
-	return &MockQuerierListRecordsByTypeCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrganizationTwoFactor", reflect.TypeOf((*MockQuerier)(nil).SetOrganizationTwoFactor), ctx, arg)
+	return &MockQuerierSetOrganizationTwoFactorCall{Call: call}
 }
 
-// MockQuerierListRecordsByTypeCall wrap *gomock.Call
-type MockQuerierListRecordsByTypeCall struct {
+// MockQuerierSetOrganizationTwoFactorCall wrap *gomock.Call
+type MockQuerierSetOrganizationTwoFactorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierListRecordsByTypeCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsByTypeCall {
+func (c *MockQuerierSetOrganizationTwoFactorCall) Return(arg0 Organization, arg1 error) *MockQuerierSetOrganizationTwoFactorCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierListRecordsByTypeCall) Do(f func(context.Context, ListRecordsByTypeParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByTypeCall {
+func (c *MockQuerierSetOrganizationTwoFactorCall) Do(f func(context.Context, SetOrganizationTwoFactorParams) (Organization, error)) *MockQuerierSetOrganizationTwoFactorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierListRecordsByTypeCall) DoAndReturn(f func(context.Context, ListRecordsByTypeParams) ([]CorednsRecord, error)) *MockQuerierListRecordsByTypeCall {
+func (c *MockQuerierSetOrganizationTwoFactorCall) DoAndReturn(f func(context.Context, SetOrganizationTwoFactorParams) (Organization, error)) *MockQuerierSetOrganizationTwoFactorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ListRecordsByZone mocks base method.
-func (m *MockQuerier) ListRecordsByZone(ctx context.Context, zone string) ([]CorednsRecord, error) {
+// SetVanityNameservers mocks base method.
+func (m *MockQuerier) SetVanityNameservers(ctx context.Context, arg SetVanityNameserversParams) (VanityNameserver, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListRecordsByZone", ctx, zone)
-	ret0, _ := ret[0].([]CorednsRecord)
+	ret := m.ctrl.Call(m, "SetVanityNameservers", ctx, arg)
+	ret0, _ := ret[0].(VanityNameserver)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListRecordsByZone indicates an expected call of ListRecordsByZone.
-func (mr *MockQuerierMockRecorder) ListRecordsByZone(ctx, zone any) *MockQuerierListRecordsByZoneCall {
+// SetVanityNameservers indicates an expected call of SetVanityNameservers.
+func (mr *MockQuerierMockRecorder) SetVanityNameservers(ctx, arg any) *MockQuerierSetVanityNameserversCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordsByZone", reflect.TypeOf((*MockQuerier)(nil).ListRecordsByZone), ctx, zone)
-	return &MockQuerierListRecordsByZoneCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVanityNameservers", reflect.TypeOf((*MockQuerier)(nil).SetVanityNameservers), ctx, arg)
+	return &MockQuerierSetVanityNameserversCall{Call: call}
 }
 
-// MockQuerierListRecordsByZoneCall wrap *gomock.Call
-type MockQuerierListRecordsByZoneCall struct {
+// MockQuerierSetVanityNameserversCall wrap *gomock.Call
+type MockQuerierSetVanityNameserversCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierListRecordsByZoneCall) Return(arg0 []CorednsRecord, arg1 error) *MockQuerierListRecordsByZoneCall {
+func (c *MockQuerierSetVanityNameserversCall) Return(arg0 VanityNameserver, arg1 error) *MockQuerierSetVanityNameserversCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierListRecordsByZoneCall) Do(f func(context.Context, string) ([]CorednsRecord, error)) *MockQuerierListRecordsByZoneCall {
+func (c *MockQuerierSetVanityNameserversCall) Do(f func(context.Context, SetVanityNameserversParams) (VanityNameserver, error)) *MockQuerierSetVanityNameserversCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierListRecordsByZoneCall) DoAndReturn(f func(context.Context, string) ([]CorednsRecord, error)) *MockQuerierListRecordsByZoneCall {
+func (c *MockQuerierSetVanityNameserversCall) DoAndReturn(f func(context.Context, SetVanityNameserversParams) (VanityNameserver, error)) *MockQuerierSetVanityNameserversCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ListZones mocks base method.
-func (m *MockQuerier) ListZones(ctx context.Context) ([]string, error) {
+// UpdateAPITokenUsage mocks base method.
+func (m *MockQuerier) UpdateAPITokenUsage(ctx context.Context, tokenHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListZones", ctx)
-	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "UpdateAPITokenUsage", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListZones indicates an expected call of ListZones.
-func (mr *MockQuerierMockRecorder) ListZones(ctx any) *MockQuerierListZonesCall {
+// UpdateAPITokenUsage indicates an expected call of UpdateAPITokenUsage.
+func (mr *MockQuerierMockRecorder) UpdateAPITokenUsage(ctx, tokenHash any) *MockQuerierUpdateAPITokenUsageCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZones", reflect.TypeOf((*MockQuerier)(nil).ListZones), ctx)
-	return &MockQuerierListZonesCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPITokenUsage", reflect.TypeOf((*MockQuerier)(nil).UpdateAPITokenUsage), ctx, tokenHash)
+	return &MockQuerierUpdateAPITokenUsageCall{Call: call}
 }
 
-// MockQuerierListZonesCall wrap *gomock.Call
-type MockQuerierListZonesCall struct {
+// MockQuerierUpdateAPITokenUsageCall wrap *gomock.Call
+type MockQuerierUpdateAPITokenUsageCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQuerierListZonesCall) Return(arg0 []string, arg1 error) *MockQuerierListZonesCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQuerierUpdateAPITokenUsageCall) Return(arg0 error) *MockQuerierUpdateAPITokenUsageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpdateAPITokenUsageCall) Do(f func(context.Context, string) error) *MockQuerierUpdateAPITokenUsageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpdateAPITokenUsageCall) DoAndReturn(f func(context.Context, string) error) *MockQuerierUpdateAPITokenUsageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateRRsetTTL mocks base method.
+func (m *MockQuerier) UpdateRRsetTTL(ctx context.Context, arg UpdateRRsetTTLParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRRsetTTL", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRRsetTTL indicates an expected call of UpdateRRsetTTL.
+func (mr *MockQuerierMockRecorder) UpdateRRsetTTL(ctx, arg any) *MockQuerierUpdateRRsetTTLCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRRsetTTL", reflect.TypeOf((*MockQuerier)(nil).UpdateRRsetTTL), ctx, arg)
+	return &MockQuerierUpdateRRsetTTLCall{Call: call}
+}
+
+// MockQuerierUpdateRRsetTTLCall wrap *gomock.Call
+type MockQuerierUpdateRRsetTTLCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpdateRRsetTTLCall) Return(arg0 error) *MockQuerierUpdateRRsetTTLCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQuerierListZonesCall) Do(f func(context.Context) ([]string, error)) *MockQuerierListZonesCall {
+func (c *MockQuerierUpdateRRsetTTLCall) Do(f func(context.Context, UpdateRRsetTTLParams) error) *MockQuerierUpdateRRsetTTLCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQuerierListZonesCall) DoAndReturn(f func(context.Context) ([]string, error)) *MockQuerierListZonesCall {
+func (c *MockQuerierUpdateRRsetTTLCall) DoAndReturn(f func(context.Context, UpdateRRsetTTLParams) error) *MockQuerierUpdateRRsetTTLCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -389,3 +2912,235 @@ func (c *MockQuerierUpdateRecordCall) DoAndReturn(f func(context.Context, Update
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UpdateTOTPLastUsedStep mocks base method.
+func (m *MockQuerier) UpdateTOTPLastUsedStep(ctx context.Context, arg UpdateTOTPLastUsedStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTOTPLastUsedStep", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTOTPLastUsedStep indicates an expected call of UpdateTOTPLastUsedStep.
+func (mr *MockQuerierMockRecorder) UpdateTOTPLastUsedStep(ctx, arg any) *MockQuerierUpdateTOTPLastUsedStepCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPLastUsedStep", reflect.TypeOf((*MockQuerier)(nil).UpdateTOTPLastUsedStep), ctx, arg)
+	return &MockQuerierUpdateTOTPLastUsedStepCall{Call: call}
+}
+
+// MockQuerierUpdateTOTPLastUsedStepCall wrap *gomock.Call
+type MockQuerierUpdateTOTPLastUsedStepCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpdateTOTPLastUsedStepCall) Return(arg0 int64, arg1 error) *MockQuerierUpdateTOTPLastUsedStepCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpdateTOTPLastUsedStepCall) Do(f func(context.Context, UpdateTOTPLastUsedStepParams) (int64, error)) *MockQuerierUpdateTOTPLastUsedStepCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpdateTOTPLastUsedStepCall) DoAndReturn(f func(context.Context, UpdateTOTPLastUsedStepParams) (int64, error)) *MockQuerierUpdateTOTPLastUsedStepCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateWebAuthnCredentialUsage mocks base method.
+func (m *MockQuerier) UpdateWebAuthnCredentialUsage(ctx context.Context, arg UpdateWebAuthnCredentialUsageParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebAuthnCredentialUsage", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebAuthnCredentialUsage indicates an expected call of UpdateWebAuthnCredentialUsage.
+func (mr *MockQuerierMockRecorder) UpdateWebAuthnCredentialUsage(ctx, arg any) *MockQuerierUpdateWebAuthnCredentialUsageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebAuthnCredentialUsage", reflect.TypeOf((*MockQuerier)(nil).UpdateWebAuthnCredentialUsage), ctx, arg)
+	return &MockQuerierUpdateWebAuthnCredentialUsageCall{Call: call}
+}
+
+// MockQuerierUpdateWebAuthnCredentialUsageCall wrap *gomock.Call
+type MockQuerierUpdateWebAuthnCredentialUsageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpdateWebAuthnCredentialUsageCall) Return(arg0 error) *MockQuerierUpdateWebAuthnCredentialUsageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpdateWebAuthnCredentialUsageCall) Do(f func(context.Context, UpdateWebAuthnCredentialUsageParams) error) *MockQuerierUpdateWebAuthnCredentialUsageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpdateWebAuthnCredentialUsageCall) DoAndReturn(f func(context.Context, UpdateWebAuthnCredentialUsageParams) error) *MockQuerierUpdateWebAuthnCredentialUsageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateZoneCheck mocks base method.
+func (m *MockQuerier) UpdateZoneCheck(ctx context.Context, arg UpdateZoneCheckParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateZoneCheck", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateZoneCheck indicates an expected call of UpdateZoneCheck.
+func (mr *MockQuerierMockRecorder) UpdateZoneCheck(ctx, arg any) *MockQuerierUpdateZoneCheckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateZoneCheck", reflect.TypeOf((*MockQuerier)(nil).UpdateZoneCheck), ctx, arg)
+	return &MockQuerierUpdateZoneCheckCall{Call: call}
+}
+
+// MockQuerierUpdateZoneCheckCall wrap *gomock.Call
+type MockQuerierUpdateZoneCheckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpdateZoneCheckCall) Return(arg0 error) *MockQuerierUpdateZoneCheckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpdateZoneCheckCall) Do(f func(context.Context, UpdateZoneCheckParams) error) *MockQuerierUpdateZoneCheckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpdateZoneCheckCall) DoAndReturn(f func(context.Context, UpdateZoneCheckParams) error) *MockQuerierUpdateZoneCheckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateZoneTemplate mocks base method.
+func (m *MockQuerier) UpdateZoneTemplate(ctx context.Context, arg UpdateZoneTemplateParams) (ZoneTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateZoneTemplate", ctx, arg)
+	ret0, _ := ret[0].(ZoneTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateZoneTemplate indicates an expected call of UpdateZoneTemplate.
+func (mr *MockQuerierMockRecorder) UpdateZoneTemplate(ctx, arg any) *MockQuerierUpdateZoneTemplateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateZoneTemplate", reflect.TypeOf((*MockQuerier)(nil).UpdateZoneTemplate), ctx, arg)
+	return &MockQuerierUpdateZoneTemplateCall{Call: call}
+}
+
+// MockQuerierUpdateZoneTemplateCall wrap *gomock.Call
+type MockQuerierUpdateZoneTemplateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpdateZoneTemplateCall) Return(arg0 ZoneTemplate, arg1 error) *MockQuerierUpdateZoneTemplateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpdateZoneTemplateCall) Do(f func(context.Context, UpdateZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierUpdateZoneTemplateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpdateZoneTemplateCall) DoAndReturn(f func(context.Context, UpdateZoneTemplateParams) (ZoneTemplate, error)) *MockQuerierUpdateZoneTemplateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpsertTOTPCredential mocks base method.
+func (m *MockQuerier) UpsertTOTPCredential(ctx context.Context, arg UpsertTOTPCredentialParams) (TotpCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTOTPCredential", ctx, arg)
+	ret0, _ := ret[0].(TotpCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTOTPCredential indicates an expected call of UpsertTOTPCredential.
+func (mr *MockQuerierMockRecorder) UpsertTOTPCredential(ctx, arg any) *MockQuerierUpsertTOTPCredentialCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTOTPCredential", reflect.TypeOf((*MockQuerier)(nil).UpsertTOTPCredential), ctx, arg)
+	return &MockQuerierUpsertTOTPCredentialCall{Call: call}
+}
+
+// MockQuerierUpsertTOTPCredentialCall wrap *gomock.Call
+type MockQuerierUpsertTOTPCredentialCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierUpsertTOTPCredentialCall) Return(arg0 TotpCredential, arg1 error) *MockQuerierUpsertTOTPCredentialCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierUpsertTOTPCredentialCall) Do(f func(context.Context, UpsertTOTPCredentialParams) (TotpCredential, error)) *MockQuerierUpsertTOTPCredentialCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierUpsertTOTPCredentialCall) DoAndReturn(f func(context.Context, UpsertTOTPCredentialParams) (TotpCredential, error)) *MockQuerierUpsertTOTPCredentialCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ValidateAndConsumeOTP mocks base method.
+func (m *MockQuerier) ValidateAndConsumeOTP(ctx context.Context, arg ValidateAndConsumeOTPParams) (OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAndConsumeOTP", ctx, arg)
+	ret0, _ := ret[0].(OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateAndConsumeOTP indicates an expected call of ValidateAndConsumeOTP.
+func (mr *MockQuerierMockRecorder) ValidateAndConsumeOTP(ctx, arg any) *MockQuerierValidateAndConsumeOTPCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAndConsumeOTP", reflect.TypeOf((*MockQuerier)(nil).ValidateAndConsumeOTP), ctx, arg)
+	return &MockQuerierValidateAndConsumeOTPCall{Call: call}
+}
+
+// MockQuerierValidateAndConsumeOTPCall wrap *gomock.Call
+type MockQuerierValidateAndConsumeOTPCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQuerierValidateAndConsumeOTPCall) Return(arg0 OtpCode, arg1 error) *MockQuerierValidateAndConsumeOTPCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQuerierValidateAndConsumeOTPCall) Do(f func(context.Context, ValidateAndConsumeOTPParams) (OtpCode, error)) *MockQuerierValidateAndConsumeOTPCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQuerierValidateAndConsumeOTPCall) DoAndReturn(f func(context.Context, ValidateAndConsumeOTPParams) (OtpCode, error)) *MockQuerierValidateAndConsumeOTPCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
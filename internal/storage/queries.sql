@@ -24,15 +24,65 @@ SELECT * FROM coredns_records
 WHERE zone = $1 AND user_id = $2
 ORDER BY name, record_type;
 
--- name: ListRecordsByName :many
+-- name: ListRecordsByRRset :many
 SELECT * FROM coredns_records
-WHERE zone = $1 AND name = $2 AND user_id = $3
+WHERE zone = $1 AND name = $2 AND record_type = $3 AND user_id = $4
 ORDER BY id;
 
--- name: ListRecordsByType :many
+-- name: SearchRecords :many
+-- Lists a page of the records of a zone matching the filters, which are
+-- ignored when empty. Records are ordered by sort_key and ID, and the page
+-- starts after the cursor's sort key and ID unless the cursor ID is zero.
+SELECT * FROM (
+    SELECT
+        coredns_records.*,
+        (CASE @sort::text
+            WHEN 'type' THEN record_type || ' ' || name
+            WHEN 'ttl' THEN lpad(COALESCE(ttl, 0)::text, 10, '0') || ' ' || name || ' ' || record_type
+            WHEN 'id' THEN ''
+            ELSE name || ' ' || record_type
+        END)::text AS sort_key
+    FROM coredns_records
+    WHERE zone = @zone AND user_id = @user_id
+        AND (@name::text = '' OR name = @name::text)
+        AND (@name_prefix::text = '' OR name LIKE @name_prefix::text || '%')
+        AND (@record_type::text = '' OR record_type = @record_type::text)
+        AND (@content::text = '' OR record_search_text(content) ILIKE '%' || @content::text || '%')
+) AS records
+WHERE @cursor_id::bigint = 0
+    OR (NOT @descending::boolean AND (sort_key, id) > (@cursor_key::text, @cursor_id::bigint))
+    OR (@descending::boolean AND (sort_key, id) < (@cursor_key::text, @cursor_id::bigint))
+ORDER BY
+    CASE WHEN @descending::boolean THEN sort_key END DESC,
+    CASE WHEN @descending::boolean THEN id END DESC,
+    sort_key,
+    id
+LIMIT @page_size;
+
+-- name: SearchUserRecords :many
+-- Lists a page of the records of all of the user's zones whose owner name or
+-- content values contain the pattern, ignoring case. Records are ordered by
+-- zone, name and type, and paginated as in SearchRecords.
+SELECT * FROM (
+    SELECT
+        coredns_records.*,
+        (zone || ' ' || name || ' ' || record_type)::text AS sort_key
+    FROM coredns_records
+    WHERE user_id = @user_id
+        AND ((name || '.' || zone) ILIKE '%' || @pattern::text || '%'
+            OR record_search_text(content) ILIKE '%' || @pattern::text || '%')
+) AS records
+WHERE @cursor_id::bigint = 0 OR (sort_key, id) > (@cursor_key::text, @cursor_id::bigint)
+ORDER BY sort_key, id
+LIMIT @page_size;
+
+-- name: ListUserRecordsByType :many
+-- Lists the records of a type of all of the user's zones, or of one zone if
+-- the zone isn't empty
 SELECT * FROM coredns_records
-WHERE zone = $1 AND record_type = $2 AND user_id = $3
-ORDER BY name;
+WHERE user_id = @user_id AND record_type = @record_type
+    AND (@zone::text = '' OR zone = @zone::text)
+ORDER BY zone, name, id;
 
 -- name: CreateRecord :one
 INSERT INTO coredns_records (
@@ -52,25 +102,268 @@ SET
     name = $2,
     ttl = $3,
     content = $4,
-    record_type = $5
+    record_type = $5,
+    version = version + 1
 WHERE id = $1 AND zone = $6 AND user_id = $7
 RETURNING *;
 
+-- name: UpdateRRsetTTL :exec
+UPDATE coredns_records
+SET
+    ttl = $5,
+    version = version + 1
+WHERE zone = $1 AND user_id = $2 AND name = $3 AND record_type = $4 AND ttl IS DISTINCT FROM $5;
+
 -- name: DeleteRecord :exec
 DELETE FROM coredns_records
 WHERE id = $1 AND zone = $2 AND user_id = $3;
 
+-- name: ListInvalidRecordsByZone :many
+SELECT * FROM coredns_records_invalid
+WHERE zone = $1 AND user_id = $2
+ORDER BY name, record_type, id;
+
+-- name: DeleteInvalidRecord :execrows
+DELETE FROM coredns_records_invalid
+WHERE id = $1 AND zone = $2 AND user_id = $3;
+
+-- name: CreateRecordHistory :exec
+INSERT INTO record_history (
+    zone,
+    user_id,
+    record_id,
+    action,
+    name,
+    record_type,
+    previous_ttl,
+    previous_content,
+    ttl,
+    content
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+);
+
 -- name: ListRecords :many
 SELECT * FROM coredns_records
 WHERE zone = $1 AND user_id = $2
 ORDER BY name, record_type;
 
 -- name: ListZones :many
-SELECT DISTINCT zone 
-FROM coredns_records 
+SELECT zone
+FROM zones
+WHERE user_id = $1
+ORDER BY zone;
+
+-- Zone Queries
+-- name: CreateZone :one
+INSERT INTO zones (
+    zone,
+    user_id,
+    status,
+    verification_token
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetZone :one
+SELECT * FROM zones
+WHERE zone = $1 AND user_id = $2;
+
+-- name: LockZone :one
+SELECT * FROM zones
+WHERE zone = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: DeleteZone :exec
+DELETE FROM zones
+WHERE zone = $1 AND user_id = $2;
+
+-- name: DeleteRecordsByZone :exec
+DELETE FROM coredns_records
+WHERE zone = $1 AND user_id = $2;
+
+-- name: DeleteInvalidRecordsByZone :exec
+DELETE FROM coredns_records_invalid
+WHERE zone = $1 AND user_id = $2;
+
+-- name: GetActiveZone :one
+SELECT * FROM zones
+WHERE zone = $1 AND status = 'active';
+
+-- name: ListZonesByUser :many
+SELECT * FROM zones
 WHERE user_id = $1
 ORDER BY zone;
 
+-- name: SearchZones :many
+-- Lists a page of the user's zones matching the filters, which are ignored
+-- when empty. Zones are ordered by sort_key, which is unique, and the page
+-- starts after the cursor's sort key unless it is empty.
+SELECT * FROM (
+    SELECT
+        zones.*,
+        (CASE @sort::text
+            WHEN 'created' THEN to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US') || ' ' || zone
+            ELSE zone
+        END)::text AS sort_key
+    FROM zones
+    WHERE user_id = @user_id
+        AND (@name_prefix::text = '' OR zone LIKE @name_prefix::text || '%')
+        AND (@status::text = '' OR status = @status::text)
+) AS zones
+WHERE @cursor_key::text = ''
+    OR (NOT @descending::boolean AND sort_key > @cursor_key::text)
+    OR (@descending::boolean AND sort_key < @cursor_key::text)
+ORDER BY
+    CASE WHEN @descending::boolean THEN sort_key END DESC,
+    sort_key
+LIMIT @page_size;
+
+-- name: ListPendingZones :many
+SELECT * FROM zones
+WHERE status = 'pending'
+ORDER BY last_checked_at NULLS FIRST
+LIMIT $1;
+
+-- name: ActivateZone :one
+UPDATE zones
+SET
+    status = 'active',
+    verification_method = $3,
+    verified_at = NOW(),
+    last_checked_at = NOW(),
+    last_check_error = NULL
+WHERE zone = $1 AND user_id = $2 AND status = 'pending'
+RETURNING *;
+
+-- name: CountZoneClaims :one
+-- Counts the users claiming the zone, whether their claim is pending or active
+SELECT COUNT(*) FROM zones
+WHERE zone = $1;
+
+-- name: UpdateZoneCheck :exec
+UPDATE zones
+SET
+    last_checked_at = NOW(),
+    last_check_error = $3
+WHERE zone = $1 AND user_id = $2;
+
+-- Zone Template Queries
+
+-- name: ListZoneTemplates :many
+SELECT * FROM zone_templates
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetZoneTemplate :one
+SELECT * FROM zone_templates
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetZoneTemplateByName :one
+SELECT * FROM zone_templates
+WHERE user_id = $1 AND name = $2;
+
+-- name: CreateZoneTemplate :one
+INSERT INTO zone_templates (
+    user_id,
+    name,
+    content
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UpdateZoneTemplate :one
+UPDATE zone_templates
+SET
+    name = $3,
+    content = $4,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteZoneTemplate :execrows
+DELETE FROM zone_templates
+WHERE id = $1 AND user_id = $2;
+
+-- Vanity Nameserver Queries
+
+-- name: GetUserVanityNameservers :one
+-- Gets the vanity nameservers of the user's organization
+SELECT vanity_nameservers.* FROM organization_members
+JOIN vanity_nameservers ON vanity_nameservers.organization_id = organization_members.organization_id
+WHERE organization_members.user_id = $1;
+
+-- name: SetVanityNameservers :one
+INSERT INTO vanity_nameservers (
+    organization_id, hostnames
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (organization_id) DO UPDATE SET
+    hostnames = EXCLUDED.hostnames,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteVanityNameservers :execrows
+DELETE FROM vanity_nameservers
+WHERE organization_id = $1;
+
+-- Organization Queries
+-- name: ListOrganizations :many
+SELECT * FROM organizations
+ORDER BY name;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1;
+
+-- name: GetOrganizationByName :one
+SELECT * FROM organizations
+WHERE name = $1;
+
+-- name: GetUserOrganization :one
+SELECT organizations.* FROM organization_members
+JOIN organizations ON organizations.id = organization_members.organization_id
+WHERE organization_members.user_id = $1;
+
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+    name
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: SetOrganizationTwoFactor :one
+UPDATE organizations
+SET
+    require_two_factor = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteOrganization :execrows
+DELETE FROM organizations
+WHERE id = $1;
+
+-- name: ListOrganizationMembers :many
+SELECT users.* FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+ORDER BY users.email;
+
+-- name: AddOrganizationMember :execrows
+-- Adds the user to the organization, unless the user already belongs to one
+INSERT INTO organization_members (
+    organization_id, user_id
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
 -- OTP Authentication Queries
 
 -- name: CreateOTP :one
@@ -98,3 +391,186 @@ WHERE id = (
     LIMIT 1
 )
 RETURNING *;
+
+-- name: ConsumeOTPByID :one
+UPDATE otp_codes
+SET consumed_at = NOW()
+WHERE id = $1 AND email = $2 AND consumed_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- TOTP Queries
+-- name: GetTOTPCredential :one
+SELECT * FROM totp_credentials
+WHERE user_id = $1;
+
+-- name: UpsertTOTPCredential :one
+INSERT INTO totp_credentials (
+    user_id,
+    secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    secret = EXCLUDED.secret,
+    last_used_step = 0,
+    confirmed_at = NULL,
+    failed_attempts = 0,
+    locked_until = NULL,
+    created_at = NOW()
+RETURNING *;
+
+-- name: ConfirmTOTPCredential :exec
+UPDATE totp_credentials
+SET confirmed_at = NOW()
+WHERE user_id = $1;
+
+-- name: UpdateTOTPLastUsedStep :execrows
+UPDATE totp_credentials
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: ClaimTOTPAttempt :one
+-- Counts an attempt at a code, unless the credential is locked. Once the
+-- attempts since the last correct code reach the limit, each one locks the
+-- credential for the lockout.
+UPDATE totp_credentials
+SET
+    failed_attempts = failed_attempts + 1,
+    locked_until = CASE
+        WHEN failed_attempts + 1 >= sqlc.arg(max_attempts)::int
+        THEN NOW() + make_interval(secs => sqlc.arg(lockout_seconds)::int)
+    END
+WHERE user_id = sqlc.arg(user_id) AND (locked_until IS NULL OR locked_until <= NOW())
+RETURNING failed_attempts;
+
+-- name: ResetTOTPAttempts :exec
+UPDATE totp_credentials
+SET failed_attempts = 0, locked_until = NULL
+WHERE user_id = $1;
+
+-- name: DeleteTOTPCredential :exec
+DELETE FROM totp_credentials
+WHERE user_id = $1;
+
+-- Recovery Code Queries
+-- name: ConsumeRecoveryCode :one
+UPDATE recovery_codes
+SET consumed_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND consumed_at IS NULL
+RETURNING *;
+
+-- name: ReplaceRecoveryCodes :exec
+-- Replaces all of a user's recovery codes in one statement, so a failure
+-- can't leave them without codes
+WITH deleted AS (
+    DELETE FROM recovery_codes
+    WHERE user_id = @user_id
+)
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+)
+SELECT @user_id, unnest(@code_hashes::text[]);
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = $1 AND consumed_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- WebAuthn Queries
+-- name: ListWebAuthnCredentialsByUser :many
+SELECT * FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (
+    user_id,
+    credential_id,
+    name,
+    credential
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateWebAuthnCredentialUsage :exec
+UPDATE webauthn_credentials
+SET
+    credential = $2,
+    last_used_at = NOW()
+WHERE credential_id = $1;
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebAuthnSession :one
+INSERT INTO webauthn_sessions (
+    session_data,
+    expires_at
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: ConsumeWebAuthnSession :one
+DELETE FROM webauthn_sessions
+WHERE id = $1 AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteExpiredWebAuthnSessions :exec
+DELETE FROM webauthn_sessions
+WHERE expires_at <= NOW();
+
+-- Signing Key Queries
+-- name: ListSigningKeys :many
+SELECT * FROM signing_keys
+WHERE expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: CreateSigningKey :one
+INSERT INTO signing_keys (
+    kid,
+    algorithm,
+    private_key,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: DeleteExpiredSigningKeys :exec
+DELETE FROM signing_keys
+WHERE expires_at <= NOW();
+
+-- API Token Queries
+-- name: ListAPITokensByUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+    user_id,
+    name,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetAPITokenUser :one
+SELECT users.* FROM api_tokens
+JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.token_hash = $1 AND (api_tokens.expires_at IS NULL OR api_tokens.expires_at > NOW());
+
+-- name: UpdateAPITokenUsage :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE token_hash = $1;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2;
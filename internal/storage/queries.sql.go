@@ -8,11 +8,244 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
+const activateZone = `-- name: ActivateZone :one
+UPDATE zones
+SET
+    status = 'active',
+    verification_method = $3,
+    verified_at = NOW(),
+    last_checked_at = NOW(),
+    last_check_error = NULL
+WHERE zone = $1 AND user_id = $2 AND status = 'pending'
+RETURNING zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at
+`
+
+type ActivateZoneParams struct {
+	Zone               string
+	UserID             uuid.UUID
+	VerificationMethod sql.NullString
+}
+
+func (q *Queries) ActivateZone(ctx context.Context, arg ActivateZoneParams) (Zone, error) {
+	row := q.db.QueryRowContext(ctx, activateZone, arg.Zone, arg.UserID, arg.VerificationMethod)
+	var i Zone
+	err := row.Scan(
+		&i.Zone,
+		&i.UserID,
+		&i.Status,
+		&i.VerificationToken,
+		&i.VerificationMethod,
+		&i.VerifiedAt,
+		&i.LastCheckedAt,
+		&i.LastCheckError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const addOrganizationMember = `-- name: AddOrganizationMember :execrows
+INSERT INTO organization_members (
+    organization_id, user_id
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO NOTHING
+`
+
+type AddOrganizationMemberParams struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+}
+
+// Adds the user to the organization, unless the user already belongs to one
+func (q *Queries) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addOrganizationMember, arg.OrganizationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimTOTPAttempt = `-- name: ClaimTOTPAttempt :one
+UPDATE totp_credentials
+SET
+    failed_attempts = failed_attempts + 1,
+    locked_until = CASE
+        WHEN failed_attempts + 1 >= $1::int
+        THEN NOW() + make_interval(secs => $2::int)
+    END
+WHERE user_id = $3 AND (locked_until IS NULL OR locked_until <= NOW())
+RETURNING failed_attempts
+`
+
+type ClaimTOTPAttemptParams struct {
+	MaxAttempts    int32
+	LockoutSeconds int32
+	UserID         uuid.UUID
+}
+
+// Counts an attempt at a code, unless the credential is locked. Once the
+// attempts since the last correct code reach the limit, each one locks the
+// credential for the lockout.
+func (q *Queries) ClaimTOTPAttempt(ctx context.Context, arg ClaimTOTPAttemptParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, claimTOTPAttempt, arg.MaxAttempts, arg.LockoutSeconds, arg.UserID)
+	var failed_attempts int32
+	err := row.Scan(&failed_attempts)
+	return failed_attempts, err
+}
+
+const confirmTOTPCredential = `-- name: ConfirmTOTPCredential :exec
+UPDATE totp_credentials
+SET confirmed_at = NOW()
+WHERE user_id = $1
+`
+
+func (q *Queries) ConfirmTOTPCredential(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, confirmTOTPCredential, userID)
+	return err
+}
+
+const consumeOTPByID = `-- name: ConsumeOTPByID :one
+UPDATE otp_codes
+SET consumed_at = NOW()
+WHERE id = $1 AND email = $2 AND consumed_at IS NULL AND expires_at > NOW()
+RETURNING id, email, code, expires_at, consumed_at, created_at
+`
+
+type ConsumeOTPByIDParams struct {
+	ID    int32
+	Email string
+}
+
+func (q *Queries) ConsumeOTPByID(ctx context.Context, arg ConsumeOTPByIDParams) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, consumeOTPByID, arg.ID, arg.Email)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Code,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeRecoveryCode = `-- name: ConsumeRecoveryCode :one
+UPDATE recovery_codes
+SET consumed_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND consumed_at IS NULL
+RETURNING id, user_id, code_hash, consumed_at, created_at
+`
+
+type ConsumeRecoveryCodeParams struct {
+	UserID   uuid.UUID
+	CodeHash string
+}
+
+// Recovery Code Queries
+func (q *Queries) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, consumeRecoveryCode, arg.UserID, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeWebAuthnSession = `-- name: ConsumeWebAuthnSession :one
+DELETE FROM webauthn_sessions
+WHERE id = $1 AND expires_at > NOW()
+RETURNING id, session_data, expires_at, created_at
+`
+
+func (q *Queries) ConsumeWebAuthnSession(ctx context.Context, id uuid.UUID) (WebauthnSession, error) {
+	row := q.db.QueryRowContext(ctx, consumeWebAuthnSession, id)
+	var i WebauthnSession
+	err := row.Scan(
+		&i.ID,
+		&i.SessionData,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countUnusedRecoveryCodes = `-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = $1 AND consumed_at IS NULL
+`
+
+func (q *Queries) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnusedRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countZoneClaims = `-- name: CountZoneClaims :one
+SELECT COUNT(*) FROM zones
+WHERE zone = $1
+`
+
+// Counts the users claiming the zone, whether their claim is pending or active
+func (q *Queries) CountZoneClaims(ctx context.Context, zone string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countZoneClaims, zone)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+    user_id,
+    name,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, user_id, name, token_hash, last_used_at, expires_at, created_at
+`
+
+type CreateAPITokenParams struct {
+	UserID    uuid.UUID
+	Name      string
+	TokenHash string
+	ExpiresAt sql.NullTime
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createAPIToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createOTP = `-- name: CreateOTP :one
 
 INSERT INTO otp_codes (
@@ -45,6 +278,27 @@ func (q *Queries) CreateOTP(ctx context.Context, arg CreateOTPParams) (OtpCode,
 	return i, err
 }
 
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (
+    name
+) VALUES (
+    $1
+) RETURNING id, name, require_two_factor, created_at, updated_at
+`
+
+func (q *Queries) CreateOrganization(ctx context.Context, name string) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, createOrganization, name)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RequireTwoFactor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createRecord = `-- name: CreateRecord :one
 INSERT INTO coredns_records (
     user_id,
@@ -55,7 +309,7 @@ INSERT INTO coredns_records (
     record_type
 ) VALUES (
     $1, $2, $3, $4, $5, $6
-) RETURNING id, user_id, zone, name, ttl, content, record_type
+) RETURNING id, user_id, zone, name, ttl, content, record_type, version
 `
 
 type CreateRecordParams struct {
@@ -63,7 +317,7 @@ type CreateRecordParams struct {
 	Zone       string
 	Name       string
 	Ttl        sql.NullInt32
-	Content    sql.NullString
+	Content    json.RawMessage
 	RecordType string
 }
 
@@ -85,6 +339,89 @@ func (q *Queries) CreateRecord(ctx context.Context, arg CreateRecordParams) (Cor
 		&i.Ttl,
 		&i.Content,
 		&i.RecordType,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createRecordHistory = `-- name: CreateRecordHistory :exec
+INSERT INTO record_history (
+    zone,
+    user_id,
+    record_id,
+    action,
+    name,
+    record_type,
+    previous_ttl,
+    previous_content,
+    ttl,
+    content
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+`
+
+type CreateRecordHistoryParams struct {
+	Zone            string
+	UserID          uuid.UUID
+	RecordID        int64
+	Action          string
+	Name            string
+	RecordType      string
+	PreviousTtl     sql.NullInt32
+	PreviousContent pqtype.NullRawMessage
+	Ttl             sql.NullInt32
+	Content         pqtype.NullRawMessage
+}
+
+func (q *Queries) CreateRecordHistory(ctx context.Context, arg CreateRecordHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, createRecordHistory,
+		arg.Zone,
+		arg.UserID,
+		arg.RecordID,
+		arg.Action,
+		arg.Name,
+		arg.RecordType,
+		arg.PreviousTtl,
+		arg.PreviousContent,
+		arg.Ttl,
+		arg.Content,
+	)
+	return err
+}
+
+const createSigningKey = `-- name: CreateSigningKey :one
+INSERT INTO signing_keys (
+    kid,
+    algorithm,
+    private_key,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING kid, algorithm, private_key, expires_at, created_at
+`
+
+type CreateSigningKeyParams struct {
+	Kid        string
+	Algorithm  string
+	PrivateKey string
+	ExpiresAt  time.Time
+}
+
+func (q *Queries) CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) (SigningKey, error) {
+	row := q.db.QueryRowContext(ctx, createSigningKey,
+		arg.Kid,
+		arg.Algorithm,
+		arg.PrivateKey,
+		arg.ExpiresAt,
+	)
+	var i SigningKey
+	err := row.Scan(
+		&i.Kid,
+		&i.Algorithm,
+		&i.PrivateKey,
+		&i.ExpiresAt,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -109,133 +446,1110 @@ func (q *Queries) CreateUser(ctx context.Context, email string) (User, error) {
 	return i, err
 }
 
-const deleteRecord = `-- name: DeleteRecord :exec
-DELETE FROM coredns_records
-WHERE id = $1 AND zone = $2 AND user_id = $3
+const createWebAuthnCredential = `-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (
+    user_id,
+    credential_id,
+    name,
+    credential
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, user_id, credential_id, name, credential, last_used_at, created_at
 `
 
-type DeleteRecordParams struct {
-	ID     int64
-	Zone   string
-	UserID uuid.UUID
-}
-
-func (q *Queries) DeleteRecord(ctx context.Context, arg DeleteRecordParams) error {
-	_, err := q.db.ExecContext(ctx, deleteRecord, arg.ID, arg.Zone, arg.UserID)
-	return err
+type CreateWebAuthnCredentialParams struct {
+	UserID       uuid.UUID
+	CredentialID []byte
+	Name         string
+	Credential   string
 }
 
-const getLatestOTPByEmail = `-- name: GetLatestOTPByEmail :one
-SELECT id, email, code, expires_at, consumed_at, created_at FROM otp_codes
-WHERE email = $1 AND consumed_at IS NULL AND expires_at > NOW()
-ORDER BY created_at DESC
-LIMIT 1
-`
-
-func (q *Queries) GetLatestOTPByEmail(ctx context.Context, email string) (OtpCode, error) {
-	row := q.db.QueryRowContext(ctx, getLatestOTPByEmail, email)
-	var i OtpCode
+func (q *Queries) CreateWebAuthnCredential(ctx context.Context, arg CreateWebAuthnCredentialParams) (WebauthnCredential, error) {
+	row := q.db.QueryRowContext(ctx, createWebAuthnCredential,
+		arg.UserID,
+		arg.CredentialID,
+		arg.Name,
+		arg.Credential,
+	)
+	var i WebauthnCredential
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Code,
-		&i.ExpiresAt,
-		&i.ConsumedAt,
+		&i.UserID,
+		&i.CredentialID,
+		&i.Name,
+		&i.Credential,
+		&i.LastUsedAt,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getRecordByID = `-- name: GetRecordByID :one
-SELECT id, user_id, zone, name, ttl, content, record_type FROM coredns_records
-WHERE id = $1 AND zone = $2 AND user_id = $3
+const createWebAuthnSession = `-- name: CreateWebAuthnSession :one
+INSERT INTO webauthn_sessions (
+    session_data,
+    expires_at
+) VALUES (
+    $1, $2
+) RETURNING id, session_data, expires_at, created_at
 `
 
-type GetRecordByIDParams struct {
-	ID     int64
-	Zone   string
-	UserID uuid.UUID
+type CreateWebAuthnSessionParams struct {
+	SessionData string
+	ExpiresAt   time.Time
 }
 
-// Records Queries
-func (q *Queries) GetRecordByID(ctx context.Context, arg GetRecordByIDParams) (CorednsRecord, error) {
-	row := q.db.QueryRowContext(ctx, getRecordByID, arg.ID, arg.Zone, arg.UserID)
-	var i CorednsRecord
+func (q *Queries) CreateWebAuthnSession(ctx context.Context, arg CreateWebAuthnSessionParams) (WebauthnSession, error) {
+	row := q.db.QueryRowContext(ctx, createWebAuthnSession, arg.SessionData, arg.ExpiresAt)
+	var i WebauthnSession
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.Zone,
-		&i.Name,
-		&i.Ttl,
-		&i.Content,
-		&i.RecordType,
+		&i.SessionData,
+		&i.ExpiresAt,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, created_at, updated_at FROM users
-WHERE email = $1
+const createZone = `-- name: CreateZone :one
+INSERT INTO zones (
+    zone,
+    user_id,
+    status,
+    verification_token
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
-	var i User
+type CreateZoneParams struct {
+	Zone              string
+	UserID            uuid.UUID
+	Status            string
+	VerificationToken string
+}
+
+// Zone Queries
+func (q *Queries) CreateZone(ctx context.Context, arg CreateZoneParams) (Zone, error) {
+	row := q.db.QueryRowContext(ctx, createZone,
+		arg.Zone,
+		arg.UserID,
+		arg.Status,
+		arg.VerificationToken,
+	)
+	var i Zone
 	err := row.Scan(
-		&i.ID,
-		&i.Email,
+		&i.Zone,
+		&i.UserID,
+		&i.Status,
+		&i.VerificationToken,
+		&i.VerificationMethod,
+		&i.VerifiedAt,
+		&i.LastCheckedAt,
+		&i.LastCheckError,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, created_at, updated_at FROM users
-WHERE id = $1
+const createZoneTemplate = `-- name: CreateZoneTemplate :one
+INSERT INTO zone_templates (
+    user_id,
+    name,
+    content
+) VALUES (
+    $1, $2, $3
+) RETURNING id, user_id, name, content, created_at, updated_at
 `
 
-// User Queries
-func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i User
+type CreateZoneTemplateParams struct {
+	UserID  uuid.UUID
+	Name    string
+	Content string
+}
+
+func (q *Queries) CreateZoneTemplate(ctx context.Context, arg CreateZoneTemplateParams) (ZoneTemplate, error) {
+	row := q.db.QueryRowContext(ctx, createZoneTemplate, arg.UserID, arg.Name, arg.Content)
+	var i ZoneTemplate
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
+		&i.UserID,
+		&i.Name,
+		&i.Content,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const listRecords = `-- name: ListRecords :many
-SELECT id, user_id, zone, name, ttl, content, record_type FROM coredns_records
-WHERE zone = $1 AND user_id = $2
-ORDER BY name, record_type
+const deleteAPIToken = `-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2
 `
 
-type ListRecordsParams struct {
-	Zone   string
+type DeleteAPITokenParams struct {
+	ID     int32
 	UserID uuid.UUID
 }
 
-func (q *Queries) ListRecords(ctx context.Context, arg ListRecordsParams) ([]CorednsRecord, error) {
-	rows, err := q.db.QueryContext(ctx, listRecords, arg.Zone, arg.UserID)
+func (q *Queries) DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAPIToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredSigningKeys = `-- name: DeleteExpiredSigningKeys :exec
+DELETE FROM signing_keys
+WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredSigningKeys(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSigningKeys)
+	return err
+}
+
+const deleteExpiredWebAuthnSessions = `-- name: DeleteExpiredWebAuthnSessions :exec
+DELETE FROM webauthn_sessions
+WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredWebAuthnSessions(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredWebAuthnSessions)
+	return err
+}
+
+const deleteInvalidRecord = `-- name: DeleteInvalidRecord :execrows
+DELETE FROM coredns_records_invalid
+WHERE id = $1 AND zone = $2 AND user_id = $3
+`
+
+type DeleteInvalidRecordParams struct {
+	ID     int64
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteInvalidRecord(ctx context.Context, arg DeleteInvalidRecordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteInvalidRecord, arg.ID, arg.Zone, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteInvalidRecordsByZone = `-- name: DeleteInvalidRecordsByZone :exec
+DELETE FROM coredns_records_invalid
+WHERE zone = $1 AND user_id = $2
+`
+
+type DeleteInvalidRecordsByZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteInvalidRecordsByZone(ctx context.Context, arg DeleteInvalidRecordsByZoneParams) error {
+	_, err := q.db.ExecContext(ctx, deleteInvalidRecordsByZone, arg.Zone, arg.UserID)
+	return err
+}
+
+const deleteOrganization = `-- name: DeleteOrganization :execrows
+DELETE FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganization(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrganization, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRecord = `-- name: DeleteRecord :exec
+DELETE FROM coredns_records
+WHERE id = $1 AND zone = $2 AND user_id = $3
+`
+
+type DeleteRecordParams struct {
+	ID     int64
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteRecord(ctx context.Context, arg DeleteRecordParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRecord, arg.ID, arg.Zone, arg.UserID)
+	return err
+}
+
+const deleteRecordsByZone = `-- name: DeleteRecordsByZone :exec
+DELETE FROM coredns_records
+WHERE zone = $1 AND user_id = $2
+`
+
+type DeleteRecordsByZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteRecordsByZone(ctx context.Context, arg DeleteRecordsByZoneParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRecordsByZone, arg.Zone, arg.UserID)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteTOTPCredential = `-- name: DeleteTOTPCredential :exec
+DELETE FROM totp_credentials
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteTOTPCredential(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTOTPCredential, userID)
+	return err
+}
+
+const deleteVanityNameservers = `-- name: DeleteVanityNameservers :execrows
+DELETE FROM vanity_nameservers
+WHERE organization_id = $1
+`
+
+func (q *Queries) DeleteVanityNameservers(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteVanityNameservers, organizationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebAuthnCredential = `-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteWebAuthnCredentialParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteWebAuthnCredential(ctx context.Context, arg DeleteWebAuthnCredentialParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebAuthnCredential, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteZone = `-- name: DeleteZone :exec
+DELETE FROM zones
+WHERE zone = $1 AND user_id = $2
+`
+
+type DeleteZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteZone(ctx context.Context, arg DeleteZoneParams) error {
+	_, err := q.db.ExecContext(ctx, deleteZone, arg.Zone, arg.UserID)
+	return err
+}
+
+const deleteZoneTemplate = `-- name: DeleteZoneTemplate :execrows
+DELETE FROM zone_templates
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteZoneTemplateParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteZoneTemplate(ctx context.Context, arg DeleteZoneTemplateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteZoneTemplate, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAPITokenUser = `-- name: GetAPITokenUser :one
+SELECT users.id, users.email, users.created_at, users.updated_at FROM api_tokens
+JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.token_hash = $1 AND (api_tokens.expires_at IS NULL OR api_tokens.expires_at > NOW())
+`
+
+func (q *Queries) GetAPITokenUser(ctx context.Context, tokenHash string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getAPITokenUser, tokenHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getActiveZone = `-- name: GetActiveZone :one
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at FROM zones
+WHERE zone = $1 AND status = 'active'
+`
+
+func (q *Queries) GetActiveZone(ctx context.Context, zone string) (Zone, error) {
+	row := q.db.QueryRowContext(ctx, getActiveZone, zone)
+	var i Zone
+	err := row.Scan(
+		&i.Zone,
+		&i.UserID,
+		&i.Status,
+		&i.VerificationToken,
+		&i.VerificationMethod,
+		&i.VerifiedAt,
+		&i.LastCheckedAt,
+		&i.LastCheckError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestOTPByEmail = `-- name: GetLatestOTPByEmail :one
+SELECT id, email, code, expires_at, consumed_at, created_at FROM otp_codes
+WHERE email = $1 AND consumed_at IS NULL AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestOTPByEmail(ctx context.Context, email string) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, getLatestOTPByEmail, email)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Code,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganization = `-- name: GetOrganization :one
+SELECT id, name, require_two_factor, created_at, updated_at FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganization, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RequireTwoFactor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationByName = `-- name: GetOrganizationByName :one
+SELECT id, name, require_two_factor, created_at, updated_at FROM organizations
+WHERE name = $1
+`
+
+func (q *Queries) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RequireTwoFactor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecordByID = `-- name: GetRecordByID :one
+SELECT id, user_id, zone, name, ttl, content, record_type, version FROM coredns_records
+WHERE id = $1 AND zone = $2 AND user_id = $3
+`
+
+type GetRecordByIDParams struct {
+	ID     int64
+	Zone   string
+	UserID uuid.UUID
+}
+
+// Records Queries
+func (q *Queries) GetRecordByID(ctx context.Context, arg GetRecordByIDParams) (CorednsRecord, error) {
+	row := q.db.QueryRowContext(ctx, getRecordByID, arg.ID, arg.Zone, arg.UserID)
+	var i CorednsRecord
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Zone,
+		&i.Name,
+		&i.Ttl,
+		&i.Content,
+		&i.RecordType,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getTOTPCredential = `-- name: GetTOTPCredential :one
+SELECT user_id, secret, last_used_step, confirmed_at, created_at, failed_attempts, locked_until FROM totp_credentials
+WHERE user_id = $1
+`
+
+// TOTP Queries
+func (q *Queries) GetTOTPCredential(ctx context.Context, userID uuid.UUID) (TotpCredential, error) {
+	row := q.db.QueryRowContext(ctx, getTOTPCredential, userID)
+	var i TotpCredential
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, created_at, updated_at FROM users
+WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, created_at, updated_at FROM users
+WHERE id = $1
+`
+
+// User Queries
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserOrganization = `-- name: GetUserOrganization :one
+SELECT organizations.id, organizations.name, organizations.require_two_factor, organizations.created_at, organizations.updated_at FROM organization_members
+JOIN organizations ON organizations.id = organization_members.organization_id
+WHERE organization_members.user_id = $1
+`
+
+func (q *Queries) GetUserOrganization(ctx context.Context, userID uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getUserOrganization, userID)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RequireTwoFactor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserVanityNameservers = `-- name: GetUserVanityNameservers :one
+
+SELECT vanity_nameservers.hostnames, vanity_nameservers.created_at, vanity_nameservers.updated_at, vanity_nameservers.organization_id FROM organization_members
+JOIN vanity_nameservers ON vanity_nameservers.organization_id = organization_members.organization_id
+WHERE organization_members.user_id = $1
+`
+
+// Vanity Nameserver Queries
+// Gets the vanity nameservers of the user's organization
+func (q *Queries) GetUserVanityNameservers(ctx context.Context, userID uuid.UUID) (VanityNameserver, error) {
+	row := q.db.QueryRowContext(ctx, getUserVanityNameservers, userID)
+	var i VanityNameserver
+	err := row.Scan(
+		pq.Array(&i.Hostnames),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getZone = `-- name: GetZone :one
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at FROM zones
+WHERE zone = $1 AND user_id = $2
+`
+
+type GetZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetZone(ctx context.Context, arg GetZoneParams) (Zone, error) {
+	row := q.db.QueryRowContext(ctx, getZone, arg.Zone, arg.UserID)
+	var i Zone
+	err := row.Scan(
+		&i.Zone,
+		&i.UserID,
+		&i.Status,
+		&i.VerificationToken,
+		&i.VerificationMethod,
+		&i.VerifiedAt,
+		&i.LastCheckedAt,
+		&i.LastCheckError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getZoneTemplate = `-- name: GetZoneTemplate :one
+SELECT id, user_id, name, content, created_at, updated_at FROM zone_templates
+WHERE id = $1 AND user_id = $2
+`
+
+type GetZoneTemplateParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetZoneTemplate(ctx context.Context, arg GetZoneTemplateParams) (ZoneTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getZoneTemplate, arg.ID, arg.UserID)
+	var i ZoneTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getZoneTemplateByName = `-- name: GetZoneTemplateByName :one
+SELECT id, user_id, name, content, created_at, updated_at FROM zone_templates
+WHERE user_id = $1 AND name = $2
+`
+
+type GetZoneTemplateByNameParams struct {
+	UserID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) GetZoneTemplateByName(ctx context.Context, arg GetZoneTemplateByNameParams) (ZoneTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getZoneTemplateByName, arg.UserID, arg.Name)
+	var i ZoneTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAPITokensByUser = `-- name: ListAPITokensByUser :many
+SELECT id, user_id, name, token_hash, last_used_at, expires_at, created_at FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+// API Token Queries
+func (q *Queries) ListAPITokensByUser(ctx context.Context, userID uuid.UUID) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, listAPITokensByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenHash,
+			&i.LastUsedAt,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvalidRecordsByZone = `-- name: ListInvalidRecordsByZone :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version, moved_at FROM coredns_records_invalid
+WHERE zone = $1 AND user_id = $2
+ORDER BY name, record_type, id
+`
+
+type ListInvalidRecordsByZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) ListInvalidRecordsByZone(ctx context.Context, arg ListInvalidRecordsByZoneParams) ([]CorednsRecordsInvalid, error) {
+	rows, err := q.db.QueryContext(ctx, listInvalidRecordsByZone, arg.Zone, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CorednsRecordsInvalid
+	for rows.Next() {
+		var i CorednsRecordsInvalid
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Zone,
+			&i.Name,
+			&i.Ttl,
+			&i.Content,
+			&i.RecordType,
+			&i.Version,
+			&i.MovedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationMembers = `-- name: ListOrganizationMembers :many
+SELECT users.id, users.email, users.created_at, users.updated_at FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+ORDER BY users.email
+`
+
+func (q *Queries) ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizationMembers, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizations = `-- name: ListOrganizations :many
+SELECT id, name, require_two_factor, created_at, updated_at FROM organizations
+ORDER BY name
+`
+
+// Organization Queries
+func (q *Queries) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.RequireTwoFactor,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingZones = `-- name: ListPendingZones :many
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at FROM zones
+WHERE status = 'pending'
+ORDER BY last_checked_at NULLS FIRST
+LIMIT $1
+`
+
+func (q *Queries) ListPendingZones(ctx context.Context, limit int32) ([]Zone, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingZones, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Zone
+	for rows.Next() {
+		var i Zone
+		if err := rows.Scan(
+			&i.Zone,
+			&i.UserID,
+			&i.Status,
+			&i.VerificationToken,
+			&i.VerificationMethod,
+			&i.VerifiedAt,
+			&i.LastCheckedAt,
+			&i.LastCheckError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecords = `-- name: ListRecords :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version FROM coredns_records
+WHERE zone = $1 AND user_id = $2
+ORDER BY name, record_type
+`
+
+type ListRecordsParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) ListRecords(ctx context.Context, arg ListRecordsParams) ([]CorednsRecord, error) {
+	rows, err := q.db.QueryContext(ctx, listRecords, arg.Zone, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CorednsRecord
+	for rows.Next() {
+		var i CorednsRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Zone,
+			&i.Name,
+			&i.Ttl,
+			&i.Content,
+			&i.RecordType,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByRRset = `-- name: ListRecordsByRRset :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version FROM coredns_records
+WHERE zone = $1 AND name = $2 AND record_type = $3 AND user_id = $4
+ORDER BY id
+`
+
+type ListRecordsByRRsetParams struct {
+	Zone       string
+	Name       string
+	RecordType string
+	UserID     uuid.UUID
+}
+
+func (q *Queries) ListRecordsByRRset(ctx context.Context, arg ListRecordsByRRsetParams) ([]CorednsRecord, error) {
+	rows, err := q.db.QueryContext(ctx, listRecordsByRRset,
+		arg.Zone,
+		arg.Name,
+		arg.RecordType,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CorednsRecord
+	for rows.Next() {
+		var i CorednsRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Zone,
+			&i.Name,
+			&i.Ttl,
+			&i.Content,
+			&i.RecordType,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByZone = `-- name: ListRecordsByZone :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version FROM coredns_records
+WHERE zone = $1 AND user_id = $2
+ORDER BY name, record_type
+`
+
+type ListRecordsByZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) ListRecordsByZone(ctx context.Context, arg ListRecordsByZoneParams) ([]CorednsRecord, error) {
+	rows, err := q.db.QueryContext(ctx, listRecordsByZone, arg.Zone, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CorednsRecord
+	for rows.Next() {
+		var i CorednsRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Zone,
+			&i.Name,
+			&i.Ttl,
+			&i.Content,
+			&i.RecordType,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSigningKeys = `-- name: ListSigningKeys :many
+SELECT kid, algorithm, private_key, expires_at, created_at FROM signing_keys
+WHERE expires_at > NOW()
+ORDER BY created_at DESC
+`
+
+// Signing Key Queries
+func (q *Queries) ListSigningKeys(ctx context.Context) ([]SigningKey, error) {
+	rows, err := q.db.QueryContext(ctx, listSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SigningKey
+	for rows.Next() {
+		var i SigningKey
+		if err := rows.Scan(
+			&i.Kid,
+			&i.Algorithm,
+			&i.PrivateKey,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRecordsByType = `-- name: ListUserRecordsByType :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version FROM coredns_records
+WHERE user_id = $1 AND record_type = $2
+    AND ($3::text = '' OR zone = $3::text)
+ORDER BY zone, name, id
+`
+
+type ListUserRecordsByTypeParams struct {
+	UserID     uuid.UUID
+	RecordType string
+	Zone       string
+}
+
+// Lists the records of a type of all of the user's zones, or of one zone if
+// the zone isn't empty
+func (q *Queries) ListUserRecordsByType(ctx context.Context, arg ListUserRecordsByTypeParams) ([]CorednsRecord, error) {
+	rows, err := q.db.QueryContext(ctx, listUserRecordsByType, arg.UserID, arg.RecordType, arg.Zone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CorednsRecord
+	for rows.Next() {
+		var i CorednsRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Zone,
+			&i.Name,
+			&i.Ttl,
+			&i.Content,
+			&i.RecordType,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebAuthnCredentialsByUser = `-- name: ListWebAuthnCredentialsByUser :many
+SELECT id, user_id, credential_id, name, credential, last_used_at, created_at FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+// WebAuthn Queries
+func (q *Queries) ListWebAuthnCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]WebauthnCredential, error) {
+	rows, err := q.db.QueryContext(ctx, listWebAuthnCredentialsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebauthnCredential
+	for rows.Next() {
+		var i WebauthnCredential
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CredentialID,
+			&i.Name,
+			&i.Credential,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listZoneTemplates = `-- name: ListZoneTemplates :many
+
+SELECT id, user_id, name, content, created_at, updated_at FROM zone_templates
+WHERE user_id = $1
+ORDER BY name
+`
+
+// Zone Template Queries
+func (q *Queries) ListZoneTemplates(ctx context.Context, userID uuid.UUID) ([]ZoneTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, listZoneTemplates, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []CorednsRecord
+	var items []ZoneTemplate
 	for rows.Next() {
-		var i CorednsRecord
+		var i ZoneTemplate
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
-			&i.Zone,
 			&i.Name,
-			&i.Ttl,
 			&i.Content,
-			&i.RecordType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -250,35 +1564,61 @@ func (q *Queries) ListRecords(ctx context.Context, arg ListRecordsParams) ([]Cor
 	return items, nil
 }
 
-const listRecordsByName = `-- name: ListRecordsByName :many
-SELECT id, user_id, zone, name, ttl, content, record_type FROM coredns_records
-WHERE zone = $1 AND name = $2 AND user_id = $3
-ORDER BY id
+const listZones = `-- name: ListZones :many
+SELECT zone
+FROM zones
+WHERE user_id = $1
+ORDER BY zone
 `
 
-type ListRecordsByNameParams struct {
-	Zone   string
-	Name   string
-	UserID uuid.UUID
+func (q *Queries) ListZones(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listZones, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var zone string
+		if err := rows.Scan(&zone); err != nil {
+			return nil, err
+		}
+		items = append(items, zone)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) ListRecordsByName(ctx context.Context, arg ListRecordsByNameParams) ([]CorednsRecord, error) {
-	rows, err := q.db.QueryContext(ctx, listRecordsByName, arg.Zone, arg.Name, arg.UserID)
+const listZonesByUser = `-- name: ListZonesByUser :many
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at FROM zones
+WHERE user_id = $1
+ORDER BY zone
+`
+
+func (q *Queries) ListZonesByUser(ctx context.Context, userID uuid.UUID) ([]Zone, error) {
+	rows, err := q.db.QueryContext(ctx, listZonesByUser, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []CorednsRecord
+	var items []Zone
 	for rows.Next() {
-		var i CorednsRecord
+		var i Zone
 		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
 			&i.Zone,
-			&i.Name,
-			&i.Ttl,
-			&i.Content,
-			&i.RecordType,
+			&i.UserID,
+			&i.Status,
+			&i.VerificationToken,
+			&i.VerificationMethod,
+			&i.VerifiedAt,
+			&i.LastCheckedAt,
+			&i.LastCheckError,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -293,27 +1633,165 @@ func (q *Queries) ListRecordsByName(ctx context.Context, arg ListRecordsByNamePa
 	return items, nil
 }
 
-const listRecordsByType = `-- name: ListRecordsByType :many
-SELECT id, user_id, zone, name, ttl, content, record_type FROM coredns_records
-WHERE zone = $1 AND record_type = $2 AND user_id = $3
-ORDER BY name
+const lockZone = `-- name: LockZone :one
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at FROM zones
+WHERE zone = $1 AND user_id = $2
+FOR UPDATE
+`
+
+type LockZoneParams struct {
+	Zone   string
+	UserID uuid.UUID
+}
+
+func (q *Queries) LockZone(ctx context.Context, arg LockZoneParams) (Zone, error) {
+	row := q.db.QueryRowContext(ctx, lockZone, arg.Zone, arg.UserID)
+	var i Zone
+	err := row.Scan(
+		&i.Zone,
+		&i.UserID,
+		&i.Status,
+		&i.VerificationToken,
+		&i.VerificationMethod,
+		&i.VerifiedAt,
+		&i.LastCheckedAt,
+		&i.LastCheckError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const removeOrganizationMember = `-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2
+`
+
+type RemoveOrganizationMemberParams struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+}
+
+func (q *Queries) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeOrganizationMember, arg.OrganizationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const replaceRecoveryCodes = `-- name: ReplaceRecoveryCodes :exec
+WITH deleted AS (
+    DELETE FROM recovery_codes
+    WHERE user_id = $1
+)
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+)
+SELECT $1, unnest($2::text[])
+`
+
+type ReplaceRecoveryCodesParams struct {
+	UserID     uuid.UUID
+	CodeHashes []string
+}
+
+// Replaces all of a user's recovery codes in one statement, so a failure
+// can't leave them without codes
+func (q *Queries) ReplaceRecoveryCodes(ctx context.Context, arg ReplaceRecoveryCodesParams) error {
+	_, err := q.db.ExecContext(ctx, replaceRecoveryCodes, arg.UserID, pq.Array(arg.CodeHashes))
+	return err
+}
+
+const resetTOTPAttempts = `-- name: ResetTOTPAttempts :exec
+UPDATE totp_credentials
+SET failed_attempts = 0, locked_until = NULL
+WHERE user_id = $1
+`
+
+func (q *Queries) ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, resetTOTPAttempts, userID)
+	return err
+}
+
+const searchRecords = `-- name: SearchRecords :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version, sort_key FROM (
+    SELECT
+        coredns_records.id, coredns_records.user_id, coredns_records.zone, coredns_records.name, coredns_records.ttl, coredns_records.content, coredns_records.record_type, coredns_records.version,
+        (CASE $1::text
+            WHEN 'type' THEN record_type || ' ' || name
+            WHEN 'ttl' THEN lpad(COALESCE(ttl, 0)::text, 10, '0') || ' ' || name || ' ' || record_type
+            WHEN 'id' THEN ''
+            ELSE name || ' ' || record_type
+        END)::text AS sort_key
+    FROM coredns_records
+    WHERE zone = $2 AND user_id = $3
+        AND ($4::text = '' OR name = $4::text)
+        AND ($5::text = '' OR name LIKE $5::text || '%')
+        AND ($6::text = '' OR record_type = $6::text)
+        AND ($7::text = '' OR record_search_text(content) ILIKE '%' || $7::text || '%')
+) AS records
+WHERE $8::bigint = 0
+    OR (NOT $9::boolean AND (sort_key, id) > ($10::text, $8::bigint))
+    OR ($9::boolean AND (sort_key, id) < ($10::text, $8::bigint))
+ORDER BY
+    CASE WHEN $9::boolean THEN sort_key END DESC,
+    CASE WHEN $9::boolean THEN id END DESC,
+    sort_key,
+    id
+LIMIT $11
 `
 
-type ListRecordsByTypeParams struct {
+type SearchRecordsParams struct {
+	Sort       string
 	Zone       string
+	UserID     uuid.UUID
+	Name       string
+	NamePrefix string
 	RecordType string
+	Content    string
+	CursorID   int64
+	Descending bool
+	CursorKey  string
+	PageSize   int32
+}
+
+type SearchRecordsRow struct {
+	ID         int64
 	UserID     uuid.UUID
+	Zone       string
+	Name       string
+	Ttl        sql.NullInt32
+	Content    json.RawMessage
+	RecordType string
+	Version    int32
+	SortKey    string
 }
 
-func (q *Queries) ListRecordsByType(ctx context.Context, arg ListRecordsByTypeParams) ([]CorednsRecord, error) {
-	rows, err := q.db.QueryContext(ctx, listRecordsByType, arg.Zone, arg.RecordType, arg.UserID)
+// Lists a page of the records of a zone matching the filters, which are
+// ignored when empty. Records are ordered by sort_key and ID, and the page
+// starts after the cursor's sort key and ID unless the cursor ID is zero.
+func (q *Queries) SearchRecords(ctx context.Context, arg SearchRecordsParams) ([]SearchRecordsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchRecords,
+		arg.Sort,
+		arg.Zone,
+		arg.UserID,
+		arg.Name,
+		arg.NamePrefix,
+		arg.RecordType,
+		arg.Content,
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorKey,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []CorednsRecord
+	var items []SearchRecordsRow
 	for rows.Next() {
-		var i CorednsRecord
+		var i SearchRecordsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -322,6 +1800,8 @@ func (q *Queries) ListRecordsByType(ctx context.Context, arg ListRecordsByTypePa
 			&i.Ttl,
 			&i.Content,
 			&i.RecordType,
+			&i.Version,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -336,26 +1816,59 @@ func (q *Queries) ListRecordsByType(ctx context.Context, arg ListRecordsByTypePa
 	return items, nil
 }
 
-const listRecordsByZone = `-- name: ListRecordsByZone :many
-SELECT id, user_id, zone, name, ttl, content, record_type FROM coredns_records
-WHERE zone = $1 AND user_id = $2
-ORDER BY name, record_type
+const searchUserRecords = `-- name: SearchUserRecords :many
+SELECT id, user_id, zone, name, ttl, content, record_type, version, sort_key FROM (
+    SELECT
+        coredns_records.id, coredns_records.user_id, coredns_records.zone, coredns_records.name, coredns_records.ttl, coredns_records.content, coredns_records.record_type, coredns_records.version,
+        (zone || ' ' || name || ' ' || record_type)::text AS sort_key
+    FROM coredns_records
+    WHERE user_id = $1
+        AND ((name || '.' || zone) ILIKE '%' || $2::text || '%'
+            OR record_search_text(content) ILIKE '%' || $2::text || '%')
+) AS records
+WHERE $3::bigint = 0 OR (sort_key, id) > ($4::text, $3::bigint)
+ORDER BY sort_key, id
+LIMIT $5
 `
 
-type ListRecordsByZoneParams struct {
-	Zone   string
-	UserID uuid.UUID
+type SearchUserRecordsParams struct {
+	UserID    uuid.UUID
+	Pattern   string
+	CursorID  int64
+	CursorKey string
+	PageSize  int32
 }
 
-func (q *Queries) ListRecordsByZone(ctx context.Context, arg ListRecordsByZoneParams) ([]CorednsRecord, error) {
-	rows, err := q.db.QueryContext(ctx, listRecordsByZone, arg.Zone, arg.UserID)
+type SearchUserRecordsRow struct {
+	ID         int64
+	UserID     uuid.UUID
+	Zone       string
+	Name       string
+	Ttl        sql.NullInt32
+	Content    json.RawMessage
+	RecordType string
+	Version    int32
+	SortKey    string
+}
+
+// Lists a page of the records of all of the user's zones whose owner name or
+// content values contain the pattern, ignoring case. Records are ordered by
+// zone, name and type, and paginated as in SearchRecords.
+func (q *Queries) SearchUserRecords(ctx context.Context, arg SearchUserRecordsParams) ([]SearchUserRecordsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUserRecords,
+		arg.UserID,
+		arg.Pattern,
+		arg.CursorID,
+		arg.CursorKey,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []CorednsRecord
+	var items []SearchUserRecordsRow
 	for rows.Next() {
-		var i CorednsRecord
+		var i SearchUserRecordsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -364,6 +1877,8 @@ func (q *Queries) ListRecordsByZone(ctx context.Context, arg ListRecordsByZonePa
 			&i.Ttl,
 			&i.Content,
 			&i.RecordType,
+			&i.Version,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -378,26 +1893,86 @@ func (q *Queries) ListRecordsByZone(ctx context.Context, arg ListRecordsByZonePa
 	return items, nil
 }
 
-const listZones = `-- name: ListZones :many
-SELECT DISTINCT zone 
-FROM coredns_records 
-WHERE user_id = $1
-ORDER BY zone
+const searchZones = `-- name: SearchZones :many
+SELECT zone, user_id, status, verification_token, verification_method, verified_at, last_checked_at, last_check_error, created_at, sort_key FROM (
+    SELECT
+        zones.zone, zones.user_id, zones.status, zones.verification_token, zones.verification_method, zones.verified_at, zones.last_checked_at, zones.last_check_error, zones.created_at,
+        (CASE $1::text
+            WHEN 'created' THEN to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US') || ' ' || zone
+            ELSE zone
+        END)::text AS sort_key
+    FROM zones
+    WHERE user_id = $2
+        AND ($3::text = '' OR zone LIKE $3::text || '%')
+        AND ($4::text = '' OR status = $4::text)
+) AS zones
+WHERE $5::text = ''
+    OR (NOT $6::boolean AND sort_key > $5::text)
+    OR ($6::boolean AND sort_key < $5::text)
+ORDER BY
+    CASE WHEN $6::boolean THEN sort_key END DESC,
+    sort_key
+LIMIT $7
 `
 
-func (q *Queries) ListZones(ctx context.Context, userID uuid.UUID) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, listZones, userID)
+type SearchZonesParams struct {
+	Sort       string
+	UserID     uuid.UUID
+	NamePrefix string
+	Status     string
+	CursorKey  string
+	Descending bool
+	PageSize   int32
+}
+
+type SearchZonesRow struct {
+	Zone               string
+	UserID             uuid.UUID
+	Status             string
+	VerificationToken  string
+	VerificationMethod sql.NullString
+	VerifiedAt         sql.NullTime
+	LastCheckedAt      sql.NullTime
+	LastCheckError     sql.NullString
+	CreatedAt          time.Time
+	SortKey            string
+}
+
+// Lists a page of the user's zones matching the filters, which are ignored
+// when empty. Zones are ordered by sort_key, which is unique, and the page
+// starts after the cursor's sort key unless it is empty.
+func (q *Queries) SearchZones(ctx context.Context, arg SearchZonesParams) ([]SearchZonesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchZones,
+		arg.Sort,
+		arg.UserID,
+		arg.NamePrefix,
+		arg.Status,
+		arg.CursorKey,
+		arg.Descending,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []SearchZonesRow
 	for rows.Next() {
-		var zone string
-		if err := rows.Scan(&zone); err != nil {
+		var i SearchZonesRow
+		if err := rows.Scan(
+			&i.Zone,
+			&i.UserID,
+			&i.Status,
+			&i.VerificationToken,
+			&i.VerificationMethod,
+			&i.VerifiedAt,
+			&i.LastCheckedAt,
+			&i.LastCheckError,
+			&i.CreatedAt,
+			&i.SortKey,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, zone)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -408,22 +1983,117 @@ func (q *Queries) ListZones(ctx context.Context, userID uuid.UUID) ([]string, er
 	return items, nil
 }
 
+const setOrganizationTwoFactor = `-- name: SetOrganizationTwoFactor :one
+UPDATE organizations
+SET
+    require_two_factor = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, require_two_factor, created_at, updated_at
+`
+
+type SetOrganizationTwoFactorParams struct {
+	ID               uuid.UUID
+	RequireTwoFactor bool
+}
+
+func (q *Queries) SetOrganizationTwoFactor(ctx context.Context, arg SetOrganizationTwoFactorParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, setOrganizationTwoFactor, arg.ID, arg.RequireTwoFactor)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RequireTwoFactor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setVanityNameservers = `-- name: SetVanityNameservers :one
+INSERT INTO vanity_nameservers (
+    organization_id, hostnames
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (organization_id) DO UPDATE SET
+    hostnames = EXCLUDED.hostnames,
+    updated_at = NOW()
+RETURNING hostnames, created_at, updated_at, organization_id
+`
+
+type SetVanityNameserversParams struct {
+	OrganizationID uuid.UUID
+	Hostnames      []string
+}
+
+func (q *Queries) SetVanityNameservers(ctx context.Context, arg SetVanityNameserversParams) (VanityNameserver, error) {
+	row := q.db.QueryRowContext(ctx, setVanityNameservers, arg.OrganizationID, pq.Array(arg.Hostnames))
+	var i VanityNameserver
+	err := row.Scan(
+		pq.Array(&i.Hostnames),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const updateAPITokenUsage = `-- name: UpdateAPITokenUsage :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE token_hash = $1
+`
+
+func (q *Queries) UpdateAPITokenUsage(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, updateAPITokenUsage, tokenHash)
+	return err
+}
+
+const updateRRsetTTL = `-- name: UpdateRRsetTTL :exec
+UPDATE coredns_records
+SET
+    ttl = $5,
+    version = version + 1
+WHERE zone = $1 AND user_id = $2 AND name = $3 AND record_type = $4 AND ttl IS DISTINCT FROM $5
+`
+
+type UpdateRRsetTTLParams struct {
+	Zone       string
+	UserID     uuid.UUID
+	Name       string
+	RecordType string
+	Ttl        sql.NullInt32
+}
+
+func (q *Queries) UpdateRRsetTTL(ctx context.Context, arg UpdateRRsetTTLParams) error {
+	_, err := q.db.ExecContext(ctx, updateRRsetTTL,
+		arg.Zone,
+		arg.UserID,
+		arg.Name,
+		arg.RecordType,
+		arg.Ttl,
+	)
+	return err
+}
+
 const updateRecord = `-- name: UpdateRecord :one
 UPDATE coredns_records
 SET 
     name = $2,
     ttl = $3,
     content = $4,
-    record_type = $5
+    record_type = $5,
+    version = version + 1
 WHERE id = $1 AND zone = $6 AND user_id = $7
-RETURNING id, user_id, zone, name, ttl, content, record_type
+RETURNING id, user_id, zone, name, ttl, content, record_type, version
 `
 
 type UpdateRecordParams struct {
 	ID         int64
 	Name       string
 	Ttl        sql.NullInt32
-	Content    sql.NullString
+	Content    json.RawMessage
 	RecordType string
 	Zone       string
 	UserID     uuid.UUID
@@ -448,6 +2118,137 @@ func (q *Queries) UpdateRecord(ctx context.Context, arg UpdateRecordParams) (Cor
 		&i.Ttl,
 		&i.Content,
 		&i.RecordType,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateTOTPLastUsedStep = `-- name: UpdateTOTPLastUsedStep :execrows
+UPDATE totp_credentials
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2
+`
+
+type UpdateTOTPLastUsedStepParams struct {
+	UserID       uuid.UUID
+	LastUsedStep int64
+}
+
+func (q *Queries) UpdateTOTPLastUsedStep(ctx context.Context, arg UpdateTOTPLastUsedStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTOTPLastUsedStep, arg.UserID, arg.LastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateWebAuthnCredentialUsage = `-- name: UpdateWebAuthnCredentialUsage :exec
+UPDATE webauthn_credentials
+SET
+    credential = $2,
+    last_used_at = NOW()
+WHERE credential_id = $1
+`
+
+type UpdateWebAuthnCredentialUsageParams struct {
+	CredentialID []byte
+	Credential   string
+}
+
+func (q *Queries) UpdateWebAuthnCredentialUsage(ctx context.Context, arg UpdateWebAuthnCredentialUsageParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebAuthnCredentialUsage, arg.CredentialID, arg.Credential)
+	return err
+}
+
+const updateZoneCheck = `-- name: UpdateZoneCheck :exec
+UPDATE zones
+SET
+    last_checked_at = NOW(),
+    last_check_error = $3
+WHERE zone = $1 AND user_id = $2
+`
+
+type UpdateZoneCheckParams struct {
+	Zone           string
+	UserID         uuid.UUID
+	LastCheckError sql.NullString
+}
+
+func (q *Queries) UpdateZoneCheck(ctx context.Context, arg UpdateZoneCheckParams) error {
+	_, err := q.db.ExecContext(ctx, updateZoneCheck, arg.Zone, arg.UserID, arg.LastCheckError)
+	return err
+}
+
+const updateZoneTemplate = `-- name: UpdateZoneTemplate :one
+UPDATE zone_templates
+SET
+    name = $3,
+    content = $4,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, content, created_at, updated_at
+`
+
+type UpdateZoneTemplateParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Name    string
+	Content string
+}
+
+func (q *Queries) UpdateZoneTemplate(ctx context.Context, arg UpdateZoneTemplateParams) (ZoneTemplate, error) {
+	row := q.db.QueryRowContext(ctx, updateZoneTemplate,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Content,
+	)
+	var i ZoneTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertTOTPCredential = `-- name: UpsertTOTPCredential :one
+INSERT INTO totp_credentials (
+    user_id,
+    secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    secret = EXCLUDED.secret,
+    last_used_step = 0,
+    confirmed_at = NULL,
+    failed_attempts = 0,
+    locked_until = NULL,
+    created_at = NOW()
+RETURNING user_id, secret, last_used_step, confirmed_at, created_at, failed_attempts, locked_until
+`
+
+type UpsertTOTPCredentialParams struct {
+	UserID uuid.UUID
+	Secret string
+}
+
+func (q *Queries) UpsertTOTPCredential(ctx context.Context, arg UpsertTOTPCredentialParams) (TotpCredential, error) {
+	row := q.db.QueryRowContext(ctx, upsertTOTPCredential, arg.UserID, arg.Secret)
+	var i TotpCredential
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
 	)
 	return i, err
 }
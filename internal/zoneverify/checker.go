@@ -0,0 +1,238 @@
+// Package zoneverify verifies that users own the zones they claim before the
+// zones are served.
+//
+// A pending zone is verified either by delegation, when the parent zone
+// delegates it only to our nameservers or the vanity nameservers of its
+// owner's organization and no other user claims it, or by a TXT record at _tofudns-verify containing
+// the zone's verification token, which is unique to the claim.
+package zoneverify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+const (
+	// TXTRecordLabel is the label under the zone holding the verification token
+	TXTRecordLabel = "_tofudns-verify"
+
+	// Verification methods
+	MethodDelegation = "delegation"
+	MethodTXT        = "txt"
+
+	// lookupTimeout bounds the DNS lookups for a single zone
+	lookupTimeout = 10 * time.Second
+)
+
+// Config contains configuration for the zone checker
+type Config struct {
+	// Nameservers are the hostnames a verified delegation must point at
+	Nameservers []string
+	// Interval is the time between checks of the pending zones
+	Interval time.Duration
+	// BatchSize is the maximum number of zones checked per interval
+	BatchSize int32
+}
+
+// Checker verifies pending zones and activates them
+type Checker struct {
+	logger   *slog.Logger
+	querier  storage.Querier
+	resolver Resolver
+	config   Config
+}
+
+// New creates a new Checker instance
+func New(logger *slog.Logger, querier storage.Querier, resolver Resolver, config Config) *Checker {
+	nameservers := make([]string, len(config.Nameservers))
+	for i, nameserver := range config.Nameservers {
+		nameservers[i] = canonicalName(nameserver)
+	}
+	config.Nameservers = nameservers
+
+	return &Checker{
+		logger:   logger,
+		querier:  querier,
+		resolver: resolver,
+		config:   config,
+	}
+}
+
+// Nameservers returns the nameservers a zone must be delegated to
+func (c *Checker) Nameservers() []string {
+	return c.config.Nameservers
+}
+
+// TXTRecordName returns the name of the TXT record verifying the zone
+func TXTRecordName(zone string) string {
+	return TXTRecordLabel + "." + canonicalName(zone)
+}
+
+// TXTRecordValue returns the TXT record value containing the token
+func TXTRecordValue(token string) string {
+	return "tofudns-verify=" + token
+}
+
+// Run checks the pending zones every interval until the context is canceled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.CheckPending(ctx); err != nil {
+				c.logger.Error("Failed to check pending zones", "error", err)
+			}
+		}
+	}
+}
+
+// CheckPending checks a batch of pending zones, least recently checked first
+func (c *Checker) CheckPending(ctx context.Context) error {
+	zones, err := c.querier.ListPendingZones(ctx, c.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list pending zones: %w", err)
+	}
+
+	for _, zone := range zones {
+		if _, err := c.Check(ctx, zone); err != nil {
+			c.logger.Error("Failed to check zone", "error", err, "zone", zone.Zone)
+		}
+	}
+	return nil
+}
+
+// Check verifies the zone, activating it on success and otherwise recording
+// why verification failed. It reports whether the zone was activated.
+func (c *Checker) Check(ctx context.Context, zone storage.Zone) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	method, verifyErr := c.verify(ctx, zone)
+	if verifyErr != nil {
+		err := c.querier.UpdateZoneCheck(ctx, storage.UpdateZoneCheckParams{
+			Zone:           zone.Zone,
+			UserID:         zone.UserID,
+			LastCheckError: nullString(verifyErr.Error()),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to record zone check: %w", err)
+		}
+		return false, nil
+	}
+
+	_, err := c.querier.ActivateZone(ctx, storage.ActivateZoneParams{
+		Zone:               zone.Zone,
+		UserID:             zone.UserID,
+		VerificationMethod: nullString(method),
+	})
+	if err != nil {
+		// Most likely another user has verified the zone first
+		checkErr := c.querier.UpdateZoneCheck(ctx, storage.UpdateZoneCheckParams{
+			Zone:           zone.Zone,
+			UserID:         zone.UserID,
+			LastCheckError: nullString("zone could not be activated, it may already be in use"),
+		})
+		if checkErr != nil {
+			c.logger.Error("Failed to record zone check", "error", checkErr, "zone", zone.Zone)
+		}
+		return false, fmt.Errorf("failed to activate zone: %w", err)
+	}
+
+	c.logger.Info("Verified zone", "zone", zone.Zone, "user_id", zone.UserID, "method", method)
+	return true, nil
+}
+
+// verify returns the method by which the zone is verified, or an error
+// describing why it isn't
+func (c *Checker) verify(ctx context.Context, zone storage.Zone) (string, error) {
+	delegationErr := c.verifyDelegation(ctx, zone)
+	if delegationErr == nil {
+		return MethodDelegation, nil
+	}
+
+	txtErr := c.verifyTXT(ctx, zone.Zone, zone.VerificationToken)
+	if txtErr == nil {
+		return MethodTXT, nil
+	}
+
+	return "", fmt.Errorf("%w; %w", delegationErr, txtErr)
+}
+
+// verifyDelegation checks that the zone's parent delegates it only to our
+// nameservers, or to the vanity nameservers of the owner's organization.
+// Delegation doesn't tell apart the users of the same nameservers, so it only
+// verifies a zone no other user has claimed.
+func (c *Checker) verifyDelegation(ctx context.Context, zone storage.Zone) error {
+	vanity, err := c.VanityNameservers(ctx, zone.UserID)
+	if err != nil {
+		return err
+	}
+	expected := c.config.Nameservers
+	if len(vanity) > 0 {
+		expected = vanity
+	}
+
+	hosts, err := c.resolver.LookupDelegation(ctx, canonicalName(zone.Zone))
+	if err != nil {
+		return fmt.Errorf("delegation lookup failed: %w", err)
+	}
+	if len(hosts) == 0 {
+		return errors.New("zone is not delegated")
+	}
+
+	for _, host := range hosts {
+		host = canonicalName(host)
+		if !slices.Contains(c.config.Nameservers, host) && !slices.Contains(vanity, host) {
+			return fmt.Errorf("zone is delegated to %s, not to %s", strings.Join(hosts, ", "), strings.Join(expected, ", "))
+		}
+	}
+
+	claims, err := c.querier.CountZoneClaims(ctx, zone.Zone)
+	if err != nil {
+		return fmt.Errorf("failed to count zone claims: %w", err)
+	}
+	if claims > 1 {
+		return errors.New("zone is delegated to us, but also claimed by another account, so it must be verified with the TXT record")
+	}
+	return nil
+}
+
+// verifyTXT checks for the verification token at _tofudns-verify
+func (c *Checker) verifyTXT(ctx context.Context, zone, token string) error {
+	name := TXTRecordName(zone)
+	values, err := c.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return fmt.Errorf("TXT lookup failed: %w", err)
+	}
+
+	if slices.Contains(values, TXTRecordValue(token)) {
+		return nil
+	}
+	return fmt.Errorf("no TXT record %q found at %s", TXTRecordValue(token), name)
+}
+
+// canonicalName lowercases the name and makes it fully qualified
+func canonicalName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// nullString returns a valid sql.NullString
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
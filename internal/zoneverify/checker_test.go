@@ -0,0 +1,407 @@
+package zoneverify
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/miekg/dns"
+	"github.com/tofudns/tofudns/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testZone  = "example.test."
+	testToken = "token"
+)
+
+var testNameservers = []string{"ns1.tofudns.test.", "ns2.tofudns.test."}
+
+// stubDNS is a DNS server answering from fixed data. It stands in for both
+// the recursive resolver and the nameserver of the parent zone test.: queries
+// with recursion desired are answered from the records, queries without it
+// with the parent's referrals.
+type stubDNS struct {
+	addr string
+	port string
+
+	mu        sync.Mutex
+	records   []dns.RR
+	servfail  []string
+	referrals map[string][]string
+}
+
+// newStubDNS starts a stub DNS server on a local port. The parent zone test.
+// is served at its address.
+func newStubDNS(t *testing.T) *stubDNS {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	host, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+
+	stub := &stubDNS{
+		addr:      conn.LocalAddr().String(),
+		port:      port,
+		referrals: make(map[string][]string),
+	}
+	stub.add(t, "test. 300 IN NS ns.parent.test.")
+	stub.add(t, "ns.parent.test. 300 IN A "+host)
+	for _, nameserver := range testNameservers {
+		stub.add(t, nameserver+" 300 IN A 192.0.2.1")
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: stub, NotifyStartedFunc: func() { close(started) }}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return stub
+}
+
+// add adds a record in zone file syntax
+func (s *stubDNS) add(t *testing.T, record string) {
+	t.Helper()
+	rr, err := dns.NewRR(record)
+	if err != nil {
+		t.Fatalf("dns.NewRR(%q) error = %v", record, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rr)
+}
+
+// delegate makes the parent refer the zone to the nameservers
+func (s *stubDNS) delegate(zone string, nameservers ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.referrals[zone] = nameservers
+}
+
+// fail makes recursive lookups of the name fail, as they do for pending
+// zones delegated to nameservers that don't serve them yet
+func (s *stubDNS) fail(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servfail = append(s.servfail, name)
+}
+
+// ServeDNS implements dns.Handler
+func (s *stubDNS) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := new(dns.Msg)
+	response.SetReply(query)
+	question := query.Question[0]
+	name := strings.ToLower(question.Name)
+
+	if !query.RecursionDesired {
+		nameservers, ok := s.referrals[name]
+		if !ok {
+			response.Rcode = dns.RcodeNameError
+		}
+		for _, nameserver := range nameservers {
+			response.Ns = append(response.Ns, &dns.NS{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: 300},
+				Ns:  nameserver,
+			})
+		}
+		w.WriteMsg(response)
+		return
+	}
+
+	response.RecursionAvailable = true
+	if slices.ContainsFunc(s.servfail, func(failing string) bool { return dns.IsSubDomain(failing, name) }) {
+		response.Rcode = dns.RcodeServerFailure
+		w.WriteMsg(response)
+		return
+	}
+	found := false
+	for _, rr := range s.records {
+		if strings.EqualFold(rr.Header().Name, name) {
+			found = true
+			if rr.Header().Rrtype == question.Qtype {
+				response.Answer = append(response.Answer, rr)
+			}
+		}
+	}
+	if !found {
+		response.Rcode = dns.RcodeNameError
+	}
+	w.WriteMsg(response)
+}
+
+// newTestChecker returns a checker resolving with the stub server
+func newTestChecker(t *testing.T, stub *stubDNS) (*Checker, *storage.MockQuerier) {
+	t.Helper()
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	checker := New(slog.New(slog.NewTextHandler(io.Discard, nil)), querier, newResolver(stub.addr, stub.port), Config{
+		Nameservers: testNameservers,
+	})
+	return checker, querier
+}
+
+// pendingZone returns a pending claim of the test zone
+func pendingZone() storage.Zone {
+	return storage.Zone{
+		Zone:              testZone,
+		UserID:            uuid.New(),
+		Status:            "pending",
+		VerificationToken: testToken,
+	}
+}
+
+// expectVanity makes the querier return the vanity nameservers of the user's
+// organization
+func expectVanity(querier *storage.MockQuerier, userID uuid.UUID, hostnames ...string) {
+	if len(hostnames) == 0 {
+		querier.EXPECT().GetUserVanityNameservers(gomock.Any(), userID).Return(storage.VanityNameserver{}, sql.ErrNoRows)
+		return
+	}
+	querier.EXPECT().GetUserVanityNameservers(gomock.Any(), userID).Return(storage.VanityNameserver{OrganizationID: uuid.New(), Hostnames: hostnames}, nil)
+}
+
+// expectActivated makes the querier expect the zone to be activated by the method
+func expectActivated(querier *storage.MockQuerier, zone storage.Zone, method string) {
+	querier.EXPECT().ActivateZone(gomock.Any(), storage.ActivateZoneParams{
+		Zone:               zone.Zone,
+		UserID:             zone.UserID,
+		VerificationMethod: nullString(method),
+	}).Return(zone, nil)
+}
+
+// expectCheckError makes the querier expect a failed check recording an
+// error containing the message
+func expectCheckError(t *testing.T, querier *storage.MockQuerier, zone storage.Zone, message string) {
+	querier.EXPECT().UpdateZoneCheck(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.UpdateZoneCheckParams) error {
+		if arg.Zone != zone.Zone || arg.UserID != zone.UserID {
+			t.Errorf("UpdateZoneCheck() zone = %s %s, want %s %s", arg.Zone, arg.UserID, zone.Zone, zone.UserID)
+		}
+		if !strings.Contains(arg.LastCheckError.String, message) {
+			t.Errorf("UpdateZoneCheck() error = %q, want it to contain %q", arg.LastCheckError.String, message)
+		}
+		return nil
+	})
+}
+
+func TestCheckDelegatedZoneNotYetServed(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, testNameservers...)
+	stub.fail(testZone)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	querier.EXPECT().CountZoneClaims(gomock.Any(), testZone).Return(int64(1), nil)
+	expectActivated(querier, zone, MethodDelegation)
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || !activated {
+		t.Fatalf("Check() = %v, %v, want true, nil", activated, err)
+	}
+}
+
+func TestCheckDelegatedToVanityNameservers(t *testing.T) {
+	stub := newStubDNS(t)
+	vanity := []string{"ns1.example.org.", "ns2.example.org."}
+	stub.delegate(testZone, "NS1.Example.org.", "ns2.example.org.")
+	stub.fail(testZone)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID, vanity...)
+	querier.EXPECT().CountZoneClaims(gomock.Any(), testZone).Return(int64(1), nil)
+	expectActivated(querier, zone, MethodDelegation)
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || !activated {
+		t.Fatalf("Check() = %v, %v, want true, nil", activated, err)
+	}
+}
+
+func TestCheckDelegatedZoneWithCompetingClaim(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, testNameservers...)
+	stub.fail(testZone)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	querier.EXPECT().CountZoneClaims(gomock.Any(), testZone).Return(int64(2), nil)
+	expectCheckError(t, querier, zone, "also claimed by another account")
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || activated {
+		t.Fatalf("Check() = %v, %v, want false, nil", activated, err)
+	}
+}
+
+func TestCheckCompetingClaimWithTXTRecord(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, testNameservers...)
+	stub.add(t, TXTRecordName(testZone)+` 300 IN TXT "`+TXTRecordValue(testToken)+`"`)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	querier.EXPECT().CountZoneClaims(gomock.Any(), testZone).Return(int64(2), nil)
+	expectActivated(querier, zone, MethodTXT)
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || !activated {
+		t.Fatalf("Check() = %v, %v, want true, nil", activated, err)
+	}
+}
+
+func TestCheckDelegatedElsewhereWithTXTRecord(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, "ns1.other.test.", "ns2.other.test.")
+	stub.add(t, TXTRecordName(testZone)+` 300 IN TXT "`+TXTRecordValue(testToken)+`"`)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	expectActivated(querier, zone, MethodTXT)
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || !activated {
+		t.Fatalf("Check() = %v, %v, want true, nil", activated, err)
+	}
+}
+
+func TestCheckActivationFailure(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, "ns1.other.test.", "ns2.other.test.")
+	stub.add(t, TXTRecordName(testZone)+` 300 IN TXT "`+TXTRecordValue(testToken)+`"`)
+	checker, querier := newTestChecker(t, stub)
+	var logs bytes.Buffer
+	checker.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	querier.EXPECT().ActivateZone(gomock.Any(), gomock.Any()).Return(storage.Zone{}, errors.New("duplicate key"))
+	querier.EXPECT().UpdateZoneCheck(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err == nil || activated || !strings.Contains(err.Error(), "duplicate key") {
+		t.Fatalf("Check() = %v, %v, want the activation error", activated, err)
+	}
+	// The check failing to be recorded isn't lost
+	if !strings.Contains(logs.String(), "connection reset") {
+		t.Errorf("logs = %q, want the failure to record the check", logs.String())
+	}
+}
+
+func TestCheckDelegatedElsewhere(t *testing.T) {
+	stub := newStubDNS(t)
+	stub.delegate(testZone, testNameservers[0], "ns.other.test.")
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	expectCheckError(t, querier, zone, "zone is delegated to ns1.tofudns.test., ns.other.test.")
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || activated {
+		t.Fatalf("Check() = %v, %v, want false, nil", activated, err)
+	}
+}
+
+func TestCheckUndelegatedZone(t *testing.T) {
+	stub := newStubDNS(t)
+	checker, querier := newTestChecker(t, stub)
+
+	zone := pendingZone()
+	expectVanity(querier, zone.UserID)
+	expectCheckError(t, querier, zone, "zone is not delegated")
+
+	activated, err := checker.Check(context.Background(), zone)
+	if err != nil || activated {
+		t.Fatalf("Check() = %v, %v, want false, nil", activated, err)
+	}
+}
+
+func TestLookupDelegationOfSubzone(t *testing.T) {
+	stub := newStubDNS(t)
+	// sub.example.test. is delegated by example.test., which the stub also
+	// serves
+	host, _, _ := net.SplitHostPort(stub.addr)
+	stub.add(t, "example.test. 300 IN NS ns.parent.test.")
+	stub.add(t, "ns.parent.test. 300 IN A "+host)
+	stub.delegate("sub.example.test.", testNameservers...)
+
+	hosts, err := newResolver(stub.addr, stub.port).LookupDelegation(context.Background(), "Sub.Example.Test")
+	if err != nil {
+		t.Fatalf("LookupDelegation() error = %v", err)
+	}
+	if !slices.Equal(hosts, testNameservers) {
+		t.Errorf("LookupDelegation() = %v, want %v", hosts, testNameservers)
+	}
+}
+
+func TestSetVanityNameservers(t *testing.T) {
+	organizationID := uuid.New()
+
+	tests := []struct {
+		name    string
+		hosts   []string
+		records []string
+		// want is the stored nameservers, or empty if they are rejected
+		want []string
+	}{
+		{
+			name:    "same addresses",
+			hosts:   []string{"NS1.example.org", "ns2.example.org."},
+			records: []string{"ns1.example.org. 300 IN A 192.0.2.1", "ns2.example.org. 300 IN A 192.0.2.1"},
+			want:    []string{"ns1.example.org.", "ns2.example.org."},
+		},
+		{
+			name:    "other address",
+			hosts:   []string{"ns1.example.org.", "ns2.example.org."},
+			records: []string{"ns1.example.org. 300 IN A 192.0.2.1", "ns2.example.org. 300 IN A 192.0.2.2"},
+		},
+		{
+			name:    "one for each nameserver",
+			hosts:   []string{"ns1.example.org."},
+			records: []string{"ns1.example.org. 300 IN A 192.0.2.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubDNS(t)
+			for _, record := range tt.records {
+				stub.add(t, record)
+			}
+			checker, querier := newTestChecker(t, stub)
+			if tt.want != nil {
+				// The nameservers are stored for the organization
+				querier.EXPECT().SetVanityNameservers(gomock.Any(), storage.SetVanityNameserversParams{
+					OrganizationID: organizationID,
+					Hostnames:      tt.want,
+				}).Return(storage.VanityNameserver{OrganizationID: organizationID, Hostnames: tt.want}, nil)
+			}
+
+			got, err := checker.SetVanityNameservers(context.Background(), organizationID, tt.hosts)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidVanityNameservers) {
+					t.Fatalf("SetVanityNameservers() error = %v, want ErrInvalidVanityNameservers", err)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Fatalf("SetVanityNameservers() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
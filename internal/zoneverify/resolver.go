@@ -0,0 +1,182 @@
+package zoneverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Resolver looks up the DNS records used to verify zones. Lookups of names
+// that don't exist return no records rather than an error.
+type Resolver interface {
+	LookupNS(ctx context.Context, name string) ([]string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupHost(ctx context.Context, name string) ([]string, error)
+	// LookupDelegation returns the nameservers the zone's parent delegates
+	// the zone to
+	LookupDelegation(ctx context.Context, zone string) ([]string, error)
+}
+
+// netResolver implements Resolver with the standard library resolver, and
+// asks the parent zones' nameservers for delegations directly
+type netResolver struct {
+	resolver *net.Resolver
+	// port is the port nameservers are asked on
+	port string
+}
+
+// NewResolver creates a resolver that queries the DNS server at addr
+// (host:port), or the system resolver when addr is empty
+func NewResolver(addr string) Resolver {
+	return newResolver(addr, "53")
+}
+
+// newResolver creates a resolver that queries the DNS server at addr and
+// asks nameservers on the port
+func newResolver(addr, port string) *netResolver {
+	if addr == "" {
+		return &netResolver{resolver: net.DefaultResolver, port: port}
+	}
+
+	return &netResolver{
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+		port: port,
+	}
+}
+
+// LookupNS returns the nameserver hostnames for the name
+func (r *netResolver) LookupNS(ctx context.Context, name string) ([]string, error) {
+	records, err := r.resolver.LookupNS(ctx, name)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, len(records))
+	for i, record := range records {
+		hosts[i] = record.Host
+	}
+	return hosts, nil
+}
+
+// LookupTXT returns the TXT record values for the name
+func (r *netResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	values, err := r.resolver.LookupTXT(ctx, name)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return values, err
+}
+
+// LookupHost returns the IPv4 and IPv6 addresses of the host
+func (r *netResolver) LookupHost(ctx context.Context, name string) ([]string, error) {
+	addrs, err := r.resolver.LookupHost(ctx, name)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return addrs, err
+}
+
+// LookupDelegation asks the nameservers of the zone's parent, without
+// recursion, for the NS set of the referral to the zone. A recursive lookup
+// would be answered by the zone's own nameservers instead, which don't serve
+// zones pending verification.
+func (r *netResolver) LookupDelegation(ctx context.Context, zone string) ([]string, error) {
+	zone = dns.Fqdn(strings.ToLower(zone))
+	parent, nameservers, err := r.parentNameservers(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, nameserver := range nameservers {
+		addrs, err := r.LookupHost(ctx, nameserver)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, addr := range addrs {
+			hosts, err := queryReferral(ctx, net.JoinHostPort(addr, r.port), zone)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return hosts, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("nameservers have no addresses")
+	}
+	return nil, fmt.Errorf("no nameserver of %s answered: %w", parent, lastErr)
+}
+
+// parentNameservers returns the closest zone above the zone, found as the
+// closest ancestor with NS records, and its nameservers
+func (r *netResolver) parentNameservers(ctx context.Context, zone string) (string, []string, error) {
+	labels := dns.SplitDomainName(zone)
+	for i := 1; i < len(labels); i++ {
+		parent := dns.Fqdn(strings.Join(labels[i:], "."))
+		nameservers, err := r.LookupNS(ctx, parent)
+		if err != nil {
+			return "", nil, fmt.Errorf("NS lookup of %s failed: %w", parent, err)
+		}
+		if len(nameservers) > 0 {
+			return parent, nameservers, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no parent zone of %s found", zone)
+}
+
+// queryReferral asks the nameserver at addr, without recursion, for the NS
+// set of the zone. A parent nameserver answers with a referral, or with the
+// NS set itself if it also serves the zone.
+func queryReferral(ctx context.Context, addr, zone string) ([]string, error) {
+	query := new(dns.Msg)
+	query.SetQuestion(zone, dns.TypeNS)
+	query.RecursionDesired = false
+
+	client := &dns.Client{}
+	response, _, err := client.ExchangeContext(ctx, query, addr)
+	if err == nil && response.Truncated {
+		client.Net = "tcp"
+		response, _, err = client.ExchangeContext(ctx, query, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s answered %s", addr, dns.RcodeToString[response.Rcode])
+	}
+
+	var hosts []string
+	for _, rr := range append(response.Answer, response.Ns...) {
+		if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, zone) {
+			hosts = append(hosts, ns.Ns)
+		}
+	}
+	return hosts, nil
+}
+
+// isNotFound reports whether the lookup failed because the name or record
+// doesn't exist
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
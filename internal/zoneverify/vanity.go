@@ -0,0 +1,159 @@
+package zoneverify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/storage"
+)
+
+// ErrInvalidVanityNameservers is returned when vanity nameservers can't stand
+// in for the service's nameservers
+var ErrInvalidVanityNameservers = errors.New("invalid vanity nameservers")
+
+// Glue is the addresses a vanity nameserver must resolve to. When the vanity
+// nameserver is inside a zone it serves, the registrar of that zone needs
+// them as glue records.
+type Glue struct {
+	Host string
+	// Nameserver is the service's nameserver the host stands in for
+	Nameserver string
+	Addresses  []string
+}
+
+// VanityNameservers returns the vanity nameservers of the user's
+// organization, or none if the user's organization has none or the user
+// belongs to no organization
+func (c *Checker) VanityNameservers(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	vanity, err := c.querier.GetUserVanityNameservers(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vanity nameservers: %w", err)
+	}
+	return vanity.Hostnames, nil
+}
+
+// ZoneNameservers returns the nameservers the user's zones are delegated to
+// and name in their SOA and apex NS records: the vanity nameservers of the
+// user's organization, or the service's if it has none
+func (c *Checker) ZoneNameservers(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	vanity, err := c.VanityNameservers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(vanity) == 0 {
+		return c.config.Nameservers, nil
+	}
+	return vanity, nil
+}
+
+// SetVanityNameservers makes the hosts the organization's vanity nameservers.
+// There must be one host for each of the service's nameservers, resolving to
+// the same addresses as the nameserver in the same position. Existing zones
+// keep the nameservers of their NS records.
+func (c *Checker) SetVanityNameservers(ctx context.Context, organizationID uuid.UUID, hosts []string) ([]string, error) {
+	if len(hosts) != len(c.config.Nameservers) {
+		return nil, fmt.Errorf("%w: give %d nameservers, one for each of %s",
+			ErrInvalidVanityNameservers, len(c.config.Nameservers), strings.Join(c.config.Nameservers, ", "))
+	}
+	canonical := make([]string, len(hosts))
+	for i, host := range hosts {
+		canonical[i] = canonicalName(host)
+		if slices.Contains(canonical[:i], canonical[i]) {
+			return nil, fmt.Errorf("%w: %s is given twice", ErrInvalidVanityNameservers, canonical[i])
+		}
+	}
+
+	glue, err := c.Glue(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	for _, g := range glue {
+		addrs, err := c.resolver.LookupHost(ctx, g.Host)
+		if err != nil {
+			return nil, fmt.Errorf("%w: address lookup of %s failed: %w", ErrInvalidVanityNameservers, g.Host, err)
+		}
+		addrs = canonicalAddresses(addrs)
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("%w: %s has no addresses, it must resolve to %s",
+				ErrInvalidVanityNameservers, g.Host, strings.Join(g.Addresses, ", "))
+		}
+		if !slices.Equal(addrs, g.Addresses) {
+			return nil, fmt.Errorf("%w: %s resolves to %s, not to the addresses of %s, %s",
+				ErrInvalidVanityNameservers, g.Host, strings.Join(addrs, ", "), g.Nameserver, strings.Join(g.Addresses, ", "))
+		}
+	}
+
+	vanity, err := c.querier.SetVanityNameservers(ctx, storage.SetVanityNameserversParams{
+		OrganizationID: organizationID,
+		Hostnames:      canonical,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set vanity nameservers: %w", err)
+	}
+	return vanity.Hostnames, nil
+}
+
+// ClearVanityNameservers removes the organization's vanity nameservers, so
+// its members' new zones name the service's nameservers again
+func (c *Checker) ClearVanityNameservers(ctx context.Context, organizationID uuid.UUID) error {
+	if _, err := c.querier.DeleteVanityNameservers(ctx, organizationID); err != nil {
+		return fmt.Errorf("failed to delete vanity nameservers: %w", err)
+	}
+	return nil
+}
+
+// Glue returns the addresses each of the vanity nameservers must resolve to,
+// which are those of the service's nameserver in the same position
+func (c *Checker) Glue(ctx context.Context, hosts []string) ([]Glue, error) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	var glue []Glue
+	for i, host := range hosts {
+		if i >= len(c.config.Nameservers) {
+			break
+		}
+		nameserver := c.config.Nameservers[i]
+		addrs, err := c.resolver.LookupHost(ctx, nameserver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up nameserver %s: %w", nameserver, err)
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("nameserver %s has no addresses", nameserver)
+		}
+		glue = append(glue, Glue{
+			Host:       canonicalName(host),
+			Nameserver: nameserver,
+			Addresses:  canonicalAddresses(addrs),
+		})
+	}
+	return glue, nil
+}
+
+// canonicalAddresses returns the IP addresses in their canonical form,
+// sorted, so address sets compare equal regardless of order
+func canonicalAddresses(addrs []string) []string {
+	canonical := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			addr = ip.String()
+		}
+		if !slices.Contains(canonical, addr) {
+			canonical = append(canonical, addr)
+		}
+	}
+	slices.Sort(canonical)
+	return canonical
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Change actions
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// AnyVersion is the version of updates and deletes that apply to the record
+// whatever its version, overwriting changes made since it was read
+const AnyVersion int32 = -1
+
+// Change is a single change of a change set. Creates and updates carry the
+// record, deletes only the ID of the record to delete. Updates and deletes
+// only apply to their version of the record, or to any version if it is
+// AnyVersion. The API rejects them without a version.
+type Change struct {
+	Action  string  `json:"action"`
+	ID      int64   `json:"id,omitempty"`
+	Version int32   `json:"version,omitempty"`
+	Record  *Record `json:"record,omitempty"`
+}
+
+// MarshalJSON sends the version AnyVersion as *. Zero isn't a version and is
+// left out.
+func (c Change) MarshalJSON() ([]byte, error) {
+	type change Change
+	var version interface{}
+	switch c.Version {
+	case 0:
+	case AnyVersion:
+		version = "*"
+	default:
+		version = c.Version
+	}
+	return json.Marshal(struct {
+		change
+		Version interface{} `json:"version,omitempty"`
+	}{change(c), version})
+}
+
+// ChangeResult is the outcome of a change. Deleted records have no record.
+type ChangeResult struct {
+	Action string  `json:"action"`
+	ID     int64   `json:"id"`
+	Record *Record `json:"record,omitempty"`
+}
+
+// Plan is the set of changes converging a zone to a desired state
+type Plan struct {
+	Zone        string `json:"zone"`
+	ZoneUnicode string `json:"zone_unicode"`
+	// Hash identifies the plan's changes and the state of the zone the plan
+	// was made against
+	Hash    string          `json:"hash"`
+	Changes []PlannedChange `json:"changes"`
+}
+
+// PlannedChange is a change of a plan. Record is the desired record of
+// creates and updates, Current the existing record updated or deleted.
+type PlannedChange struct {
+	Action  string  `json:"action"`
+	ID      int64   `json:"id,omitempty"`
+	Record  *Record `json:"record,omitempty"`
+	Current *Record `json:"current,omitempty"`
+}
+
+// ApplyChanges applies the changes to the zone atomically. Either all changes
+// are applied and their results returned, or none are and the error's
+// validation errors identify the failing change by its index.
+func (c *Client) ApplyChanges(ctx context.Context, zone string, changes []Change) ([]ChangeResult, error) {
+	var response struct {
+		Results []ChangeResult `json:"results"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   zonePath(zone, "changes"),
+		body:   map[string]interface{}{"changes": changes},
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
+
+// PlanZone plans the changes converging the zone to the desired records
+// without applying them. The zone's SOA and apex NS records are kept if the
+// desired records have none.
+func (c *Client) PlanZone(ctx context.Context, zone string, desired []Record) (*Plan, error) {
+	var plan Plan
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   zonePath(zone, "plan"),
+		body:   map[string]interface{}{"records": desired},
+	}, &plan)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ApplyZone converges the zone to the desired records atomically, provided
+// the plan with the hash still applies. If the zone has changed since the
+// plan was made, an error matching ErrConflict is returned.
+func (c *Client) ApplyZone(ctx context.Context, zone string, desired []Record, planHash string) (*Plan, []ChangeResult, error) {
+	var response struct {
+		Plan    Plan           `json:"plan"`
+		Results []ChangeResult `json:"results"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   zonePath(zone, "apply"),
+		body: map[string]interface{}{
+			"records":   desired,
+			"plan_hash": planHash,
+		},
+	}, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response.Plan, response.Results, nil
+}
@@ -0,0 +1,399 @@
+// Package client is a Go client for the TofuDNS API.
+//
+// Requests authenticate with an API token created on the account page. Rate
+// limited requests, and failed requests that are safe to repeat, are retried
+// with exponential backoff.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	userAgent         = "tofudns-go"
+)
+
+// Client is a client for the TofuDNS API
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+	userAgent  string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how often a request is retried and the bounds of the
+// backoff between attempts. Zero retries disables retrying.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the TofuDNS instance at the base URL, such as
+// https://tofudns.net, authenticating with the API token
+func New(baseURL, token string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL: scheme must be http or https")
+	}
+
+	c := &Client{
+		baseURL:    u,
+		token:      token,
+		httpClient: http.DefaultClient,
+		userAgent:  userAgent,
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request is an API request
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   interface{}
+}
+
+// do sends the request, retrying it if it may succeed when repeated, and
+// decodes the response body into out unless it is nil. Error responses are
+// returned as *Error.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += "/api" + req.path
+	u.RawQuery = req.query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		for name, values := range req.header {
+			httpReq.Header[name] = values
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("User-Agent", c.userAgent)
+		if c.token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			if attempt < c.maxRetries && isIdempotent(req.method) && ctx.Err() == nil {
+				if err := c.wait(ctx, attempt, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("request failed: %w", err)
+		}
+
+		if attempt < c.maxRetries && shouldRetry(req.method, resp.StatusCode) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := c.wait(ctx, attempt, resp); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decodeResponse(resp, out)
+	}
+}
+
+// decodeResponse decodes a response into out, or into an *Error if it is an
+// error response
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return newError(resp, data)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// shouldRetry reports whether a response with the status may succeed when
+// the request is repeated. Rate limited requests weren't processed, so they
+// can always be repeated, server errors only if the request is idempotent.
+func shouldRetry(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// isIdempotent reports whether repeating a request with the method has the
+// same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// wait sleeps before the next attempt, for as long as the response's
+// Retry-After header asks or an exponential backoff with jitter otherwise
+func (c *Client) wait(ctx context.Context, attempt int, resp *http.Response) error {
+	delay := c.backoff(attempt)
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			delay = min(retryAfter, c.maxBackoff)
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the delay before the attempt after the given one
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.minBackoff << attempt
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	// Jitter spreads out clients retrying at the same time
+	if delay > 0 {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as a date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// Sentinel errors matched by *Error with errors.Is
+var (
+	// ErrUnauthorized is matched by errors for missing or invalid API tokens
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is matched by errors for zones, records or tokens that
+	// don't exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by errors for zones that already exist, or have
+	// changed since a plan was made
+	ErrConflict = errors.New("conflict")
+	// ErrRecordChanged is matched by errors for records that have changed
+	// since the version a request expected
+	ErrRecordChanged = errors.New("record has been changed")
+)
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int
+	Message    string
+	// Errors are the validation errors of the request, if any
+	Errors []ValidationError
+	// Current is the current state of a record that has changed since the
+	// version a request expected
+	Current *Record
+}
+
+// ValidationError is an invalid field of a request. Index is the index of
+// the failing change of a change set, or of the failing record of a sync.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Index   *int   `json:"index,omitempty"`
+}
+
+// newError returns the error of an error response
+func newError(resp *http.Response, data []byte) *Error {
+	var body struct {
+		Message string            `json:"message"`
+		Errors  []ValidationError `json:"errors"`
+		Record  *Record           `json:"record"`
+	}
+	e := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		e.Message = body.Message
+		e.Errors = body.Errors
+		e.Current = body.Record
+	} else {
+		e.Message = strings.TrimSpace(string(data))
+		if e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return e
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tofudns: %d %s", e.StatusCode, e.Message)
+	for _, err := range e.Errors {
+		b.WriteString("; ")
+		if err.Index != nil {
+			fmt.Fprintf(&b, "[%d] ", *err.Index)
+		}
+		if err.Field != "" {
+			fmt.Fprintf(&b, "%s: ", err.Field)
+		}
+		b.WriteString(err.Message)
+	}
+	return b.String()
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRecordChanged:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// page is a page of a list response
+type page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// listAll requests every page of a list, following the cursor of each page
+// to the next. The items are the array under key of each response.
+func listAll[T any](ctx context.Context, c *Client, path string, query url.Values, key string) ([]T, error) {
+	var items []T
+	query = cloneValues(query)
+	for {
+		p, err := listPage[T](ctx, c, path, query, key)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, p.Items...)
+		if p.NextCursor == "" {
+			return items, nil
+		}
+		query.Set("cursor", p.NextCursor)
+	}
+}
+
+// listPage requests a single page of a list
+func listPage[T any](ctx context.Context, c *Client, path string, query url.Values, key string) (page[T], error) {
+	var body map[string]json.RawMessage
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, query: query}, &body); err != nil {
+		return page[T]{}, err
+	}
+
+	var p page[T]
+	if data, ok := body[key]; ok {
+		if err := json.Unmarshal(data, &p.Items); err != nil {
+			return page[T]{}, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	if data, ok := body["next_cursor"]; ok {
+		if err := json.Unmarshal(data, &p.NextCursor); err != nil {
+			return page[T]{}, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// cloneValues returns a copy of the query values that can be modified
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
+
+// zonePath returns the API path of the zone, or of a path within it. Paths
+// are escaped when the request URL is built.
+func zonePath(zone string, elem ...string) string {
+	return "/zones/" + strings.Join(append([]string{zone}, elem...), "/")
+}
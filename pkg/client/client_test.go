@@ -0,0 +1,833 @@
+package client_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/tofudns/tofudns/internal/email"
+	"github.com/tofudns/tofudns/internal/frontend"
+	"github.com/tofudns/tofudns/internal/jwtkeys"
+	"github.com/tofudns/tofudns/internal/recordmanager"
+	"github.com/tofudns/tofudns/internal/storage"
+	"github.com/tofudns/tofudns/internal/zoneverify"
+	"github.com/tofudns/tofudns/pkg/client"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testEmail = "user@example.com"
+	testZone  = "example.com."
+)
+
+// testNameservers are the service's nameservers
+var testNameservers = []string{"ns1.example.net.", "ns2.example.net."}
+
+// testServer runs the API's real handlers on a mock querier keeping the
+// user's tokens, zones and records in memory
+type testServer struct {
+	t    *testing.T
+	url  string
+	user storage.User
+
+	mu      sync.Mutex
+	tokens  []storage.ApiToken
+	zones   []storage.Zone
+	records []storage.CorednsRecord
+	// failures are the responses sent instead of the handlers' to the next
+	// requests
+	failures []failure
+	// requests counts the requests received, including failed ones
+	requests int
+	// zoneSearches counts the pages of zones listed
+	zoneSearches int
+}
+
+// failure is a response sent instead of the handlers'
+type failure struct {
+	status     int
+	retryAfter string
+}
+
+// newTestServer starts a server for a user with the zone testZone
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	querier := storage.NewMockQuerier(gomock.NewController(t))
+	s := &testServer{
+		t:    t,
+		user: storage.User{ID: uuid.New(), Email: testEmail},
+	}
+	s.zones = []storage.Zone{{Zone: testZone, UserID: s.user.ID, Status: recordmanager.ZoneStatusActive, CreatedAt: time.Now()}}
+	s.expect(querier)
+
+	keys, err := jwtkeys.New(logger, querier, jwtkeys.Config{
+		Algorithm:        jwtkeys.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("jwtkeys.New() error = %v", err)
+	}
+	renderer, err := email.NewRenderer(email.Branding{Name: "TofuDNS", URL: "http://localhost"})
+	if err != nil {
+		t.Fatalf("email.NewRenderer() error = %v", err)
+	}
+	service, err := frontend.New(logger, recordmanager.NewWithQuerier(querier), querier,
+		email.NewService(email.NewLogSender(logger), renderer), keys,
+		zoneverify.New(logger, querier, nil, zoneverify.Config{Nameservers: testNameservers}), frontend.Config{
+			BaseURL:           "http://localhost",
+			WebAuthnRPID:      "localhost",
+			WebAuthnRPOrigins: []string{"http://localhost"},
+			Hostmaster:        "hostmaster@example.net",
+			SOATimers:         recordmanager.SOATimers{Refresh: 86400, Retry: 7200, Expire: 604800, MinTtl: 300},
+		})
+	if err != nil {
+		t.Fatalf("frontend.New() error = %v", err)
+	}
+
+	router := chi.NewRouter()
+	service.Router(router)
+	server := httptest.NewServer(s.failing(router))
+	t.Cleanup(server.Close)
+	s.url = server.URL
+	return s
+}
+
+// failing sends the pending failures, if any, instead of the handler's
+// responses
+func (s *testServer) failing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		var fail failure
+		if len(s.failures) > 0 {
+			fail, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if fail.status == 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if fail.retryAfter != "" {
+			w.Header().Set("Retry-After", fail.retryAfter)
+		}
+		http.Error(w, http.StatusText(fail.status), fail.status)
+	})
+}
+
+// fail makes the server fail the next requests with the statuses
+func (s *testServer) fail(retryAfter string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, status := range statuses {
+		s.failures = append(s.failures, failure{status: status, retryAfter: retryAfter})
+	}
+}
+
+// requestCount returns the number of requests received
+func (s *testServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// expect makes the querier serve the server's state
+func (s *testServer) expect(querier *storage.MockQuerier) {
+	querier.EXPECT().GetAPITokenUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tokenHash string) (storage.User, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, token := range s.tokens {
+			if token.TokenHash == tokenHash && (!token.ExpiresAt.Valid || token.ExpiresAt.Time.After(time.Now())) {
+				return s.user, nil
+			}
+		}
+		return storage.User{}, sql.ErrNoRows
+	}).AnyTimes()
+	querier.EXPECT().UpdateAPITokenUsage(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	querier.EXPECT().GetUserOrganization(gomock.Any(), s.user.ID).Return(storage.Organization{}, sql.ErrNoRows).AnyTimes()
+	querier.EXPECT().ListAPITokensByUser(gomock.Any(), s.user.ID).DoAndReturn(func(context.Context, uuid.UUID) ([]storage.ApiToken, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return append([]storage.ApiToken(nil), s.tokens...), nil
+	}).AnyTimes()
+	querier.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateAPITokenParams) (storage.ApiToken, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		token := storage.ApiToken{
+			ID:        int32(len(s.tokens) + 1),
+			UserID:    arg.UserID,
+			Name:      arg.Name,
+			TokenHash: arg.TokenHash,
+			ExpiresAt: arg.ExpiresAt,
+			CreatedAt: time.Now(),
+		}
+		s.tokens = append(s.tokens, token)
+		return token, nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteAPIToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.DeleteAPITokenParams) (int64, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, token := range s.tokens {
+			if token.ID == arg.ID && token.UserID == arg.UserID {
+				s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+				return 1, nil
+			}
+		}
+		return 0, nil
+	}).AnyTimes()
+
+	querier.EXPECT().GetUserVanityNameservers(gomock.Any(), gomock.Any()).Return(storage.VanityNameserver{}, sql.ErrNoRows).AnyTimes()
+	querier.EXPECT().LockZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.LockZoneParams) (storage.Zone, error) {
+		return s.zone(arg.Zone, arg.UserID)
+	}).AnyTimes()
+	querier.EXPECT().GetZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.GetZoneParams) (storage.Zone, error) {
+		return s.zone(arg.Zone, arg.UserID)
+	}).AnyTimes()
+	querier.EXPECT().GetActiveZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, name string) (storage.Zone, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, zone := range s.zones {
+			if zone.Zone == name && zone.Status == recordmanager.ZoneStatusActive {
+				return zone, nil
+			}
+		}
+		return storage.Zone{}, sql.ErrNoRows
+	}).AnyTimes()
+	querier.EXPECT().CreateZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateZoneParams) (storage.Zone, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		zone := storage.Zone{
+			Zone:              arg.Zone,
+			UserID:            arg.UserID,
+			Status:            arg.Status,
+			VerificationToken: arg.VerificationToken,
+			CreatedAt:         time.Now(),
+		}
+		s.zones = append(s.zones, zone)
+		return zone, nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.DeleteZoneParams) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.zones = slices.DeleteFunc(s.zones, func(zone storage.Zone) bool {
+			return zone.Zone == arg.Zone && zone.UserID == arg.UserID
+		})
+		return nil
+	}).AnyTimes()
+	querier.EXPECT().SearchZones(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.SearchZonesParams) ([]storage.SearchZonesRow, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.zoneSearches++
+		return s.searchZones(arg), nil
+	}).AnyTimes()
+
+	querier.EXPECT().ListRecordsByZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.ListRecordsByZoneParams) ([]storage.CorednsRecord, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var records []storage.CorednsRecord
+		for _, record := range s.records {
+			if record.Zone == arg.Zone && record.UserID == arg.UserID {
+				records = append(records, record)
+			}
+		}
+		return records, nil
+	}).AnyTimes()
+	querier.EXPECT().CreateRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.CreateRecordParams) (storage.CorednsRecord, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		record := storage.CorednsRecord{
+			ID:         1,
+			UserID:     arg.UserID,
+			Zone:       arg.Zone,
+			Name:       arg.Name,
+			Ttl:        arg.Ttl,
+			Content:    arg.Content,
+			RecordType: arg.RecordType,
+			Version:    1,
+		}
+		for _, existing := range s.records {
+			record.ID = max(record.ID, existing.ID+1)
+		}
+		s.records = append(s.records, record)
+		return record, nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteRecordsByZone(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.DeleteRecordsByZoneParams) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.records = slices.DeleteFunc(s.records, func(record storage.CorednsRecord) bool {
+			return record.Zone == arg.Zone && record.UserID == arg.UserID
+		})
+		return nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteInvalidRecordsByZone(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	querier.EXPECT().GetRecordByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.GetRecordByIDParams) (storage.CorednsRecord, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, record := range s.records {
+			if record.ID == arg.ID && record.Zone == arg.Zone && record.UserID == arg.UserID {
+				return record, nil
+			}
+		}
+		return storage.CorednsRecord{}, sql.ErrNoRows
+	}).AnyTimes()
+	querier.EXPECT().DeleteRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, arg storage.DeleteRecordParams) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, record := range s.records {
+			if record.ID == arg.ID && record.Zone == arg.Zone && record.UserID == arg.UserID {
+				s.records = append(s.records[:i], s.records[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}).AnyTimes()
+	querier.EXPECT().DeleteInvalidRecord(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	querier.EXPECT().CreateRecordHistory(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+// zone returns the user's zone with the name
+func (s *testServer) zone(name string, userID uuid.UUID) (storage.Zone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, zone := range s.zones {
+		if zone.Zone == name && zone.UserID == userID {
+			return zone, nil
+		}
+	}
+	return storage.Zone{}, sql.ErrNoRows
+}
+
+// searchZones lists a page of zones as the SearchZones query does, sorted by
+// name
+func (s *testServer) searchZones(arg storage.SearchZonesParams) []storage.SearchZonesRow {
+	if arg.Sort != "name" {
+		s.t.Errorf("SearchZones() sort = %q, want name", arg.Sort)
+	}
+	var rows []storage.SearchZonesRow
+	for _, zone := range s.zones {
+		if zone.UserID != arg.UserID || !strings.HasPrefix(zone.Zone, arg.NamePrefix) {
+			continue
+		}
+		if arg.Status != "" && zone.Status != arg.Status {
+			continue
+		}
+		if arg.CursorKey != "" && (arg.Descending && zone.Zone >= arg.CursorKey || !arg.Descending && zone.Zone <= arg.CursorKey) {
+			continue
+		}
+		rows = append(rows, storage.SearchZonesRow{
+			Zone:              zone.Zone,
+			UserID:            zone.UserID,
+			Status:            zone.Status,
+			VerificationToken: zone.VerificationToken,
+			CreatedAt:         zone.CreatedAt,
+			SortKey:           zone.Zone,
+		})
+	}
+	slices.SortFunc(rows, func(a, b storage.SearchZonesRow) int {
+		if arg.Descending {
+			return strings.Compare(b.SortKey, a.SortKey)
+		}
+		return strings.Compare(a.SortKey, b.SortKey)
+	})
+	return rows[:min(len(rows), int(arg.PageSize))]
+}
+
+// addZone adds an active zone of the user
+func (s *testServer) addZone(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zones = append(s.zones, storage.Zone{Zone: name, UserID: s.user.ID, Status: recordmanager.ZoneStatusActive, CreatedAt: time.Now()})
+}
+
+// addToken adds an API token of the user, returning the token itself
+func (s *testServer) addToken(name string) string {
+	token := "tofu_" + name
+	sum := sha256.Sum256([]byte(token))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = append(s.tokens, storage.ApiToken{
+		ID:        int32(len(s.tokens) + 1),
+		UserID:    s.user.ID,
+		Name:      name,
+		TokenHash: hex.EncodeToString(sum[:]),
+		CreatedAt: time.Now(),
+	})
+	return token
+}
+
+// expireToken makes the token with the ID expire
+func (s *testServer) expireToken(id int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tokens {
+		if s.tokens[i].ID == id {
+			s.tokens[i].ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+		}
+	}
+}
+
+// addRecord adds an A record to the zone
+func (s *testServer) addRecord(id int64, name, ip string, version int32) {
+	content, _ := json.Marshal(map[string]string{"ip": ip})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, storage.CorednsRecord{
+		ID:         id,
+		UserID:     s.user.ID,
+		Zone:       testZone,
+		Name:       name,
+		Ttl:        sql.NullInt32{Int32: 300, Valid: true},
+		Content:    content,
+		RecordType: "A",
+		Version:    version,
+	})
+}
+
+// client returns a client authenticating with the token, without retries
+// unless the options enable them
+func (s *testServer) client(token string, opts ...client.Option) *client.Client {
+	s.t.Helper()
+	c, err := client.New(s.url, token, append([]client.Option{client.WithRetries(0, 0, 0)}, opts...)...)
+	if err != nil {
+		s.t.Fatalf("client.New() error = %v", err)
+	}
+	return c
+}
+
+// do sends a request with the body, if any, authenticated with the token
+// outside the client, returning the status of the response
+func (s *testServer) do(method, path, token string, header http.Header, body string) int {
+	s.t.Helper()
+	r, err := http.NewRequest(method, s.url+path, strings.NewReader(body))
+	if err != nil {
+		s.t.Fatalf("http.NewRequest() error = %v", err)
+	}
+	r.Header = header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		s.t.Fatalf("%s %s error = %v", method, path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// assertStatus checks the error is an API error with the status
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want an API error with status %d", err, status)
+	}
+	if apiErr.StatusCode != status {
+		t.Fatalf("status = %d, want %d: %v", apiErr.StatusCode, status, err)
+	}
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	s := newTestServer(t)
+	token := s.addToken("valid")
+	ctx := context.Background()
+
+	if _, err := s.client(token).ListTokens(ctx); err != nil {
+		t.Fatalf("ListTokens() with a valid token error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "no token", token: ""},
+		{name: "unknown token", token: "tofu_unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.client(tt.token).ListTokens(ctx)
+			if !errors.Is(err, client.ErrUnauthorized) {
+				t.Fatalf("ListTokens() error = %v, want ErrUnauthorized", err)
+			}
+		})
+	}
+}
+
+func TestExpiredToken(t *testing.T) {
+	s := newTestServer(t)
+	s.addToken("valid")
+	expiring := s.addToken("expiring")
+	ctx := context.Background()
+
+	if _, err := s.client(expiring).ListTokens(ctx); err != nil {
+		t.Fatalf("ListTokens() before expiry error = %v", err)
+	}
+
+	s.expireToken(2)
+	_, err := s.client(expiring).ListTokens(ctx)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("ListTokens() after expiry error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestDeleteToken(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"))
+	deploy := s.addToken("deploy")
+	ctx := context.Background()
+
+	tokens, err := s.client(deploy).ListTokens(ctx)
+	if err != nil {
+		t.Fatalf("ListTokens() with the second token error = %v", err)
+	}
+	if len(tokens) != 2 || tokens[1].Name != "deploy" {
+		t.Fatalf("ListTokens() = %+v, want the valid and deploy tokens", tokens)
+	}
+
+	// A revoked token no longer authenticates, and can't be deleted again
+	if err := c.DeleteToken(ctx, tokens[1].ID); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+	if _, err := s.client(deploy).ListTokens(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("ListTokens() with the revoked token error = %v, want ErrUnauthorized", err)
+	}
+	if err := c.DeleteToken(ctx, tokens[1].ID); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("DeleteToken() of the revoked token error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTokenCannotCreateTokens(t *testing.T) {
+	s := newTestServer(t)
+	token := s.addToken("valid")
+
+	status := s.do(http.MethodPost, "/api/tokens", token, http.Header{"Content-Type": {"application/json"}}, `{"name":"minted"}`)
+	if status != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", status, http.StatusForbidden)
+	}
+	tokens, err := s.client(token).ListTokens(context.Background())
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if len(tokens) != 1 {
+		t.Errorf("ListTokens() = %d tokens, want no token created", len(tokens))
+	}
+}
+
+func TestRecordNotFound(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"))
+	ctx := context.Background()
+
+	if _, err := c.GetRecord(ctx, testZone, 1); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetRecord() error = %v, want ErrNotFound", err)
+	}
+	if err := c.DeleteRecord(ctx, testZone, 1, client.AnyVersion); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteRecord() error = %v, want ErrNotFound", err)
+	}
+	if err := c.DeleteRecord(ctx, "other.example.", 1, client.AnyVersion); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteRecord() in an unknown zone error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateChangedRecord(t *testing.T) {
+	s := newTestServer(t)
+	s.addRecord(1, "www", "192.0.2.1", 2)
+	c := s.client(s.addToken("valid"))
+
+	_, err := c.UpdateRecord(context.Background(), testZone, &client.Record{
+		ID:      1,
+		Name:    "www",
+		Type:    "A",
+		TTL:     300,
+		Version: 1,
+		A:       &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.2")}},
+	})
+	if !errors.Is(err, client.ErrRecordChanged) {
+		t.Fatalf("UpdateRecord() error = %v, want ErrRecordChanged", err)
+	}
+	var apiErr *client.Error
+	errors.As(err, &apiErr)
+	if apiErr.Current == nil || apiErr.Current.Version != 2 || apiErr.Current.A.Ip.String() != "192.0.2.1" {
+		t.Errorf("UpdateRecord() current record = %+v, want version 2 of the record", apiErr.Current)
+	}
+}
+
+func TestDeleteRecord(t *testing.T) {
+	s := newTestServer(t)
+	s.addRecord(1, "www", "192.0.2.1", 2)
+	token := s.addToken("valid")
+	c := s.client(token)
+	ctx := context.Background()
+	path := "/api/zones/" + testZone + "/records/1"
+
+	if status := s.do(http.MethodDelete, path, token, nil, ""); status != http.StatusPreconditionRequired {
+		t.Fatalf("DELETE without If-Match status = %d, want %d", status, http.StatusPreconditionRequired)
+	}
+	assertStatus(t, c.DeleteRecord(ctx, testZone, 1, 0), http.StatusPreconditionRequired)
+	if err := c.DeleteRecord(ctx, testZone, 1, 1); !errors.Is(err, client.ErrRecordChanged) {
+		t.Fatalf("DeleteRecord() of an old version error = %v, want ErrRecordChanged", err)
+	}
+	if err := c.DeleteRecord(ctx, testZone, 1, 2); err != nil {
+		t.Fatalf("DeleteRecord() error = %v", err)
+	}
+	if _, err := c.GetRecord(ctx, testZone, 1); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetRecord() of the deleted record error = %v, want ErrNotFound", err)
+	}
+	if status := s.do(http.MethodDelete, path, token, http.Header{"If-Match": {strconv.Quote("2")}}, ""); status != http.StatusNotFound {
+		t.Errorf("DELETE of the deleted record status = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestApplyChangesRequiresVersion(t *testing.T) {
+	s := newTestServer(t)
+	s.addRecord(1, "www", "192.0.2.1", 2)
+	token := s.addToken("valid")
+	c := s.client(token)
+	ctx := context.Background()
+	path := "/api/zones/" + testZone + "/changes"
+
+	if status := s.do(http.MethodPost, path, token, nil, `{"changes":[{"action":"delete","id":1}]}`); status != http.StatusPreconditionRequired {
+		t.Fatalf("delete without a version status = %d, want %d", status, http.StatusPreconditionRequired)
+	}
+	if status := s.do(http.MethodPost, path, token, nil, `{"changes":[{"action":"delete","id":1,"version":0}]}`); status != http.StatusBadRequest {
+		t.Fatalf("delete with version 0 status = %d, want %d", status, http.StatusBadRequest)
+	}
+	_, err := c.ApplyChanges(ctx, testZone, []client.Change{{Action: client.ChangeDelete, ID: 1, Version: 1}})
+	assertStatus(t, err, http.StatusPreconditionFailed)
+
+	// The client doesn't make a missing version apply to any version
+	_, err = c.ApplyChanges(ctx, testZone, []client.Change{{Action: client.ChangeDelete, ID: 1}})
+	assertStatus(t, err, http.StatusPreconditionRequired)
+
+	if _, err := c.ApplyChanges(ctx, testZone, []client.Change{{Action: client.ChangeDelete, ID: 1, Version: client.AnyVersion}}); err != nil {
+		t.Fatalf("ApplyChanges() error = %v", err)
+	}
+	if _, err := c.GetRecord(ctx, testZone, 1); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetRecord() of the deleted record error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestZoneLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"))
+	ctx := context.Background()
+
+	created, err := c.CreateZone(ctx, "Example.org")
+	if err != nil {
+		t.Fatalf("CreateZone() error = %v", err)
+	}
+	if created.Name != "example.org." || created.Status != client.ZoneStatusActive || !slices.Equal(created.Nameservers, testNameservers) {
+		t.Errorf("CreateZone() = %+v, want the active zone example.org. on the service's nameservers", created)
+	}
+	if _, err := c.CreateZone(ctx, "example.org."); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("CreateZone() of an existing zone error = %v, want ErrConflict", err)
+	}
+
+	// The zone starts with its SOA record and an NS record per nameserver
+	var types []string
+	for _, record := range s.records {
+		if record.Zone == "example.org." {
+			types = append(types, record.RecordType)
+		}
+	}
+	slices.Sort(types)
+	if !slices.Equal(types, []string{"NS", "NS", "SOA"}) {
+		t.Errorf("created record types = %q, want the SOA and NS records", types)
+	}
+
+	zone, err := c.GetZone(ctx, "example.org")
+	if err != nil {
+		t.Fatalf("GetZone() error = %v", err)
+	}
+	if zone.Name != created.Name || !zone.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("GetZone() = %+v, want %+v", zone, created)
+	}
+	zones, err := c.ListZones(ctx)
+	if err != nil {
+		t.Fatalf("ListZones() error = %v", err)
+	}
+	if len(zones) != 2 || zones[0].Name != testZone || zones[1].Name != "example.org." {
+		t.Errorf("ListZones() = %+v, want %s and example.org.", zones, testZone)
+	}
+
+	if err := c.DeleteZone(ctx, "example.org."); err != nil {
+		t.Fatalf("DeleteZone() error = %v", err)
+	}
+	if _, err := c.GetZone(ctx, "example.org."); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetZone() of the deleted zone error = %v, want ErrNotFound", err)
+	}
+	if err := c.DeleteZone(ctx, "example.org."); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteZone() of the deleted zone error = %v, want ErrNotFound", err)
+	}
+	for _, record := range s.records {
+		if record.Zone == "example.org." {
+			t.Errorf("record %d left after deleting the zone", record.ID)
+		}
+	}
+}
+
+func TestListZonesPages(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"))
+	want := []string{testZone}
+	for i := range 2 * recordmanager.DefaultPageSize {
+		name := fmt.Sprintf("zone%03d.example.", i)
+		s.addZone(name)
+		want = append(want, name)
+	}
+	slices.Sort(want)
+
+	zones, err := c.ListZones(context.Background())
+	if err != nil {
+		t.Fatalf("ListZones() error = %v", err)
+	}
+	var names []string
+	for _, zone := range zones {
+		names = append(names, zone.Name)
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("ListZones() = %d zones, want all %d in order", len(names), len(want))
+	}
+	if s.zoneSearches != 3 {
+		t.Errorf("ListZones() listed %d pages, want 3", s.zoneSearches)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *client.Client) error
+		failures []int
+		// status is the status of the error, if the request fails
+		status   int
+		requests int
+	}{
+		{
+			name:     "GET after server errors",
+			call:     listTokens,
+			failures: []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			requests: 3,
+		},
+		{
+			name:     "GET after rate limiting",
+			call:     listTokens,
+			failures: []int{http.StatusTooManyRequests},
+			requests: 2,
+		},
+		{
+			name:     "POST after rate limiting",
+			call:     createZone,
+			failures: []int{http.StatusTooManyRequests},
+			requests: 2,
+		},
+		{
+			name:     "POST not after server errors",
+			call:     createZone,
+			failures: []int{http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+			requests: 1,
+		},
+		{
+			name:     "DELETE after server errors",
+			call:     deleteZone,
+			failures: []int{http.StatusInternalServerError},
+			requests: 2,
+		},
+		{
+			name:     "retries exhausted",
+			call:     listTokens,
+			failures: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+			requests: 4,
+		},
+		{
+			name:     "client errors",
+			call:     listTokens,
+			failures: []int{http.StatusBadRequest},
+			status:   http.StatusBadRequest,
+			requests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			c := s.client(s.addToken("valid"), client.WithRetries(3, time.Millisecond, 10*time.Millisecond))
+			s.fail("", tt.failures...)
+
+			err := tt.call(c)
+			if tt.status == 0 && err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.status != 0 {
+				assertStatus(t, err, tt.status)
+			}
+			if requests := s.requestCount(); requests != tt.requests {
+				t.Errorf("requests = %d, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"), client.WithRetries(1, time.Millisecond, 10*time.Millisecond))
+	s.fail("3600", http.StatusTooManyRequests)
+
+	// The server asks for an hour, which is capped by the maximum backoff
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.ListTokens(ctx); err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if requests := s.requestCount(); requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client(s.addToken("valid"), client.WithRetries(3, time.Hour, time.Hour))
+	s.fail("", http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListTokens(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListTokens() error = %v, want the context's error", err)
+	}
+	if requests := s.requestCount(); requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+// listTokens, createZone and deleteZone send GET, POST and DELETE requests
+func listTokens(c *client.Client) error {
+	_, err := c.ListTokens(context.Background())
+	return err
+}
+
+func createZone(c *client.Client) error {
+	_, err := c.CreateZone(context.Background(), "example.org.")
+	return err
+}
+
+func deleteZone(c *client.Client) error {
+	return c.DeleteZone(context.Background(), testZone)
+}
@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/tofudns/tofudns/internal/recordmanager"
+)
+
+// Record content types, shared with the server
+type (
+	AData     = recordmanager.AData
+	AAAAData  = recordmanager.AAAAData
+	TXTData   = recordmanager.TXTData
+	CNAMEData = recordmanager.CNAMEData
+	NSData    = recordmanager.NSData
+	MXData    = recordmanager.MXData
+	SRVData   = recordmanager.SRVData
+	SOAData   = recordmanager.SOAData
+	CAAData   = recordmanager.CAAData
+	IPAddr    = recordmanager.IPAddr
+)
+
+// ApexName is the name of records at the zone apex
+const ApexName = recordmanager.ApexName
+
+// Record is a DNS record of a zone. The content is set in the field of the
+// record's type.
+type Record struct {
+	ID          int64
+	Zone        string
+	ZoneUnicode string
+	// Name is relative to the zone, empty or @ for the apex
+	Name        string
+	NameUnicode string
+	Type        string
+	TTL         int32
+	// Version is incremented on every change of the record. Updates and
+	// deletes only apply to the version, or to any version if it is
+	// AnyVersion. The API rejects them without a version.
+	Version int32
+	// ContentError is set on records whose stored content the server can't
+	// decode, leaving the content fields nil. Such records can be deleted or
+	// replaced.
+	ContentError string
+
+	A     *AData
+	AAAA  *AAAAData
+	TXT   *TXTData
+	CNAME *CNAMEData
+	NS    *NSData
+	MX    *MXData
+	SRV   *SRVData
+	SOA   *SOAData
+	CAA   *CAAData
+}
+
+// recordJSON is the API representation of a record
+type recordJSON struct {
+	ID          int64           `json:"id,omitempty"`
+	Zone        string          `json:"zone,omitempty"`
+	ZoneUnicode string          `json:"zone_unicode,omitempty"`
+	Name        string          `json:"name"`
+	NameUnicode string          `json:"name_unicode,omitempty"`
+	RecordType  string          `json:"record_type"`
+	TTL         int32           `json:"ttl"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	Version     int32           `json:"version,omitempty"`
+	// ContentError is only set in responses
+	ContentError string `json:"content_error,omitempty"`
+}
+
+// Content returns the content of the record's type
+func (r *Record) Content() (interface{}, error) {
+	switch r.Type {
+	case "A":
+		return r.A, nil
+	case "AAAA":
+		return r.AAAA, nil
+	case "TXT":
+		return r.TXT, nil
+	case "CNAME":
+		return r.CNAME, nil
+	case "NS":
+		return r.NS, nil
+	case "MX":
+		return r.MX, nil
+	case "SRV":
+		return r.SRV, nil
+	case "SOA":
+		return r.SOA, nil
+	case "CAA":
+		return r.CAA, nil
+	default:
+		return nil, fmt.Errorf("unknown record type: %s", r.Type)
+	}
+}
+
+// MarshalJSON encodes the record with the content of its type
+func (r Record) MarshalJSON() ([]byte, error) {
+	content, err := r.Content()
+	if err != nil {
+		return nil, err
+	}
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(recordJSON{
+		ID:          r.ID,
+		Zone:        r.Zone,
+		ZoneUnicode: r.ZoneUnicode,
+		Name:        r.Name,
+		NameUnicode: r.NameUnicode,
+		RecordType:  r.Type,
+		TTL:         r.TTL,
+		Content:     contentJSON,
+		Version:     max(r.Version, 0),
+	})
+}
+
+// UnmarshalJSON decodes the record, decoding the content into the field of
+// its type
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var v recordJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*r = Record{
+		ID:           v.ID,
+		Zone:         v.Zone,
+		ZoneUnicode:  v.ZoneUnicode,
+		Name:         v.Name,
+		NameUnicode:  v.NameUnicode,
+		Type:         v.RecordType,
+		TTL:          v.TTL,
+		Version:      v.Version,
+		ContentError: v.ContentError,
+	}
+	if len(v.Content) == 0 || v.ContentError != "" {
+		return nil
+	}
+
+	var content interface{}
+	switch r.Type {
+	case "A":
+		r.A = &AData{}
+		content = r.A
+	case "AAAA":
+		r.AAAA = &AAAAData{}
+		content = r.AAAA
+	case "TXT":
+		r.TXT = &TXTData{}
+		content = r.TXT
+	case "CNAME":
+		r.CNAME = &CNAMEData{}
+		content = r.CNAME
+	case "NS":
+		r.NS = &NSData{}
+		content = r.NS
+	case "MX":
+		r.MX = &MXData{}
+		content = r.MX
+	case "SRV":
+		r.SRV = &SRVData{}
+		content = r.SRV
+	case "SOA":
+		r.SOA = &SOAData{}
+		content = r.SOA
+	case "CAA":
+		r.CAA = &CAAData{}
+		content = r.CAA
+	default:
+		// Keep records of types this client doesn't know without content
+		return nil
+	}
+	if err := json.Unmarshal(v.Content, content); err != nil {
+		return fmt.Errorf("invalid %s record content: %w", r.Type, err)
+	}
+	return nil
+}
+
+// ListRecords lists the records of a zone
+func (c *Client) ListRecords(ctx context.Context, zone string) ([]Record, error) {
+	return listAll[Record](ctx, c, zonePath(zone, "records"), nil, "records")
+}
+
+// ListInvalidRecords lists the records of a zone that the server set aside,
+// and doesn't serve, as their content was invalid. Their ContentError says
+// why and their content is given as stored. They can only be deleted.
+func (c *Client) ListInvalidRecords(ctx context.Context, zone string) ([]Record, error) {
+	return listAll[Record](ctx, c, zonePath(zone, "invalid-records"), nil, "records")
+}
+
+// RecordQuery filters and orders the records of a zone. Empty fields match
+// any record.
+type RecordQuery struct {
+	// Name only matches records with the owner name, @ for the apex
+	Name string
+	// NamePrefix matches records whose relative owner name starts with it
+	NamePrefix string
+	Type       string
+	// Content matches records whose content contains it, ignoring case
+	Content string
+	// Sort is name, type, ttl or id, optionally prefixed with - for
+	// descending order
+	Sort string
+}
+
+// FindRecords lists the records of a zone with the owner name and type. An
+// empty name or type matches any.
+func (c *Client) FindRecords(ctx context.Context, zone, name, recordType string) ([]Record, error) {
+	return c.SearchRecords(ctx, zone, RecordQuery{Name: name, Type: recordType})
+}
+
+// SearchRecords lists the records of a zone matching the query, in its order
+func (c *Client) SearchRecords(ctx context.Context, zone string, q RecordQuery) ([]Record, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"name":        q.Name,
+		"name_prefix": q.NamePrefix,
+		"type":        q.Type,
+		"content":     q.Content,
+		"sort":        q.Sort,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return listAll[Record](ctx, c, zonePath(zone, "records"), query, "records")
+}
+
+// SearchAllRecords lists the records of all of the user's zones whose owner
+// name or content values contain the text, ignoring case, such as the
+// records pointing at an IP address
+func (c *Client) SearchAllRecords(ctx context.Context, text string) ([]Record, error) {
+	return listAll[Record](ctx, c, "/search", url.Values{"q": {text}}, "records")
+}
+
+// GetRecord returns a record of a zone
+func (c *Client) GetRecord(ctx context.Context, zone string, id int64) (*Record, error) {
+	var record Record
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   zonePath(zone, "records", strconv.FormatInt(id, 10)),
+	}, &record)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// CreateRecord creates a record in a zone
+func (c *Client) CreateRecord(ctx context.Context, zone string, record *Record) (*Record, error) {
+	var response struct {
+		Record Record `json:"record"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   zonePath(zone, "records"),
+		body:   record,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Record, nil
+}
+
+// UpdateRecord replaces the record with the record's ID. It is only updated if
+// it hasn't changed since the record's version, otherwise an error matching
+// ErrRecordChanged is returned with the current record.
+func (c *Client) UpdateRecord(ctx context.Context, zone string, record *Record) (*Record, error) {
+	var response struct {
+		Record Record `json:"record"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   zonePath(zone, "records", strconv.FormatInt(record.ID, 10)),
+		header: ifMatch(record.Version),
+		body:   record,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Record, nil
+}
+
+// DeleteRecord deletes a record of a zone if it hasn't changed since the
+// version, or whatever its version if it is AnyVersion.
+func (c *Client) DeleteRecord(ctx context.Context, zone string, id int64, version int32) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   zonePath(zone, "records", strconv.FormatInt(id, 10)),
+		header: ifMatch(version),
+	}, nil)
+}
+
+// ifMatch returns the If-Match header expecting the version of a record, or
+// any version for AnyVersion. Zero isn't a version, so there is no header and
+// the API rejects the request.
+func ifMatch(version int32) http.Header {
+	switch version {
+	case 0:
+		return nil
+	case AnyVersion:
+		return http.Header{"If-Match": {"*"}}
+	}
+	return http.Header{"If-Match": {fmt.Sprintf(`"%d"`, version)}}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// BulkReplace replaces a value of the records of a type, such as the IP
+// address of A records, across zones. The value of a record is its IP
+// address, host, target, text or CAA value.
+type BulkReplace struct {
+	// Zone limits the replacement to one zone. Empty replaces the value in
+	// all of the user's zones.
+	Zone string `json:"zone,omitempty"`
+	Type string `json:"record_type"`
+	// Value selects the records whose value is equal to it
+	Value       string `json:"value"`
+	Replacement string `json:"replacement"`
+}
+
+// BulkReplacePlan is the set of updates of a bulk replacement
+type BulkReplacePlan struct {
+	// Hash identifies the plan's changes and the state of the records the
+	// plan was made against
+	Hash    string          `json:"hash"`
+	Changes []PlannedChange `json:"changes"`
+}
+
+// PlanBulkReplace previews the updates of a bulk replacement without
+// applying them
+func (c *Client) PlanBulkReplace(ctx context.Context, replace BulkReplace) (*BulkReplacePlan, error) {
+	var plan BulkReplacePlan
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/replace/plan",
+		body:   replace,
+	}, &plan)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ApplyBulkReplace applies a bulk replacement to all selected records
+// atomically, provided the plan with the hash still applies. If the records
+// have changed since the plan was made, an error matching ErrConflict is
+// returned.
+func (c *Client) ApplyBulkReplace(ctx context.Context, replace BulkReplace, planHash string) (*BulkReplacePlan, []ChangeResult, error) {
+	var response struct {
+		Plan    BulkReplacePlan `json:"plan"`
+		Results []ChangeResult  `json:"results"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/replace/apply",
+		body: struct {
+			BulkReplace
+			PlanHash string `json:"plan_hash"`
+		}{replace, planHash},
+	}, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response.Plan, response.Results, nil
+}
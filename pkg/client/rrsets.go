@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// RRSet is the records of a zone sharing an owner name and type, which share
+// a TTL
+type RRSet struct {
+	Zone        string   `json:"zone"`
+	ZoneUnicode string   `json:"zone_unicode"`
+	Name        string   `json:"name"`
+	NameUnicode string   `json:"name_unicode"`
+	Type        string   `json:"record_type"`
+	TTL         int32    `json:"ttl"`
+	Records     []Record `json:"records"`
+}
+
+// ListRRSets lists the records of a zone grouped into RRsets
+func (c *Client) ListRRSets(ctx context.Context, zone string) ([]RRSet, error) {
+	return listAll[RRSet](ctx, c, zonePath(zone, "rrsets"), nil, "rrsets")
+}
+
+// GetRRSet returns the RRset of a zone with the owner name and type. An
+// error matching ErrNotFound is returned if the zone has no such records.
+func (c *Client) GetRRSet(ctx context.Context, zone, name, recordType string) (*RRSet, error) {
+	var rrset RRSet
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   rrsetPath(zone, name, recordType),
+	}, &rrset)
+	if err != nil {
+		return nil, err
+	}
+	return &rrset, nil
+}
+
+// ReplaceRRSet replaces the records of an RRset atomically with the records,
+// of which only the content is used. Records whose content is unchanged keep
+// their IDs. Replacing an RRset with no records deletes it.
+func (c *Client) ReplaceRRSet(ctx context.Context, zone, name, recordType string, ttl int32, records []Record) (*RRSet, error) {
+	recordType = strings.ToUpper(recordType)
+	contents := make([]Record, len(records))
+	for i, record := range records {
+		record.Type = recordType
+		contents[i] = record
+	}
+
+	var response struct {
+		RRSet RRSet `json:"rrset"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   rrsetPath(zone, name, recordType),
+		body: map[string]interface{}{
+			"ttl":     ttl,
+			"records": contents,
+		},
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.RRSet, nil
+}
+
+// DeleteRRSet deletes the records of an RRset atomically
+func (c *Client) DeleteRRSet(ctx context.Context, zone, name, recordType string) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   rrsetPath(zone, name, recordType),
+	}, nil)
+}
+
+// rrsetPath returns the API path of an RRset
+func rrsetPath(zone, name, recordType string) string {
+	if name == "" {
+		name = ApexName
+	}
+	return zonePath(zone, "rrsets", name, recordType)
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Token is an API token of the user. Tokens are created on the account page,
+// which shows the token itself once, and can't be created with a token.
+type Token struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// ListTokens lists the user's API tokens
+func (c *Client) ListTokens(ctx context.Context) ([]Token, error) {
+	return listAll[Token](ctx, c, "/tokens", nil, "tokens")
+}
+
+// DeleteToken deletes an API token
+func (c *Client) DeleteToken(ctx context.Context, id int32) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/tokens/" + strconv.FormatInt(int64(id), 10),
+	}, nil)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Zone statuses
+const (
+	// ZoneStatusPending zones are claimed but not served until verified
+	ZoneStatusPending = "pending"
+	// ZoneStatusActive zones are verified and served
+	ZoneStatusActive = "active"
+)
+
+// Zone is a zone of the user
+type Zone struct {
+	Name        string     `json:"name"`
+	NameUnicode string     `json:"name_unicode"`
+	Status      string     `json:"status"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Nameservers are the nameservers the zone must be delegated to
+	Nameservers []string `json:"nameservers"`
+	// Verification is the TXT record proving the ownership of a pending zone
+	Verification *ZoneVerification `json:"verification,omitempty"`
+}
+
+// SOATimers are the timers of a zone's SOA record, in seconds
+type SOATimers struct {
+	Refresh uint32 `json:"refresh"`
+	Retry   uint32 `json:"retry"`
+	Expire  uint32 `json:"expire"`
+	MinTtl  uint32 `json:"minttl"`
+}
+
+// ZoneVerification is the TXT record proving the ownership of a zone
+type ZoneVerification struct {
+	TXTName  string `json:"txt_name"`
+	TXTValue string `json:"txt_value"`
+}
+
+// ListZones lists the user's zones
+func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
+	return listAll[Zone](ctx, c, "/zones", nil, "zones")
+}
+
+// GetZone returns one of the user's zones
+func (c *Client) GetZone(ctx context.Context, zone string) (*Zone, error) {
+	var z Zone
+	if err := c.do(ctx, request{method: http.MethodGet, path: zonePath(zone)}, &z); err != nil {
+		return nil, err
+	}
+	return &z, nil
+}
+
+// CreateZone claims a zone for the user. Unless the server is configured
+// otherwise, the zone is pending until its ownership has been verified.
+func (c *Client) CreateZone(ctx context.Context, zone string) (*Zone, error) {
+	var response struct {
+		Zone Zone `json:"zone"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/zones",
+		body:   map[string]string{"name": zone},
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Zone, nil
+}
+
+// DeleteZone deletes one of the user's zones along with its records
+func (c *Client) DeleteZone(ctx context.Context, zone string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: zonePath(zone)}, nil)
+}
+
+// UpdateSOATimers sets the timers of a zone's SOA record, keeping its
+// nameserver and mailbox. The server rejects timers outside sane bounds.
+func (c *Client) UpdateSOATimers(ctx context.Context, zone string, timers SOATimers) (*Record, error) {
+	var response struct {
+		Record Record `json:"record"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   zonePath(zone) + "/soa",
+		body:   timers,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Record, nil
+}
@@ -0,0 +1,298 @@
+// Package zonefile converts between TofuDNS records and the zone file format
+// of RFC 1035, as written by the tofudns CLI and the Terraform provider.
+package zonefile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/tofudns/tofudns/pkg/client"
+)
+
+// maxTXTStringLength is the longest character string of a TXT record
+const maxTXTStringLength = 255
+
+// DefaultTTL is the TTL of zone file records that don't have one
+const DefaultTTL = 3600
+
+// Parse parses the records of a zone file. Relative names are relative to the
+// zone. The zone's SOA and apex NS records are managed by TofuDNS, so they are
+// skipped unless withSOANS is set.
+func Parse(r io.Reader, zone, filename string, withSOANS bool) ([]client.Record, error) {
+	origin := dns.Fqdn(zone)
+	parser := dns.NewZoneParser(r, origin, filename)
+	parser.SetDefaultTTL(DefaultTTL)
+
+	var records []client.Record
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		header := rr.Header()
+		apex := strings.EqualFold(header.Name, origin)
+		if !withSOANS && apex && (header.Rrtype == dns.TypeSOA || header.Rrtype == dns.TypeNS) {
+			continue
+		}
+
+		record, err := rrToRecord(rr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		records = append(records, record)
+	}
+	if err := parser.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ParseRData parses records given by their owner name and type and the rdata
+// of each, as written in a zone file. A TXT value is taken as a single string
+// unless it is quoted.
+func ParseRData(zone, name, recordType string, ttl uint32, rdata []string) ([]client.Record, error) {
+	var b strings.Builder
+	for _, value := range rdata {
+		if strings.EqualFold(recordType, "TXT") && !strings.HasPrefix(value, `"`) {
+			value = quoteTXT(value)
+		}
+		fmt.Fprintf(&b, "%s %d IN %s %s\n", name, ttl, recordType, value)
+	}
+	return Parse(strings.NewReader(b.String()), zone, "rdata", true)
+}
+
+// Write writes the records of a zone as a zone file. Records that can't be
+// written are noted in comments.
+func Write(w io.Writer, zone string, records []client.Record) error {
+	origin := dns.Fqdn(zone)
+	if _, err := fmt.Fprintf(w, "$ORIGIN %s\n", origin); err != nil {
+		return err
+	}
+	for _, record := range records {
+		rr, err := recordToRR(origin, record)
+		if err != nil {
+			fmt.Fprintf(w, "; skipped record %d: %v\n", record.ID, err)
+			continue
+		}
+		if _, err := fmt.Fprintln(w, rr.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RData returns the record's rdata as written in a zone file
+func RData(zone string, record client.Record) (string, error) {
+	rr, err := recordToRR(dns.Fqdn(zone), record)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String())), nil
+}
+
+// Line returns the record as a line of a zone file
+func Line(zone string, record client.Record) (string, error) {
+	rr, err := recordToRR(dns.Fqdn(zone), record)
+	if err != nil {
+		return "", err
+	}
+	return rr.String(), nil
+}
+
+// ownerName returns the absolute owner name of a record
+func ownerName(origin string, record client.Record) string {
+	name := strings.TrimSpace(record.Name)
+	switch {
+	case name == "" || name == client.ApexName:
+		return origin
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + origin
+	}
+}
+
+// recordToRR converts a record to a resource record of the zone
+func recordToRR(origin string, record client.Record) (dns.RR, error) {
+	if record.ContentError != "" {
+		return nil, errors.New(record.ContentError)
+	}
+	noContent := fmt.Errorf("%s record has no content", record.Type)
+	header := dns.RR_Header{
+		Name:  ownerName(origin, record),
+		Class: dns.ClassINET,
+		Ttl:   uint32(record.TTL),
+	}
+	switch record.Type {
+	case "A":
+		if record.A == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeA
+		return &dns.A{Hdr: header, A: record.A.Ip.IP}, nil
+	case "AAAA":
+		if record.AAAA == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeAAAA
+		return &dns.AAAA{Hdr: header, AAAA: record.AAAA.Ip.IP}, nil
+	case "TXT":
+		if record.TXT == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeTXT
+		return &dns.TXT{Hdr: header, Txt: splitTXT(record.TXT.Text)}, nil
+	case "CNAME":
+		if record.CNAME == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeCNAME
+		return &dns.CNAME{Hdr: header, Target: dns.Fqdn(record.CNAME.Host)}, nil
+	case "NS":
+		if record.NS == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeNS
+		return &dns.NS{Hdr: header, Ns: dns.Fqdn(record.NS.Host)}, nil
+	case "MX":
+		if record.MX == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeMX
+		return &dns.MX{Hdr: header, Preference: record.MX.Preference, Mx: dns.Fqdn(record.MX.Host)}, nil
+	case "SRV":
+		if record.SRV == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeSRV
+		return &dns.SRV{
+			Hdr:      header,
+			Priority: record.SRV.Priority,
+			Weight:   record.SRV.Weight,
+			Port:     record.SRV.Port,
+			Target:   dns.Fqdn(record.SRV.Target),
+		}, nil
+	case "SOA":
+		if record.SOA == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeSOA
+		// TofuDNS doesn't store SOA serials
+		return &dns.SOA{
+			Hdr:     header,
+			Ns:      dns.Fqdn(record.SOA.Ns),
+			Mbox:    dns.Fqdn(record.SOA.MBox),
+			Refresh: record.SOA.Refresh,
+			Retry:   record.SOA.Retry,
+			Expire:  record.SOA.Expire,
+			Minttl:  record.SOA.MinTtl,
+		}, nil
+	case "CAA":
+		if record.CAA == nil {
+			return nil, noContent
+		}
+		header.Rrtype = dns.TypeCAA
+		return &dns.CAA{Hdr: header, Flag: record.CAA.Flag, Tag: record.CAA.Tag, Value: record.CAA.Value}, nil
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", record.Type)
+	}
+}
+
+// rrToRecord converts a resource record of the zone to a record
+func rrToRecord(rr dns.RR) (client.Record, error) {
+	header := rr.Header()
+	record := client.Record{
+		Name: header.Name,
+		Type: dns.TypeToString[header.Rrtype],
+		TTL:  int32(header.Ttl),
+	}
+
+	switch rr := rr.(type) {
+	case *dns.A:
+		record.A = &client.AData{Ip: client.IPAddr{IP: rr.A}}
+	case *dns.AAAA:
+		record.AAAA = &client.AAAAData{Ip: client.IPAddr{IP: rr.AAAA}}
+	case *dns.TXT:
+		var text strings.Builder
+		for _, str := range rr.Txt {
+			text.WriteString(unescapeTXT(str))
+		}
+		record.TXT = &client.TXTData{Text: text.String()}
+	case *dns.CNAME:
+		record.CNAME = &client.CNAMEData{Host: rr.Target}
+	case *dns.NS:
+		record.NS = &client.NSData{Host: rr.Ns}
+	case *dns.MX:
+		record.MX = &client.MXData{Host: rr.Mx, Preference: rr.Preference}
+	case *dns.SRV:
+		record.SRV = &client.SRVData{
+			Priority: rr.Priority,
+			Weight:   rr.Weight,
+			Port:     rr.Port,
+			Target:   rr.Target,
+		}
+	case *dns.SOA:
+		record.SOA = &client.SOAData{
+			Ns:      rr.Ns,
+			MBox:    rr.Mbox,
+			Refresh: rr.Refresh,
+			Retry:   rr.Retry,
+			Expire:  rr.Expire,
+			MinTtl:  rr.Minttl,
+		}
+	case *dns.CAA:
+		record.CAA = &client.CAAData{Flag: rr.Flag, Tag: rr.Tag, Value: rr.Value}
+	default:
+		return client.Record{}, fmt.Errorf("unsupported record type %s at %s", record.Type, header.Name)
+	}
+	return record, nil
+}
+
+// splitTXT splits a TXT value into character strings of at most 255 bytes,
+// escaped as the dns package keeps them
+func splitTXT(text string) []string {
+	var strs []string
+	for len(text) > maxTXTStringLength {
+		strs = append(strs, escapeTXT(text[:maxTXTStringLength]))
+		text = text[maxTXTStringLength:]
+	}
+	return append(strs, escapeTXT(text))
+}
+
+// escapeTXT escapes the backslashes and quotes of a character string
+func escapeTXT(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str)
+}
+
+// unescapeTXT returns the bytes of a character string as the dns package
+// keeps it, with \X standing for X and \DDD for the byte with the decimal
+// value DDD
+func unescapeTXT(str string) string {
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '\\' || i+1 == len(str) {
+			b.WriteByte(str[i])
+			continue
+		}
+		if i+3 < len(str) && isDigit(str[i+1]) && isDigit(str[i+2]) && isDigit(str[i+3]) {
+			value := int(str[i+1]-'0')*100 + int(str[i+2]-'0')*10 + int(str[i+3]-'0')
+			if value <= 255 {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		i++
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// isDigit reports whether the byte is a decimal digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// quoteTXT quotes a TXT value as a zone file character string
+func quoteTXT(text string) string {
+	return `"` + escapeTXT(text) + `"`
+}
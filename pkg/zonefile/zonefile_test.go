@@ -0,0 +1,260 @@
+package zonefile_test
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+const testZone = "example.org."
+
+const testZoneFile = `$ORIGIN example.org.
+$TTL 300
+@        3600 IN SOA ns1.example.net. hostmaster.example.net. 1 86400 7200 604800 300
+@        3600 IN NS  ns1.example.net.
+@             IN A   192.0.2.1
+www           IN CNAME @
+mail          IN MX  10 mx.example.net.
+sub           IN NS  ns.example.com.
+_sip._tcp     IN SRV 10 20 5060 sip.example.org.
+@             IN CAA 0 issue "letsencrypt.org"
+v6            IN AAAA 2001:db8::1
+txt           IN TXT "hello" " world"
+`
+
+// lines returns the records as zone file lines
+func lines(t *testing.T, records []client.Record) []string {
+	t.Helper()
+	var result []string
+	for _, record := range records {
+		line, err := zonefile.Line(testZone, record)
+		if err != nil {
+			t.Fatalf("Line(%+v) error = %v", record, err)
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	records := []string{
+		"example.org.\t300\tIN\tA\t192.0.2.1",
+		"www.example.org.\t300\tIN\tCNAME\texample.org.",
+		"mail.example.org.\t300\tIN\tMX\t10 mx.example.net.",
+		"sub.example.org.\t300\tIN\tNS\tns.example.com.",
+		"_sip._tcp.example.org.\t300\tIN\tSRV\t10 20 5060 sip.example.org.",
+		"example.org.\t300\tIN\tCAA\t0 issue \"letsencrypt.org\"",
+		"v6.example.org.\t300\tIN\tAAAA\t2001:db8::1",
+		"txt.example.org.\t300\tIN\tTXT\t\"hello world\"",
+	}
+	tests := []struct {
+		name      string
+		withSOANS bool
+		want      []string
+	}{
+		{
+			// The apex SOA and NS records are managed by TofuDNS, but the
+			// delegation of a subzone isn't
+			name: "without SOA and NS",
+			want: records,
+		},
+		{
+			name:      "with SOA and NS",
+			withSOANS: true,
+			want: append([]string{
+				"example.org.\t3600\tIN\tSOA\tns1.example.net. hostmaster.example.net. 0 86400 7200 604800 300",
+				"example.org.\t3600\tIN\tNS\tns1.example.net.",
+			}, records...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := zonefile.Parse(strings.NewReader(testZoneFile), "example.org", "test.zone", tt.withSOANS)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := lines(t, parsed); !slices.Equal(got, tt.want) {
+				t.Errorf("Parse() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "unsupported type", file: "@ IN HINFO \"x86\" \"Linux\"\n", want: "unsupported record type HINFO"},
+		{name: "invalid address", file: "www IN A 192.0.2.300\n", want: "bad A A"},
+		{name: "missing preference", file: "mail IN MX mx.example.net.\n", want: "bad MX Pref"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := zonefile.Parse(strings.NewReader(tt.file), testZone, "test.zone", false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	longText := strings.Repeat("0123456789", 30)
+	records := []client.Record{
+		{Name: client.ApexName, Type: "A", TTL: 300, A: &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.1")}}},
+		{Name: "v6", Type: "AAAA", TTL: 300, AAAA: &client.AAAAData{Ip: client.IPAddr{IP: net.ParseIP("2001:db8::1")}}},
+		{Name: "www", Type: "CNAME", TTL: 300, CNAME: &client.CNAMEData{Host: "example.org."}},
+		{Name: "", Type: "MX", TTL: 300, MX: &client.MXData{Host: "mx.example.net.", Preference: 10}},
+		{Name: "_sip._tcp", Type: "SRV", TTL: 300, SRV: &client.SRVData{Priority: 10, Weight: 20, Port: 5060, Target: "sip.example.org."}},
+		{Name: "", Type: "CAA", TTL: 300, CAA: &client.CAAData{Flag: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		// Long TXT values are split into character strings and joined again
+		{Name: "long", Type: "TXT", TTL: 300, TXT: &client.TXTData{Text: longText}},
+		{Name: "quoted", Type: "TXT", TTL: 300, TXT: &client.TXTData{Text: `say "hi" \o/`}},
+		{Name: "unicode", Type: "TXT", TTL: 300, TXT: &client.TXTData{Text: "grüße\tdir"}},
+		{Name: "absolute.example.org.", Type: "A", TTL: 60, A: &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.2")}}},
+	}
+
+	var b strings.Builder
+	if err := zonefile.Write(&b, "example.org", records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	parsed, err := zonefile.Parse(strings.NewReader(b.String()), testZone, "test.zone", true)
+	if err != nil {
+		t.Fatalf("Parse() of the written zone file error = %v\n%s", err, b.String())
+	}
+	if got, want := lines(t, parsed), lines(t, records); !slices.Equal(got, want) {
+		t.Errorf("round trip =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, record := range parsed {
+		if record.Type != "TXT" {
+			continue
+		}
+		want := map[string]string{
+			"long.example.org.":    longText,
+			"quoted.example.org.":  `say "hi" \o/`,
+			"unicode.example.org.": "grüße\tdir",
+		}[record.Name]
+		if record.TXT.Text != want {
+			t.Errorf("%s TXT = %q, want %q", record.Name, record.TXT.Text, want)
+		}
+	}
+}
+
+func TestWriteSkipsInvalidRecords(t *testing.T) {
+	records := []client.Record{
+		{ID: 1, Name: "www", Type: "A", TTL: 300, A: &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.1")}}},
+		{ID: 2, Name: "bad", Type: "A", TTL: 300, ContentError: "invalid A record content"},
+		{ID: 3, Name: "empty", Type: "MX", TTL: 300},
+		{ID: 4, Name: "old", Type: "HINFO", TTL: 300},
+	}
+	var b strings.Builder
+	if err := zonefile.Write(&b, testZone, records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := strings.Join([]string{
+		"$ORIGIN example.org.",
+		"www.example.org.\t300\tIN\tA\t192.0.2.1",
+		"; skipped record 2: invalid A record content",
+		"; skipped record 3: MX record has no content",
+		"; skipped record 4: unsupported record type HINFO",
+		"",
+	}, "\n")
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestParseRData(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      string
+		recordType string
+		rdata      []string
+		want       []string
+	}{
+		{
+			name:       "addresses",
+			owner:      "www",
+			recordType: "A",
+			rdata:      []string{"192.0.2.1", "192.0.2.2"},
+			want:       []string{"www.example.org.\t300\tIN\tA\t192.0.2.1", "www.example.org.\t300\tIN\tA\t192.0.2.2"},
+		},
+		{
+			name:       "apex MX",
+			owner:      "@",
+			recordType: "MX",
+			rdata:      []string{"10 mx.example.net."},
+			want:       []string{"example.org.\t300\tIN\tMX\t10 mx.example.net."},
+		},
+		{
+			// An unquoted TXT value is a single string, even with spaces
+			name:       "unquoted TXT",
+			owner:      "txt",
+			recordType: "TXT",
+			rdata:      []string{`v=spf1 include:"x" -all`},
+			want:       []string{"txt.example.org.\t300\tIN\tTXT\t\"v=spf1 include:\\\"x\\\" -all\""},
+		},
+		{
+			name:       "quoted TXT",
+			owner:      "txt",
+			recordType: "TXT",
+			rdata:      []string{`"one" "two"`},
+			want:       []string{"txt.example.org.\t300\tIN\tTXT\t\"onetwo\""},
+		},
+		{
+			name:       "escaped TXT",
+			owner:      "txt",
+			recordType: "TXT",
+			rdata:      []string{`"a\"b\\c\065"`},
+			want:       []string{"txt.example.org.\t300\tIN\tTXT\t\"a\\\"b\\\\cA\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := zonefile.ParseRData(testZone, tt.owner, tt.recordType, 300, tt.rdata)
+			if err != nil {
+				t.Fatalf("ParseRData() error = %v", err)
+			}
+			if got := lines(t, records); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseRData() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := zonefile.ParseRData(testZone, "mail", "MX", 300, []string{"mx.example.net."}); err == nil {
+		t.Errorf("ParseRData() of invalid rdata succeeded")
+	}
+}
+
+func TestRData(t *testing.T) {
+	tests := []struct {
+		record client.Record
+		want   string
+	}{
+		{record: client.Record{Name: "www", Type: "A", TTL: 300, A: &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.1")}}}, want: "192.0.2.1"},
+		{record: client.Record{Name: "", Type: "MX", TTL: 300, MX: &client.MXData{Host: "mx.example.net", Preference: 10}}, want: "10 mx.example.net."},
+		{record: client.Record{Name: "txt", Type: "TXT", TTL: 300, TXT: &client.TXTData{Text: "hello world"}}, want: `"hello world"`},
+		{record: client.Record{Name: "", Type: "SOA", TTL: 3600, SOA: &client.SOAData{Ns: "ns1.example.net.", MBox: "hostmaster.example.net.", Refresh: 86400, Retry: 7200, Expire: 604800, MinTtl: 300}}, want: "ns1.example.net. hostmaster.example.net. 0 86400 7200 604800 300"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.record.Type, func(t *testing.T) {
+			got, err := zonefile.RData(testZone, tt.record)
+			if err != nil {
+				t.Fatalf("RData() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RData() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := zonefile.RData(testZone, client.Record{Type: "A", ContentError: "invalid A record content"}); err == nil {
+		t.Errorf("RData() of a record with a content error succeeded")
+	}
+}
@@ -0,0 +1,131 @@
+// Package provider implements the TofuDNS Terraform provider on the API
+// client.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tofudns/tofudns/pkg/client"
+)
+
+// defaultURL is the API URL used when none is configured
+const defaultURL = "https://tofudns.net"
+
+// tofudnsProvider is the TofuDNS provider
+type tofudnsProvider struct {
+	version string
+}
+
+// providerModel is the provider configuration
+type providerModel struct {
+	URL   types.String `tfsdk:"url"`
+	Token types.String `tfsdk:"token"`
+}
+
+// New returns a function creating the provider of the version
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &tofudnsProvider{version: version}
+	}
+}
+
+// Metadata implements provider.Provider
+func (p *tofudnsProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "tofudns"
+	resp.Version = p.version
+}
+
+// Schema implements provider.Provider
+func (p *tofudnsProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages zones and records on TofuDNS.",
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				Description: "URL of the TofuDNS instance. Defaults to TOFUDNS_URL, then " + defaultURL + ".",
+				Optional:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "API token, created on the account page. Defaults to TOFUDNS_TOKEN.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// Configure creates the API client used by the resources
+func (p *tofudnsProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	url := configValue(config.URL, "TOFUDNS_URL", defaultURL)
+	token := configValue(config.Token, "TOFUDNS_TOKEN", "")
+	if token == "" {
+		resp.Diagnostics.AddError("Missing API token",
+			"Set the provider's token or TOFUDNS_TOKEN to an API token created on the TofuDNS account page.")
+		return
+	}
+
+	api, err := client.New(url, token, client.WithUserAgent("terraform-provider-tofudns/"+p.version))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create API client", err.Error())
+		return
+	}
+	resp.ResourceData = api
+	resp.DataSourceData = api
+}
+
+// Resources implements provider.Provider
+func (p *tofudnsProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newZoneResource,
+		newRecordResource,
+		newRecordSetResource,
+	}
+}
+
+// DataSources implements provider.Provider
+func (p *tofudnsProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+// configValue returns the configured value, falling back to the environment
+// variable and then the default
+func configValue(value types.String, env, def string) string {
+	if !value.IsNull() && !value.IsUnknown() && value.ValueString() != "" {
+		return value.ValueString()
+	}
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return def
+}
+
+// apiResource holds the API client of a resource
+type apiResource struct {
+	api *client.Client
+}
+
+// Configure takes the API client from the provider
+func (r *apiResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// The provider isn't configured yet while validating
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	r.api = api
+}
@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// The acceptance tests run with TF_ACC set against the server in TOFUDNS_URL,
+// such as a local one started with task run, with an API token of a user of
+// that server in TOFUDNS_TOKEN. Every test creates a zone of its own.
+
+// testProviders are the providers the acceptance tests run Terraform with
+var testProviders = map[string]func() (tfprotov6.ProviderServer, error){
+	"tofudns": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testPreCheck fails acceptance tests without a server to run against
+func testPreCheck(t *testing.T) {
+	t.Helper()
+	if os.Getenv("TOFUDNS_URL") == "" || os.Getenv("TOFUDNS_TOKEN") == "" {
+		t.Fatal("TOFUDNS_URL and TOFUDNS_TOKEN must be set for acceptance tests")
+	}
+}
+
+// testClient returns a client of the server the tests run against, to change
+// resources outside of Terraform
+func testClient(t *testing.T) *client.Client {
+	t.Helper()
+	api, err := client.New(os.Getenv("TOFUDNS_URL"), os.Getenv("TOFUDNS_TOKEN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+// testRecord returns the record with the ID in the state
+func testRecord(t *testing.T, zone, id string) *client.Record {
+	t.Helper()
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := testClient(t).GetRecord(context.Background(), zone, recordID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// testZone returns the name of a zone no other test uses
+func testZone() string {
+	return acctest.RandomWithPrefix("tf-acc") + ".test"
+}
+
+// testCheckZoneDestroyed checks that the zones in the state are deleted
+func testCheckZoneDestroyed(t *testing.T) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		api := testClient(t)
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "tofudns_zone" {
+				continue
+			}
+			_, err := api.GetZone(context.Background(), rs.Primary.ID)
+			if err == nil {
+				return fmt.Errorf("zone %s still exists", rs.Primary.ID)
+			}
+			if !errors.Is(err, client.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func testZoneConfig(zone string) string {
+	return fmt.Sprintf(`
+resource "tofudns_zone" "test" {
+  name = %q
+}
+`, zone)
+}
+
+func TestAccZone(t *testing.T) {
+	zone := testZone()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testPreCheck(t) },
+		ProtoV6ProviderFactories: testProviders,
+		CheckDestroy:             testCheckZoneDestroyed(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testZoneConfig(zone),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tofudns_zone.test", "id", zone+"."),
+					resource.TestCheckResourceAttr("tofudns_zone.test", "name", zone),
+					resource.TestCheckResourceAttrSet("tofudns_zone.test", "status"),
+					resource.TestCheckResourceAttrSet("tofudns_zone.test", "nameservers.#"),
+				),
+			},
+			{
+				ResourceName:      "tofudns_zone.test",
+				ImportState:       true,
+				ImportStateId:     zone,
+				ImportStateVerify: true,
+			},
+			{
+				// A zone deleted outside of Terraform is created again
+				PreConfig: func() {
+					if err := testClient(t).DeleteZone(context.Background(), zone); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testZoneConfig(zone),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_zone.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testRecordConfig(zone string, ttl int, content string) string {
+	return testZoneConfig(zone) + fmt.Sprintf(`
+resource "tofudns_record" "test" {
+  zone    = tofudns_zone.test.name
+  name    = "www"
+  type    = "A"
+  ttl     = %d
+  content = %q
+}
+`, ttl, content)
+}
+
+func TestAccRecord(t *testing.T) {
+	zone := testZone()
+	var id string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testPreCheck(t) },
+		ProtoV6ProviderFactories: testProviders,
+		CheckDestroy:             testCheckZoneDestroyed(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testRecordConfig(zone, 300, "192.0.2.1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("tofudns_record.test", "id", func(value string) error {
+						id = value
+						return nil
+					}),
+					resource.TestCheckResourceAttr("tofudns_record.test", "ttl", "300"),
+					resource.TestCheckResourceAttr("tofudns_record.test", "content", "192.0.2.1"),
+					resource.TestCheckResourceAttr("tofudns_record.test", "version", "1"),
+				),
+			},
+			{
+				Config: testRecordConfig(zone, 600, "192.0.2.2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("tofudns_record.test", "id", &id),
+					resource.TestCheckResourceAttr("tofudns_record.test", "ttl", "600"),
+					resource.TestCheckResourceAttr("tofudns_record.test", "content", "192.0.2.2"),
+					resource.TestCheckResourceAttr("tofudns_record.test", "version", "2"),
+				),
+			},
+			{
+				ResourceName:      "tofudns_record.test",
+				ImportState:       true,
+				ImportStateIdFunc: func(*terraform.State) (string, error) { return zone + "/" + id, nil },
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "tofudns_record.test",
+				ImportState:       true,
+				ImportStateId:     zone + "/www/A",
+				ImportStateVerify: true,
+			},
+			{
+				// A record changed outside of Terraform is changed back
+				PreConfig: func() {
+					record := testRecord(t, zone, id)
+					record.A = &client.AData{Ip: client.IPAddr{IP: net.ParseIP("192.0.2.3")}}
+					if _, err := testClient(t).UpdateRecord(context.Background(), zone, record); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testRecordConfig(zone, 600, "192.0.2.2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("tofudns_record.test", "id", &id),
+					resource.TestCheckResourceAttr("tofudns_record.test", "content", "192.0.2.2"),
+					resource.TestCheckResourceAttr("tofudns_record.test", "version", "4"),
+				),
+			},
+			{
+				// A record deleted outside of Terraform is created again
+				PreConfig: func() {
+					record := testRecord(t, zone, id)
+					if err := testClient(t).DeleteRecord(context.Background(), zone, record.ID, record.Version); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testRecordConfig(zone, 600, "192.0.2.2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testRecordSetConfig(zone string, records ...string) string {
+	values := ""
+	for _, record := range records {
+		values += strconv.Quote(record) + ", "
+	}
+	return testZoneConfig(zone) + fmt.Sprintf(`
+resource "tofudns_record_set" "test" {
+  zone    = tofudns_zone.test.name
+  name    = "@"
+  type    = "MX"
+  records = [%s]
+}
+`, values)
+}
+
+func TestAccRecordSet(t *testing.T) {
+	zone := testZone()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testPreCheck(t) },
+		ProtoV6ProviderFactories: testProviders,
+		CheckDestroy:             testCheckZoneDestroyed(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testRecordSetConfig(zone, "10 mx1.example.com.", "20 mx2.example.com."),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tofudns_record_set.test", "id", zone+"/@/MX"),
+					resource.TestCheckResourceAttr("tofudns_record_set.test", "records.#", "2"),
+					resource.TestCheckTypeSetElemAttr("tofudns_record_set.test", "records.*", "10 mx1.example.com."),
+				),
+			},
+			{
+				Config: testRecordSetConfig(zone, "10 mx1.example.com.", "30 mx3.example.com."),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record_set.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tofudns_record_set.test", "records.#", "2"),
+					resource.TestCheckTypeSetElemAttr("tofudns_record_set.test", "records.*", "30 mx3.example.com."),
+				),
+			},
+			{
+				ResourceName:      "tofudns_record_set.test",
+				ImportState:       true,
+				ImportStateId:     zone + "/@/MX",
+				ImportStateVerify: true,
+			},
+			{
+				// Records added outside of Terraform are removed
+				PreConfig: func() {
+					records, err := parseRecords(zone, "@", "MX", zonefile.DefaultTTL, []string{
+						"10 mx1.example.com.", "30 mx3.example.com.", "40 mx4.example.com.",
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					_, err = testClient(t).ReplaceRRSet(context.Background(), zone, "@", "MX", zonefile.DefaultTTL, records)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testRecordSetConfig(zone, "10 mx1.example.com.", "30 mx3.example.com."),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record_set.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("tofudns_record_set.test", "records.#", "2"),
+			},
+			{
+				// Records deleted outside of Terraform are created again
+				PreConfig: func() {
+					if err := testClient(t).DeleteRRSet(context.Background(), zone, "@", "MX"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testRecordSetConfig(zone, "10 mx1.example.com.", "30 mx3.example.com."),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tofudns_record_set.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// Records are configured by their rdata as written in a zone file, such as
+// "10 mail.example.com." for an MX record. The API returns hostnames as
+// absolute names, so configured values are kept in the state as long as
+// they are equivalent to the records read back.
+
+// parseRecords parses the records with the owner name, type and rdata values
+func parseRecords(zone, name, recordType string, ttl int64, rdata []string) ([]client.Record, error) {
+	if name == "" {
+		name = client.ApexName
+	}
+	records, err := zonefile.ParseRData(zone, name, strings.ToUpper(recordType), uint32(ttl), rdata)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != len(rdata) {
+		return nil, fmt.Errorf("expected %d records, got %d", len(rdata), len(records))
+	}
+	return records, nil
+}
+
+// normalRData returns the rdata of a record in a form that compares equal
+// for equivalent values. Only TXT and CAA values are case sensitive.
+func normalRData(zone string, record client.Record) (string, error) {
+	rdata, err := zonefile.RData(zone, record)
+	if err != nil {
+		return "", err
+	}
+	if record.Type != "TXT" && record.Type != "CAA" {
+		rdata = strings.ToLower(rdata)
+	}
+	return rdata, nil
+}
+
+// configuredRData returns the rdata of the records, using the configured
+// value for records equivalent to one
+func configuredRData(zone, name, recordType string, configured []string, records []client.Record) ([]string, error) {
+	byNormal := make(map[string]string)
+	for _, value := range configured {
+		parsed, err := parseRecords(zone, name, recordType, zonefile.DefaultTTL, []string{value})
+		if err != nil {
+			continue
+		}
+		normal, err := normalRData(zone, parsed[0])
+		if err != nil {
+			continue
+		}
+		byNormal[normal] = value
+	}
+
+	values := make([]string, len(records))
+	for i, record := range records {
+		normal, err := normalRData(zone, record)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := byNormal[normal]; ok {
+			values[i] = value
+			continue
+		}
+		values[i], err = zonefile.RData(zone, record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// sameName reports whether two owner names of records in the zone are the
+// same, whether relative, absolute or @ for the apex
+func sameName(zone, a, b string) bool {
+	absolute := func(name string) string {
+		origin := dns.Fqdn(zone)
+		switch {
+		case name == "" || name == client.ApexName:
+			return origin
+		case strings.HasSuffix(name, "."):
+			return name
+		default:
+			return name + "." + origin
+		}
+	}
+	return strings.EqualFold(absolute(a), absolute(b))
+}
+
+// recordName returns the name of a record read from the API as configured,
+// @ for the apex
+func recordName(record client.Record) string {
+	name := record.NameUnicode
+	if name == "" {
+		name = record.Name
+	}
+	if name == "" {
+		return client.ApexName
+	}
+	return name
+}
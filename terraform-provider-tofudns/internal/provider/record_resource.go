@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// recordResource manages a single record. Records keep their ID when they
+// are changed, so changing any attribute but the zone updates them in place.
+type recordResource struct {
+	apiResource
+}
+
+// recordResourceModel is the state of a record
+type recordResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Zone    types.String `tfsdk:"zone"`
+	Name    types.String `tfsdk:"name"`
+	Type    types.String `tfsdk:"type"`
+	TTL     types.Int64  `tfsdk:"ttl"`
+	Content types.String `tfsdk:"content"`
+	Version types.Int64  `tfsdk:"version"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &recordResource{}
+	_ resource.ResourceWithImportState = &recordResource{}
+)
+
+func newRecordResource() resource.Resource {
+	return &recordResource{}
+}
+
+// Metadata implements resource.Resource
+func (r *recordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record"
+}
+
+// Schema implements resource.Resource
+func (r *recordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A single record. Records of an RRset share their TTL, so changing the TTL of one changes it for all records with the same name and type.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "ID of the record, which stays the same when the record is updated.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"zone": schema.StringAttribute{
+				Description: "Name of the zone.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Owner name of the record, relative to the zone or absolute with a trailing dot. @ is the zone apex.",
+				Required:    true,
+			},
+			"type": schema.StringAttribute{
+				Description: "Type of the record, such as A or MX.",
+				Required:    true,
+			},
+			"ttl": schema.Int64Attribute{
+				Description: "TTL of the record in seconds.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(zonefile.DefaultTTL),
+			},
+			"content": schema.StringAttribute{
+				Description: "Rdata of the record as written in a zone file, such as 192.0.2.1 or \"10 mail.example.com.\". A TXT value is taken as a single string unless quoted.",
+				Required:    true,
+			},
+			"version": schema.Int64Attribute{
+				Description: "Version of the record, incremented on every change. Updates fail if the record was changed outside of Terraform since it was read.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource
+func (r *recordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan recordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	record, diags := plan.record()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	created, err := r.api.CreateRecord(ctx, plan.Zone.ValueString(), record)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create record", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(strconv.FormatInt(created.ID, 10))
+	plan.Version = types.Int64Value(int64(created.Version))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements resource.Resource
+func (r *recordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state recordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id, err := strconv.ParseInt(state.ID.ValueString(), 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid record ID", err.Error())
+		return
+	}
+
+	record, err := r.api.GetRecord(ctx, state.Zone.ValueString(), id)
+	if errors.Is(err, client.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read record", err.Error())
+		return
+	}
+
+	// Keep the configured form of the name and content if they are
+	// equivalent to the record's
+	if state.Name.IsNull() || !sameName(record.Zone, state.Name.ValueString(), record.Name) {
+		state.Name = types.StringValue(recordName(*record))
+	}
+	if !strings.EqualFold(state.Type.ValueString(), record.Type) {
+		state.Type = types.StringValue(record.Type)
+	}
+	var configured []string
+	if !state.Content.IsNull() {
+		configured = []string{state.Content.ValueString()}
+	}
+	content, err := configuredRData(record.Zone, record.Name, record.Type, configured, []client.Record{*record})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read record", err.Error())
+		return
+	}
+	state.Content = types.StringValue(content[0])
+	state.TTL = types.Int64Value(int64(record.TTL))
+	state.Version = types.Int64Value(int64(record.Version))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update implements resource.Resource. The record is only updated if it
+// hasn't changed since it was read.
+func (r *recordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state recordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	record, diags := plan.record()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id, err := strconv.ParseInt(state.ID.ValueString(), 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid record ID", err.Error())
+		return
+	}
+	record.ID = id
+	record.Version = int32(state.Version.ValueInt64())
+
+	updated, err := r.api.UpdateRecord(ctx, plan.Zone.ValueString(), record)
+	if errors.Is(err, client.ErrRecordChanged) {
+		resp.Diagnostics.AddError("Record changed",
+			"The record was changed outside of Terraform since it was read. Refresh the state and plan again.")
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to update record", err.Error())
+		return
+	}
+
+	plan.ID = state.ID
+	plan.Version = types.Int64Value(int64(updated.Version))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements resource.Resource
+func (r *recordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state recordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id, err := strconv.ParseInt(state.ID.ValueString(), 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid record ID", err.Error())
+		return
+	}
+
+	err = r.api.DeleteRecord(ctx, state.Zone.ValueString(), id, int32(state.Version.ValueInt64()))
+	if errors.Is(err, client.ErrRecordChanged) {
+		resp.Diagnostics.AddError("Record changed",
+			"The record was changed outside of Terraform since it was read. Refresh the state and plan again.")
+		return
+	}
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		resp.Diagnostics.AddError("Failed to delete record", err.Error())
+	}
+}
+
+// ImportState imports a record by its zone and ID, such as example.com/42,
+// or by its zone, name and type if it is the only record of its RRset, such
+// as example.com/www/CNAME
+func (r *recordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	var zone, id string
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		zone, id = parts[0], parts[1]
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("invalid record ID %q", id))
+			return
+		}
+
+	case len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != "":
+		zone = parts[0]
+		records, err := r.api.FindRecords(ctx, zone, parts[1], parts[2])
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to find record", err.Error())
+			return
+		}
+		if len(records) != 1 {
+			resp.Diagnostics.AddError("Ambiguous import ID",
+				fmt.Sprintf("%s has %d records, import a single record by its ID, or the RRset as a tofudns_record_set", req.ID, len(records)))
+			return
+		}
+		id = strconv.FormatInt(records[0].ID, 10)
+
+	default:
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("expected zone/id or zone/name/type, such as example.com/42 or example.com/www/CNAME, got %q", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("zone"), zone)...)
+}
+
+// record parses the configured record
+func (m *recordResourceModel) record() (*client.Record, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	records, err := parseRecords(m.Zone.ValueString(), m.Name.ValueString(), m.Type.ValueString(), m.TTL.ValueInt64(), []string{m.Content.ValueString()})
+	if err != nil {
+		diags.AddAttributeError(path.Root("content"), "Invalid record", err.Error())
+		return nil, diags
+	}
+	return &records[0], diags
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tofudns/tofudns/pkg/client"
+	"github.com/tofudns/tofudns/pkg/zonefile"
+)
+
+// recordSetResource manages all records of a name and type, which share a
+// TTL
+type recordSetResource struct {
+	apiResource
+}
+
+// recordSetResourceModel is the state of an RRset
+type recordSetResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Zone    types.String `tfsdk:"zone"`
+	Name    types.String `tfsdk:"name"`
+	Type    types.String `tfsdk:"type"`
+	TTL     types.Int64  `tfsdk:"ttl"`
+	Records types.Set    `tfsdk:"records"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &recordSetResource{}
+	_ resource.ResourceWithImportState = &recordSetResource{}
+)
+
+func newRecordSetResource() resource.Resource {
+	return &recordSetResource{}
+}
+
+// Metadata implements resource.Resource
+func (r *recordSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_set"
+}
+
+// Schema implements resource.Resource
+func (r *recordSetResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "All records of a name and type in a zone. Records of the set that keep their content keep their IDs, and changed records are updated in place.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The zone, name and type, separated by slashes. Existing RRsets are imported by this ID, such as example.com/www/A.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"zone": schema.StringAttribute{
+				Description: "Name of the zone.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Owner name of the records, relative to the zone or absolute with a trailing dot. @ is the zone apex.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "Type of the records, such as A or MX.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.Int64Attribute{
+				Description: "TTL of the records in seconds.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(zonefile.DefaultTTL),
+			},
+			"records": schema.SetAttribute{
+				Description: "Rdata of the records as written in a zone file, such as 192.0.2.1 or \"10 mail.example.com.\". TXT values are taken as a single string unless quoted.",
+				ElementType: types.StringType,
+				Required:    true,
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource
+func (r *recordSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan recordSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	zone, name, recordType := plan.Zone.ValueString(), plan.Name.ValueString(), plan.Type.ValueString()
+
+	current, err := r.api.GetRRSet(ctx, zone, name, recordType)
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		resp.Diagnostics.AddError("Failed to read records", err.Error())
+		return
+	}
+	if err == nil {
+		resp.Diagnostics.AddError("Records already exist",
+			fmt.Sprintf("%s already has %d %s records. Import them with the ID %s.", name, len(current.Records), recordType, recordSetID(zone, name, recordType)))
+		return
+	}
+
+	desired, diags := plan.records(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if _, err := r.api.ReplaceRRSet(ctx, zone, name, recordType, int32(plan.TTL.ValueInt64()), desired); err != nil {
+		resp.Diagnostics.AddError("Failed to create records", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(recordSetID(zone, name, recordType))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements resource.Resource
+func (r *recordSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state recordSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	name := state.Name.ValueString()
+
+	rrset, err := r.api.GetRRSet(ctx, state.Zone.ValueString(), name, state.Type.ValueString())
+	if errors.Is(err, client.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read records", err.Error())
+		return
+	}
+
+	var configured []string
+	if !state.Records.IsNull() {
+		resp.Diagnostics.Append(state.Records.ElementsAs(ctx, &configured, false)...)
+	}
+	values, err := configuredRData(rrset.Zone, name, rrset.Type, configured, rrset.Records)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read records", err.Error())
+		return
+	}
+	set, diags := types.SetValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	state.Records = set
+	state.TTL = types.Int64Value(int64(rrset.TTL))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update implements resource.Resource. It replaces the RRset with the
+// configured records atomically, so the records change at once.
+func (r *recordSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan recordSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	desired, diags := plan.records(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, err := r.api.ReplaceRRSet(ctx, plan.Zone.ValueString(), plan.Name.ValueString(), plan.Type.ValueString(),
+		int32(plan.TTL.ValueInt64()), desired)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to update records", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements resource.Resource
+func (r *recordSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state recordSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.api.DeleteRRSet(ctx, state.Zone.ValueString(), state.Name.ValueString(), state.Type.ValueString())
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		resp.Diagnostics.AddError("Failed to delete records", err.Error())
+	}
+}
+
+// ImportState imports an RRset by its zone, name and type, such as
+// example.com/www/A
+func (r *recordSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("expected zone/name/type, such as example.com/www/A, got %q", req.ID))
+		return
+	}
+	zone, name, recordType := parts[0], parts[1], strings.ToUpper(parts[2])
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), recordSetID(zone, name, recordType))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("zone"), zone)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("type"), recordType)...)
+}
+
+// records parses the configured records
+func (m *recordSetResourceModel) records(ctx context.Context) ([]client.Record, diag.Diagnostics) {
+	var values []string
+	diags := m.Records.ElementsAs(ctx, &values, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+	records, err := parseRecords(m.Zone.ValueString(), m.Name.ValueString(), m.Type.ValueString(), m.TTL.ValueInt64(), values)
+	if err != nil {
+		diags.AddAttributeError(path.Root("records"), "Invalid records", err.Error())
+	}
+	return records, diags
+}
+
+// recordSetID returns the ID of an RRset
+func recordSetID(zone, name, recordType string) string {
+	if name == "" {
+		name = client.ApexName
+	}
+	return zone + "/" + name + "/" + recordType
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tofudns/tofudns/pkg/client"
+)
+
+// zoneResource manages a zone. Zones stay pending, and aren't served, until
+// their ownership is verified with the TXT record they carry.
+type zoneResource struct {
+	apiResource
+}
+
+// zoneResourceModel is the state of a zone
+type zoneResourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Name                 types.String `tfsdk:"name"`
+	NameUnicode          types.String `tfsdk:"name_unicode"`
+	Status               types.String `tfsdk:"status"`
+	Nameservers          types.List   `tfsdk:"nameservers"`
+	VerificationTXTName  types.String `tfsdk:"verification_txt_name"`
+	VerificationTXTValue types.String `tfsdk:"verification_txt_value"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &zoneResource{}
+	_ resource.ResourceWithImportState = &zoneResource{}
+)
+
+func newZoneResource() resource.Resource {
+	return &zoneResource{}
+}
+
+// Metadata implements resource.Resource
+func (r *zoneResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone"
+}
+
+// Schema implements resource.Resource
+func (r *zoneResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A zone. New zones are pending until the TXT record in verification_txt_name and verification_txt_value is published at the zone's current DNS provider.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Canonical name of the zone, with a trailing dot.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Name of the zone, such as example.com.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name_unicode": schema.StringAttribute{
+				Description: "Name of the zone with internationalized labels in Unicode.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"status": schema.StringAttribute{
+				Description: "Status of the zone, pending or active.",
+				Computed:    true,
+			},
+			"nameservers": schema.ListAttribute{
+				Description: "Nameservers the zone must be delegated to.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"verification_txt_name": schema.StringAttribute{
+				Description: "Name of the TXT record proving the ownership of a pending zone.",
+				Computed:    true,
+			},
+			"verification_txt_value": schema.StringAttribute{
+				Description: "Value of the TXT record proving the ownership of a pending zone.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource
+func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan zoneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := r.api.CreateZone(ctx, plan.Name.ValueString())
+	if errors.Is(err, client.ErrConflict) {
+		resp.Diagnostics.AddError("Zone already exists",
+			"The zone already exists or is used by another account. Existing zones can be imported by name.")
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create zone", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(plan.set(ctx, zone)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements resource.Resource
+func (r *zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state zoneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := r.api.GetZone(ctx, state.ID.ValueString())
+	if errors.Is(err, client.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read zone", err.Error())
+		return
+	}
+
+	// Imported zones take their name as configured, without the trailing
+	// dot
+	if state.Name.IsNull() {
+		state.Name = types.StringValue(strings.TrimSuffix(zone.NameUnicode, "."))
+	}
+	resp.Diagnostics.Append(state.set(ctx, zone)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update implements resource.Resource. Changing the name replaces the zone,
+// so it only refreshes the computed attributes.
+func (r *zoneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan zoneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := r.api.GetZone(ctx, plan.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read zone", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(plan.set(ctx, zone)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements resource.Resource
+func (r *zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state zoneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.api.DeleteZone(ctx, state.ID.ValueString())
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		resp.Diagnostics.AddError("Failed to delete zone", err.Error())
+	}
+}
+
+// ImportState imports a zone by its name
+func (r *zoneResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// set sets the computed attributes from the zone
+func (m *zoneResourceModel) set(ctx context.Context, zone *client.Zone) diag.Diagnostics {
+	m.ID = types.StringValue(zone.Name)
+	m.NameUnicode = types.StringValue(zone.NameUnicode)
+	m.Status = types.StringValue(zone.Status)
+	m.VerificationTXTName = types.StringNull()
+	m.VerificationTXTValue = types.StringNull()
+	if zone.Verification != nil {
+		m.VerificationTXTName = types.StringValue(zone.Verification.TXTName)
+		m.VerificationTXTValue = types.StringValue(zone.Verification.TXTValue)
+	}
+
+	nameservers, diags := types.ListValueFrom(ctx, types.StringType, zone.Nameservers)
+	m.Nameservers = nameservers
+	return diags
+}
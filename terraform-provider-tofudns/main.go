@@ -0,0 +1,27 @@
+// Command terraform-provider-tofudns is the Terraform provider of TofuDNS.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/tofudns/tofudns/terraform-provider-tofudns/internal/provider"
+)
+
+// version is set when releases are built
+var version = "dev"
+
+func main() {
+	debug := flag.Bool("debug", false, "run the provider with support for debuggers")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
+		Address: "registry.terraform.io/tofudns/tofudns",
+		Debug:   *debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}